@@ -0,0 +1,506 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"goaltracker/config"
+	"goaltracker/database"
+	"goaltracker/models"
+	"goaltracker/services"
+
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage: goaltracker <command> [flags]
+
+Commands:
+  serve                      run the HTTP API (default)
+  migrate up|down|status     apply, revert or inspect schema migrations
+  migrate create name        scaffold the next migration files
+  seed                       apply catalog fixtures and reference data
+  catalog import|export      load or dump catalog fixture packs
+  catalog framework          import SFIA, NICE or O*NET files into the catalog
+  user export|delete         GDPR export or immediate erasure of one user
+  admin grant|revoke|list    manage admin grants
+
+Run "goaltracker <command> -h" for the flags of a command.
+`
+
+var migrationName = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// runCommand dispatches the command line. No arguments means serve, so the
+// container entrypoint keeps working unchanged.
+func runCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return serve(cfg, nil)
+	}
+	cmd, rest := args[0], args[1:]
+	switch cmd {
+	case "serve":
+		return serve(cfg, rest)
+	case "migrate":
+		return runSubcommand(cfg, "migrate", rest, map[string]func(*config.Config, []string) error{
+			"up": migrateUp, "down": migrateDown, "status": migrateStatus, "create": migrateCreate,
+		})
+	case "seed":
+		return seed(cfg, rest)
+	case "catalog":
+		return runSubcommand(cfg, "catalog", rest, map[string]func(*config.Config, []string) error{
+			"import": catalogImport, "export": catalogExport, "framework": catalogFramework,
+		})
+	case "user":
+		return runSubcommand(cfg, "user", rest, map[string]func(*config.Config, []string) error{
+			"export": userExport, "delete": userDelete,
+		})
+	case "admin":
+		return runSubcommand(cfg, "admin", rest, map[string]func(*config.Config, []string) error{
+			"grant": adminGrant, "revoke": adminRevoke, "list": adminList,
+		})
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+		return nil
+	}
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+func runSubcommand(cfg *config.Config, name string, args []string, subs map[string]func(*config.Config, []string) error) error {
+	names := make([]string, 0, len(subs))
+	for n := range subs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(args) == 0 {
+		return fmt.Errorf("%s needs a subcommand: %s", name, strings.Join(names, ", "))
+	}
+	run, ok := subs[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown %s subcommand %q (want %s)", name, args[0], strings.Join(names, ", "))
+	}
+	return run(cfg, args[1:])
+}
+
+// newFlags returns a flag set that reports parse errors instead of exiting.
+func newFlags(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: goaltracker %s\n", synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses args, treating -h as success.
+func parseFlags(fs *flag.FlagSet, args []string) (bool, error) {
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// userIDFlag validates a required -id flag.
+func userIDFlag(id string) error {
+	if !uuidPattern.MatchString(id) {
+		return errors.New("-id must be a user UUID")
+	}
+	return nil
+}
+
+// openOutput returns stdout for "" or "-", otherwise creates path.
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func migrateUp(cfg *config.Config, args []string) error {
+	fs := newFlags("migrate up", "migrate up [-to version] [-seed]")
+	target := fs.Int("to", 0, "stop after this version (default: apply all)")
+	withSeed := fs.Bool("seed", false, "also apply catalog fixtures and reference data")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	database.Connect(cfg)
+	done, err := database.MigrateUp(*target)
+	for _, m := range done {
+		fmt.Printf("Applied %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(done) == 0 {
+		fmt.Println("No pending migrations")
+	}
+	if *withSeed {
+		_, err := database.Seed(cfg.CatalogFixtures)
+		return err
+	}
+	return nil
+}
+
+func migrateDown(cfg *config.Config, args []string) error {
+	fs := newFlags("migrate down", "migrate down -yes [-steps n]")
+	steps := fs.Int("steps", 1, "number of migrations to revert")
+	yes := fs.Bool("yes", false, "confirm; down migrations can drop tables and data")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if *steps < 1 {
+		return errors.New("-steps must be at least 1")
+	}
+	if !*yes {
+		return errors.New("refusing to revert migrations without -yes")
+	}
+	database.Connect(cfg)
+	done, err := database.MigrateDown(*steps)
+	for _, m := range done {
+		fmt.Printf("Reverted %04d_%s\n", m.Version, m.Name)
+	}
+	return err
+}
+
+// migrateStatus prints each migration's state and fails on drift, so release
+// pipelines can gate on it.
+func migrateStatus(cfg *config.Config, args []string) error {
+	fs := newFlags("migrate status", "migrate status")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	database.Connect(cfg)
+	status, err := database.SchemaStatusReport()
+	if err != nil {
+		return err
+	}
+	for _, a := range status.Applied {
+		state := "applied"
+		if a.Baseline {
+			state = "baseline"
+		}
+		fmt.Printf("%04d_%-30s %-9s %s\n", a.Version, a.Name, state, a.AppliedAt.Format(time.RFC3339))
+	}
+	for _, m := range status.Pending {
+		fmt.Printf("%04d_%-30s pending\n", m.Version, m.Name)
+	}
+	if err := status.Drift(); err != nil {
+		return err
+	}
+	fmt.Println("Schema is up to date")
+	return nil
+}
+
+// migrateCreate scaffolds the next migration pair in the source tree.
+func migrateCreate(cfg *config.Config, args []string) error {
+	fs := newFlags("migrate create", "migrate create [-dir path] name")
+	dir := fs.String("dir", "migrations", "migrations source directory")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	name := strings.ToLower(strings.Join(fs.Args(), "_"))
+	if !migrationName.MatchString(name) {
+		return errors.New("migrate create needs a name of lowercase letters, digits and underscores")
+	}
+	existing, err := database.LoadMigrations(os.DirFS(*dir))
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(existing) > 0 {
+		next = existing[len(existing)-1].Version + 1
+	}
+	for _, dirn := range []string{"up", "down"} {
+		path := filepath.Join(*dir, fmt.Sprintf("%04d_%s.%s.sql", next, name, dirn))
+		if err := os.WriteFile(path, []byte("-- "+strings.ReplaceAll(name, "_", " ")+" ("+dirn+")\n"), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("Created " + path)
+	}
+	return nil
+}
+
+func seed(cfg *config.Config, args []string) error {
+	fs := newFlags("seed", "seed [-fixtures paths]")
+	extra := fs.String("fixtures", cfg.CatalogFixtures, "comma-separated extra catalog fixture files")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	database.Connect(cfg)
+	report, err := database.Seed(*extra)
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+func catalogImport(cfg *config.Config, args []string) error {
+	fs := newFlags("catalog import", "catalog import [-dry-run] file...")
+	dryRun := fs.Bool("dry-run", false, "report changes without writing them")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return errors.New("catalog import needs at least one fixture file")
+	}
+	packs, err := services.ReadCatalogFixtures(fs.Args())
+	if err != nil {
+		return err
+	}
+	database.Connect(cfg)
+	report, err := services.ApplyCatalogFixtures(database.DB, packs, *dryRun, time.Now())
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+func catalogExport(cfg *config.Config, args []string) error {
+	fs := newFlags("catalog export", "catalog export [-o file] [-format yaml|json] [-pack name] [-drafts]")
+	out := fs.String("o", "", "output file (default stdout)")
+	format := fs.String("format", "yaml", "yaml or json")
+	pack := fs.String("pack", "export", "pack name written in the header")
+	drafts := fs.Bool("drafts", false, "include draft rows")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if *format != "yaml" && *format != "json" {
+		return errors.New("-format must be yaml or json")
+	}
+	database.Connect(cfg)
+	fx, err := services.ExportCatalogFixture(database.DB, *pack, *drafts)
+	if err != nil {
+		return err
+	}
+	w, err := openOutput(*out)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(fx)
+	} else {
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		err = enc.Encode(fx)
+		if err == nil {
+			err = enc.Close()
+		}
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// catalogFramework imports a local copy of a competency framework; see
+// services.ParseSFIA, ParseNICE and ParseONET for the files each reads.
+func catalogFramework(cfg *config.Config, args []string) error {
+	fs := newFlags("catalog framework", "catalog framework -source sfia|nice|onet [-only ids] [-map id=slug,...] [-publish] [-dry-run] path")
+	source := fs.String("source", "", "sfia (skills CSV), nice (components JSON) or onet (text files directory)")
+	only := fs.String("only", "", "comma-separated NICE work role or O*NET occupation ids to import; onet defaults to the crosswalked ones, all imports every occupation")
+	mapping := fs.String("map", "", "comma-separated source-id=job-role-slug entries added to the built-in crosswalk")
+	publish := fs.Bool("publish", false, "publish new roles and responsibilities instead of creating drafts")
+	dryRun := fs.Bool("dry-run", false, "report changes without writing them")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("catalog framework needs one file or directory")
+	}
+	opts := services.FrameworkOptions{Crosswalk: map[string]string{}}
+	for _, id := range strings.Split(*only, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.Only = append(opts.Only, id)
+		}
+	}
+	for _, entry := range strings.Split(*mapping, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		id, slug, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(id) == "" || strings.TrimSpace(slug) == "" {
+			return fmt.Errorf("-map entry %q must be source-id=job-role-slug", entry)
+		}
+		opts.Crosswalk[id] = slug
+	}
+
+	var imp *services.FrameworkImport
+	var err error
+	switch *source {
+	case "sfia", "nice":
+		f, ferr := os.Open(fs.Arg(0))
+		if ferr != nil {
+			return ferr
+		}
+		if *source == "sfia" {
+			imp, err = services.ParseSFIA(f)
+		} else {
+			imp, err = services.ParseNICE(f, opts)
+		}
+		f.Close()
+	case "onet":
+		imp, err = services.ParseONET(os.DirFS(fs.Arg(0)), opts)
+	default:
+		return errors.New("-source must be sfia, nice or onet")
+	}
+	if err != nil {
+		return err
+	}
+	database.Connect(cfg)
+	report, err := services.ApplyFrameworkImport(database.DB, imp, *publish, *dryRun, time.Now())
+	if err != nil {
+		return err
+	}
+	return printJSON(report)
+}
+
+func userExport(cfg *config.Config, args []string) error {
+	fs := newFlags("user export", "user export -id UUID [-format json|zip] [-o file]")
+	id := fs.String("id", "", "user id (required)")
+	format := fs.String("format", "json", "json or zip")
+	out := fs.String("o", "", "output file (default stdout)")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if err := userIDFlag(*id); err != nil {
+		return err
+	}
+	if *format != "json" && *format != "zip" {
+		return errors.New("-format must be json or zip")
+	}
+	database.Connect(cfg)
+	archive, err := services.ExportUserData(database.DB, *id, time.Now())
+	if err != nil {
+		return err
+	}
+	w, err := openOutput(*out)
+	if err != nil {
+		return err
+	}
+	if *format == "zip" {
+		err = archive.WriteZip(w)
+	} else {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(archive)
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// userDelete erases a user immediately, skipping the grace period. A pending
+// self-service request is executed rather than duplicated.
+func userDelete(cfg *config.Config, args []string) error {
+	fs := newFlags("user delete", "user delete -id UUID -yes [-reason text]")
+	id := fs.String("id", "", "user id (required)")
+	yes := fs.Bool("yes", false, "confirm the permanent deletion")
+	reason := fs.String("reason", "operator request", "reason recorded on the erasure log")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if err := userIDFlag(*id); err != nil {
+		return err
+	}
+	if !*yes {
+		return errors.New("refusing to delete without -yes")
+	}
+	database.Connect(cfg)
+	now := time.Now()
+	req, err := services.RequestErasure(database.DB, *id, *reason, 0, now)
+	if err != nil && !errors.Is(err, services.ErrErasurePending) {
+		return err
+	}
+	if err := services.ExecuteErasure(database.DB, &req, now); err != nil {
+		return err
+	}
+	var done models.ErasureRequest
+	database.DB.First(&done, req.ID)
+	return printJSON(done)
+}
+
+func adminGrant(cfg *config.Config, args []string) error {
+	fs := newFlags("admin grant", "admin grant -id UUID [-note text] [-by name]")
+	id := fs.String("id", "", "user id (required)")
+	note := fs.String("note", "", "why the grant was made")
+	by := fs.String("by", os.Getenv("USER"), "operator recorded as granting")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if err := userIDFlag(*id); err != nil {
+		return err
+	}
+	database.Connect(cfg)
+	grant, created, err := services.GrantAdmin(database.DB, *id, strings.TrimSpace(*note), *by)
+	if err != nil {
+		return err
+	}
+	if !created {
+		fmt.Fprintln(os.Stderr, "User already had an admin grant")
+	}
+	return printJSON(grant)
+}
+
+func adminRevoke(cfg *config.Config, args []string) error {
+	fs := newFlags("admin revoke", "admin revoke -id UUID")
+	id := fs.String("id", "", "user id (required)")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	if err := userIDFlag(*id); err != nil {
+		return err
+	}
+	database.Connect(cfg)
+	revoked, err := services.RevokeAdmin(database.DB, *id)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return errors.New("user has no admin grant")
+	}
+	for _, allowed := range strings.Split(cfg.AdminUserIDs, ",") {
+		if strings.TrimSpace(allowed) == *id {
+			fmt.Fprintln(os.Stderr, "Grant revoked, but the user is still an admin via ADMIN_USER_IDS")
+			return nil
+		}
+	}
+	fmt.Println("Admin grant revoked")
+	return nil
+}
+
+func adminList(cfg *config.Config, args []string) error {
+	fs := newFlags("admin list", "admin list")
+	if ok, err := parseFlags(fs, args); !ok {
+		return err
+	}
+	database.Connect(cfg)
+	var grants []models.AdminGrant
+	if err := database.DB.Order("created_at").Find(&grants).Error; err != nil {
+		return err
+	}
+	return printJSON(grants)
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"github.com/joho/godotenv"
 )
 
@@ -29,6 +30,25 @@ type Config struct {
     // Admin allowlist (comma-separated Supabase user IDs)
     AdminUserIDs string
 
+    // Optimistic concurrency: "required" | "optional" | "off" (installed with middleware.SetIfMatchMode)
+    IfMatchMode string
+
+    // Certification renewal goals are created this many days before expiry
+    CertRenewalLeadDays int
+
+    // Days between an account erasure request and its execution
+    ErasureGraceDays int
+
+    // Migrate and seed on "serve" start; turn off when migrations run as a
+    // separate release step
+    AutoMigrate bool
+    // Start even when the schema doesn't match this build's migrations
+    AllowSchemaDrift bool
+
+    // Extra catalog fixture packs (comma-separated files or directories),
+    // applied after the built-in packs on every start
+    CatalogFixtures string
+
     // AI/LLM settings
     AISuggestionsProvider string // "openai" | "local"
     OpenAIAPIKey          string
@@ -56,6 +76,12 @@ func Load() *Config {
         SupabaseAnonKey: getEnvOrDefault("SUPABASE_ANON_KEY", ""),
         SupabaseJWTSecret: getEnvOrDefault("SUPABASE_JWT_SECRET", ""),
         AdminUserIDs: getEnvOrDefault("ADMIN_USER_IDS", ""),
+        IfMatchMode:  getEnvOrDefault("IF_MATCH_MODE", "optional"),
+        CertRenewalLeadDays: getEnvIntOrDefault("CERT_RENEWAL_LEAD_DAYS", 90),
+        ErasureGraceDays:    getEnvIntOrDefault("ERASURE_GRACE_DAYS", 30),
+        AutoMigrate:         getEnvOrDefault("AUTO_MIGRATE", "true") == "true",
+        AllowSchemaDrift:    getEnvOrDefault("ALLOW_SCHEMA_DRIFT", "false") == "true",
+        CatalogFixtures:     getEnvOrDefault("CATALOG_FIXTURES", ""),
 
         // AI
         AISuggestionsProvider: getEnvOrDefault("AI_SUGGESTIONS_PROVIDER", "local"),
@@ -63,12 +89,13 @@ func Load() *Config {
         OpenAIModel:           getEnvOrDefault("OPENAI_MODEL", "gpt-4o-mini"),
 	}
 	
-	// Safe debug logging - only non-sensitive config values
-	fmt.Printf("Config loaded - DBHost: %s, GinMode: %s, APIPort: %s\n", 
+	// Safe debug logging - only non-sensitive config values (stderr, so CLI
+	// exports written to stdout stay clean)
+	fmt.Fprintf(os.Stderr, "Config loaded - DBHost: %s, GinMode: %s, APIPort: %s\n", 
 		config.DBHost, config.GinMode, config.APIPort)
-	fmt.Printf("Auth configured: OIDC=%t, Supabase=%t\n", 
+	fmt.Fprintf(os.Stderr, "Auth configured: OIDC=%t, Supabase=%t\n", 
 		config.OIDCIssuerURL != "", config.SupabaseJWTSecret != "")
-	fmt.Printf("AI provider: %s\n", config.AISuggestionsProvider)
+	fmt.Fprintf(os.Stderr, "AI provider: %s\n", config.AISuggestionsProvider)
 	
 	return config
 }
@@ -87,6 +114,13 @@ func (c *Config) DatabaseURL() string {
     return constructedURL
 }
 
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return defaultValue
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -0,0 +1,353 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goaltracker/migrations"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the Postgres advisory lock held while migrating, so pods
+// starting together apply each migration once.
+const migrationLockID int64 = 7210460001
+
+// migrationLockWait bounds how long a migrator waits for another to finish.
+const migrationLockWait = 5 * time.Minute
+
+// ErrSchemaDrift is wrapped by SchemaStatus.Drift.
+var ErrSchemaDrift = errors.New("schema drift")
+
+var migrationFile = regexp.MustCompile(`^(\d{4,})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 of the up and down scripts
+}
+
+// SchemaMigration records an applied migration. Baseline rows mark a
+// database AutoMigrate had built, which the baseline only brought level.
+type SchemaMigration struct {
+	Version   int       `json:"version" gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `json:"name"`
+	Checksum  string    `json:"checksum"`
+	Baseline  bool      `json:"baseline"`
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// SchemaStatus compares the database against the migrations this binary
+// ships and the models it expects.
+type SchemaStatus struct {
+	Applied  []SchemaMigration `json:"applied"`
+	Pending  []Migration       `json:"-"`
+	Modified []int             `json:"modified"` // applied, but the shipped file changed since
+	Unknown  []int             `json:"unknown"`  // applied by a newer binary
+	Missing  []string          `json:"missing"`  // model tables/columns absent from the database
+}
+
+// Drift describes why the schema doesn't match this binary, or returns nil.
+func (s *SchemaStatus) Drift() error {
+	var problems []string
+	if len(s.Pending) > 0 {
+		versions := make([]string, 0, len(s.Pending))
+		for _, m := range s.Pending {
+			versions = append(versions, strconv.Itoa(m.Version))
+		}
+		problems = append(problems, "pending migrations "+strings.Join(versions, ", "))
+	}
+	if len(s.Modified) > 0 {
+		problems = append(problems, fmt.Sprintf("applied migrations %v were modified", s.Modified))
+	}
+	if len(s.Unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("database has migrations %v this binary doesn't know", s.Unknown))
+	}
+	if len(s.Missing) > 0 {
+		problems = append(problems, "missing "+strings.Join(s.Missing, ", "))
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(problems, "; "))
+}
+
+// LoadMigrations reads NNNN_name.up.sql / NNNN_name.down.sql pairs from fsys,
+// ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		m := migrationFile.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s: name must be NNNN_name.up.sql or NNNN_name.down.sql", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %d_%s needs non-empty up and down files", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up + "\x00" + m.Down))
+		m.Checksum = hex.EncodeToString(sum[:])
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// MigrateUp applies pending migrations in order, up to and including target
+// (0 means all). A database built by AutoMigrate before versioned migrations
+// is brought level with the baseline and stamped rather than recreated.
+func MigrateUp(target int) ([]Migration, error) {
+	all, err := LoadMigrations(migrations.Files)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	err = withMigrationLock(func() error {
+		status, err := schemaStatus(all)
+		if err != nil {
+			return err
+		}
+		if len(status.Modified) > 0 || len(status.Unknown) > 0 {
+			return status.Drift()
+		}
+		pending := status.Pending
+		if len(status.Applied) == 0 && len(pending) > 0 && DB.Migrator().HasTable("job_roles") {
+			if err := adoptLegacySchema(pending[0]); err != nil {
+				return err
+			}
+			done, pending = append(done, pending[0]), pending[1:]
+		}
+		for _, m := range pending {
+			if target > 0 && m.Version > target {
+				break
+			}
+			log.Printf("Applying migration %d_%s", m.Version, m.Name)
+			err := DB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, Checksum: m.Checksum, AppliedAt: time.Now()}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown reverts the latest steps applied migrations, newest first.
+func MigrateDown(steps int) ([]Migration, error) {
+	all, err := LoadMigrations(migrations.Files)
+	if err != nil {
+		return nil, err
+	}
+	known := map[int]Migration{}
+	for _, m := range all {
+		known[m.Version] = m
+	}
+	var done []Migration
+	err = withMigrationLock(func() error {
+		if err := ensureMigrationTable(); err != nil {
+			return err
+		}
+		var applied []SchemaMigration
+		if err := DB.Order("version DESC").Limit(steps).Find(&applied).Error; err != nil {
+			return err
+		}
+		for _, a := range applied {
+			m, ok := known[a.Version]
+			if !ok {
+				return fmt.Errorf("migration %d_%s isn't in this binary; roll back with the release that added it", a.Version, a.Name)
+			}
+			if m.Checksum != a.Checksum {
+				return fmt.Errorf("migration %d_%s was modified after it was applied", a.Version, a.Name)
+			}
+			log.Printf("Reverting migration %d_%s", m.Version, m.Name)
+			err := DB.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(m.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&SchemaMigration{}, m.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("reverting migration %d_%s failed: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// SchemaStatusReport loads the shipped migrations and compares them, and the
+// models, against the database.
+func SchemaStatusReport() (*SchemaStatus, error) {
+	all, err := LoadMigrations(migrations.Files)
+	if err != nil {
+		return nil, err
+	}
+	status, err := schemaStatus(all)
+	if err != nil {
+		return nil, err
+	}
+	if status.Missing, err = PendingSchema(); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// CheckSchema returns the drift between the database and this binary, if any.
+func CheckSchema() error {
+	status, err := SchemaStatusReport()
+	if err != nil {
+		return err
+	}
+	return status.Drift()
+}
+
+func schemaStatus(all []Migration) (*SchemaStatus, error) {
+	if err := ensureMigrationTable(); err != nil {
+		return nil, err
+	}
+	status := &SchemaStatus{}
+	if err := DB.Order("version").Find(&status.Applied).Error; err != nil {
+		return nil, err
+	}
+	applied := map[int]SchemaMigration{}
+	for _, a := range status.Applied {
+		applied[a.Version] = a
+	}
+	known := map[int]bool{}
+	for _, m := range all {
+		known[m.Version] = true
+		a, ok := applied[m.Version]
+		switch {
+		case !ok:
+			status.Pending = append(status.Pending, m)
+		case a.Checksum != m.Checksum:
+			status.Modified = append(status.Modified, m.Version)
+		}
+	}
+	for _, a := range status.Applied {
+		if !known[a.Version] {
+			status.Unknown = append(status.Unknown, a.Version)
+		}
+	}
+	return status, nil
+}
+
+func ensureMigrationTable() error {
+	return DB.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint PRIMARY KEY,
+		name text NOT NULL,
+		checksum text NOT NULL,
+		baseline boolean NOT NULL DEFAULT false,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`).Error
+}
+
+// adoptLegacySchema brings a database created by AutoMigrate up to the
+// baseline by running its idempotent script (older deployments may lack
+// later tables and columns), runs the data backfills those deployments
+// relied on, and stamps the baseline. Later migrations then run as usual.
+func adoptLegacySchema(baseline Migration) error {
+	log.Printf("Existing schema found; adopting it as baseline %d_%s", baseline.Version, baseline.Name)
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(baseline.Up).Error; err != nil {
+			return fmt.Errorf("failed to bring legacy schema to baseline: %w", err)
+		}
+		// Acceptances recorded on the profile become consent ledger entries
+		for _, kind := range []string{"terms", "privacy"} {
+			err := tx.Exec(`INSERT INTO policy_acceptances (user_id, kind, version, accepted_at, source)
+				SELECT p.user_id, ?, COALESCE(NULLIF(p.policies_version, ''), '1.0'), p.`+kind+`_accepted_at, 'legacy'
+				FROM user_profiles p
+				WHERE p.`+kind+`_accepted_at IS NOT NULL
+				  AND NOT EXISTS (SELECT 1 FROM policy_acceptances a WHERE a.user_id = p.user_id AND a.kind = ?)`, kind, kind).Error
+			if err != nil {
+				return fmt.Errorf("failed to backfill %s acceptances: %w", kind, err)
+			}
+		}
+		// Catalog rows that predate the draft/publish workflow count as
+		// published since they were created
+		for _, table := range []string{"job_roles", "responsibilities", "goal_suggestions", "progress_suggestions"} {
+			if err := tx.Exec("UPDATE " + table + " SET published_at = created_at WHERE status = 'published' AND published_at IS NULL").Error; err != nil {
+				return fmt.Errorf("failed to backfill %s published_at: %w", table, err)
+			}
+		}
+		return tx.Create(&SchemaMigration{
+			Version: baseline.Version, Name: baseline.Name, Checksum: baseline.Checksum, Baseline: true, AppliedAt: time.Now(),
+		}).Error
+	})
+}
+
+// withMigrationLock runs fn holding the migration advisory lock. The lock is
+// session-scoped, so it is taken on a dedicated connection.
+func withMigrationLock(fn func() error) error {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", migrationLockID).Scan(&locked); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	if !locked {
+		log.Println("Waiting for another instance to finish migrating")
+		wait, cancel := context.WithTimeout(ctx, migrationLockWait)
+		defer cancel()
+		if _, err := conn.ExecContext(wait, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+			return fmt.Errorf("failed to take migration lock: %w", err)
+		}
+	}
+	defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockID)
+	return fn()
+}
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"goaltracker/fixtures"
+	"goaltracker/services"
+)
+
+// seedCatalog applies the built-in catalog packs and then the deployment's
+// extra packs (comma-separated files or directories), in order. Rows are
+// upserted by slug, so fixture edits reach existing databases on the next
+// seed. All packs apply in one transaction: a bad pack changes nothing.
+func seedCatalog(extra string) (*services.CatalogFixtureReport, error) {
+	packs, err := services.ReadCatalogFixturesFS(fixtures.Catalog, "catalog")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built-in catalog fixtures: %w", err)
+	}
+	if extra != "" {
+		more, err := services.ReadCatalogFixtures(strings.Split(extra, ","))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read catalog fixtures: %w", err)
+		}
+		packs = append(packs, more...)
+	}
+	report, err := services.ApplyCatalogFixtures(DB, packs, false, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply catalog fixtures: %w", err)
+	}
+	for _, t := range services.CatalogTypes {
+		c := report.Counts[t.Name]
+		if c.Created > 0 || c.Updated > 0 {
+			log.Printf("Catalog %s: %d created, %d updated, %d unchanged", t.Name, c.Created, c.Updated, c.Unchanged)
+		}
+	}
+	return report, nil
+}
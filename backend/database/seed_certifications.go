@@ -0,0 +1,54 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"goaltracker/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// defaultCertifications covers common IT certs and their published renewal
+// rules. Inserted once by slug; admins maintain them afterwards.
+var defaultCertifications = []models.Certification{
+	{Slug: "cissp", Name: "CISSP", Issuer: "ISC2", ValidityMonths: 36, CreditsRequired: 120, AnnualMinimum: 40, CreditUnit: "CPE"},
+	{Slug: "comptia-security-plus", Name: "CompTIA Security+", Issuer: "CompTIA", ValidityMonths: 36, CreditsRequired: 50, CreditUnit: "CEU"},
+	{Slug: "cism", Name: "CISM", Issuer: "ISACA", ValidityMonths: 36, CreditsRequired: 120, AnnualMinimum: 20, CreditUnit: "CPE"},
+	{Slug: "cisa", Name: "CISA", Issuer: "ISACA", ValidityMonths: 36, CreditsRequired: 120, AnnualMinimum: 20, CreditUnit: "CPE"},
+	{Slug: "ccna", Name: "CCNA", Issuer: "Cisco", ValidityMonths: 36, CreditsRequired: 30, CreditUnit: "CE"},
+	{Slug: "pmp", Name: "PMP", Issuer: "PMI", ValidityMonths: 36, CreditsRequired: 60, CreditUnit: "PDU"},
+	{Slug: "aws-solutions-architect-associate", Name: "AWS Certified Solutions Architect - Associate", Issuer: "Amazon Web Services", ValidityMonths: 36},
+	{Slug: "azure-administrator-associate", Name: "Microsoft Certified: Azure Administrator Associate", Issuer: "Microsoft", ValidityMonths: 12},
+	{Slug: "cka", Name: "Certified Kubernetes Administrator", Issuer: "CNCF", ValidityMonths: 24},
+	{Slug: "itil-4-foundation", Name: "ITIL 4 Foundation", Issuer: "PeopleCert", ValidityMonths: 36, CreditsRequired: 20, CreditUnit: "CPD"},
+}
+
+var defaultCertificationAliases = map[string][]string{
+	"comptia-security-plus":             {"security+", "sec+"},
+	"aws-solutions-architect-associate": {"aws saa", "saa-c03", "aws solutions architect associate"},
+	"azure-administrator-associate":     {"az-104", "azure administrator"},
+	"cka":                               {"certified kubernetes administrator"},
+	"itil-4-foundation":                 {"itil 4", "itil foundation"},
+}
+
+func seedCertifications() {
+	certs := make([]models.Certification, len(defaultCertifications))
+	for i, c := range defaultCertifications {
+		aliases := defaultCertificationAliases[c.Slug]
+		if aliases == nil {
+			aliases = []string{}
+		}
+		b, _ := json.Marshal(aliases)
+		c.Aliases = string(b)
+		certs[i] = c
+	}
+	res := DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&certs)
+	if res.Error != nil {
+		fmt.Println("Warning: failed to seed certifications:", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		fmt.Printf("Seeded %d certifications\n", res.RowsAffected)
+	}
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"fmt"
+
+	"goaltracker/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type roleRequirementSeed struct {
+	role, skill, cert string
+	level, importance int
+}
+
+// defaultRoleRequirements gives the seeded job roles a starting set of
+// expected skills and certifications for gap analysis. Only inserted when
+// the role, skill and certification exist; admins can edit them afterwards.
+var defaultRoleRequirements = []roleRequirementSeed{
+	{role: "Software Engineer", skill: "go", level: 3, importance: 2},
+	{role: "Software Engineer", skill: "javascript", level: 3, importance: 2},
+	{role: "Software Engineer", skill: "sql", level: 2, importance: 2},
+	{role: "Software Engineer", skill: "tdd", level: 3, importance: 3},
+	{role: "Software Engineer", skill: "docker", level: 2, importance: 2},
+	{role: "Software Engineer", skill: "github-actions", level: 2, importance: 1},
+	{role: "Software Engineer", skill: "kubernetes", level: 1, importance: 1},
+	{role: "Product Manager", skill: "product-roadmapping", level: 3, importance: 3},
+	{role: "Product Manager", skill: "sql", level: 1, importance: 1},
+	{role: "Product Manager", cert: "pmp", importance: 1},
+	{role: "Security Analyst", skill: "soc-operations", level: 3, importance: 3},
+	{role: "Security Analyst", skill: "iam", level: 3, importance: 3},
+	{role: "Security Analyst", skill: "nist-csf", level: 2, importance: 2},
+	{role: "Security Analyst", skill: "iso-27001", level: 2, importance: 2},
+	{role: "Security Analyst", skill: "python", level: 2, importance: 1},
+	{role: "Security Analyst", cert: "comptia-security-plus", importance: 3},
+	{role: "Security Analyst", cert: "cissp", importance: 1},
+	{role: "Designer", skill: "figma", level: 3, importance: 3},
+	{role: "Designer", skill: "react", level: 1, importance: 1},
+}
+
+func seedRoleRequirements() {
+	roles := map[string]uint{}
+	var jobRoles []models.JobRole
+	DB.Find(&jobRoles)
+	for _, r := range jobRoles {
+		roles[r.Title] = r.ID
+	}
+	var reqs []models.JobRoleRequirement
+	for _, s := range defaultRoleRequirements {
+		roleID, ok := roles[s.role]
+		if !ok {
+			continue
+		}
+		req := models.JobRoleRequirement{JobRoleID: roleID, Level: s.level, Importance: s.importance}
+		if s.skill != "" {
+			var skill models.Skill
+			if DB.Where("slug = ?", s.skill).Limit(1).Find(&skill).RowsAffected == 0 {
+				continue
+			}
+			req.SkillID = &skill.ID
+		} else {
+			var cert models.Certification
+			if DB.Where("slug = ?", s.cert).Limit(1).Find(&cert).RowsAffected == 0 {
+				continue
+			}
+			req.CertificationID = &cert.ID
+		}
+		reqs = append(reqs, req)
+	}
+	if len(reqs) == 0 {
+		return
+	}
+	res := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&reqs)
+	if res.Error != nil {
+		fmt.Println("Warning: failed to seed role requirements:", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		fmt.Printf("Seeded %d role requirements\n", res.RowsAffected)
+	}
+}
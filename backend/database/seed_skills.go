@@ -0,0 +1,87 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"goaltracker/models"
+
+	"gorm.io/gorm/clause"
+)
+
+type skillSeed struct {
+	slug, name, category string
+	aliases              []string
+}
+
+// frameworkSkills are methods and standards rather than tools; the rest of
+// the catalog is seeded as platforms.
+var frameworkSkills = map[string]bool{
+	"aws-well-architected": true, "sre": true, "iso-27001": true, "cis-benchmarks": true,
+	"nist-csf": true, "itil-4": true, "tdd": true, "product-roadmapping": true,
+}
+
+// defaultSkills is the starter taxonomy. Slugs are stable identifiers;
+// entries are inserted once and never overwritten, so admins can edit them.
+var defaultSkills = []skillSeed{
+	{"aws", "AWS", "cloud", []string{"amazon web services"}},
+	{"azure", "Azure", "cloud", []string{"microsoft azure"}},
+	{"gcp", "GCP", "cloud", []string{"google cloud", "google cloud platform"}},
+	{"aws-well-architected", "AWS Well-Architected", "cloud", []string{"well-architected", "well architected framework"}},
+	{"kubernetes", "Kubernetes", "devops", []string{"k8s", "eks", "aks", "gke"}},
+	{"docker", "Docker", "devops", []string{"containers"}},
+	{"terraform", "Terraform", "devops", []string{"opentofu", "iac", "infrastructure as code"}},
+	{"argocd", "ArgoCD", "devops", []string{"argo cd", "argo"}},
+	{"github-actions", "GitHub Actions", "devops", []string{"gha"}},
+	{"gitlab-ci", "GitLab CI", "devops", []string{"gitlab"}},
+	{"jenkins", "Jenkins", "devops", nil},
+	{"sre", "SRE", "devops", []string{"site reliability engineering", "devops/sre"}},
+	{"observability", "Observability", "devops", []string{"monitoring", "prometheus", "grafana"}},
+	{"iso-27001", "ISO 27001", "security", []string{"iso27001", "isms"}},
+	{"cis-benchmarks", "CIS Benchmarks", "security", []string{"cis"}},
+	{"nist-csf", "NIST CSF", "security", []string{"nist", "nist cybersecurity framework"}},
+	{"iam", "Identity & Access Management", "security", []string{"identity and access management", "least privilege"}},
+	{"soc-operations", "SOC Operations", "security", []string{"soc", "siem"}},
+	{"itil-4", "ITIL 4", "itsm", []string{"itil"}},
+	{"servicenow", "ServiceNow", "itsm", []string{"snow"}},
+	{"sql", "SQL", "data", []string{"postgresql", "postgres", "mysql"}},
+	{"python", "Python", "programming", nil},
+	{"go", "Go", "programming", []string{"golang"}},
+	{"javascript", "JavaScript", "programming", []string{"js", "typescript", "ts"}},
+	{"react", "React", "programming", []string{"reactjs", "react.js"}},
+	{"tdd", "Test-Driven Development", "programming", []string{"test driven development", "testing"}},
+	{"figma", "Figma", "design", nil},
+	{"product-roadmapping", "Product Roadmapping", "product", []string{"roadmap", "roadmapping"}},
+}
+
+func seedSkills() {
+	skills := make([]models.Skill, 0, len(defaultSkills))
+	for _, s := range defaultSkills {
+		aliases := s.aliases
+		if aliases == nil {
+			aliases = []string{}
+		}
+		b, _ := json.Marshal(aliases)
+		kind := "platform"
+		if frameworkSkills[s.slug] {
+			kind = "framework"
+		}
+		skills = append(skills, models.Skill{Slug: s.slug, Name: s.name, Category: s.category, Kind: kind, Aliases: string(b)})
+	}
+	res := DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&skills)
+	if res.Error != nil {
+		fmt.Println("Warning: failed to seed skills:", res.Error)
+		return
+	}
+	if res.RowsAffected > 0 {
+		fmt.Printf("Seeded %d skills\n", res.RowsAffected)
+	}
+	// A default skill first named in a user's profile was created unreviewed
+	slugs := make([]string, 0, len(skills))
+	for _, s := range skills {
+		slugs = append(slugs, s.Slug)
+	}
+	if err := DB.Model(&models.Skill{}).Where("slug IN ? AND unreviewed = ?", slugs, true).Update("unreviewed", false).Error; err != nil {
+		fmt.Println("Warning: failed to review seeded skills:", err)
+	}
+}
@@ -0,0 +1,16 @@
+// Package fixtures embeds the built-in data files shipped with the server.
+package fixtures
+
+import "embed"
+
+// Catalog holds the built-in goal catalog packs (catalog/*.yaml), applied in
+// file name order before any deployment packs.
+//
+//go:embed catalog/*.yaml
+var Catalog embed.FS
+
+// LegacySeed is the catalog seeded before fixture packs existed, which
+// fixture loads compare untracked rows against (legacy/seed.yaml).
+//
+//go:embed legacy/seed.yaml
+var LegacySeed []byte
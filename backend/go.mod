@@ -10,4 +10,5 @@ require (
 	github.com/joho/godotenv v1.5.1
 	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/lestrrat-go/jwx/v2 v2.1.6
+	gopkg.in/yaml.v3 v3.0.1
 )
\ No newline at end of file
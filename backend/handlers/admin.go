@@ -4,16 +4,23 @@ import (
     "net/http"
     "time"
     "goaltracker/database"
+    "goaltracker/middleware"
     "goaltracker/models"
     "goaltracker/services"
     "github.com/gin-gonic/gin"
 )
 
+// IsGrantedAdmin adapts services.IsGrantedAdmin for
+// middleware.SetAdminGrantLookup.
+func IsGrantedAdmin(userID string) (bool, error) {
+    return services.IsGrantedAdmin(database.DB, userID)
+}
+
 func AdminHealth(c *gin.Context) {
     start := time.Now()
     sql, err := database.DB.DB()
-    if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db"}); return }
-    if err := sql.Ping(); err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error": "db_ping"}); return }
+    if err != nil { c.JSON(http.StatusInternalServerError, middleware.Error(c, "database_unavailable")); return }
+    if err := sql.Ping(); err != nil { c.JSON(http.StatusInternalServerError, middleware.Error(c, "database_unavailable")); return }
     latency := time.Since(start).Milliseconds()
     c.JSON(http.StatusOK, gin.H{"data": gin.H{"db_ms": latency, "time": time.Now().UTC()}})
 }
@@ -22,7 +29,7 @@ func AdminUsers(c *gin.Context) {
     limit := 50
     var users []models.UserProfile
     if err := database.DB.Limit(limit).Order("created_at DESC").Find(&users).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list users"})
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "list_users_failed"))
         return
     }
     c.JSON(http.StatusOK, gin.H{"data": users})
@@ -6,10 +6,12 @@ import (
     "time"
     "encoding/json"
     "goaltracker/database"
+    "goaltracker/i18n"
     "goaltracker/models"
     "goaltracker/services"
     "goaltracker/middleware"
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
     "strings"
     "log"
 )
@@ -34,29 +36,72 @@ type updateProfilePayload struct {
     ITProfile          *map[string]interface{} `json:"it_profile,omitempty"`
     AcceptTerms        *bool   `json:"accept_terms,omitempty"`
     AcceptPrivacy      *bool   `json:"accept_privacy,omitempty"`
+    Locale             *string `json:"locale,omitempty"`
 }
 
-var expLevels = map[string]struct{}{ "entry":{}, "junior":{}, "mid":{}, "senior":{}, "lead":{}, "expert":{} }
+var expLevels = func() map[string]struct{} {
+    m := map[string]struct{}{}
+    for _, l := range services.ExperienceLevels { m[l] = struct{}{} }
+    return m
+}()
+
+// profileLocale maps a submitted locale preference onto a supported locale
+// ("de-AT" -> "de"); blank clears the preference.
+func profileLocale(v string) (string, error) {
+    if strings.TrimSpace(v) == "" { return "", nil }
+    l, ok := i18n.Match(v)
+    if !ok { return "", i18n.Errorf("invalid_choice", "locale", strings.Join(i18n.Supported, ", ")) }
+    return l, nil
+}
+
+// UserLocalePreference returns a user's saved locale ("" for none) for
+// middleware.SetLocalePreference.
+func UserLocalePreference(userID string) (string, error) {
+    var locales []string
+    err := database.DB.Model(&models.UserProfile{}).Where("user_id = ?", userID).Limit(1).Pluck("COALESCE(locale, '')", &locales).Error
+    if err != nil || len(locales) == 0 { return "", err }
+    return locales[0], nil
+}
+
+// parseITProfile validates a submitted it_profile against the canonical
+// schema and returns its normalized stored form. On failure it writes the
+// field-level errors with status and returns false.
+func parseITProfile(c *gin.Context, status int, raw interface{}) (string, bool) {
+    b, _ := json.Marshal(raw)
+    if err := middleware.ValidateJSONSize("it_profile", string(b), middleware.MaxJSONFieldSize); err != nil {
+        c.JSON(status, middleware.ErrorFrom(c, err, "invalid_request"))
+        return "", false
+    }
+    it, errs := services.ParseITProfile(b)
+    if len(errs) > 0 {
+        for i := range errs {
+            errs[i].Field = strings.TrimSuffix("it_profile."+errs[i].Field, ".")
+        }
+        c.JSON(status, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "it_profile_invalid"))
+        return "", false
+    }
+    return services.EncodeITProfile(it), true
+}
 
 func GetAIGoalSuggestions(c *gin.Context) {
 	var req AIGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
 		return
 	}
 
 	// Validate input sizes and content
 	if err := middleware.ValidateStringLength("company_context", req.CompanyContext, 0, 1000); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
 		return
 	}
 	if len(req.MarketTrends) > 20 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many market trends specified"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "too_many_market_trends"))
 		return
 	}
 	for _, trend := range req.MarketTrends {
 		if err := middleware.ValidateStringLength("market_trend", trend, 1, 100); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
 			return
 		}
 	}
@@ -72,7 +117,7 @@ func GetAIGoalSuggestions(c *gin.Context) {
 	// Get responsibility details
 	var responsibility models.Responsibility
 	if err := database.DB.First(&responsibility, req.ResponsibilityID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Responsibility not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "responsibility_not_found"))
 		return
 	}
 	
@@ -89,7 +134,7 @@ func GetAIGoalSuggestions(c *gin.Context) {
     start := time.Now()
     suggestions, err := aiService.GeneratePersonalizedGoals(aiReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate AI suggestions"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "ai_suggestions_failed"))
 		return
 	}
     elapsed := time.Since(start)
@@ -101,8 +146,27 @@ func GetAIGoalSuggestions(c *gin.Context) {
         Timestamp: time.Now(),
     })
     
-    // attach user ownership for any persisted AI entities (if/when saved later)
-    _ = middleware.GetUserID
+    // Leave out suggestions the user dismissed or adopted before and key the
+    // rest so feedback can name them (see services.AISuggestionKey)
+    if uid, err := middleware.GetUserID(c); err == nil {
+        hidden, err := services.LoadHiddenSuggestions(database.DB, uid)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_suggestion_feedback_failed"))
+            return
+        }
+        kept := suggestions[:0]
+        shown := make([]models.SuggestionFeedback, 0, len(suggestions))
+        for _, s := range suggestions {
+            s.Key = services.AISuggestionKey(s.Title)
+            if hidden.AI[s.Key] { continue }
+            kept = append(kept, s)
+            shown = append(shown, services.AIImpression(s.Title))
+        }
+        suggestions = kept
+        if err := services.RecordSuggestionImpressions(database.DB, uid, shown, time.Now()); err != nil {
+            log.Printf("Failed to record suggestion impressions: %v", err)
+        }
+    }
 	c.JSON(http.StatusOK, gin.H{
 		"data": suggestions,
 		"user_context": req.UserProfile,
@@ -127,55 +191,58 @@ func GetOrCreateMyProfile(c *gin.Context) {
                     return
                 }
             }
-            c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.SanitizeDBError(err2)})
+            c.JSON(http.StatusInternalServerError, middleware.DBError(c, err2))
             return
         }
     }
 
     // Ensure ITProfile at least empty JSON string to avoid frontend parse issues
     if strings.TrimSpace(profile.ITProfile) == "" { profile.ITProfile = "{}" }
+    if middleware.NotModified(c, profileETag(profile)) { return }
     c.JSON(http.StatusOK, gin.H{"data": profile})
 }
 
+func profileETag(p models.UserProfile) string {
+    return middleware.VersionETag("profile", p.ID, p.Version)
+}
+
 func CreateUserProfile(c *gin.Context) {
     var p updateProfilePayload
     if err := c.ShouldBindJSON(&p); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
         return
     }
 
     // Validate string fields
     if p.CurrentRole != nil {
         if err := middleware.ValidateStringLength("current_role", *p.CurrentRole, 1, 100); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
             return
         }
     }
     if p.Industry != nil {
         if err := middleware.ValidateStringLength("industry", *p.Industry, 1, 100); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
             return
         }
     }
     if p.CareerGoals != nil {
         if err := middleware.ValidateStringLength("career_goals", *p.CareerGoals, 0, 2000); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
             return
         }
     }
+    var itProfile string
     if p.ITProfile != nil {
-        itProfileJSON, _ := json.Marshal(*p.ITProfile)
-        if err := middleware.ValidateJSONSize("it_profile", string(itProfileJSON), middleware.MaxJSONFieldSize); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-            return
-        }
+        var ok bool
+        if itProfile, ok = parseITProfile(c, http.StatusBadRequest, *p.ITProfile); !ok { return }
     }
     userID, _ := middleware.GetUserID(c)
-    profile := models.UserProfile{UserID: userID}
+    profile := models.UserProfile{UserID: userID, Version: 1}
     if p.CurrentRole != nil { profile.CurrentRole = *p.CurrentRole }
     if p.ExperienceLevel != nil {
         if _, ok := expLevels[*p.ExperienceLevel]; !ok {
-            c.JSON(http.StatusBadRequest, gin.H{"error":"invalid experience_level"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_field", "experience_level"))
             return
         }
         profile.ExperienceLevel = *p.ExperienceLevel
@@ -189,14 +256,26 @@ func CreateUserProfile(c *gin.Context) {
     if p.CareerGoals != nil { profile.CareerGoals = *p.CareerGoals }
     if p.CurrentTools != nil { profile.CurrentTools = *p.CurrentTools }
     if p.SkillGaps != nil { profile.SkillGaps = *p.SkillGaps }
-    if p.ITProfile != nil {
-        // Marshal the underlying map, not the pointer itself
-        if b, err := json.Marshal(*p.ITProfile); err == nil { profile.ITProfile = string(b) }
+    if p.ITProfile != nil { profile.ITProfile = itProfile }
+    if p.Locale != nil {
+        l, err := profileLocale(*p.Locale)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+            return
+        }
+        profile.Locale = l
     }
-    if err := database.DB.Create(&profile).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.SanitizeDBError(err)})
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&profile).Error; err != nil { return err }
+        if _, err := services.RecordProfileSnapshot(tx, nil, profile); err != nil { return err }
+        if err := services.SyncProfileSkills(tx, nil, profile); err != nil { return err }
+        return services.SyncProfileCertifications(tx, profile)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
         return
     }
+    c.Header("ETag", profileETag(profile))
     c.JSON(http.StatusCreated, gin.H{"data": profile})
 }
 
@@ -206,9 +285,10 @@ func GetUserProfile(c *gin.Context) {
 	
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Where("user_id = ?", userID).First(&profile, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "profile_not_found"))
 		return
 	}
+	if middleware.NotModified(c, profileETag(profile)) { return }
 	
 	c.JSON(http.StatusOK, gin.H{"data": profile})
 }
@@ -218,18 +298,20 @@ func UpdateUserProfile(c *gin.Context) {
     var profile models.UserProfile
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Where("user_id = ?", userID).First(&profile, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found"})
+        c.JSON(http.StatusNotFound, middleware.Error(c, "profile_not_found"))
         return
     }
+    if !middleware.CheckIfMatch(c, profileETag(profile), profile) { return }
+    before := profile
     var p updateProfilePayload
     if err := c.ShouldBindJSON(&p); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
         return
     }
     if p.CurrentRole != nil { profile.CurrentRole = *p.CurrentRole }
     if p.ExperienceLevel != nil {
         if _, ok := expLevels[*p.ExperienceLevel]; !ok {
-            c.JSON(http.StatusBadRequest, gin.H{"error":"invalid experience_level"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_field", "experience_level"))
             return
         }
         profile.ExperienceLevel = *p.ExperienceLevel
@@ -242,46 +324,89 @@ func UpdateUserProfile(c *gin.Context) {
     if p.CurrentTools != nil { profile.CurrentTools = *p.CurrentTools }
     if p.SkillGaps != nil { profile.SkillGaps = *p.SkillGaps }
     if p.ITProfile != nil {
-        if *p.ITProfile == nil {
-            profile.ITProfile = "{}"
-        } else if b, err := json.Marshal(*p.ITProfile); err == nil { profile.ITProfile = string(b) }
+        it, ok := parseITProfile(c, http.StatusBadRequest, *p.ITProfile)
+        if !ok { return }
+        profile.ITProfile = it
     }
-    
-    // Handle policy acceptance
-    if p.AcceptTerms != nil && *p.AcceptTerms {
-        now := time.Now()
-        profile.TermsAcceptedAt = &now
-        profile.PoliciesVersion = "1.0" // Set current version
+    if p.Locale != nil {
+        l, err := profileLocale(*p.Locale)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+            return
+        }
+        profile.Locale = l
     }
-    if p.AcceptPrivacy != nil && *p.AcceptPrivacy {
-        now := time.Now()
-        profile.PrivacyAcceptedAt = &now
-        if profile.PoliciesVersion == "" {
-            profile.PoliciesVersion = "1.0" // Set current version if not set
+    
+    // Handle policy acceptance: resolve the version in effect and append it
+    // to the consent ledger alongside the profile save
+    var accepts []func(tx *gorm.DB) error
+    now := time.Now()
+    for _, a := range []struct{ kind string; on *bool }{{"terms", p.AcceptTerms}, {"privacy", p.AcceptPrivacy}} {
+        if a.on == nil || !*a.on { continue }
+        doc, version, err := services.ResolvePolicy(database.DB, a.kind, "", now)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "resolve_policy_version_failed"))
+            return
+        }
+        if a.kind == "terms" {
+            profile.TermsAcceptedAt = &now
+            profile.PoliciesVersion = version
+        } else {
+            profile.PrivacyAcceptedAt = &now
+            if profile.PoliciesVersion == "" { profile.PoliciesVersion = version }
         }
+        kind := a.kind
+        ctx := services.AcceptanceContext{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent(), Source: "profile"}
+        accepts = append(accepts, func(tx *gorm.DB) error {
+            _, err := services.RecordAcceptance(tx, userID, kind, doc, version, ctx, now)
+            return err
+        })
     }
     
     profile.UserID = userID
     // Safe debug logging without sensitive data
     func() { defer func(){ recover() }(); log.Printf("UpdateUserProfile: user=%s id=%d it_profile_size=%d", userID, profile.ID, len(profile.ITProfile)) }()
-    if err := database.DB.Save(&profile).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": middleware.SanitizeDBError(err)})
+    persistProfileUpdate(c, before, profile, accepts...)
+}
+
+// persistProfileUpdate saves profile over before with a version check and
+// writes the response. extra runs inside the same transaction.
+func persistProfileUpdate(c *gin.Context, before, profile models.UserProfile, extra ...func(tx *gorm.DB) error) {
+    profile.Version = before.Version + 1
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := services.SaveVersioned(tx, &profile, before.Version); err != nil { return err }
+        if _, err := services.RecordProfileSnapshot(tx, &before, profile); err != nil { return err }
+        if err := services.SyncProfileSkills(tx, &before, profile); err != nil { return err }
+        for _, fn := range extra {
+            if err := fn(tx); err != nil { return err }
+        }
+        return services.SyncProfileCertifications(tx, profile)
+    })
+    if err != nil {
+        if err == services.ErrVersionConflict {
+            var current models.UserProfile
+            database.DB.First(&current, profile.ID)
+            middleware.PreconditionFailed(c, profileETag(current), current)
+            return
+        }
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
         return
     }
     if strings.TrimSpace(profile.ITProfile) == "" { profile.ITProfile = "{}" }
+    c.Header("ETag", profileETag(profile))
     c.JSON(http.StatusOK, gin.H{"data": profile})
 }
 
 func GetAIInsights(c *gin.Context) {
 	profileIdStr := c.Query("profile_id")
 	if profileIdStr == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "profile_id is required"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "profile_id"))
 		return
 	}
 	
 	profileId, err := strconv.ParseUint(profileIdStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile_id"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_profile_id"))
 		return
 	}
 	
@@ -295,7 +420,7 @@ func GetAIInsights(c *gin.Context) {
 	}
 	
 	if err := query.Order("confidence DESC, created_at DESC").Find(&insights).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch insights"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_insights_failed"))
 		return
 	}
 	
@@ -307,7 +432,7 @@ func GetMarketAwareGoals(c *gin.Context) {
 	responsibilityIdStr := c.Param("responsibility_id")
 	responsibilityId, err := strconv.ParseUint(responsibilityIdStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid responsibility_id"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_responsibility_id"))
 		return
 	}
 	
@@ -325,7 +450,7 @@ func GetMarketAwareGoals(c *gin.Context) {
 	// Get responsibility details
 	var responsibility models.Responsibility
 	if err := database.DB.First(&responsibility, responsibilityId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Responsibility not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "responsibility_not_found"))
 		return
 	}
 	
@@ -340,7 +465,7 @@ func GetMarketAwareGoals(c *gin.Context) {
 	
 	aiSuggestions, err := aiService.GeneratePersonalizedGoals(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate AI suggestions"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "ai_suggestions_failed"))
 		return
 	}
 	
@@ -358,14 +483,14 @@ func GenerateProgressInsights(c *gin.Context) {
 	goalIdStr := c.Param("goal_id")
 	goalId, err := strconv.ParseUint(goalIdStr, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal_id"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_goal_id"))
 		return
 	}
 	
 	// Get goal and progress data
 	var goal models.Goal
 	if err := database.DB.Preload("Progress").First(&goal, goalId).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
 		return
 	}
 	
@@ -420,7 +545,7 @@ func RefineOKRSmart(c *gin.Context) {
     // Parse request
     var req refineOKRRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
         return
     }
 
@@ -429,18 +554,18 @@ func RefineOKRSmart(c *gin.Context) {
     if req.Draft != nil {
         b, err := json.Marshal(req.Draft)
         if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft format"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_draft_format"))
             return
         }
         
         // Limit JSON size to prevent DoS
         if len(b) > 10000 { // 10KB limit for draft objects
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Draft object too large"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "draft_too_large"))
             return
         }
         
         if err := json.Unmarshal(b, &draft); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft structure"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_draft_structure"))
             return
         }
     }
@@ -450,7 +575,7 @@ func RefineOKRSmart(c *gin.Context) {
         Title: req.Title, Description: req.Description, DueDate: req.DueDate, Draft: draft,
     })
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refine"})
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "refine_failed"))
         return
     }
 
@@ -468,7 +593,7 @@ type refineSMARTRequest struct {
 func RefineSMARTRoute(c *gin.Context) {
     var req refineSMARTRequest
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
         return
     }
     // Safely convert draft map to typed struct with validation
@@ -476,44 +601,48 @@ func RefineSMARTRoute(c *gin.Context) {
     if req.Draft != nil {
         b, err := json.Marshal(req.Draft)
         if err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft format"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_draft_format"))
             return
         }
         
         // Limit JSON size to prevent DoS
         if len(b) > 10000 { // 10KB limit for draft objects
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Draft object too large"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "draft_too_large"))
             return
         }
         
         if err := json.Unmarshal(b, &draft); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft structure"})
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_draft_structure"))
             return
         }
     }
     svc := services.NewAIService()
     refined, err := svc.RefineSMART(services.RefineSMARTRequest{ Title: req.Title, Description: req.Description, DueDate: req.DueDate, Draft: draft })
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error":"failed"}); return
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "refine_failed")); return
     }
     c.JSON(http.StatusOK, gin.H{"data": refined})
 }
 
 // Milestones generation
 type milestonesReq struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
-    DueDate     string `json:"due_date"`
-    Count       int    `json:"count"`
+    Title       string  `json:"title"`
+    Description string  `json:"description"`
+    DueDate     string  `json:"due_date"`
+    Count       int     `json:"count"`
+    EffortHours float64 `json:"effort_hours"` // optional total estimate to split across milestones
 }
 
 func GenerateMilestonesRoute(c *gin.Context) {
     var req milestonesReq
     if err := c.ShouldBindJSON(&req); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()}); return
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request")); return
+    }
+    if req.EffortHours < 0 || req.EffortHours > 1000 {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_field", "effort_hours")); return
     }
     svc := services.NewAIService()
-    out, err := svc.GenerateMilestones(services.GenerateMilestonesRequest{ Title: req.Title, Description: req.Description, DueDate: req.DueDate, Count: req.Count })
-    if err != nil { c.JSON(http.StatusInternalServerError, gin.H{"error":"failed"}); return }
+    out, err := svc.GenerateMilestones(services.GenerateMilestonesRequest{ Title: req.Title, Description: req.Description, DueDate: req.DueDate, Count: req.Count, EffortHours: req.EffortHours })
+    if err != nil { c.JSON(http.StatusInternalServerError, middleware.Error(c, "generate_milestones_failed")); return }
     c.JSON(http.StatusOK, gin.H{"data": out})
 }
\ No newline at end of file
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/i18n"
+    "goaltracker/middleware"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// maxAnalyticsRange caps the window to keep aggregate queries bounded
+const maxAnalyticsRange = 5 * 366 * 24 * time.Hour
+
+// GetAnalytics returns dashboard aggregates for the current user.
+// Query: from, to (YYYY-MM-DD or RFC3339). Defaults to the last 12 months.
+func GetAnalytics(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+
+    rng, err := parseDateRange(c.Query("from"), c.Query("to"), time.Now().AddDate(-1, 0, 0))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    if rng.To.Sub(rng.From) > maxAnalyticsRange {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "date_range_too_long"))
+        return
+    }
+
+    svc := services.NewAnalyticsService(database.DB)
+    summary, err := svc.Summary(userID, services.AnalyticsRange{From: rng.From, To: rng.To})
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "compute_analytics_failed"))
+        return
+    }
+
+    c.JSON(http.StatusOK, gin.H{"data": summary})
+}
+
+type dateRange struct {
+    From time.Time
+    To   time.Time
+}
+
+// parseDateRange accepts YYYY-MM-DD or RFC3339 bounds. A date-only "to" is
+// treated as inclusive of that whole day.
+func parseDateRange(fromStr, toStr string, defaultFrom time.Time) (dateRange, error) {
+    rng := dateRange{From: defaultFrom, To: time.Now()}
+    if fromStr != "" {
+        t, _, err := parseDateParam(fromStr)
+        if err != nil {
+            return rng, i18n.Errorf("invalid_date", "from")
+        }
+        rng.From = t
+    }
+    if toStr != "" {
+        t, dateOnly, err := parseDateParam(toStr)
+        if err != nil {
+            return rng, i18n.Errorf("invalid_date", "to")
+        }
+        if dateOnly { t = t.AddDate(0, 0, 1) }
+        rng.To = t
+    }
+    if !rng.From.Before(rng.To) {
+        return rng, i18n.Errorf("from_after_to")
+    }
+    return rng, nil
+}
+
+func parseDateParam(v string) (time.Time, bool, error) {
+    if t, err := time.Parse("2006-01-02", v); err == nil {
+        return t, true, nil
+    }
+    t, err := time.Parse(time.RFC3339, v)
+    return t, false, err
+}
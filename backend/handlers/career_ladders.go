@@ -0,0 +1,187 @@
+package handlers
+
+import (
+    "encoding/json"
+    "io"
+    "mime"
+    "net/http"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gopkg.in/yaml.v3"
+    "gorm.io/gorm"
+)
+
+const maxLadderSize = 1 << 20
+
+// GetCareerLadders lists tracks with their levels; use GetCareerLadder for
+// the competency matrix.
+func GetCareerLadders(c *gin.Context) {
+    var tracks []models.CareerTrack
+    err := database.DB.Preload("Levels", func(db *gorm.DB) *gorm.DB { return db.Order("rank") }).
+        Order("name").Find(&tracks).Error
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_career_ladders_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": tracks})
+}
+
+// GetCareerLadder returns one track (by slug or id) with competencies,
+// levels and per-level expectations.
+func GetCareerLadder(c *gin.Context) {
+    track, err := services.LoadLadder(database.DB, c.Param("ref"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "career_ladder_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": track})
+}
+
+// ExportCareerLadder returns a track in the import format so it can be
+// edited and re-imported. Query: format=yaml (default) or json.
+func ExportCareerLadder(c *gin.Context) {
+    track, err := services.LoadLadder(database.DB, c.Param("ref"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "career_ladder_not_found"))
+        return
+    }
+    jobRole := ""
+    if track.JobRoleID != nil {
+        var role models.JobRole
+        if database.DB.Limit(1).Find(&role, *track.JobRoleID).RowsAffected > 0 { jobRole = role.Title }
+    }
+    doc := services.ExportLadder(track, jobRole)
+    switch c.DefaultQuery("format", "yaml") {
+    case "json":
+        b, _ := json.MarshalIndent(doc, "", "  ")
+        c.Header("Content-Disposition", `attachment; filename="`+track.Slug+`.json"`)
+        c.Data(http.StatusOK, "application/json; charset=utf-8", b)
+    case "yaml":
+        b, err := yaml.Marshal(doc)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "render_career_ladder_failed"))
+            return
+        }
+        c.Header("Content-Disposition", `attachment; filename="`+track.Slug+`.yaml"`)
+        c.Data(http.StatusOK, "application/yaml; charset=utf-8", b)
+    default:
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "format", "yaml, json"))
+    }
+}
+
+// AdminImportCareerLadder creates or replaces a track from a YAML or JSON
+// ladder file (see services.LadderDocument). The format comes from the
+// Content-Type, or ?format=yaml|json when uploading as text/plain.
+func AdminImportCareerLadder(c *gin.Context) {
+    format := documentFormat(c)
+    body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLadderSize+1))
+    if err != nil || len(body) > maxLadderSize {
+        c.JSON(http.StatusRequestEntityTooLarge, middleware.Error(c, "ladder_too_large"))
+        return
+    }
+    doc, err := services.ParseLadder(body, format)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    skills, err := services.LoadSkillCatalog(database.DB)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "load_skill_catalog_failed"))
+        return
+    }
+    if errs := services.ValidateLadder(&doc, skills); len(errs) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "career_ladder_invalid"))
+        return
+    }
+
+    track, created, err := services.ImportLadder(database.DB, doc)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    status := http.StatusOK
+    if created { status = http.StatusCreated }
+    c.JSON(status, gin.H{"data": track})
+}
+
+// GetPromotionReadiness scores the caller against the next level of a
+// ladder. Query:
+//   track  slug or id; defaults to the track for the profile's current role,
+//          or the only track when there is just one
+//   level  the caller's current level slug; defaults from experience_level
+func GetPromotionReadiness(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var profile models.UserProfile
+    database.DB.Where("user_id = ?", userID).Limit(1).Find(&profile)
+
+    ref := c.Query("track")
+    if ref == "" {
+        var tracks []models.CareerTrack
+        if err := database.DB.Order("name").Find(&tracks).Error; err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_career_ladders_failed"))
+            return
+        }
+        ref = defaultTrack(tracks, profile)
+        if ref == "" {
+            slugs := make([]string, 0, len(tracks))
+            for _, t := range tracks { slugs = append(slugs, t.Slug) }
+            body := middleware.Error(c, "career_ladder_ambiguous")
+            body["candidates"] = slugs
+            c.JSON(http.StatusUnprocessableEntity, body)
+            return
+        }
+    }
+    track, err := services.LoadLadder(database.DB, ref)
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "career_ladder_not_found"))
+        return
+    }
+    current, source, err := services.PlaceOnLadder(track, c.Query("level"), profile.ExperienceLevel)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    readiness, err := services.AssessReadiness(database.DB, userID, track, current)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "assess_readiness_failed"))
+        return
+    }
+    readiness.LevelSource = source
+    c.JSON(http.StatusOK, gin.H{"data": readiness})
+}
+
+// defaultTrack picks the ladder for the profile's current role, falling back
+// to the only ladder when there is one.
+func defaultTrack(tracks []models.CareerTrack, profile models.UserProfile) string {
+    if profile.CurrentRole != "" {
+        var roles []models.JobRole
+        database.DB.Scopes(services.Published("job_roles")).Find(&roles)
+        if role, _ := services.ResolveTargetRole(roles, profile.CurrentRole); role != nil {
+            for _, t := range tracks {
+                if t.JobRoleID != nil && *t.JobRoleID == role.ID { return t.Slug }
+            }
+        }
+    }
+    if len(tracks) == 1 { return tracks[0].Slug }
+    return ""
+}
+
+// documentFormat picks json or yaml for an uploaded document from the format
+// query parameter, then the Content-Type; empty means sniff the body.
+func documentFormat(c *gin.Context) string {
+    if format := c.Query("format"); format != "" { return format }
+    mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+    switch mediaType {
+    case "application/json":
+        return "json"
+    case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+        return "yaml"
+    }
+    return ""
+}
@@ -0,0 +1,280 @@
+package handlers
+
+import (
+    "encoding/json"
+    "errors"
+    "io"
+    "net/http"
+    "strconv"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/i18n"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+const maxFixtureSize = 1 << 20
+
+// catalogType resolves the :type route segment, writing a 404 when unknown.
+func catalogType(c *gin.Context) (services.CatalogType, bool) {
+    t, ok := services.FindCatalogType(c.Param("type"))
+    if !ok || t.Name != c.Param("type") {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "unknown_catalog_type"))
+        return t, false
+    }
+    return t, true
+}
+
+// catalogLabel is t's label in the request's locale, for messages.
+func catalogLabel(c *gin.Context, t services.CatalogType) string {
+    return i18n.Text(middleware.GetLocale(c), "catalog_type_"+t.Table)
+}
+
+// loadCatalogItem fetches :id of type t, writing a 404 when missing.
+func loadCatalogItem(c *gin.Context, t services.CatalogType) (interface{}, bool) {
+    item := t.New()
+    if err := database.DB.First(item, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+        return nil, false
+    }
+    return item, true
+}
+
+// AdminListCatalog lists every row of a catalog type, drafts included.
+// Query: status (draft or published), parent_id.
+func AdminListCatalog(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    q := database.DB.Order("id")
+    if s := c.Query("status"); s != "" { q = q.Where("status = ?", s) }
+    if p := c.Query("parent_id"); p != "" && t.ParentColumn != "" { q = q.Where(t.ParentColumn+" = ?", p) }
+    rows := t.NewSlice()
+    if err := q.Find(rows).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_catalog_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+// AdminGetCatalogItem returns one row in any state.
+func AdminGetCatalogItem(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    item, ok := loadCatalogItem(c, t)
+    if !ok { return }
+    c.JSON(http.StatusOK, gin.H{"data": item})
+}
+
+// AdminCreateCatalogItem creates a draft row. The body is the row's JSON
+// representation; id, status and timestamps are ignored.
+func AdminCreateCatalogItem(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    saveCatalogItem(c, t, t.New(), nil)
+}
+
+// AdminUpdateCatalogItem replaces the editable fields of a row; fields left
+// out of the body keep their current values. Published rows stay published.
+func AdminUpdateCatalogItem(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    before, ok := loadCatalogItem(c, t)
+    if !ok { return }
+    item := t.New()
+    b, _ := json.Marshal(before)
+    _ = json.Unmarshal(b, item)
+    saveCatalogItem(c, t, item, before)
+}
+
+func saveCatalogItem(c *gin.Context, t services.CatalogType, item, before interface{}) {
+    userID, _ := middleware.GetUserID(c)
+    body, err := io.ReadAll(c.Request.Body)
+    if err == nil { err = json.Unmarshal(body, item) }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    keep := before
+    if keep == nil { keep = t.New() }
+    services.KeepCatalogFields(item, keep)
+    if errs := services.ValidateCatalogItem(database.DB, t, item); len(errs) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "catalog_item_invalid", catalogLabel(c, t)))
+        return
+    }
+    if _, err := services.SaveCatalogItem(database.DB, t, item, before, userID); err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    status := http.StatusOK
+    if before == nil { status = http.StatusCreated }
+    c.JSON(status, gin.H{"data": item})
+}
+
+// AdminPublishCatalogItem makes a draft visible on the public routes. Its
+// parent must already be published.
+func AdminPublishCatalogItem(c *gin.Context) { setCatalogStatus(c, "published") }
+
+// AdminUnpublishCatalogItem returns a row to draft, hiding it and its
+// children from the public routes.
+func AdminUnpublishCatalogItem(c *gin.Context) { setCatalogStatus(c, "draft") }
+
+func setCatalogStatus(c *gin.Context, status string) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+        return
+    }
+    userID, _ := middleware.GetUserID(c)
+    item, err := services.SetCatalogStatus(database.DB, t, uint(id), status, userID, time.Now())
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+    case errors.Is(err, services.ErrCatalogStatusUnchanged):
+        c.JSON(http.StatusConflict, middleware.Error(c, "catalog_already_"+status, catalogLabel(c, t)))
+    case errors.Is(err, services.ErrCatalogParentDraft):
+        parent, _ := services.FindCatalogType(t.ParentTable)
+        c.JSON(http.StatusConflict, middleware.Error(c, "catalog_parent_draft", catalogLabel(c, parent)))
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+    default:
+        c.JSON(http.StatusOK, gin.H{"data": item})
+    }
+}
+
+// AdminDeleteCatalogItem deletes a row that nothing references any more;
+// otherwise it answers 409 with the referencing row counts. Unpublish rows
+// that are still in use instead.
+func AdminDeleteCatalogItem(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+        return
+    }
+    userID, _ := middleware.GetUserID(c)
+    refs, err := services.DeleteCatalogItem(database.DB, t, uint(id), userID)
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+    case errors.Is(err, services.ErrCatalogInUse):
+        body := middleware.Error(c, "catalog_item_in_use", catalogLabel(c, t))
+        body["references"] = refs
+        c.JSON(http.StatusConflict, body)
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+    default:
+        c.JSON(http.StatusOK, gin.H{"message": t.Label + " deleted successfully"})
+    }
+}
+
+// AdminGetCatalogTranslations returns a row's translations (locale -> field
+// -> text) and the fields that take them.
+func AdminGetCatalogTranslations(c *gin.Context) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    item, ok := loadCatalogItem(c, t)
+    if !ok { return }
+    translations, err := services.CatalogTranslations(database.DB, t, services.CatalogItemID(item))
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": translations, "fields": services.TranslatableFields(t)})
+}
+
+// AdminPutCatalogTranslations replaces a row's translations for :locale. The
+// body maps field to text, e.g. {"title": "Datenanalyst:in"}; fields left out
+// or blank fall back to the English text.
+func AdminPutCatalogTranslations(c *gin.Context) {
+    var fields map[string]string
+    if err := c.ShouldBindJSON(&fields); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    setCatalogTranslations(c, fields)
+}
+
+// AdminDeleteCatalogTranslations removes a row's translations for :locale.
+func AdminDeleteCatalogTranslations(c *gin.Context) { setCatalogTranslations(c, nil) }
+
+func setCatalogTranslations(c *gin.Context, fields map[string]string) {
+    t, ok := catalogType(c)
+    if !ok { return }
+    id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+        return
+    }
+    locale, errs := services.ValidateCatalogTranslations(t, c.Param("locale"), fields)
+    if len(errs) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "catalog_translations_invalid"))
+        return
+    }
+    userID, _ := middleware.GetUserID(c)
+    translations, err := services.SetCatalogTranslations(database.DB, t, uint(id), locale, fields, userID)
+    switch {
+    case errors.Is(err, gorm.ErrRecordNotFound):
+        c.JSON(http.StatusNotFound, middleware.Error(c, "catalog_item_not_found", catalogLabel(c, t)))
+    case err != nil:
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+    default:
+        c.JSON(http.StatusOK, gin.H{"data": translations})
+    }
+}
+
+// AdminCatalogAudit lists catalog changes, newest first. Query: entity_type,
+// entity_id, actor_id. Returns at most 200 entries.
+func AdminCatalogAudit(c *gin.Context) {
+    q := database.DB.Order("created_at DESC, id DESC").Limit(200)
+    if s := c.Query("entity_type"); s != "" { q = q.Where("entity_type = ?", s) }
+    if s := c.Query("entity_id"); s != "" { q = q.Where("entity_id = ?", s) }
+    if s := c.Query("actor_id"); s != "" { q = q.Where("actor_id = ?", s) }
+    var entries []models.CatalogAuditEntry
+    if err := q.Find(&entries).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_catalog_audit_failed"))
+        return
+    }
+    out := make([]services.CatalogAuditView, 0, len(entries))
+    for _, e := range entries { out = append(out, services.ViewCatalogAudit(e)) }
+    c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// AdminApplyCatalogFixture applies one fixture pack (YAML or JSON body, see
+// services.CatalogFixture) and reports what changed. Query: dry_run=true to
+// preview without writing.
+func AdminApplyCatalogFixture(c *gin.Context) {
+    format := documentFormat(c)
+    body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxFixtureSize+1))
+    if err != nil || len(body) > maxFixtureSize {
+        c.JSON(http.StatusRequestEntityTooLarge, middleware.Error(c, "fixture_too_large"))
+        return
+    }
+    fx, err := services.ParseCatalogFixture(body, format)
+    var fxErr *services.CatalogFixtureError
+    if errors.As(err, &fxErr) {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(fxErr.Fields, middleware.GetLocale(c)), "catalog_fixture_invalid"))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    report, err := services.ApplyCatalogFixtures(database.DB, []services.CatalogFixture{fx}, c.Query("dry_run") == "true", time.Now())
+    if errors.As(err, &fxErr) {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(fxErr.Fields, middleware.GetLocale(c)), "catalog_fixture_invalid"))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/config"
+    "goaltracker/database"
+    "goaltracker/i18n"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// certRenewalLeadDays is set from config at startup
+var certRenewalLeadDays = 90
+
+// ConfigureCertifications applies certification settings from config.
+func ConfigureCertifications(cfg *config.Config) {
+    if cfg.CertRenewalLeadDays > 0 { certRenewalLeadDays = cfg.CertRenewalLeadDays }
+}
+
+type certificationPayload struct {
+    Slug            string   `json:"slug"`
+    Name            string   `json:"name" binding:"required"`
+    Issuer          string   `json:"issuer" binding:"required"`
+    ValidityMonths  int      `json:"validity_months"`
+    CreditsRequired float64  `json:"credits_required"`
+    AnnualMinimum   float64  `json:"annual_minimum"`
+    CreditUnit      string   `json:"credit_unit"`
+    Aliases         []string `json:"aliases"`
+}
+
+type userCertificationPayload struct {
+    CertificationID uint   `json:"certification_id"`
+    CredentialID    string `json:"credential_id"`
+    EarnedAt        string `json:"earned_at"`  // YYYY-MM-DD
+    ExpiresAt       string `json:"expires_at"` // YYYY-MM-DD; defaults from the catalog validity
+}
+
+type creditPayload struct {
+    Title       string  `json:"title" binding:"required"`
+    Provider    string  `json:"provider"`
+    Credits     float64 `json:"credits" binding:"required"`
+    EarnedAt    string  `json:"earned_at"` // YYYY-MM-DD, defaults to today
+    EvidenceURL string  `json:"evidence_url"`
+}
+
+func parseDay(field, v string, def *time.Time) (*time.Time, error) {
+    if strings.TrimSpace(v) == "" { return def, nil }
+    t, err := time.Parse("2006-01-02", v)
+    if err != nil { return nil, i18n.Errorf("invalid_date", field) }
+    return &t, nil
+}
+
+// GetCertifications lists the certification catalog. Query: q
+func GetCertifications(c *gin.Context) {
+    query := database.DB.Order("issuer, name")
+    if q := strings.TrimSpace(c.Query("q")); q != "" {
+        like := "%" + strings.ToLower(q) + "%"
+        query = query.Where("LOWER(name) LIKE ? OR LOWER(issuer) LIKE ? OR LOWER(aliases::text) LIKE ?", like, like, like)
+    }
+    var certs []models.Certification
+    if err := query.Find(&certs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_certifications_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": certs})
+}
+
+func loadOwnedCertification(c *gin.Context) (models.UserCertification, bool) {
+    var uc models.UserCertification
+    userID, _ := middleware.GetUserID(c)
+    if err := database.DB.Preload("Certification").Where("user_id = ?", userID).First(&uc, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "certification_not_found"))
+        return uc, false
+    }
+    return uc, true
+}
+
+func certificationView(uc models.UserCertification) services.CertificationView {
+    var credits []models.CECredit
+    database.DB.Where("user_certification_id = ?", uc.ID).Find(&credits)
+    return services.ViewCertification(uc, credits, time.Now(), certRenewalLeadDays)
+}
+
+// GetMyCertifications lists held certifications with expiry status and CE
+// credit totals. Listing also runs the reminder sweep for the caller so
+// reminders are current even between scheduled sweeps.
+func GetMyCertifications(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    if _, err := services.SweepCertifications(database.DB, userID, time.Now(), certRenewalLeadDays); err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "refresh_reminders_failed"))
+        return
+    }
+    var held []models.UserCertification
+    if err := database.DB.Preload("Certification").Where("user_id = ?", userID).Order("expires_at ASC NULLS LAST").Find(&held).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_certifications_failed"))
+        return
+    }
+    out := make([]services.CertificationView, 0, len(held))
+    for _, uc := range held { out = append(out, certificationView(uc)) }
+    c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+func AddMyCertification(c *gin.Context) {
+    var p userCertificationPayload
+    if err := c.ShouldBindJSON(&p); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    var cert models.Certification
+    if err := database.DB.First(&cert, p.CertificationID).Error; err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_certification_id"))
+        return
+    }
+    today := time.Now().UTC().Truncate(24 * time.Hour)
+    earned, err := parseDay("earned_at", p.EarnedAt, &today)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    expires, err := parseDay("expires_at", p.ExpiresAt, services.DefaultExpiry(cert, *earned))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    if expires != nil && !expires.After(*earned) {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "expires_before_earned"))
+        return
+    }
+    if err := middleware.ValidateStringLength("credential_id", p.CredentialID, 0, 100); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    userID, _ := middleware.GetUserID(c)
+    uc := models.UserCertification{
+        UserID: userID, CertificationID: cert.ID, CredentialID: strings.TrimSpace(p.CredentialID),
+        EarnedAt: *earned, CycleStartedAt: *earned, ExpiresAt: expires,
+    }
+    if err := database.DB.Create(&uc).Error; err != nil {
+        c.JSON(http.StatusConflict, middleware.DBError(c, err))
+        return
+    }
+    uc.Certification = cert
+    c.JSON(http.StatusCreated, gin.H{"data": certificationView(uc)})
+}
+
+// UpdateMyCertification corrects the credential id or dates.
+func UpdateMyCertification(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    var p userCertificationPayload
+    if err := c.ShouldBindJSON(&p); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    earned, err := parseDay("earned_at", p.EarnedAt, &uc.EarnedAt)
+    if err == nil {
+        uc.ExpiresAt, err = parseDay("expires_at", p.ExpiresAt, uc.ExpiresAt)
+    }
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    if uc.CycleStartedAt.Equal(uc.EarnedAt) { uc.CycleStartedAt = *earned }
+    uc.EarnedAt = *earned
+    if p.CredentialID != "" { uc.CredentialID = strings.TrimSpace(p.CredentialID) }
+    if err := database.DB.Omit("Certification").Save(&uc).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "update_certification_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": certificationView(uc)})
+}
+
+func DeleteMyCertification(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("user_certification_id = ?", uc.ID).Delete(&models.CECredit{}).Error; err != nil { return err }
+        if err := tx.Where("user_certification_id = ?", uc.ID).Delete(&models.CertificationReminder{}).Error; err != nil { return err }
+        return tx.Delete(&uc).Error
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "delete_certification_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Certification deleted successfully"})
+}
+
+// RenewMyCertification starts a new renewal cycle. Body (optional):
+// {"renewed_at": "YYYY-MM-DD", "expires_at": "YYYY-MM-DD"}. The open renewal
+// goal, if any, is marked completed.
+func RenewMyCertification(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    var p struct {
+        RenewedAt string `json:"renewed_at"`
+        ExpiresAt string `json:"expires_at"`
+    }
+    _ = c.ShouldBindJSON(&p)
+    today := time.Now().UTC().Truncate(24 * time.Hour)
+    renewed, err := parseDay("renewed_at", p.RenewedAt, &today)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    base := *renewed
+    if uc.ExpiresAt != nil && uc.ExpiresAt.After(base) { base = *uc.ExpiresAt }
+    expires, err := parseDay("expires_at", p.ExpiresAt, services.DefaultExpiry(uc.Certification, base))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    userID, _ := middleware.GetUserID(c)
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if uc.RenewalGoalID != nil {
+            var goal models.Goal
+            if tx.Where("user_id = ?", userID).Limit(1).Find(&goal, *uc.RenewalGoalID).RowsAffected > 0 && goal.Status != "completed" {
+                before := goal
+                setGoalStatus(&goal, "completed")
+                goal.Version = before.Version + 1
+                if err := services.SaveVersioned(tx, &goal, before.Version); err != nil { return err }
+                if _, err := services.RecordGoalRevision(tx, &before, goal, userID, "update", nil); err != nil { return err }
+                if err := services.ApplyGoalCompletion(tx, goal); err != nil { return err }
+            }
+        }
+        return tx.Model(&models.UserCertification{}).Where("id = ?", uc.ID).Updates(map[string]interface{}{
+            "cycle_started_at": *renewed,
+            "expires_at":       expires,
+            "renewal_goal_id":  nil,
+        }).Error
+    })
+    if err == services.ErrVersionConflict {
+        c.JSON(http.StatusConflict, middleware.Error(c, "renewal_goal_conflict"))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "renew_certification_failed"))
+        return
+    }
+    uc.CycleStartedAt, uc.ExpiresAt, uc.RenewalGoalID = *renewed, expires, nil
+    c.JSON(http.StatusOK, gin.H{"data": certificationView(uc)})
+}
+
+func GetCertificationCredits(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    var credits []models.CECredit
+    if err := database.DB.Where("user_certification_id = ?", uc.ID).Order("earned_at DESC").Find(&credits).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_credits_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": credits})
+}
+
+// AddCertificationCredit logs CPE/CEU credits. When a renewal goal is open
+// its credits key result gets a snapshot with the cycle total.
+func AddCertificationCredit(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    var p creditPayload
+    if err := c.ShouldBindJSON(&p); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "credit_title_required"))
+        return
+    }
+    if p.Credits <= 0 || p.Credits > 1000 {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "credits_out_of_range"))
+        return
+    }
+    if err := middleware.ValidateStringLength("title", p.Title, 1, middleware.MaxTitleLength); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    today := time.Now().UTC().Truncate(24 * time.Hour)
+    earned, err := parseDay("earned_at", p.EarnedAt, &today)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    userID, _ := middleware.GetUserID(c)
+    credit := models.CECredit{
+        UserID: userID, UserCertificationID: uc.ID, Title: strings.TrimSpace(p.Title), Provider: p.Provider,
+        Credits: p.Credits, EarnedAt: *earned, EvidenceURL: strings.TrimSpace(p.EvidenceURL),
+    }
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&credit).Error; err != nil { return err }
+        if uc.RenewalGoalID == nil { return nil }
+        var credits []models.CECredit
+        if err := tx.Where("user_certification_id = ?", uc.ID).Find(&credits).Error; err != nil { return err }
+        now := time.Now()
+        v := services.ViewCertification(uc, credits, now, certRenewalLeadDays)
+        return tx.Create(&models.KRSnapshot{
+            UserID: userID, GoalID: *uc.RenewalGoalID, KRID: services.RenewalCreditsKRID,
+            Value: v.CreditsEarned, Status: services.RenewalCreditStatus(v, now), CapturedAt: now,
+        }).Error
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "log_credits_failed"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": credit, "certification": certificationView(uc)})
+}
+
+func DeleteCertificationCredit(c *gin.Context) {
+    uc, ok := loadOwnedCertification(c)
+    if !ok { return }
+    res := database.DB.Where("user_certification_id = ?", uc.ID).Delete(&models.CECredit{}, c.Param("credit_id"))
+    if res.Error != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "delete_credit_failed"))
+        return
+    }
+    if res.RowsAffected == 0 {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "credit_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Credit deleted successfully"})
+}
+
+// GetCertificationReminders lists undismissed reminders, newest first.
+// Query: all=true includes dismissed ones.
+func GetCertificationReminders(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    query := database.DB.Where("user_id = ?", userID).Order("created_at DESC")
+    if c.Query("all") != "true" { query = query.Where("dismissed_at IS NULL") }
+    var reminders []models.CertificationReminder
+    if err := query.Find(&reminders).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_reminders_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": reminders})
+}
+
+func DismissCertificationReminder(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    res := database.DB.Model(&models.CertificationReminder{}).
+        Where("user_id = ? AND id = ? AND dismissed_at IS NULL", userID, c.Param("id")).
+        Update("dismissed_at", time.Now())
+    if res.Error != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "dismiss_reminder_failed"))
+        return
+    }
+    if res.RowsAffected == 0 {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "reminder_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Reminder dismissed"})
+}
+
+// AdminUpsertCertification creates a catalog certification or, with :id, updates one.
+func AdminUpsertCertification(c *gin.Context) {
+    var p certificationPayload
+    if err := c.ShouldBindJSON(&p); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "certification_name_required"))
+        return
+    }
+    if p.ValidityMonths < 0 || p.CreditsRequired < 0 || p.AnnualMinimum < 0 {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "negative_requirements"))
+        return
+    }
+    var cert models.Certification
+    status := http.StatusCreated
+    if id := c.Param("id"); id != "" {
+        if err := database.DB.First(&cert, id).Error; err != nil {
+            c.JSON(http.StatusNotFound, middleware.Error(c, "certification_not_found"))
+            return
+        }
+        status = http.StatusOK
+    }
+    cert.Name, cert.Issuer = strings.TrimSpace(p.Name), strings.TrimSpace(p.Issuer)
+    if p.Slug != "" || cert.Slug == "" {
+        cert.Slug = services.SkillSlug(p.Slug)
+        if cert.Slug == "" { cert.Slug = services.SkillSlug(cert.Name) }
+    }
+    cert.ValidityMonths, cert.CreditsRequired, cert.AnnualMinimum = p.ValidityMonths, p.CreditsRequired, p.AnnualMinimum
+    cert.CreditUnit = strings.ToUpper(strings.TrimSpace(p.CreditUnit))
+    aliases := []string{}
+    for _, a := range p.Aliases {
+        if a = strings.TrimSpace(a); a != "" { aliases = append(aliases, a) }
+    }
+    b, _ := json.Marshal(aliases)
+    cert.Aliases = string(b)
+    if err := database.DB.Save(&cert).Error; err != nil {
+        c.JSON(http.StatusBadRequest, middleware.DBError(c, err))
+        return
+    }
+    c.JSON(status, gin.H{"data": cert})
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/i18n"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// maxCycleDays keeps cycles to planning-sized periods
+const maxCycleDays = 366
+
+type cyclePayload struct {
+    Name      string `json:"name"`
+    StartDate string `json:"start_date"` // YYYY-MM-DD
+    EndDate   string `json:"end_date"`   // YYYY-MM-DD, inclusive
+}
+
+// cycleView adds per-caller state to a cycle
+type cycleView struct {
+    models.Cycle
+    Status string              `json:"status"`
+    Review *models.CycleReview `json:"review,omitempty"`
+}
+
+type closeCyclePayload struct {
+    Grades          []services.KRGradeInput `json:"grades"`
+    Reflection      string                  `json:"reflection"`
+    NextCycleID     *uint                   `json:"next_cycle_id"`
+    CreateNext      bool                    `json:"create_next"`
+    RolloverGoalIDs *[]uint                 `json:"rollover_goal_ids"` // omitted = every unfinished goal
+}
+
+func loadVisibleCycle(c *gin.Context, id interface{}) (models.Cycle, bool) {
+    var cycle models.Cycle
+    userID, _ := middleware.GetUserID(c)
+    if err := services.VisibleCycles(database.DB, userID).First(&cycle, id).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "cycle_not_found"))
+        return cycle, false
+    }
+    return cycle, true
+}
+
+func (p cyclePayload) parse() (models.Cycle, error) {
+    var cy models.Cycle
+    cy.Name = strings.TrimSpace(p.Name)
+    if err := middleware.ValidateStringLength("name", cy.Name, 1, 100); err != nil { return cy, err }
+    start, err := time.Parse("2006-01-02", p.StartDate)
+    if err != nil { return cy, i18n.Errorf("invalid_date", "start_date") }
+    end, err := time.Parse("2006-01-02", p.EndDate)
+    if err != nil { return cy, i18n.Errorf("invalid_date", "end_date") }
+    if end.Before(start) { return cy, i18n.Errorf("end_before_start") }
+    if end.Sub(start).Hours()/24 >= maxCycleDays { return cy, i18n.Errorf("cycle_too_long", maxCycleDays) }
+    cy.StartDate, cy.EndDate = start, end
+    return cy, nil
+}
+
+// ListCycles returns org cycles and the caller's own, newest first.
+func ListCycles(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var cycles []models.Cycle
+    if err := services.VisibleCycles(database.DB, userID).Order("start_date DESC").Find(&cycles).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_cycles_failed"))
+        return
+    }
+    var reviews []models.CycleReview
+    database.DB.Where("user_id = ?", userID).Find(&reviews)
+    byCycle := map[uint]*models.CycleReview{}
+    for i := range reviews { byCycle[reviews[i].CycleID] = &reviews[i] }
+
+    now := time.Now()
+    out := make([]cycleView, 0, len(cycles))
+    for _, cy := range cycles {
+        out = append(out, cycleView{Cycle: cy, Status: services.CycleStatus(cy, now), Review: byCycle[cy.ID]})
+    }
+    c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+func GetCycle(c *gin.Context) {
+    cycle, ok := loadVisibleCycle(c, c.Param("id"))
+    if !ok { return }
+    userID, _ := middleware.GetUserID(c)
+
+    goals, err := services.CycleGoals(database.DB, cycle.ID, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_goals_failed"))
+        return
+    }
+    view := cycleView{Cycle: cycle, Status: services.CycleStatus(cycle, time.Now())}
+    var review models.CycleReview
+    if database.DB.Where("user_id = ? AND cycle_id = ?", userID, cycle.ID).Limit(1).Find(&review).RowsAffected > 0 {
+        view.Review = &review
+    }
+    c.JSON(http.StatusOK, gin.H{"data": gin.H{"cycle": view, "goals": goals}})
+}
+
+// CreateCycle creates a personal cycle for the caller.
+func CreateCycle(c *gin.Context) {
+    createCycle(c, "user")
+}
+
+// AdminCreateCycle creates an org-wide cycle visible to every user.
+func AdminCreateCycle(c *gin.Context) {
+    createCycle(c, "org")
+}
+
+func createCycle(c *gin.Context, scope string) {
+    var payload cyclePayload
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    cycle, err := payload.parse()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    cycle.Scope = scope
+    if scope == "user" {
+        userID, _ := middleware.GetUserID(c)
+        cycle.UserID = &userID
+    }
+    if err := database.DB.Create(&cycle).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "create_cycle_failed"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": cycleView{Cycle: cycle, Status: services.CycleStatus(cycle, time.Now())}})
+}
+
+// UpdateCycle renames or re-dates one of the caller's own cycles. Org
+// cycles are managed by admins.
+func UpdateCycle(c *gin.Context) {
+    var cycle models.Cycle
+    userID, _ := middleware.GetUserID(c)
+    if err := database.DB.Where("scope = 'user' AND user_id = ?", userID).First(&cycle, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "cycle_not_found"))
+        return
+    }
+    var payload cyclePayload
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    updated, err := payload.parse()
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    cycle.Name, cycle.StartDate, cycle.EndDate = updated.Name, updated.StartDate, updated.EndDate
+    if err := database.DB.Save(&cycle).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "update_cycle_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": cycleView{Cycle: cycle, Status: services.CycleStatus(cycle, time.Now())}})
+}
+
+// AttachCycleGoals moves the caller's goals into a cycle. Body: {"goal_ids": [..]}
+func AttachCycleGoals(c *gin.Context) {
+    cycle, ok := loadVisibleCycle(c, c.Param("id"))
+    if !ok { return }
+    userID, _ := middleware.GetUserID(c)
+
+    var payload struct {
+        GoalIDs []uint `json:"goal_ids" binding:"required"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil || len(payload.GoalIDs) == 0 {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "goal_ids"))
+        return
+    }
+    err := services.AttachCycleGoals(database.DB, cycle.ID, userID, payload.GoalIDs)
+    var conflict *services.GoalConflictError
+    if errors.As(err, &conflict) {
+        respondGoalConflict(c, conflict.GoalID)
+        return
+    }
+    if errors.Is(err, services.ErrGoalsNotFound) {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "goals_not_found"))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "attach_goals_failed"))
+        return
+    }
+    goals, _ := services.CycleGoals(database.DB, cycle.ID, userID)
+    c.JSON(http.StatusOK, gin.H{"data": goals})
+}
+
+// GetCycleGrading returns the grading sheet: one row per KR (or per goal
+// without KRs) with a suggested 0.0-1.0 grade and any saved grade.
+func GetCycleGrading(c *gin.Context) {
+    cycle, ok := loadVisibleCycle(c, c.Param("id"))
+    if !ok { return }
+    userID, _ := middleware.GetUserID(c)
+
+    rows, err := services.GradingSheet(database.DB, cycle.ID, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "build_grading_sheet_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+// SaveCycleGrades stores draft grades before the cycle is closed.
+func SaveCycleGrades(c *gin.Context) {
+    cycle, ok := loadVisibleCycle(c, c.Param("id"))
+    if !ok { return }
+    userID, _ := middleware.GetUserID(c)
+    if services.CycleClosed(database.DB, cycle.ID, userID) {
+        c.JSON(http.StatusConflict, middleware.Error(c, "cycle_closed"))
+        return
+    }
+    var payload struct {
+        Grades []services.KRGradeInput `json:"grades"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    goals, err := services.CycleGoals(database.DB, cycle.ID, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_goals_failed"))
+        return
+    }
+    if err := services.ValidateGrades(payload.Grades, goals); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    if err := services.SaveGrades(database.DB, cycle.ID, userID, payload.Grades); err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "save_grades_failed"))
+        return
+    }
+    rows, _ := services.GradingSheet(database.DB, cycle.ID, userID)
+    c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+// CloseCycle validates the close request and hands the end-of-cycle workflow
+// to services.CloseCycle.
+func CloseCycle(c *gin.Context) {
+    cycle, ok := loadVisibleCycle(c, c.Param("id"))
+    if !ok { return }
+    userID, _ := middleware.GetUserID(c)
+    if services.CycleClosed(database.DB, cycle.ID, userID) {
+        c.JSON(http.StatusConflict, middleware.Error(c, "cycle_closed"))
+        return
+    }
+
+    var payload closeCyclePayload
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
+        return
+    }
+    if err := middleware.ValidateStringLength("reflection", payload.Reflection, 0, middleware.MaxDescriptionLength); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    goals, err := services.CycleGoals(database.DB, cycle.ID, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_goals_failed"))
+        return
+    }
+    if err := services.ValidateGrades(payload.Grades, goals); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    rollover, err := services.SelectRollover(goals, payload.RolloverGoalIDs)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    var next *models.Cycle
+    if payload.NextCycleID != nil {
+        n, ok := loadVisibleCycle(c, *payload.NextCycleID)
+        if !ok { return }
+        if n.ID == cycle.ID {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "next_cycle_same"))
+            return
+        }
+        next = &n
+    } else if payload.CreateNext {
+        next = services.ProposeNextCycle(database.DB, cycle, userID)
+    }
+    if len(rollover) > 0 && next == nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "next_cycle_required"))
+        return
+    }
+
+    closure, err := services.CloseCycle(database.DB, cycle, userID, payload.Grades, payload.Reflection, next, rollover, time.Now())
+    var conflict *services.GoalConflictError
+    if errors.As(err, &conflict) {
+        respondGoalConflict(c, conflict.GoalID)
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "close_cycle_failed"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": closure})
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strconv"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// loadOwnedGoalUnscoped finds a goal owned by the caller, including
+// soft-deleted ones so their history stays reachable.
+func loadOwnedGoalUnscoped(c *gin.Context) (models.Goal, bool) {
+    var goal models.Goal
+    userID, _ := middleware.GetUserID(c)
+    if err := database.DB.Unscoped().Where("user_id = ?", userID).First(&goal, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
+        return goal, false
+    }
+    return goal, true
+}
+
+func loadGoalRevision(goalID uint, revParam string) (models.GoalRevision, error) {
+    var rev models.GoalRevision
+    n, err := strconv.Atoi(revParam)
+    if err != nil {
+        return rev, gorm.ErrRecordNotFound
+    }
+    err = database.DB.Where("goal_id = ? AND revision = ?", goalID, n).First(&rev).Error
+    return rev, err
+}
+
+// ListGoalRevisions returns every revision of a goal (newest first) along
+// with the due-date slips across its history.
+func ListGoalRevisions(c *gin.Context) {
+    goal, ok := loadOwnedGoalUnscoped(c)
+    if !ok { return }
+
+    var revs []models.GoalRevision
+    if err := database.DB.Where("goal_id = ?", goal.ID).Order("revision DESC").Find(&revs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_revisions_failed"))
+        return
+    }
+    views := make([]services.GoalRevisionView, 0, len(revs))
+    for _, r := range revs { views = append(views, services.ViewGoalRevision(r)) }
+
+    c.JSON(http.StatusOK, gin.H{
+        "data":           views,
+        "due_date_slips": services.DueDateSlips(views),
+    })
+}
+
+func GetGoalRevision(c *gin.Context) {
+    goal, ok := loadOwnedGoalUnscoped(c)
+    if !ok { return }
+
+    rev, err := loadGoalRevision(goal.ID, c.Param("rev"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "revision_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": services.ViewGoalRevision(rev)})
+}
+
+// DiffGoalRevisions compares two revisions. Query: from, to (revision numbers).
+func DiffGoalRevisions(c *gin.Context) {
+    goal, ok := loadOwnedGoalUnscoped(c)
+    if !ok { return }
+
+    from, err := loadGoalRevision(goal.ID, c.Query("from"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "from_revision_not_found"))
+        return
+    }
+    to, err := loadGoalRevision(goal.ID, c.Query("to"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "to_revision_not_found"))
+        return
+    }
+
+    a, b := services.ViewGoalRevision(from), services.ViewGoalRevision(to)
+    c.JSON(http.StatusOK, gin.H{"data": gin.H{
+        "from":    from.Revision,
+        "to":      to.Revision,
+        "changes": services.DiffGoalSnapshots(a.Snapshot, b.Snapshot),
+    }})
+}
+
+// RestoreGoalRevision rewrites the goal to a prior revision's snapshot and
+// records the restore as a new revision. Restoring un-deletes the goal.
+func RestoreGoalRevision(c *gin.Context) {
+    goal, ok := loadOwnedGoalUnscoped(c)
+    if !ok { return }
+
+    rev, err := loadGoalRevision(goal.ID, c.Param("rev"))
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "revision_not_found"))
+        return
+    }
+    if !middleware.CheckIfMatch(c, middleware.VersionETag("goal", goal.ID, goal.Version), goal) { return }
+    var snap services.GoalSnapshot
+    if err := json.Unmarshal([]byte(rev.Snapshot), &snap); err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "revision_unreadable"))
+        return
+    }
+
+    userID, _ := middleware.GetUserID(c)
+    before := goal
+    services.ApplySnapshot(&goal, snap)
+    goal.DeletedAt = gorm.DeletedAt{}
+    goal.Version = before.Version + 1
+    restoredFrom := rev.Revision
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := services.SaveVersioned(tx.Unscoped(), &goal, before.Version); err != nil { return err }
+        _, err := services.RecordGoalRevision(tx, &before, goal, userID, "restore", &restoredFrom)
+        return err
+    })
+    if err == services.ErrVersionConflict {
+        respondGoalConflict(c, goal.ID)
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "restore_goal_failed"))
+        return
+    }
+
+    database.DB.Preload("JobRole").Preload("Progress").First(&goal, goal.ID)
+    c.Header("ETag", goalETag(goal))
+    c.JSON(http.StatusOK, gin.H{"data": goal})
+}
@@ -8,7 +8,9 @@ import (
     "goaltracker/database"
     "goaltracker/models"
     "goaltracker/middleware"
+    "goaltracker/services"
     "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
 )
 
 func GetGoals(c *gin.Context) {
@@ -26,22 +28,36 @@ func GetGoals(c *gin.Context) {
 	}
 	
 	if err := query.Find(&goals).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch goals"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_goals_failed"))
 		return
 	}
+	if middleware.NotModified(c, middleware.HashETag(goals)) { return }
 	
 	c.JSON(http.StatusOK, gin.H{"data": goals})
 }
 
+// goalETag is the version ETag plus a fingerprint of the preloaded representation
+func goalETag(goal models.Goal) string {
+    return middleware.ContentETag(middleware.VersionETag("goal", goal.ID, goal.Version), goal)
+}
+
+// respondGoalConflict reloads the goal and answers 412 with its current state
+func respondGoalConflict(c *gin.Context, id uint) {
+    var current models.Goal
+    database.DB.Preload("JobRole").Preload("Progress").First(&current, id)
+    middleware.PreconditionFailed(c, goalETag(current), current)
+}
+
 func GetGoal(c *gin.Context) {
 	id := c.Param("id")
 	var goal models.Goal
 	
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Preload("JobRole").Preload("Progress").Where("user_id = ?", userID).First(&goal, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
 		return
 	}
+	if middleware.NotModified(c, goalETag(goal)) { return }
 	
 	c.JSON(http.StatusOK, gin.H{"data": goal})
 }
@@ -49,7 +65,7 @@ func GetGoal(c *gin.Context) {
 func CreateGoal(c *gin.Context) {
     var payload map[string]interface{}
     if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_request"))
 		return
 	}
     
@@ -57,24 +73,25 @@ func CreateGoal(c *gin.Context) {
     var goal models.Goal
     if v, ok := payload["title"].(string); ok { 
         if err := middleware.ValidateStringLength("title", v, 1, middleware.MaxTitleLength); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
             return
         }
         goal.Title = v 
     } else {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "title"))
         return
     }
     
     if v, ok := payload["description"].(string); ok { 
         if err := middleware.ValidateStringLength("description", v, 0, middleware.MaxDescriptionLength); err != nil {
-            c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
             return
         }
         goal.Description = v 
     }
     if v, ok := payload["priority"].(string); ok { goal.Priority = v }
     if v, ok := payload["status"].(string); ok { goal.Status = v }
+    if goal.Status == "completed" { now := time.Now(); goal.CompletedAt = &now }
     // due_date can be ISO string
     if v, ok := payload["due_date"].(string); ok && v != "" {
         if t, err := time.Parse(time.RFC3339, v); err == nil {
@@ -92,6 +109,10 @@ func CreateGoal(c *gin.Context) {
     if mr, ok := payload["metadata"]; ok && mr != nil { metaRaw = mr }
     if metaRaw != nil {
         if b, err := json.Marshal(metaRaw); err == nil { goal.Metadata = string(b) }
+        if err := services.ValidateGoalMilestones(goal.Metadata); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+            return
+        }
     }
     // optional job_role_id
     if v, ok := payload["job_role_id"].(float64); ok { // JSON numbers are float64
@@ -100,9 +121,23 @@ func CreateGoal(c *gin.Context) {
     }
 
     userID, _ := middleware.GetUserID(c)
+    if v, ok := payload["cycle_id"].(float64); ok {
+        id := uint(v)
+        if !services.CycleVisibleTo(database.DB, id, userID) {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_cycle_id"))
+            return
+        }
+        goal.CycleID = &id
+    }
     goal.UserID = userID
-    if err := database.DB.Create(&goal).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create goal"})
+    goal.Version = 1
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Create(&goal).Error; err != nil { return err }
+        _, err := services.RecordGoalRevision(tx, nil, goal, userID, "create", nil)
+        return err
+    })
+    if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "create_goal_failed"))
 		return
 	}
 	
@@ -135,6 +170,7 @@ func CreateGoal(c *gin.Context) {
     }
 
 	database.DB.Preload("JobRole").Preload("Progress").First(&goal, goal.ID)
+	c.Header("ETag", goalETag(goal))
 	
 	c.JSON(http.StatusCreated, gin.H{"data": goal})
 }
@@ -145,19 +181,21 @@ func UpdateGoal(c *gin.Context) {
 	
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Where("user_id = ?", userID).First(&goal, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
 		return
 	}
+    if !middleware.CheckIfMatch(c, middleware.VersionETag("goal", goal.ID, goal.Version), goal) { return }
 	
+    before := goal
     var payload map[string]interface{}
     if err := c.ShouldBindJSON(&payload); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
         return
     }
     if v, ok := payload["title"].(string); ok { goal.Title = v }
     if v, ok := payload["description"].(string); ok { goal.Description = v }
     if v, ok := payload["priority"].(string); ok { goal.Priority = v }
-    if v, ok := payload["status"].(string); ok { goal.Status = v }
+    if v, ok := payload["status"].(string); ok { setGoalStatus(&goal, v) }
     if v, ok := payload["due_date"].(string); ok {
         if v == "" { goal.DueDate = nil } else if t, err := time.Parse(time.RFC3339, v); err == nil { goal.DueDate = &t }
     }
@@ -166,17 +204,58 @@ func UpdateGoal(c *gin.Context) {
     }
     if metaRaw, ok := payload["metadata"]; ok {
         if metaRaw == nil { goal.Metadata = "" } else if b, err := json.Marshal(metaRaw); err == nil { goal.Metadata = string(b) }
+        if err := services.ValidateGoalMilestones(goal.Metadata); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+            return
+        }
     }
     if v, ok := payload["job_role_id"].(float64); ok { id := uint(v); goal.JobRoleID = &id }
+    if v, ok := payload["cycle_id"]; ok {
+        if v == nil {
+            goal.CycleID = nil
+        } else if f, ok := v.(float64); ok && services.CycleVisibleTo(database.DB, uint(f), userID) {
+            id := uint(f); goal.CycleID = &id
+        } else {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_cycle_id"))
+            return
+        }
+    }
 	
     // ensure the record stays bound to the same user
     goal.UserID = userID
-    if err := database.DB.Save(&goal).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update goal"})
+    persistGoalUpdate(c, before, goal, userID)
+}
+
+// setGoalStatus tracks completion time for analytics; reopening a goal clears it
+func setGoalStatus(goal *models.Goal, status string) {
+    if status == "completed" && goal.Status != "completed" { now := time.Now(); goal.CompletedAt = &now }
+    if status != "completed" { goal.CompletedAt = nil }
+    goal.Status = status
+}
+
+// persistGoalUpdate saves goal over before with a version check, records the
+// revision, and writes the response.
+func persistGoalUpdate(c *gin.Context, before, goal models.Goal, userID string) {
+    goal.Version = before.Version + 1
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := services.SaveVersioned(tx, &goal, before.Version); err != nil { return err }
+        if _, err := services.RecordGoalRevision(tx, &before, goal, userID, "update", nil); err != nil { return err }
+        if goal.Status == "completed" && before.Status != "completed" {
+            return services.ApplyGoalCompletion(tx, goal)
+        }
+        return nil
+    })
+    if err == services.ErrVersionConflict {
+        respondGoalConflict(c, goal.ID)
+        return
+    }
+    if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "update_goal_failed"))
 		return
 	}
 	
 	database.DB.Preload("JobRole").Preload("Progress").First(&goal, goal.ID)
+	c.Header("ETag", goalETag(goal))
 	
 	c.JSON(http.StatusOK, gin.H{"data": goal})
 }
@@ -184,8 +263,22 @@ func UpdateGoal(c *gin.Context) {
 func DeleteGoal(c *gin.Context) {
     id := c.Param("id")
     userID, _ := middleware.GetUserID(c)
-    if err := database.DB.Where("user_id = ?", userID).Delete(&models.Goal{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete goal"})
+    var goal models.Goal
+    if err := database.DB.Where("user_id = ?", userID).First(&goal, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
+		return
+	}
+    if !middleware.CheckIfMatch(c, middleware.VersionETag("goal", goal.ID, goal.Version), goal) { return }
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        if _, err := services.RecordGoalRevision(tx, &goal, goal, userID, "delete", nil); err != nil { return err }
+        return services.DeleteVersioned(tx, &goal, goal.Version)
+    })
+    if err == services.ErrVersionConflict {
+        respondGoalConflict(c, goal.ID)
+        return
+    }
+    if err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "delete_goal_failed"))
 		return
 	}
 	
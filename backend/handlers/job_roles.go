@@ -1,20 +1,24 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"goaltracker/database"
+	"goaltracker/middleware"
 	"goaltracker/models"
+	"goaltracker/services"
 	"github.com/gin-gonic/gin"
 )
 
 func GetJobRoles(c *gin.Context) {
 	var jobRoles []models.JobRole
 	
-	if err := database.DB.Find(&jobRoles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job roles"})
+	if err := database.DB.Scopes(services.Published("job_roles")).Find(&jobRoles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_job_roles_failed"))
 		return
 	}
 	
+	localizeCatalog(c, jobRoles)
 	c.JSON(http.StatusOK, gin.H{"data": jobRoles})
 }
 
@@ -22,26 +26,20 @@ func GetJobRole(c *gin.Context) {
 	id := c.Param("id")
 	var jobRole models.JobRole
 	
-	if err := database.DB.First(&jobRole, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Job role not found"})
+	if err := database.DB.Scopes(services.Published("job_roles")).First(&jobRole, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, middleware.Error(c, "job_role_not_found"))
 		return
 	}
 	
+	localizeCatalog(c, &jobRole)
 	c.JSON(http.StatusOK, gin.H{"data": jobRole})
 }
 
-func CreateJobRole(c *gin.Context) {
-	var jobRole models.JobRole
-	
-	if err := c.ShouldBindJSON(&jobRole); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-	
-	if err := database.DB.Create(&jobRole).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create job role"})
-		return
+// localizeCatalog translates the catalog rows in v (see
+// services.LocalizeCatalog) into the request's locale. Rows keep their
+// English text when the lookup fails.
+func localizeCatalog(c *gin.Context, v interface{}) {
+	if err := services.LocalizeCatalog(database.DB, middleware.GetLocale(c), v); err != nil {
+		log.Printf("Failed to load catalog translations: %v", err)
 	}
-	
-	c.JSON(http.StatusCreated, gin.H{"data": jobRole})
-}
\ No newline at end of file
+}
@@ -0,0 +1,277 @@
+package handlers
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "io"
+    "mime"
+    "net/http"
+    "reflect"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/i18n"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// maxPatchSize bounds PATCH bodies independently of the global request limit
+const maxPatchSize = 2 * middleware.MaxJSONFieldSize
+
+var goalStatuses = map[string]struct{}{"active": {}, "completed": {}, "paused": {}}
+var goalPriorities = map[string]struct{}{"low": {}, "medium": {}, "high": {}}
+
+// goalDocument is the patchable view of a goal. Metadata is a nested object
+// so patches can address paths like /metadata/smart/specific; legacy
+// metadata that isn't one is carried as is and only rejected when a patch
+// changes it.
+type goalDocument struct {
+    Title       string      `json:"title"`
+    Description string      `json:"description"`
+    Status      string      `json:"status"`
+    Priority    string      `json:"priority"`
+    DueDate     *time.Time  `json:"due_date"`
+    Tags        []string    `json:"tags"`
+    Metadata    interface{} `json:"metadata"`
+    JobRoleID   *uint       `json:"job_role_id"`
+    CycleID     *uint       `json:"cycle_id"`
+}
+
+// profileDocument is the patchable view of a user profile.
+type profileDocument struct {
+    CurrentRole        string                 `json:"current_role"`
+    ExperienceLevel    string                 `json:"experience_level"`
+    Industry           string                 `json:"industry"`
+    CompanySize        string                 `json:"company_size"`
+    LearningStyle      string                 `json:"learning_style"`
+    AvailableHoursWeek int                    `json:"available_hours_week"`
+    CareerGoals        string                 `json:"career_goals"`
+    CurrentTools       string                 `json:"current_tools"`
+    SkillGaps          string                 `json:"skill_gaps"`
+    ITProfile          interface{}            `json:"it_profile"` // object, or legacy content carried as is
+    Locale             string                 `json:"locale"`
+}
+
+func goalDocumentFrom(g models.Goal) map[string]interface{} {
+    doc := map[string]interface{}{
+        "title":       g.Title,
+        "description": g.Description,
+        "status":      g.Status,
+        "priority":    g.Priority,
+        "due_date":    g.DueDate,
+        "tags":        services.GoalTags(g.Tags),
+        "metadata":    decodeStoredJSON(g.Metadata),
+        "job_role_id": g.JobRoleID,
+        "cycle_id":    g.CycleID,
+    }
+    return normalizeDocument(doc)
+}
+
+func profileDocumentFrom(p models.UserProfile) map[string]interface{} {
+    it := decodeStoredJSON(p.ITProfile)
+    if it == nil { it = map[string]interface{}{} }
+    doc := map[string]interface{}{
+        "current_role":         p.CurrentRole,
+        "experience_level":     p.ExperienceLevel,
+        "industry":             p.Industry,
+        "company_size":         p.CompanySize,
+        "learning_style":       p.LearningStyle,
+        "available_hours_week": p.AvailableHoursWeek,
+        "career_goals":         p.CareerGoals,
+        "current_tools":        p.CurrentTools,
+        "skill_gaps":           p.SkillGaps,
+        "it_profile":           it,
+        "locale":               p.Locale,
+    }
+    return normalizeDocument(doc)
+}
+
+// validate checks the patched document; metadata is only checked when the
+// patch changed it.
+func (d goalDocument) validate(userID string, metadataChanged bool) error {
+    if err := middleware.ValidateStringLength("title", d.Title, 1, middleware.MaxTitleLength); err != nil { return err }
+    if err := middleware.ValidateStringLength("description", d.Description, 0, middleware.MaxDescriptionLength); err != nil { return err }
+    if _, ok := goalStatuses[d.Status]; !ok { return i18n.Errorf("invalid_field", "status") }
+    if _, ok := goalPriorities[d.Priority]; !ok { return i18n.Errorf("invalid_field", "priority") }
+    if metadataChanged && d.Metadata != nil {
+        if _, ok := d.Metadata.(map[string]interface{}); !ok { return i18n.Errorf("invalid_field", "metadata") }
+        b, _ := json.Marshal(d.Metadata)
+        if err := middleware.ValidateJSONSize("metadata", string(b), middleware.MaxJSONFieldSize); err != nil { return err }
+        if err := services.ValidateGoalMilestones(string(b)); err != nil { return err }
+    }
+    if d.CycleID != nil && !services.CycleVisibleTo(database.DB, *d.CycleID, userID) { return i18n.Errorf("invalid_field", "cycle_id") }
+    return nil
+}
+
+// applyTo writes the document onto g, keeping stored metadata verbatim
+// unless the patch changed it.
+func (d goalDocument) applyTo(g *models.Goal, metadataChanged bool) {
+    g.Title = d.Title
+    g.Description = d.Description
+    g.Priority = d.Priority
+    setGoalStatus(g, d.Status)
+    g.DueDate = d.DueDate
+    g.Tags = ""
+    if d.Tags != nil { b, _ := json.Marshal(d.Tags); g.Tags = string(b) }
+    if metadataChanged {
+        g.Metadata = ""
+        if d.Metadata != nil { b, _ := json.Marshal(d.Metadata); g.Metadata = string(b) }
+    }
+    g.JobRoleID = d.JobRoleID
+    g.CycleID = d.CycleID
+}
+
+func (d profileDocument) validate(itProfileChanged bool) error {
+    if err := middleware.ValidateStringLength("current_role", d.CurrentRole, 0, 100); err != nil { return err }
+    if err := middleware.ValidateStringLength("industry", d.Industry, 0, 100); err != nil { return err }
+    if err := middleware.ValidateStringLength("career_goals", d.CareerGoals, 0, 2000); err != nil { return err }
+    if _, ok := expLevels[d.ExperienceLevel]; !ok { return i18n.Errorf("invalid_field", "experience_level") }
+    if d.AvailableHoursWeek < 0 || d.AvailableHoursWeek > 168 { return i18n.Errorf("invalid_field", "available_hours_week") }
+    if _, err := profileLocale(d.Locale); err != nil { return err }
+    if _, ok := d.ITProfile.(map[string]interface{}); itProfileChanged && !ok { return i18n.Errorf("invalid_field", "it_profile") }
+    return nil
+}
+
+func (d profileDocument) applyTo(p *models.UserProfile) {
+    p.CurrentRole = d.CurrentRole
+    p.ExperienceLevel = d.ExperienceLevel
+    p.Industry = d.Industry
+    p.CompanySize = d.CompanySize
+    p.LearningStyle = d.LearningStyle
+    p.AvailableHoursWeek = d.AvailableHoursWeek
+    p.CareerGoals = d.CareerGoals
+    p.CurrentTools = d.CurrentTools
+    p.SkillGaps = d.SkillGaps
+    p.Locale, _ = profileLocale(d.Locale)
+}
+
+// PatchGoal applies a merge patch or JSON Patch to a goal, including nested
+// metadata paths. The whole patch is validated before anything is written.
+func PatchGoal(c *gin.Context) {
+    var goal models.Goal
+    userID, _ := middleware.GetUserID(c)
+    if err := database.DB.Where("user_id = ?", userID).First(&goal, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
+        return
+    }
+    if !middleware.CheckIfMatch(c, middleware.VersionETag("goal", goal.ID, goal.Version), goal) { return }
+
+    stored := goalDocumentFrom(goal)
+    var doc goalDocument
+    if !patchDocument(c, stored, &doc) { return }
+    metadataChanged := !reflect.DeepEqual(stored["metadata"], doc.Metadata)
+    if err := doc.validate(userID, metadataChanged); err != nil {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    before := goal
+    doc.applyTo(&goal, metadataChanged)
+    persistGoalUpdate(c, before, goal, userID)
+}
+
+// PatchUserProfile applies a merge patch or JSON Patch to the caller's
+// profile, including nested it_profile paths.
+func PatchUserProfile(c *gin.Context) {
+    var profile models.UserProfile
+    userID, _ := middleware.GetUserID(c)
+    if err := database.DB.Where("user_id = ?", userID).First(&profile, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "profile_not_found"))
+        return
+    }
+    if !middleware.CheckIfMatch(c, profileETag(profile), profile) { return }
+
+    stored := profileDocumentFrom(profile)
+    var doc profileDocument
+    if !patchDocument(c, stored, &doc) { return }
+    itProfileChanged := !reflect.DeepEqual(stored["it_profile"], doc.ITProfile)
+    if err := doc.validate(itProfileChanged); err != nil {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    before := profile
+    doc.applyTo(&profile)
+    if itProfileChanged {
+        it, ok := parseITProfile(c, http.StatusUnprocessableEntity, doc.ITProfile)
+        if !ok { return }
+        profile.ITProfile = it
+    }
+    persistProfileUpdate(c, before, profile)
+}
+
+// patchDocument reads the request body, applies it to doc according to the
+// Content-Type, and strictly decodes the result into out. It writes the
+// error response and returns false on failure.
+func patchDocument(c *gin.Context, doc map[string]interface{}, out interface{}) bool {
+    mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+    if mediaType != services.MergePatchContentType && mediaType != services.JSONPatchContentType {
+        c.JSON(http.StatusUnsupportedMediaType, middleware.Error(c, "unsupported_patch_type", services.MergePatchContentType, services.JSONPatchContentType))
+        return false
+    }
+    body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxPatchSize+1))
+    if err != nil || len(body) > maxPatchSize {
+        c.JSON(http.StatusRequestEntityTooLarge, middleware.Error(c, "patch_too_large"))
+        return false
+    }
+
+    var patched interface{}
+    if mediaType == services.MergePatchContentType {
+        var patch interface{}
+        if err := json.Unmarshal(body, &patch); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_merge_patch"))
+            return false
+        }
+        if _, ok := patch.(map[string]interface{}); !ok {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "merge_patch_not_object"))
+            return false
+        }
+        patched = services.MergePatch(doc, patch)
+    } else {
+        var ops []services.JSONPatchOp
+        if err := json.Unmarshal(body, &ops); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json_patch"))
+            return false
+        }
+        patched, err = services.ApplyJSONPatch(doc, ops)
+        if errors.Is(err, services.ErrPatchTestFailed) {
+            c.JSON(http.StatusConflict, middleware.ErrorFrom(c, err, "patch_test_failed"))
+            return false
+        }
+        if err != nil {
+            c.JSON(http.StatusUnprocessableEntity, middleware.ErrorFrom(c, err, "invalid_json_patch"))
+            return false
+        }
+    }
+
+    b, _ := json.Marshal(patched)
+    dec := json.NewDecoder(bytes.NewReader(b))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(out); err != nil {
+        c.JSON(http.StatusUnprocessableEntity, middleware.Error(c, "patched_document_invalid", strings.TrimPrefix(err.Error(), "json: ")))
+        return false
+    }
+    return true
+}
+
+// decodeStoredJSON decodes a JSON column for a patch document. Legacy
+// content that isn't JSON is carried as a string so it survives patches
+// that don't touch it.
+func decodeStoredJSON(s string) interface{} {
+    if strings.TrimSpace(s) == "" { return nil }
+    var v interface{}
+    if err := json.Unmarshal([]byte(s), &v); err != nil { return s }
+    return v
+}
+
+func normalizeDocument(doc map[string]interface{}) map[string]interface{} {
+    b, _ := json.Marshal(doc)
+    var out map[string]interface{}
+    _ = json.Unmarshal(b, &out)
+    return out
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// maxWeeklyHours bounds the ?hours what-if override.
+const maxWeeklyHours = 80
+
+// GetPlan previews the week-by-week milestone schedule without saving it.
+// Query: hours overrides the profile's available_hours_week.
+func GetPlan(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    hours, ok := plannerHours(c)
+    if !ok { return }
+    plan, _, err := services.LoadPlan(database.DB, userID, hours, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "build_plan_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// ApplyPlan re-plans from today and writes the scheduled due dates into each
+// goal's metadata.milestones, so it is also how users re-plan after a slip.
+// Body (optional): {"goal_ids": [...]} to update only those goals.
+// Query: hours as for GetPlan.
+func ApplyPlan(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    hours, ok := plannerHours(c)
+    if !ok { return }
+    var payload struct {
+        GoalIDs []uint `json:"goal_ids"`
+    }
+    if c.Request.ContentLength > 0 {
+        if err := c.ShouldBindJSON(&payload); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+            return
+        }
+    }
+    only := map[uint]bool{}
+    for _, id := range payload.GoalIDs { only[id] = true }
+
+    var plan services.Plan
+    updated := []uint{}
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        var goals []models.Goal
+        var err error
+        plan, goals, err = services.LoadPlan(tx, userID, hours, time.Now())
+        if err != nil { return err }
+        for _, g := range goals {
+            if len(only) > 0 && !only[g.ID] { continue }
+            before := g
+            changed, err := services.ApplyPlanDates(&g, plan.Milestones)
+            if err != nil { return err }
+            if !changed { continue }
+            g.Version = before.Version + 1
+            if err := services.SaveVersioned(tx, &g, before.Version); err != nil {
+                if errors.Is(err, services.ErrVersionConflict) { return &services.GoalConflictError{GoalID: g.ID} }
+                return err
+            }
+            if _, err := services.RecordGoalRevision(tx, &before, g, userID, "update", nil); err != nil { return err }
+            updated = append(updated, g.ID)
+        }
+        return nil
+    })
+    var conflict *services.GoalConflictError
+    if errors.As(err, &conflict) {
+        respondGoalConflict(c, conflict.GoalID)
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "apply_plan_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": plan, "updated_goal_ids": updated})
+}
+
+func plannerHours(c *gin.Context) (int, bool) {
+    v := c.Query("hours")
+    if v == "" { return 0, true }
+    n, err := strconv.Atoi(v)
+    if err != nil || n < 1 || n > maxWeeklyHours {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "hours_out_of_range"))
+        return 0, false
+    }
+    return n, true
+}
+
+// ListBlockedPeriods returns the caller's blocked-out dates, current and future.
+func ListBlockedPeriods(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var periods []models.BlockedPeriod
+    q := database.DB.Where("user_id = ?", userID)
+    if c.Query("all") != "true" { q = q.Where("end_date >= ?", time.Now().Format("2006-01-02")) }
+    if err := q.Order("start_date").Find(&periods).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_blocked_periods_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": periods})
+}
+
+// CreateBlockedPeriod blocks out a span of days. Body:
+// {"start_date": "YYYY-MM-DD", "end_date": "YYYY-MM-DD" (defaults to start), "reason"}
+func CreateBlockedPeriod(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var payload struct {
+        StartDate string `json:"start_date"`
+        EndDate   string `json:"end_date"`
+        Reason    string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    start, err := time.Parse("2006-01-02", payload.StartDate)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_date", "start_date"))
+        return
+    }
+    end := start
+    if payload.EndDate != "" {
+        if end, err = time.Parse("2006-01-02", payload.EndDate); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_date", "end_date"))
+            return
+        }
+    }
+    if end.Before(start) {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "end_before_start"))
+        return
+    }
+    if end.Sub(start) > 366*24*time.Hour {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "blocked_period_too_long"))
+        return
+    }
+    if err := middleware.ValidateStringLength("reason", payload.Reason, 0, 200); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    period := models.BlockedPeriod{UserID: userID, StartDate: start, EndDate: end, Reason: strings.TrimSpace(payload.Reason)}
+    if err := database.DB.Create(&period).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "save_blocked_period_failed"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": period})
+}
+
+func DeleteBlockedPeriod(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    res := database.DB.Where("user_id = ?", userID).Delete(&models.BlockedPeriod{}, c.Param("id"))
+    if res.Error != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "delete_blocked_period_failed"))
+        return
+    }
+    if res.RowsAffected == 0 {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "blocked_period_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Blocked period deleted successfully"})
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+    "errors"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// PendingPolicies adapts services.PendingPolicies for
+// middleware.RequirePolicyAcceptance.
+func PendingPolicies(userID string) ([]middleware.RequiredPolicy, error) {
+    pending, err := services.PendingPolicies(database.DB, userID, time.Now())
+    if err != nil { return nil, err }
+    out := make([]middleware.RequiredPolicy, 0, len(pending))
+    for _, p := range pending {
+        out = append(out, middleware.RequiredPolicy{Kind: p.Kind, Version: p.Version, Title: p.Title, URL: p.URL})
+    }
+    return out, nil
+}
+
+// GetPolicies lists, per kind, the version in effect and any newer version
+// already published with a future effective date.
+func GetPolicies(c *gin.Context) {
+    now := time.Now()
+    current, err := services.CurrentPolicies(database.DB, now)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_policies_failed"))
+        return
+    }
+    out := gin.H{}
+    for _, kind := range services.PolicyKinds {
+        entry := gin.H{"current": nil, "upcoming": nil}
+        if doc, ok := current[kind]; ok { entry["current"] = doc }
+        var upcoming models.PolicyDocument
+        if database.DB.Omit("body").Where("kind = ? AND effective_at > ?", kind, now).
+            Order("effective_at").Limit(1).Find(&upcoming).RowsAffected > 0 {
+            entry["upcoming"] = upcoming
+        }
+        out[kind] = entry
+    }
+    c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// GetPolicyDocument returns one version with its full text.
+func GetPolicyDocument(c *gin.Context) {
+    var doc models.PolicyDocument
+    if err := database.DB.Where("kind = ? AND version = ?", c.Param("kind"), c.Param("version")).First(&doc).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "policy_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": doc})
+}
+
+// GetMyConsents returns the caller's pending policies and full consent ledger.
+func GetMyConsents(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    pending, err := services.PendingPolicies(database.DB, userID, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "check_policies_failed"))
+        return
+    }
+    if pending == nil { pending = []services.PendingPolicy{} }
+    var ledger []models.PolicyAcceptance
+    if err := database.DB.Where("user_id = ?", userID).Order("accepted_at DESC, id DESC").Find(&ledger).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_consent_history_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": gin.H{"pending": pending, "history": ledger}})
+}
+
+// AcceptPolicy records the caller's acceptance. Body: {"kind": "terms",
+// "version": "2.0"}; version defaults to the one in effect.
+func AcceptPolicy(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var payload struct {
+        Kind    string `json:"kind"`
+        Version string `json:"version"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil || (payload.Kind != "terms" && payload.Kind != "privacy") {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "kind", "terms, privacy"))
+        return
+    }
+    var acc models.PolicyAcceptance
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        var err error
+        acc, err = services.AcceptPolicy(tx, userID, payload.Kind, strings.TrimSpace(payload.Version),
+            services.AcceptanceContext{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent(), Source: "api"}, time.Now())
+        return err
+    })
+    if errors.Is(err, services.ErrPolicyNotFound) {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "policy_version_not_found"))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "record_acceptance_failed"))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": acc})
+}
+
+// AdminListPolicies lists every published version with its acceptance count.
+func AdminListPolicies(c *gin.Context) {
+    type row struct {
+        models.PolicyDocument
+        Acceptances int64 `json:"acceptances"`
+    }
+    var docs []models.PolicyDocument
+    if err := database.DB.Omit("body").Order("kind, effective_at DESC").Find(&docs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_policies_failed"))
+        return
+    }
+    out := make([]row, 0, len(docs))
+    for _, d := range docs {
+        var n int64
+        database.DB.Model(&models.PolicyAcceptance{}).Where("kind = ? AND version = ?", d.Kind, d.Version).
+            Distinct("user_id").Count(&n)
+        out = append(out, row{d, n})
+    }
+    c.JSON(http.StatusOK, gin.H{"data": out})
+}
+
+// AdminPublishPolicy publishes a new policy version. Body: kind, version,
+// title, body and/or url, required (default true), effective_at (RFC3339 or
+// YYYY-MM-DD; default now). Users are asked to re-accept once a required
+// version takes effect.
+func AdminPublishPolicy(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var payload struct {
+        Kind        string `json:"kind"`
+        Version     string `json:"version"`
+        Title       string `json:"title"`
+        Body        string `json:"body"`
+        URL         string `json:"url"`
+        Required    *bool  `json:"required"`
+        EffectiveAt string `json:"effective_at"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    if payload.Kind != "terms" && payload.Kind != "privacy" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "kind", "terms, privacy"))
+        return
+    }
+    for _, f := range []struct{ name, value string; min, max int }{
+        {"version", payload.Version, 1, 20}, {"title", payload.Title, 1, 200}, {"url", payload.URL, 0, 500},
+    } {
+        if err := middleware.ValidateStringLength(f.name, f.value, f.min, f.max); err != nil {
+            c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+            return
+        }
+    }
+    if strings.TrimSpace(payload.Body) == "" && strings.TrimSpace(payload.URL) == "" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "policy_body_required"))
+        return
+    }
+    effective := time.Now()
+    if payload.EffectiveAt != "" {
+        t, _, err := parseDateParam(payload.EffectiveAt)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_effective_at"))
+            return
+        }
+        effective = t
+    }
+    doc := models.PolicyDocument{
+        Kind: payload.Kind, Version: strings.TrimSpace(payload.Version), Title: strings.TrimSpace(payload.Title),
+        Body: payload.Body, URL: strings.TrimSpace(payload.URL), Required: payload.Required == nil || *payload.Required,
+        EffectiveAt: effective, PublishedBy: userID,
+    }
+    if err := database.DB.Create(&doc).Error; err != nil {
+        if strings.Contains(err.Error(), "duplicate key") {
+            c.JSON(http.StatusConflict, middleware.Error(c, "policy_version_exists"))
+            return
+        }
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    services.InvalidatePolicyCache()
+    c.JSON(http.StatusCreated, gin.H{"data": doc})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+    "bytes"
+    "errors"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/config"
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// erasureGraceDays is set from config at startup
+var erasureGraceDays = 30
+
+// ConfigurePrivacy applies data-subject settings from config.
+func ConfigurePrivacy(cfg *config.Config) {
+    if cfg.ErasureGraceDays > 0 { erasureGraceDays = cfg.ErasureGraceDays }
+}
+
+// ExportMyData returns everything stored about the caller, soft-deleted
+// goals included. Query: format=json (default) or zip (one file per table).
+func ExportMyData(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    format := c.DefaultQuery("format", "json")
+    if format != "json" && format != "zip" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "format", "json, zip"))
+        return
+    }
+    archive, err := services.ExportUserData(database.DB, userID, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "export_data_failed"))
+        return
+    }
+    stamp := archive.GeneratedAt.Format("20060102")
+    if format == "zip" {
+        var buf bytes.Buffer
+        if err := archive.WriteZip(&buf); err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "build_archive_failed"))
+            return
+        }
+        c.Header("Content-Disposition", `attachment; filename="my-data-`+stamp+`.zip"`)
+        c.Data(http.StatusOK, "application/zip", buf.Bytes())
+        return
+    }
+    c.Header("Content-Disposition", `attachment; filename="my-data-`+stamp+`.json"`)
+    c.JSON(http.StatusOK, archive)
+}
+
+// RequestMyErasure schedules erasure of all the caller's data after the grace
+// period. Body: {"confirm": "ERASE", "reason": "..."}. The identity-provider
+// account itself is not touched.
+func RequestMyErasure(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var payload struct {
+        Confirm string `json:"confirm"`
+        Reason  string `json:"reason"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil || payload.Confirm != "ERASE" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "erase_confirmation_required"))
+        return
+    }
+    if err := middleware.ValidateStringLength("reason", payload.Reason, 0, 500); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    req, err := services.RequestErasure(database.DB, userID, strings.TrimSpace(payload.Reason), erasureGraceDays, time.Now())
+    if errors.Is(err, services.ErrErasurePending) {
+        body := middleware.Error(c, "erasure_already_scheduled")
+        body["data"] = req
+        c.JSON(http.StatusConflict, body)
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "schedule_erasure_failed"))
+        return
+    }
+    c.JSON(http.StatusAccepted, gin.H{"data": req})
+}
+
+// GetMyErasure returns the caller's pending erasure, if any.
+func GetMyErasure(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var req models.ErasureRequest
+    if database.DB.Where("user_id = ? AND status = 'pending'", userID).Limit(1).Find(&req).RowsAffected == 0 {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "no_erasure_scheduled"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": req})
+}
+
+// CancelMyErasure withdraws a pending erasure during the grace period.
+func CancelMyErasure(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    now := time.Now()
+    res := database.DB.Model(&models.ErasureRequest{}).
+        Where("user_id = ? AND status = 'pending'", userID).
+        Updates(map[string]interface{}{"status": "cancelled", "cancelled_at": now})
+    if res.Error != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "cancel_erasure_failed"))
+        return
+    }
+    if res.RowsAffected == 0 {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "no_erasure_scheduled"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"message": "Erasure cancelled successfully"})
+}
+
+// AdminErasureLog lists erasure requests, newest first. Completed entries
+// carry only the subject hash. Query: status, subject (a user id to look up
+// by hash). Returns at most 200 entries.
+func AdminErasureLog(c *gin.Context) {
+    q := database.DB.Order("requested_at DESC").Limit(200)
+    if s := c.Query("status"); s != "" { q = q.Where("status = ?", s) }
+    if s := c.Query("subject"); s != "" { q = q.Where("subject_hash = ?", services.SubjectHash(s)) }
+    var reqs []models.ErasureRequest
+    if err := q.Find(&reqs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_erasure_log_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": reqs})
+}
+
+// AdminExecuteErasure runs a pending erasure now instead of waiting for the
+// grace period, e.g. when the user asked for it through support.
+func AdminExecuteErasure(c *gin.Context) {
+    var req models.ErasureRequest
+    if err := database.DB.First(&req, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "erasure_request_not_found"))
+        return
+    }
+    if req.Status != "pending" {
+        c.JSON(http.StatusConflict, middleware.Error(c, "erasure_request_not_pending", req.Status))
+        return
+    }
+    err := services.ExecuteErasure(database.DB, &req, time.Now())
+    if errors.Is(err, services.ErrErasureNotPending) {
+        database.DB.First(&req, req.ID)
+        c.JSON(http.StatusConflict, middleware.Error(c, "erasure_request_not_pending", req.Status))
+        return
+    }
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "erasure_failed"))
+        return
+    }
+    database.DB.First(&req, req.ID)
+    c.JSON(http.StatusOK, gin.H{"data": req})
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ListProfileSnapshots returns the caller's profile snapshots, newest first.
+func ListProfileSnapshots(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var snaps []models.ProfileSnapshot
+    if err := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(500).Find(&snaps).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_profile_snapshots_failed"))
+        return
+    }
+    views := make([]services.ProfileSnapshotView, 0, len(snaps))
+    for _, s := range snaps { views = append(views, services.ViewProfileSnapshot(s)) }
+    c.JSON(http.StatusOK, gin.H{"data": views})
+}
+
+func GetProfileSnapshot(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var snap models.ProfileSnapshot
+    if err := database.DB.Where("user_id = ?", userID).First(&snap, c.Param("snapshot_id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "snapshot_not_found"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": services.ViewProfileSnapshot(snap)})
+}
+
+// GetProfileTimeline returns profile changes and goal completions, newest
+// first. Query: from, to (YYYY-MM-DD or RFC3339); defaults to the last 2 years.
+func GetProfileTimeline(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    rng, err := parseDateRange(c.Query("from"), c.Query("to"), time.Now().AddDate(-2, 0, 0))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    events, err := services.ProfileTimeline(database.DB, userID, rng.From, rng.To)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "build_timeline_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": events})
+}
+
+// DiffProfileSnapshots compares the profile at two points. Query: from, to,
+// each a snapshot id or a date (YYYY-MM-DD covers that whole day, or
+// RFC3339). An omitted to means the latest snapshot.
+func DiffProfileSnapshots(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    if c.Query("from") == "" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "from"))
+        return
+    }
+    from, ok := resolveProfileSnapshot(c, userID, "from")
+    if !ok { return }
+    to, ok := resolveProfileSnapshot(c, userID, "to")
+    if !ok { return }
+
+    a, b := services.ViewProfileSnapshot(from), services.ViewProfileSnapshot(to)
+    c.JSON(http.StatusOK, gin.H{"data": gin.H{
+        "from":    gin.H{"snapshot_id": from.ID, "at": from.CreatedAt},
+        "to":      gin.H{"snapshot_id": to.ID, "at": to.CreatedAt},
+        "changes": services.DiffJSON(a.Snapshot, b.Snapshot),
+    }})
+}
+
+// resolveProfileSnapshot reads a snapshot id or point in time from the
+// named query parameter. It writes the error response and returns false
+// when nothing matches.
+func resolveProfileSnapshot(c *gin.Context, userID, param string) (models.ProfileSnapshot, bool) {
+    var snap models.ProfileSnapshot
+    v := c.Query(param)
+    var err error
+    switch id, convErr := strconv.ParseUint(v, 10, 32); {
+    case v == "":
+        err = database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").First(&snap).Error
+    case convErr == nil:
+        err = database.DB.Where("user_id = ?", userID).First(&snap, id).Error
+    default:
+        t, dateOnly, perr := parseDateParam(v)
+        if perr != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_snapshot_ref", param))
+            return snap, false
+        }
+        if dateOnly { t = t.AddDate(0, 0, 1).Add(-time.Nanosecond) }
+        snap, err = services.ProfileSnapshotAt(database.DB, userID, t)
+    }
+    if err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "profile_snapshot_not_found", param))
+        return snap, false
+    }
+    return snap, true
+}
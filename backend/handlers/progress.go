@@ -6,6 +6,7 @@ import (
     "goaltracker/database"
     "goaltracker/models"
     "goaltracker/middleware"
+    "goaltracker/services"
     "github.com/gin-gonic/gin"
 )
 
@@ -15,67 +16,100 @@ func GetProgress(c *gin.Context) {
 	
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Where("user_id = ? AND goal_id = ?", userID, goalID).Order("created_at DESC").Find(&progress).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_progress_failed"))
 		return
 	}
+	if middleware.NotModified(c, middleware.HashETag(progress)) { return }
 	
 	c.JSON(http.StatusOK, gin.H{"data": progress})
 }
 
+func progressETag(p models.Progress) string {
+    return middleware.VersionETag("progress", p.ID, p.Version)
+}
+
 func CreateProgress(c *gin.Context) {
 	goalID := c.Param("id")
 	var progress models.Progress
 	
 	if err := c.ShouldBindJSON(&progress); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
 		return
 	}
 	
 	goalIDUint, err := strconv.ParseUint(goalID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid goal ID"})
+		c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_goal_id"))
 		return
 	}
 	
     progress.GoalID = uint(goalIDUint)
     userID, _ := middleware.GetUserID(c)
     progress.UserID = userID
+    progress.ID = 0
+    progress.Version = 1
 	
 	var goal models.Goal
     if err := database.DB.Where("user_id = ?", userID).First(&goal, goalID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
 		return
 	}
 	
 	if err := database.DB.Create(&progress).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create progress"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "create_progress_failed"))
 		return
 	}
+	c.Header("ETag", progressETag(progress))
 	
 	c.JSON(http.StatusCreated, gin.H{"data": progress})
 }
 
+// progressPayload holds the fields a client may change on a progress entry;
+// absent fields keep their stored value.
+type progressPayload struct {
+    Description *string `json:"description"`
+    Percentage  *int    `json:"percentage"`
+    Notes       *string `json:"notes"`
+    Outcome     *string `json:"outcome"`
+    ActionTaken *string `json:"action_taken"`
+    NextSteps   *string `json:"next_steps"`
+}
+
 func UpdateProgress(c *gin.Context) {
 	id := c.Param("id")
 	var progress models.Progress
 	
     userID, _ := middleware.GetUserID(c)
     if err := database.DB.Where("user_id = ?", userID).First(&progress, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Progress not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "progress_not_found"))
 		return
 	}
+    if !middleware.CheckIfMatch(c, progressETag(progress), progress) { return }
+    stored := progress
 	
-	if err := c.ShouldBindJSON(&progress); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    var p progressPayload
+	if err := c.ShouldBindJSON(&p); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
 		return
 	}
-	
-    // enforce ownership
-    progress.UserID = userID
-    if err := database.DB.Save(&progress).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update progress"})
+    if p.Description != nil { progress.Description = *p.Description }
+    if p.Percentage != nil { progress.Percentage = *p.Percentage }
+    if p.Notes != nil { progress.Notes = *p.Notes }
+    if p.Outcome != nil { progress.Outcome = *p.Outcome }
+    if p.ActionTaken != nil { progress.ActionTaken = *p.ActionTaken }
+    if p.NextSteps != nil { progress.NextSteps = *p.NextSteps }
+    progress.Version = stored.Version + 1
+    if err := services.SaveVersioned(database.DB, &progress, stored.Version); err != nil {
+        if err == services.ErrVersionConflict {
+            var current models.Progress
+            database.DB.First(&current, stored.ID)
+            middleware.PreconditionFailed(c, progressETag(current), current)
+            return
+        }
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "update_progress_failed"))
 		return
 	}
+	c.Header("ETag", progressETag(progress))
 	
 	c.JSON(http.StatusOK, gin.H{"data": progress})
 }
@@ -84,8 +118,20 @@ func DeleteProgress(c *gin.Context) {
 	id := c.Param("id")
 	
     userID, _ := middleware.GetUserID(c)
-    if err := database.DB.Where("user_id = ?", userID).Delete(&models.Progress{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete progress"})
+    var progress models.Progress
+    if err := database.DB.Where("user_id = ?", userID).First(&progress, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, middleware.Error(c, "progress_not_found"))
+		return
+	}
+    if !middleware.CheckIfMatch(c, progressETag(progress), progress) { return }
+    if err := services.DeleteVersioned(database.DB, &progress, progress.Version); err != nil {
+        if err == services.ErrVersionConflict {
+            var current models.Progress
+            database.DB.First(&current, progress.ID)
+            middleware.PreconditionFailed(c, progressETag(current), current)
+            return
+        }
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "delete_progress_failed"))
 		return
 	}
 	
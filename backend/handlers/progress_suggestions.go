@@ -3,14 +3,16 @@ package handlers
 import (
 	"net/http"
 	"goaltracker/database"
+	"goaltracker/middleware"
 	"goaltracker/models"
+	"goaltracker/services"
 	"github.com/gin-gonic/gin"
 )
 
 func GetProgressSuggestions(c *gin.Context) {
 	var suggestions []models.ProgressSuggestion
 	
-	query := database.DB.Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility")
+	query := database.DB.Scopes(services.Published("progress_suggestions")).Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility")
 	
 	// Filter by goal_suggestion_id if provided
 	if goalSuggestionID := c.Query("goal_suggestion_id"); goalSuggestionID != "" {
@@ -28,10 +30,11 @@ func GetProgressSuggestions(c *gin.Context) {
 	}
 	
 	if err := query.Find(&suggestions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress suggestions"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_progress_suggestions_failed"))
 		return
 	}
 	
+	localizeCatalog(c, suggestions)
 	c.JSON(http.StatusOK, gin.H{"data": suggestions})
 }
 
@@ -39,11 +42,12 @@ func GetProgressSuggestion(c *gin.Context) {
 	id := c.Param("id")
 	var suggestion models.ProgressSuggestion
 	
-	if err := database.DB.Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility").First(&suggestion, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Progress suggestion not found"})
+	if err := database.DB.Scopes(services.Published("progress_suggestions")).Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility").First(&suggestion, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, middleware.Error(c, "progress_suggestion_not_found"))
 		return
 	}
 	
+	localizeCatalog(c, &suggestion)
 	c.JSON(http.StatusOK, gin.H{"data": suggestion})
 }
 
@@ -55,7 +59,7 @@ func GetProgressSuggestionsForGoal(c *gin.Context) {
 	// First, get the goal to find its original suggestion
 	var goal models.Goal
 	if err := database.DB.First(&goal, goalID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		c.JSON(http.StatusNotFound, middleware.Error(c, "goal_not_found"))
 		return
 	}
 	
@@ -93,14 +97,15 @@ func GetProgressSuggestionsForGoal(c *gin.Context) {
 		percentageRanges = []string{"76-100", "51-75"}
 	}
 	
-	query := database.DB.Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility")
+	query := database.DB.Scopes(services.Published("progress_suggestions")).Preload("GoalSuggestion").Preload("GoalSuggestion.Responsibility")
 	query = query.Where("percentage_range IN ?", percentageRanges)
 	
 	if err := query.Limit(10).Find(&suggestions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch progress suggestions"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_progress_suggestions_failed"))
 		return
 	}
 	
+	localizeCatalog(c, suggestions)
 	c.JSON(http.StatusOK, gin.H{
 		"data": suggestions,
 		"current_percentage": currentPercentage,
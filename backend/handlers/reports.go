@@ -0,0 +1,72 @@
+package handlers
+
+import (
+    "net/http"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// GetBragDocument compiles the caller's brag document / review packet.
+// Query:
+//   from, to  period (YYYY-MM-DD or RFC3339); defaults to the last 6 months
+//   format    json (default), markdown or html
+//   summary   ai to have the AI provider write the summary; falls back to
+//             the deterministic summary when no provider is available
+func GetBragDocument(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+
+    rng, err := parseDateRange(c.Query("from"), c.Query("to"), time.Now().AddDate(0, -6, 0))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    if rng.To.Sub(rng.From) > maxAnalyticsRange {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "date_range_too_long"))
+        return
+    }
+    format := c.DefaultQuery("format", "json")
+    if format != "json" && format != "markdown" && format != "html" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "format", "json, markdown, html"))
+        return
+    }
+
+    doc, err := services.CompileBragDoc(database.DB, userID, services.AnalyticsRange{From: rng.From, To: rng.To})
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "compile_brag_document_failed"))
+        return
+    }
+    if c.Query("summary") == "ai" && doc.Totals.Goals > 0 {
+        aiService := services.NewAIService()
+        start := time.Now()
+        summary, err := aiService.SummarizeBragDoc(doc)
+        services.RecordAIStat(services.AIStat{
+            Provider:  aiService.Provider(),
+            Success:   err == nil,
+            LatencyMs: int(time.Since(start).Milliseconds()),
+            Timestamp: time.Now(),
+        })
+        if err == nil {
+            doc.Summary, doc.SummaryEngine = summary, "ai"
+        }
+    }
+
+    switch format {
+    case "markdown":
+        c.Header("Content-Disposition", `attachment; filename="brag-document.md"`)
+        c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(doc.Markdown()))
+    case "html":
+        page, err := doc.HTML()
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, middleware.Error(c, "render_brag_document_failed"))
+            return
+        }
+        c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+    default:
+        c.JSON(http.StatusOK, gin.H{"data": doc})
+    }
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"net/http"
 	"goaltracker/database"
+	"goaltracker/middleware"
 	"goaltracker/models"
+	"goaltracker/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -12,7 +14,7 @@ func GetResponsibilities(c *gin.Context) {
 	category := c.Query("category")
 	
 	var responsibilities []models.Responsibility
-	query := database.DB.Preload("JobRole")
+	query := database.DB.Scopes(services.Published("responsibilities")).Preload("JobRole")
 	
 	if jobRoleID != "" {
 		query = query.Where("job_role_id = ?", jobRoleID)
@@ -23,10 +25,11 @@ func GetResponsibilities(c *gin.Context) {
 	}
 	
 	if err := query.Find(&responsibilities).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch responsibilities"})
+		c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_responsibilities_failed"))
 		return
 	}
 	
+	localizeCatalog(c, responsibilities)
 	c.JSON(http.StatusOK, gin.H{"data": responsibilities})
 }
 
@@ -34,10 +37,11 @@ func GetResponsibility(c *gin.Context) {
 	id := c.Param("id")
 	var responsibility models.Responsibility
 	
-	if err := database.DB.Preload("JobRole").First(&responsibility, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Responsibility not found"})
+	if err := database.DB.Scopes(services.Published("responsibilities")).Preload("JobRole").First(&responsibility, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, middleware.Error(c, "responsibility_not_found"))
 		return
 	}
 	
+	localizeCatalog(c, &responsibility)
 	c.JSON(http.StatusOK, gin.H{"data": responsibility})
 }
\ No newline at end of file
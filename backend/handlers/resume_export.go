@@ -0,0 +1,92 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// ExportCV renders the caller's profile and goals completed in the period
+// as a CV. Query:
+//   format        json_resume (default), markdown, or preview (the CV model
+//                 with goal and progress ids, for choosing what to include)
+//   from, to      period (YYYY-MM-DD or RFC3339); defaults to the last 12 months
+//   goal_ids      comma-separated goals to include; default all completed
+//   progress_ids  comma-separated progress entries whose outcomes to include
+//   outcomes      false to leave out progress outcomes
+//   evidence      false to leave out IT profile evidence
+func ExportCV(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+
+    rng, err := parseDateRange(c.Query("from"), c.Query("to"), time.Now().AddDate(-1, 0, 0))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    goalIDs, err := parseIDList(c.Query("goal_ids"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_id_list", "goal_ids"))
+        return
+    }
+    progressIDs, err := parseIDList(c.Query("progress_ids"))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_id_list", "progress_ids"))
+        return
+    }
+    format := c.DefaultQuery("format", "json_resume")
+    if format != "json_resume" && format != "markdown" && format != "preview" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "format", "json_resume, markdown, preview"))
+        return
+    }
+
+    var profile models.UserProfile
+    if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "profile_not_found"))
+        return
+    }
+    query := database.DB.Preload("Progress").Preload("JobRole").
+        Where("user_id = ? AND status = ? AND COALESCE(completed_at, updated_at) >= ? AND COALESCE(completed_at, updated_at) < ?", userID, "completed", rng.From, rng.To)
+    if len(goalIDs) > 0 { query = query.Where("id IN ?", goalIDs) }
+    var goals []models.Goal
+    if err := query.Find(&goals).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_goals_failed"))
+        return
+    }
+
+    cv := services.BuildCV(profile, goals, services.CVOptions{
+        From: rng.From, To: rng.To, GoalIDs: goalIDs, ProgressIDs: progressIDs,
+        IncludeOutcomes: c.Query("outcomes") != "false",
+        IncludeEvidence: c.Query("evidence") != "false",
+    })
+    switch format {
+    case "markdown":
+        c.Header("Content-Disposition", `attachment; filename="cv.md"`)
+        c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(cv.Markdown()))
+    case "preview":
+        c.JSON(http.StatusOK, gin.H{"data": cv})
+    default:
+        // A bare JSON Resume document so it can be fed to resume tooling as-is
+        c.Header("Content-Disposition", `attachment; filename="resume.json"`)
+        c.JSON(http.StatusOK, cv.JSONResume())
+    }
+}
+
+// parseIDList reads "1,2,3"; empty input yields nil.
+func parseIDList(s string) ([]uint, error) {
+    var ids []uint
+    for _, part := range strings.Split(s, ",") {
+        if part = strings.TrimSpace(part); part == "" { continue }
+        id, err := strconv.ParseUint(part, 10, 32)
+        if err != nil { return nil, err }
+        ids = append(ids, uint(id))
+    }
+    return ids, nil
+}
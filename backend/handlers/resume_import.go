@@ -0,0 +1,125 @@
+package handlers
+
+import (
+    "io"
+    "mime"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+const maxResumeSize = 512 << 10
+
+// ImportResume reads a JSON Resume, plain text or Markdown résumé and
+// proposes profile changes without saving them. The response carries an
+// update body for PUT /profiles/:id and the If-Match value to send with
+// it, so the user reviews the diff before anything is applied.
+// Query: engine=rules|ai (ai falls back to rules when no provider is set up).
+func ImportResume(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var profile models.UserProfile
+    if err := database.DB.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "profile_not_found"))
+        return
+    }
+
+    mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+    switch mediaType {
+    case "application/json", "text/plain", "text/markdown", "text/x-markdown":
+    default:
+        c.JSON(http.StatusUnsupportedMediaType, middleware.Error(c, "unsupported_import_content_type"))
+        return
+    }
+    body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxResumeSize+1))
+    if err != nil || len(body) > maxResumeSize {
+        c.JSON(http.StatusRequestEntityTooLarge, middleware.Error(c, "resume_too_large"))
+        return
+    }
+    resume, err := services.ParseResume(body, mediaType)
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    skills, err := services.LoadSkillCatalog(database.DB)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "load_skill_catalog_failed"))
+        return
+    }
+    certs, err := services.LoadCertificationCatalog(database.DB)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "load_certification_catalog_failed"))
+        return
+    }
+
+    engine := "rules"
+    aiFallback := false
+    var ex services.ResumeExtraction
+    if c.Query("engine") == "ai" {
+        aiService := services.NewAIService()
+        start := time.Now()
+        ex, err = aiService.ExtractResumeWithAI(resume, skills, certs)
+        services.RecordAIStat(services.AIStat{
+            Provider:  aiService.Provider(),
+            Success:   err == nil,
+            LatencyMs: int(time.Since(start).Milliseconds()),
+            Timestamp: time.Now(),
+        })
+        if err == nil {
+            engine = "ai"
+        } else {
+            aiFallback = true
+        }
+    }
+    if engine == "rules" {
+        ex = services.ExtractResume(resume, skills, certs, time.Now())
+    }
+
+    proposed := services.ProposeProfileFromResume(profile, ex)
+    it, errs := services.ParseITProfile([]byte(proposed.ITProfile))
+    if len(errs) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "import_profile_invalid"))
+        return
+    }
+
+    before, after := resumeProfileDocument(profile), resumeProfileDocument(proposed)
+    changes := services.DiffJSON(before, after)
+    update := gin.H{}
+    for _, ch := range changes {
+        field := ch.Field
+        if strings.HasPrefix(field, "it_profile.") { field = "it_profile" }
+        update[field] = after[field]
+    }
+    if _, ok := update["it_profile"]; ok { update["it_profile"] = it }
+
+    c.Header("ETag", profileETag(profile))
+    c.JSON(http.StatusOK, gin.H{"data": gin.H{
+        "source":      resume.Format,
+        "engine":      engine,
+        "ai_fallback": aiFallback,
+        "extracted":   ex,
+        "changes":     changes,
+        "profile_id":  profile.ID,
+        "if_match":    profileETag(profile),
+        "update":      update,
+    }})
+}
+
+// resumeProfileDocument is the part of a profile an import can change.
+func resumeProfileDocument(p models.UserProfile) gin.H {
+    it := services.DecodeITProfile(p.ITProfile)
+    if it.SchemaVersion == 0 { it.SchemaVersion = models.ITProfileSchemaVersion }
+    return gin.H{
+        "current_role":     p.CurrentRole,
+        "experience_level": p.ExperienceLevel,
+        "industry":         p.Industry,
+        "it_profile":       it,
+    }
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+    "net/http"
+
+    "goaltracker/middleware"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// GetITProfileSchema serves the JSON Schema for UserProfile.it_profile so
+// clients can build and pre-validate profile forms.
+func GetITProfileSchema(c *gin.Context) {
+    schema := services.ITProfileJSONSchema()
+    if middleware.NotModified(c, middleware.HashETag(schema)) { return }
+    c.Header("Content-Type", "application/schema+json")
+    c.JSON(http.StatusOK, schema)
+}
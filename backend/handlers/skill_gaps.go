@@ -0,0 +1,201 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+// GetSkillGaps ranks what the caller is missing for a target job role.
+// The target is, in order: ?job_role_id, ?target (free text), the IT
+// profile's role.target, then the profile's career goals.
+func GetSkillGaps(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+
+    var roles []models.JobRole
+    if err := database.DB.Scopes(services.Published("job_roles")).Preload("Responsibilities", services.Published("responsibilities")).Order("title").Find(&roles).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_job_roles_failed"))
+        return
+    }
+
+    var target services.GapTarget
+    if id := c.Query("job_role_id"); id != "" {
+        n, err := strconv.ParseUint(id, 10, 32)
+        if err != nil {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_job_role_id"))
+            return
+        }
+        for i := range roles {
+            if roles[i].ID == uint(n) { target = services.GapTarget{Text: roles[i].Title, Source: "job_role_id", Match: "exact", JobRole: &roles[i]} }
+        }
+        if target.JobRole == nil {
+            c.JSON(http.StatusNotFound, middleware.Error(c, "job_role_not_found"))
+            return
+        }
+    } else {
+        candidates := []services.GapTarget{{Text: c.Query("target"), Source: "query"}}
+        var profile models.UserProfile
+        if database.DB.Where("user_id = ?", userID).Limit(1).Find(&profile).RowsAffected > 0 {
+            candidates = append(candidates,
+                services.GapTarget{Text: services.DecodeITProfile(profile.ITProfile).Role.Target, Source: "it_profile"},
+                services.GapTarget{Text: profile.CareerGoals, Source: "career_goals"})
+        }
+        for _, cand := range candidates {
+            if strings.TrimSpace(cand.Text) == "" { continue }
+            role, match := services.ResolveTargetRole(roles, cand.Text)
+            if role != nil {
+                cand.JobRole, cand.Match = role, match
+                target = cand
+                break
+            }
+            // An explicit target that matches nothing is an error, not a cue to guess
+            if cand.Source == "query" { break }
+        }
+        if target.JobRole == nil {
+            titles := make([]string, 0, len(roles))
+            for _, r := range roles { titles = append(titles, r.Title) }
+            body := middleware.Error(c, "target_role_ambiguous")
+            body["candidates"] = titles
+            c.JSON(http.StatusUnprocessableEntity, body)
+            return
+        }
+    }
+
+    analysis, err := services.AnalyzeGaps(database.DB, userID, *target.JobRole, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "analyze_skill_gaps_failed"))
+        return
+    }
+    analysis.Target = target
+    localizeCatalog(c, &analysis)
+    c.JSON(http.StatusOK, gin.H{"data": analysis})
+}
+
+// GetJobRoleRequirements lists the skills and certifications expected of a role.
+func GetJobRoleRequirements(c *gin.Context) {
+    var role models.JobRole
+    if err := database.DB.First(&role, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "job_role_not_found"))
+        return
+    }
+    var reqs []models.JobRoleRequirement
+    if err := database.DB.Preload("Skill").Preload("Certification").
+        Where("job_role_id = ?", role.ID).Order("importance DESC, id").Find(&reqs).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_requirements_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": reqs})
+}
+
+type requirementsPayload struct {
+    Skills []struct {
+        Skill      string `json:"skill"` // slug, name or alias
+        Level      int    `json:"level"`
+        Importance int    `json:"importance"`
+    } `json:"skills"`
+    Certifications []struct {
+        Certification string `json:"certification"`
+        Importance    int    `json:"importance"`
+    } `json:"certifications"`
+}
+
+// AdminSetJobRoleRequirements replaces a role's requirement set. Skills and
+// certifications must already be in the catalogs; importance defaults to 2.
+func AdminSetJobRoleRequirements(c *gin.Context) {
+    var role models.JobRole
+    if err := database.DB.First(&role, c.Param("id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "job_role_not_found"))
+        return
+    }
+    var payload requirementsPayload
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    skills, err := services.LoadSkillCatalog(database.DB)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "load_skill_catalog_failed"))
+        return
+    }
+    certs, err := services.LoadCertificationCatalog(database.DB)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "load_certification_catalog_failed"))
+        return
+    }
+
+    var reqs []models.JobRoleRequirement
+    var fieldErrors []services.FieldError
+    seenSkills, seenCerts := map[uint]bool{}, map[uint]bool{}
+    for i, s := range payload.Skills {
+        field := "skills[" + strconv.Itoa(i) + "]"
+        skill, ok := skills.Resolve(s.Skill)
+        switch {
+        case !ok:
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".skill", "value_unknown_skill", s.Skill))
+            continue
+        case seenSkills[skill.ID]:
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".skill", "value_duplicate", skill.Name))
+            continue
+        case s.Level < 1 || s.Level > 4:
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".level", "value_out_of_range", 1, 4))
+            continue
+        }
+        importance, ok := requirementImportance(s.Importance)
+        if !ok {
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".importance", "value_out_of_range", 1, 3))
+            continue
+        }
+        seenSkills[skill.ID] = true
+        id := skill.ID
+        reqs = append(reqs, models.JobRoleRequirement{JobRoleID: role.ID, SkillID: &id, Level: s.Level, Importance: importance})
+    }
+    for i, cr := range payload.Certifications {
+        field := "certifications[" + strconv.Itoa(i) + "]"
+        cert, ok := certs.Resolve(cr.Certification)
+        switch {
+        case !ok:
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".certification", "value_unknown_certification", cr.Certification))
+            continue
+        case seenCerts[cert.ID]:
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".certification", "value_duplicate", cert.Name))
+            continue
+        }
+        importance, ok := requirementImportance(cr.Importance)
+        if !ok {
+            fieldErrors = append(fieldErrors, services.NewFieldError(field+".importance", "value_out_of_range", 1, 3))
+            continue
+        }
+        seenCerts[cert.ID] = true
+        id := cert.ID
+        reqs = append(reqs, models.JobRoleRequirement{JobRoleID: role.ID, CertificationID: &id, Importance: importance})
+    }
+    if len(fieldErrors) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(fieldErrors, middleware.GetLocale(c)), "requirements_invalid"))
+        return
+    }
+
+    err = database.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Where("job_role_id = ?", role.ID).Delete(&models.JobRoleRequirement{}).Error; err != nil { return err }
+        if len(reqs) == 0 { return nil }
+        return tx.Create(&reqs).Error
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "save_requirements_failed"))
+        return
+    }
+    GetJobRoleRequirements(c)
+}
+
+func requirementImportance(n int) (int, bool) {
+    if n == 0 { return 2, true }
+    return n, n >= 1 && n <= 3
+}
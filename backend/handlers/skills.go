@@ -0,0 +1,183 @@
+package handlers
+
+import (
+    "encoding/json"
+    "net/http"
+    "strings"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/models"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+    "gorm.io/gorm"
+)
+
+type skillPayload struct {
+    Slug     string   `json:"slug"`
+    Name     string   `json:"name" binding:"required"`
+    Category string   `json:"category"`
+    Kind     string   `json:"kind"` // platform or framework
+    Aliases  []string `json:"aliases"`
+}
+
+// userSkillView adds the proficiency label to a user skill
+type userSkillView struct {
+    models.UserSkill
+    Level string `json:"level"`
+}
+
+// GetSkills lists the reviewed skills catalog. Query: category, q
+// (name/alias search).
+func GetSkills(c *gin.Context) {
+    query := database.DB.Where("unreviewed = ?", false).Order("category, name")
+    if cat := c.Query("category"); cat != "" {
+        query = query.Where("category = ?", cat)
+    }
+    if q := strings.TrimSpace(c.Query("q")); q != "" {
+        like := "%" + strings.ToLower(q) + "%"
+        query = query.Where("LOWER(name) LIKE ? OR slug LIKE ? OR LOWER(aliases::text) LIKE ?", like, like, like)
+    }
+    var skills []models.Skill
+    if err := query.Find(&skills).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_skills_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": skills})
+}
+
+// GetMySkills returns the caller's skill records, strongest first.
+func GetMySkills(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var skills []models.UserSkill
+    if err := database.DB.Preload("Skill").Where("user_id = ?", userID).
+        Order("proficiency DESC, last_used_at DESC NULLS LAST").Find(&skills).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_skills_failed"))
+        return
+    }
+    out := make([]userSkillView, 0, len(skills))
+    for _, s := range skills {
+        out = append(out, userSkillView{UserSkill: s, Level: services.ProficiencyLevels[s.Proficiency]})
+    }
+    c.JSON(http.StatusOK, gin.H{"data": out, "levels": services.ProficiencyLevels})
+}
+
+// GetMySkillHistory returns proficiency changes, newest first. Query: skill_id.
+func GetMySkillHistory(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    query := database.DB.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Limit(500)
+    if id := c.Query("skill_id"); id != "" {
+        query = query.Where("skill_id = ?", id)
+    }
+    var changes []models.SkillProficiencyChange
+    if err := query.Find(&changes).Error; err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_skill_history_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": changes})
+}
+
+// SetMySkill records a self-assessed proficiency. Body: {"proficiency": 0-4, "last_used_at": RFC3339}
+func SetMySkill(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var skill models.Skill
+    if err := database.DB.First(&skill, c.Param("skill_id")).Error; err != nil {
+        c.JSON(http.StatusNotFound, middleware.Error(c, "skill_not_found"))
+        return
+    }
+    var payload struct {
+        Proficiency *int       `json:"proficiency" binding:"required"`
+        LastUsedAt  *time.Time `json:"last_used_at"`
+    }
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "proficiency"))
+        return
+    }
+    if *payload.Proficiency < 0 || *payload.Proficiency >= len(services.ProficiencyLevels) {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "proficiency_out_of_range"))
+        return
+    }
+    err := database.DB.Transaction(func(tx *gorm.DB) error {
+        return services.SetUserSkill(tx, userID, skill.ID, *payload.Proficiency, payload.LastUsedAt)
+    })
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "update_skill_failed"))
+        return
+    }
+    var us models.UserSkill
+    database.DB.Preload("Skill").Where("user_id = ? AND skill_id = ?", userID, skill.ID).First(&us)
+    c.JSON(http.StatusOK, gin.H{"data": userSkillView{UserSkill: us, Level: services.ProficiencyLevels[us.Proficiency]}})
+}
+
+// AdminListUnreviewedSkills lists skills created from users' profile
+// entries that await review, with how many users hold each.
+func AdminListUnreviewedSkills(c *gin.Context) {
+    type row struct {
+        models.Skill
+        Users int64 `json:"users"`
+    }
+    var rows []row
+    err := database.DB.Model(&models.Skill{}).
+        Select("skills.*, (SELECT COUNT(*) FROM user_skills WHERE user_skills.skill_id = skills.id) AS users").
+        Where("unreviewed = ?", true).Order("users DESC, name").Scan(&rows).Error
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_skills_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": rows})
+}
+
+// AdminUpsertSkill creates a catalog skill or, with :id, updates one.
+// Saving an unreviewed skill adds it to the public catalog.
+func AdminUpsertSkill(c *gin.Context) {
+    var payload skillPayload
+    if err := c.ShouldBindJSON(&payload); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "field_required", "name"))
+        return
+    }
+    if err := middleware.ValidateStringLength("name", payload.Name, 1, 100); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+
+    var skill models.Skill
+    status := http.StatusCreated
+    if id := c.Param("id"); id != "" {
+        if err := database.DB.First(&skill, id).Error; err != nil {
+            c.JSON(http.StatusNotFound, middleware.Error(c, "skill_not_found"))
+            return
+        }
+        status = http.StatusOK
+    }
+    skill.Name = strings.TrimSpace(payload.Name)
+    if payload.Slug != "" || skill.Slug == "" {
+        skill.Slug = services.SkillSlug(payload.Slug)
+        if skill.Slug == "" { skill.Slug = services.SkillSlug(skill.Name) }
+    }
+    skill.Category = strings.ToLower(strings.TrimSpace(payload.Category))
+    if skill.Category == "" { skill.Category = "other" }
+    switch payload.Kind {
+    case "framework", "platform":
+        skill.Kind = payload.Kind
+    case "":
+        if skill.Kind == "" { skill.Kind = "platform" }
+    default:
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_choice", "kind", "platform, framework"))
+        return
+    }
+    aliases := []string{}
+    for _, a := range payload.Aliases {
+        if a = strings.TrimSpace(a); a != "" { aliases = append(aliases, a) }
+    }
+    b, _ := json.Marshal(aliases)
+    skill.Aliases = string(b)
+    skill.Unreviewed = false
+
+    if err := database.DB.Save(&skill).Error; err != nil {
+        c.JSON(http.StatusBadRequest, middleware.DBError(c, err))
+        return
+    }
+    c.JSON(status, gin.H{"data": skill})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+
+    "goaltracker/database"
+    "goaltracker/middleware"
+    "goaltracker/services"
+
+    "github.com/gin-gonic/gin"
+)
+
+// PostSuggestionFeedback records one feedback event on a catalog or AI goal
+// suggestion (see services.SuggestionFeedbackInput). Dismissed and adopted
+// suggestions stop appearing in the user's results until restored.
+func PostSuggestionFeedback(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    var in services.SuggestionFeedbackInput
+    if err := c.ShouldBindJSON(&in); err != nil {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_json"))
+        return
+    }
+    if errs := services.ValidateSuggestionFeedback(database.DB, userID, &in); len(errs) > 0 {
+        c.JSON(http.StatusUnprocessableEntity, middleware.ValidationFailed(c, services.LocalizeFieldErrors(errs, middleware.GetLocale(c)), "suggestion_feedback_invalid"))
+        return
+    }
+    fb, err := services.RecordSuggestionFeedback(database.DB, userID, in, time.Now())
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.DBError(c, err))
+        return
+    }
+    c.JSON(http.StatusCreated, gin.H{"data": fb})
+}
+
+// GetMySuggestionFeedback lists where the user stands with each suggestion
+// they gave feedback on: saved, dismissed, adopted and their rating.
+func GetMySuggestionFeedback(c *gin.Context) {
+    userID, _ := middleware.GetUserID(c)
+    states, err := services.UserSuggestionFeedback(database.DB, userID)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_suggestion_feedback_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": states})
+}
+
+// AdminSuggestionAdoption reports impressions, dismissals (with reasons),
+// saves, adoptions and ratings per suggestion, lowest adoption rate first,
+// to find catalog entries worth pruning. Query: source (catalog or ai),
+// from/to (default the last 90 days), min_reach (users who saw it).
+func AdminSuggestionAdoption(c *gin.Context) {
+    rng, err := parseDateRange(c.Query("from"), c.Query("to"), time.Now().AddDate(0, 0, -90))
+    if err != nil {
+        c.JSON(http.StatusBadRequest, middleware.ErrorFrom(c, err, "invalid_request"))
+        return
+    }
+    source := c.Query("source")
+    if source != "" && source != "catalog" && source != "ai" {
+        c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_suggestion_source"))
+        return
+    }
+    var minReach int64
+    if v := c.Query("min_reach"); v != "" {
+        if minReach, err = strconv.ParseInt(v, 10, 64); err != nil || minReach < 0 {
+            c.JSON(http.StatusBadRequest, middleware.Error(c, "invalid_min_reach"))
+            return
+        }
+    }
+    report, err := services.BuildSuggestionAdoptionReport(database.DB, source, rng.From, rng.To, minReach)
+    if err != nil {
+        c.JSON(http.StatusInternalServerError, middleware.Error(c, "fetch_suggestion_feedback_failed"))
+        return
+    }
+    c.JSON(http.StatusOK, gin.H{"data": report})
+}
@@ -0,0 +1,155 @@
+// Package i18n holds the locales the API speaks, Accept-Language
+// negotiation, and the message catalog behind the stable error codes returned
+// to clients. Messages live in messages/<locale>.json, keyed by code, as
+// fmt format strings; a code missing from a locale falls back to English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the locale of the catalog's own text and the final fallback.
+const Default = "en"
+
+// Supported lists the locales with a message file, default first.
+var Supported = []string{"en", "de", "pt-BR"}
+
+//go:embed messages/*.json
+var files embed.FS
+
+var messages = map[string]map[string]string{}
+
+func init() {
+	for _, locale := range Supported {
+		b, err := files.ReadFile("messages/" + locale + ".json")
+		if err != nil {
+			panic("i18n: missing messages for " + locale)
+		}
+		m := map[string]string{}
+		if err := json.Unmarshal(b, &m); err != nil {
+			panic(fmt.Sprintf("i18n: messages/%s.json: %v", locale, err))
+		}
+		messages[locale] = m
+	}
+}
+
+// Code is a message argument that is itself a code, such as a catalog type
+// label; Text renders it in the same locale as the message around it.
+type Code string
+
+// Text renders the message for code in locale, falling back to English and
+// then to the code itself.
+func Text(locale, code string, args ...interface{}) string {
+	msg, ok := messages[locale][code]
+	if !ok {
+		if msg, ok = messages[Default][code]; !ok {
+			return code
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	rendered := make([]interface{}, len(args))
+	for i, a := range args {
+		if c, ok := a.(Code); ok {
+			a = Text(locale, string(c))
+		}
+		rendered[i] = a
+	}
+	return fmt.Sprintf(msg, rendered...)
+}
+
+// Has reports whether code is in the English catalog.
+func Has(code string) bool {
+	_, ok := messages[Default][code]
+	return ok
+}
+
+// Error is an error with a stable code. Error() renders it in English for
+// logs; handlers render it in the client's locale.
+type Error struct {
+	Code string
+	Args []interface{}
+}
+
+// Errorf returns an *Error for code with format arguments.
+func Errorf(code string, args ...interface{}) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+func (e *Error) Error() string { return Text(Default, e.Code, e.Args...) }
+
+// Localize renders e in locale.
+func (e *Error) Localize(locale string) string { return Text(locale, e.Code, e.Args...) }
+
+// Canonical returns the supported locale tag names, ignoring case and "_"
+// vs "-", so "pt_br" gets "pt-BR".
+func Canonical(tag string) (string, bool) {
+	tag = normalizeTag(tag)
+	for _, l := range Supported {
+		if strings.ToLower(l) == tag {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// Match maps a language tag onto a supported locale: the Canonical one
+// first, then any supported locale with the same base language, so "de-AT"
+// gets "de" and "pt-PT" gets "pt-BR".
+func Match(tag string) (string, bool) {
+	if l, ok := Canonical(tag); ok {
+		return l, true
+	}
+	tag = normalizeTag(tag)
+	if tag == "" || tag == "*" {
+		return "", false
+	}
+	base := strings.SplitN(tag, "-", 2)[0]
+	for _, l := range Supported {
+		if strings.SplitN(strings.ToLower(l), "-", 2)[0] == base {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// Negotiate picks the supported locale the Accept-Language header prefers
+// most, or Default when none match.
+func Negotiate(header string) string {
+	type pref struct {
+		tag string
+		q   float64
+	}
+	var prefs []pref
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		p := pref{tag: strings.TrimSpace(fields[0]), q: 1}
+		for _, f := range fields[1:] {
+			if v, ok := strings.CutPrefix(strings.TrimSpace(f), "q="); ok {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					p.q = q
+				}
+			}
+		}
+		if p.tag != "" && p.q > 0 {
+			prefs = append(prefs, p)
+		}
+	}
+	sort.SliceStable(prefs, func(i, j int) bool { return prefs[i].q > prefs[j].q })
+	for _, p := range prefs {
+		if l, ok := Match(p.tag); ok {
+			return l
+		}
+	}
+	return Default
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+    "errors"
+    "flag"
+    "fmt"
     "log"
+    "os"
     "goaltracker/config"
     "goaltracker/database"
     "goaltracker/handlers"
     "goaltracker/middleware"
+    "goaltracker/services"
+    "time"
     "github.com/gin-gonic/gin"
     "github.com/gin-contrib/cors"
 )
@@ -15,7 +21,56 @@ func main() {
 	
 	gin.SetMode(cfg.GinMode)
 	
+	if err := runCommand(cfg, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
+
+// serve runs the HTTP API. With -migrate (default AUTO_MIGRATE) the schema
+// is migrated and the catalog seeded first; production deployments run
+// "migrate up" and "seed" as a release step instead. The server refuses to
+// start when the schema doesn't match this build unless -allow-drift is set.
+func serve(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	port := fs.String("port", cfg.APIPort, "port to listen on")
+	migrate := fs.Bool("migrate", cfg.AutoMigrate, "migrate the schema and seed before serving")
+	allowDrift := fs.Bool("allow-drift", cfg.AllowSchemaDrift, "start even if the schema doesn't match the migrations")
+	sweepers := fs.Bool("sweepers", true, "run the certification and erasure background sweepers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	database.Connect(cfg)
+	if *migrate {
+		// Drift is reported (and maybe overridden) just below
+		if _, err := database.MigrateUp(0); err != nil && !errors.Is(err, database.ErrSchemaDrift) {
+			return err
+		}
+	}
+	if err := database.CheckSchema(); err != nil {
+		if !*allowDrift {
+			return fmt.Errorf("%w (run \"migrate up\", or set ALLOW_SCHEMA_DRIFT=true to start anyway)", err)
+		}
+		log.Println("Warning:", err)
+	}
+	if *migrate {
+		if _, err := database.Seed(cfg.CatalogFixtures); err != nil {
+			return err
+		}
+	}
+	handlers.ConfigureCertifications(cfg)
+	handlers.ConfigurePrivacy(cfg)
+	middleware.SetAdminGrantLookup(handlers.IsGrantedAdmin)
+	middleware.SetLocalePreference(handlers.UserLocalePreference)
+	middleware.SetIfMatchMode(cfg.IfMatchMode)
+
+	if *sweepers {
+		// Expiry reminders and renewal goals for tracked certifications
+		go services.RunCertificationSweeper(database.DB, cfg.CertRenewalLeadDays, 6*time.Hour)
+		// Account erasures whose grace period has passed
+		go services.RunErasureSweeper(database.DB, time.Hour)
+	}
 	
     r := gin.New()
     // Log requests for debugging; keep in production for now (can be toggled with mode if needed)
@@ -24,12 +79,16 @@ func main() {
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.FrontendURL},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-CSRF-Token", "If-Match", "If-None-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", "Content-Language"},
 		AllowCredentials: true,
 	}))
 
+	// Response language from Accept-Language (en, de, pt-BR); signed-in
+	// users' saved preference overrides it below
+	r.Use(middleware.Locale())
+
 	// Light rate limiting across all routes (e.g., 120 req/min ~= 2 rps with burst 60)
 	r.Use(middleware.RateLimitMiddleware(60, 2))
 	
@@ -41,14 +100,33 @@ func main() {
         // Security endpoints
         api.GET("/csrf-token", middleware.CSRFTokenEndpoint())
         
+        // Public schemas for client-side forms
+        api.GET("/schemas/it-profile", handlers.GetITProfileSchema)
+
         // Public groups
+        policies := api.Group("/policies")
+        {
+            policies.GET("", handlers.GetPolicies)
+            policies.GET("/:kind/:version", handlers.GetPolicyDocument)
+        }
+
         jobRoles := api.Group("/job-roles")
         {
             jobRoles.GET("", handlers.GetJobRoles)
             jobRoles.GET("/:id", handlers.GetJobRole)
-            jobRoles.POST("", handlers.CreateJobRole)
+            jobRoles.GET("/:id/requirements", handlers.GetJobRoleRequirements)
+        }
+
+        careerLadders := api.Group("/career-ladders")
+        {
+            careerLadders.GET("", handlers.GetCareerLadders)
+            careerLadders.GET("/:ref", handlers.GetCareerLadder)
+            careerLadders.GET("/:ref/export", handlers.ExportCareerLadder)
         }
 
+        api.GET("/skills", handlers.GetSkills)
+        api.GET("/certifications", handlers.GetCertifications)
+
         responsibilities := api.Group("/responsibilities")
         {
             responsibilities.GET("", handlers.GetResponsibilities)
@@ -56,11 +134,10 @@ func main() {
         }
 
         suggestions := api.Group("/suggestions")
+        suggestions.Use(middleware.OptionalAuth())
         {
             suggestions.GET("", handlers.GetGoalSuggestions)
             suggestions.GET("/:id", handlers.GetGoalSuggestion)
-            // Profile-based
-            suggestions.GET("/for-profile", handlers.GetProfileBasedSuggestions)
         }
 
         progressSuggestions := api.Group("/progress-suggestions")
@@ -73,28 +150,50 @@ func main() {
         // Protected groups with authentication and CSRF protection
         authRequired := api.Group("")
         authRequired.Use(middleware.RequireAuth())
+        authRequired.Use(middleware.UserLocale())
         authRequired.Use(middleware.CSRFProtection())
 
-        goals := authRequired.Group("/goals")
+        // Routes below this group answer 403 policy_acceptance_required until
+        // the user has accepted the current terms and privacy policy. Profile
+        // basics, /account and /admin stay reachable so users can accept.
+        consented := authRequired.Group("")
+        consented.Use(middleware.RequirePolicyAcceptance(handlers.PendingPolicies))
+
+        goals := consented.Group("/goals")
         {
             goals.GET("", handlers.GetGoals)
             goals.GET("/:id", handlers.GetGoal)
             goals.POST("", handlers.CreateGoal)
             goals.PUT("/:id", handlers.UpdateGoal)
+            goals.PATCH("/:id", handlers.PatchGoal)
             goals.DELETE("/:id", handlers.DeleteGoal)
 
             // Progress routes for specific goals
             goals.GET("/:id/progress", handlers.GetProgress)
             goals.POST("/:id/progress", handlers.CreateProgress)
+
+            // Revision history
+            goals.GET("/:id/revisions", handlers.ListGoalRevisions)
+            goals.GET("/:id/revisions/diff", handlers.DiffGoalRevisions)
+            goals.GET("/:id/revisions/:rev", handlers.GetGoalRevision)
+            goals.POST("/:id/revisions/:rev/restore", handlers.RestoreGoalRevision)
+        }
+
+        // Profile-based suggestions and the feedback that filters and ranks them
+        mySuggestions := consented.Group("/suggestions")
+        {
+            mySuggestions.GET("/for-profile", handlers.GetProfileBasedSuggestions)
+            mySuggestions.GET("/feedback", handlers.GetMySuggestionFeedback)
+            mySuggestions.POST("/feedback", handlers.PostSuggestionFeedback)
         }
 
-        progress := authRequired.Group("/progress")
+        progress := consented.Group("/progress")
         {
             progress.PUT("/:id", handlers.UpdateProgress)
             progress.DELETE("/:id", handlers.DeleteProgress)
         }
 
-        aiGoals := authRequired.Group("/ai")
+        aiGoals := consented.Group("/ai")
         {
             aiGoals.POST("/goal-suggestions", handlers.GetAIGoalSuggestions)
             // SMART-only endpoint (preferred)
@@ -105,21 +204,128 @@ func main() {
             aiGoals.POST("/milestones", handlers.GenerateMilestonesRoute)
         }
 
+        analytics := consented.Group("/analytics")
+        {
+            analytics.GET("", handlers.GetAnalytics)
+        }
+
+        reports := consented.Group("/reports")
+        {
+            reports.GET("/brag", handlers.GetBragDocument)
+        }
+
+        // Capacity-aware milestone planner
+        planner := consented.Group("/planner")
+        {
+            planner.GET("", handlers.GetPlan)
+            planner.POST("/apply", handlers.ApplyPlan)
+            planner.GET("/blocked", handlers.ListBlockedPeriods)
+            planner.POST("/blocked", handlers.CreateBlockedPeriod)
+            planner.DELETE("/blocked/:id", handlers.DeleteBlockedPeriod)
+        }
+
+        // Planning cycles (quarters) with end-of-cycle grading and rollover
+        cycles := consented.Group("/cycles")
+        {
+            cycles.GET("", handlers.ListCycles)
+            cycles.POST("", handlers.CreateCycle)
+            cycles.GET("/:id", handlers.GetCycle)
+            cycles.PUT("/:id", handlers.UpdateCycle)
+            cycles.POST("/:id/goals", handlers.AttachCycleGoals)
+            cycles.GET("/:id/grading", handlers.GetCycleGrading)
+            cycles.PUT("/:id/grades", handlers.SaveCycleGrades)
+            cycles.POST("/:id/close", handlers.CloseCycle)
+        }
+
+        mySkills := consented.Group("/skills/me")
+        {
+            mySkills.GET("", handlers.GetMySkills)
+            mySkills.GET("/history", handlers.GetMySkillHistory)
+            mySkills.PUT("/:skill_id", handlers.SetMySkill)
+        }
+
+        myCerts := consented.Group("/certifications/me")
+        {
+            myCerts.GET("", handlers.GetMyCertifications)
+            myCerts.POST("", handlers.AddMyCertification)
+            myCerts.PUT("/:id", handlers.UpdateMyCertification)
+            myCerts.DELETE("/:id", handlers.DeleteMyCertification)
+            myCerts.POST("/:id/renew", handlers.RenewMyCertification)
+            myCerts.GET("/:id/credits", handlers.GetCertificationCredits)
+            myCerts.POST("/:id/credits", handlers.AddCertificationCredit)
+            myCerts.DELETE("/:id/credits/:credit_id", handlers.DeleteCertificationCredit)
+        }
+        consented.GET("/certifications/reminders", handlers.GetCertificationReminders)
+        consented.POST("/certifications/reminders/:id/dismiss", handlers.DismissCertificationReminder)
+
+        // Profile CRUD stays outside the policy gate: onboarding accepts the
+        // policies through it.
         userProfiles := authRequired.Group("/profiles")
         {
             userProfiles.GET("/me", handlers.GetOrCreateMyProfile)
             userProfiles.POST("", handlers.CreateUserProfile)
             userProfiles.GET("/:id", handlers.GetUserProfile)
             userProfiles.PUT("/:id", handlers.UpdateUserProfile)
+            userProfiles.PATCH("/:id", handlers.PatchUserProfile)
+        }
+        myProfile := consented.Group("/profiles/me")
+        {
+            myProfile.POST("/import", handlers.ImportResume)
+            myProfile.GET("/cv", handlers.ExportCV)
+            myProfile.GET("/snapshots", handlers.ListProfileSnapshots)
+            myProfile.GET("/snapshots/:snapshot_id", handlers.GetProfileSnapshot)
+            myProfile.GET("/timeline", handlers.GetProfileTimeline)
+            myProfile.GET("/diff", handlers.DiffProfileSnapshots)
+            myProfile.GET("/gaps", handlers.GetSkillGaps)
+            myProfile.GET("/readiness", handlers.GetPromotionReadiness)
+        }
+
+        // Data-subject requests: export everything, schedule erasure, policy consents
+        account := authRequired.Group("/account")
+        {
+            account.GET("/export", handlers.ExportMyData)
+            account.GET("/erasure", handlers.GetMyErasure)
+            account.POST("/erasure", handlers.RequestMyErasure)
+            account.DELETE("/erasure", handlers.CancelMyErasure)
+            account.GET("/consents", handlers.GetMyConsents)
+            account.POST("/consents", handlers.AcceptPolicy)
         }
 
-        // Admin: system health and users (read-only)
+        // Admin: system health, users, org-wide cycles, the skills/certification catalogs, role requirements, career ladders, the erasure log, policy documents, the goal catalog and suggestion adoption
         admin := authRequired.Group("/admin")
         admin.Use(middleware.RequireAdmin())
         {
             admin.GET("/health", handlers.AdminHealth)
             admin.GET("/users", handlers.AdminUsers)
             admin.GET("/ai-status", handlers.AdminAIStatus)
+            admin.POST("/cycles", handlers.AdminCreateCycle)
+            admin.GET("/skills/unreviewed", handlers.AdminListUnreviewedSkills)
+            admin.POST("/skills", handlers.AdminUpsertSkill)
+            admin.PUT("/skills/:id", handlers.AdminUpsertSkill)
+            admin.POST("/certifications", handlers.AdminUpsertCertification)
+            admin.PUT("/certifications/:id", handlers.AdminUpsertCertification)
+            admin.PUT("/job-roles/:id/requirements", handlers.AdminSetJobRoleRequirements)
+            admin.POST("/career-ladders/import", handlers.AdminImportCareerLadder)
+            admin.GET("/erasures", handlers.AdminErasureLog)
+            admin.POST("/erasures/:id/execute", handlers.AdminExecuteErasure)
+            admin.GET("/policies", handlers.AdminListPolicies)
+            admin.POST("/policies", handlers.AdminPublishPolicy)
+
+            // Catalog CRUD (job-roles, responsibilities, goal-suggestions,
+            // progress-suggestions) with draft/publish and an audit trail
+            admin.GET("/catalog/audit", handlers.AdminCatalogAudit)
+            admin.GET("/suggestions/adoption", handlers.AdminSuggestionAdoption)
+            admin.POST("/catalog/fixtures", handlers.AdminApplyCatalogFixture)
+            admin.GET("/catalog/:type", handlers.AdminListCatalog)
+            admin.POST("/catalog/:type", handlers.AdminCreateCatalogItem)
+            admin.GET("/catalog/:type/:id", handlers.AdminGetCatalogItem)
+            admin.PUT("/catalog/:type/:id", handlers.AdminUpdateCatalogItem)
+            admin.DELETE("/catalog/:type/:id", handlers.AdminDeleteCatalogItem)
+            admin.POST("/catalog/:type/:id/publish", handlers.AdminPublishCatalogItem)
+            admin.POST("/catalog/:type/:id/unpublish", handlers.AdminUnpublishCatalogItem)
+            admin.GET("/catalog/:type/:id/translations", handlers.AdminGetCatalogTranslations)
+            admin.PUT("/catalog/:type/:id/translations/:locale", handlers.AdminPutCatalogTranslations)
+            admin.DELETE("/catalog/:type/:id/translations/:locale", handlers.AdminDeleteCatalogTranslations)
         }
     }
 	
@@ -129,8 +335,6 @@ func main() {
 	
     // Ingestion scheduler removed for now
 
-    log.Printf("Starting server on port %s", cfg.APIPort)
-	if err := r.Run(":" + cfg.APIPort); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
+    log.Printf("Starting server on port %s", *port)
+	return r.Run(":" + *port)
 }
\ No newline at end of file
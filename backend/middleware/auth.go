@@ -37,49 +37,73 @@ func RequireAuth() gin.HandlerFunc {
 	cfg := config.Load()
 
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+		userID, code, err := authenticate(c, cfg)
+		if code != "" {
+			body := Error(c, code)
+			if err != nil {
+				body["detail"] = err.Error()
+			}
+			c.AbortWithStatusJSON(http.StatusUnauthorized, body)
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Bearer token required"})
-			return
-		}
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
 
-		// Verify HS256 token using Supabase JWT secret
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			if cfg.SupabaseJWTSecret == "" {
-				return nil, fmt.Errorf("missing SUPABASE_JWT_SECRET")
-			}
-			return []byte(cfg.SupabaseJWTSecret), nil
-		}, jwt.WithIssuer(cfg.OIDCIssuerURL), jwt.WithAudience(cfg.OIDCAudience))
+// OptionalAuth sets the user ID when the request carries a valid token and
+// otherwise lets it through anonymously, so a stale session never locks a
+// client out of public routes.
+func OptionalAuth() gin.HandlerFunc {
+	cfg := config.Load()
 
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": fmt.Sprintf("Invalid token: %v", err)})
-			return
+	return func(c *gin.Context) {
+		if userID, code, _ := authenticate(c, cfg); code == "" {
+			c.Set(UserIDKey, userID)
 		}
+		c.Next()
+	}
+}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
-			return
-		}
+// authenticate verifies the request's bearer token and returns its subject,
+// or the error code (and cause, for invalid tokens) to reject it with.
+func authenticate(c *gin.Context, cfg *config.Config) (string, string, error) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		return "", "authorization_header_required", nil
+	}
 
-		userID, ok := claims["sub"].(string)
-		if !ok || userID == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "User ID (sub) not found in token claims"})
-			return
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return "", "bearer_token_required", nil
+	}
+
+	// Verify HS256 token using Supabase JWT secret
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		if cfg.SupabaseJWTSecret == "" {
+			return nil, fmt.Errorf("missing SUPABASE_JWT_SECRET")
 		}
+		return []byte(cfg.SupabaseJWTSecret), nil
+	}, jwt.WithIssuer(cfg.OIDCIssuerURL), jwt.WithAudience(cfg.OIDCAudience))
 
-		c.Set(UserIDKey, userID)
-		c.Next()
+	if err != nil {
+		return "", "invalid_token", err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", "invalid_token_claims", nil
 	}
+
+	userID, ok := claims["sub"].(string)
+	if !ok || userID == "" {
+		return "", "token_subject_missing", nil
+	}
+	return userID, "", nil
 }
 
 // loadAdminIDs loads and caches admin user IDs with TTL
@@ -116,19 +140,40 @@ func loadAdminIDs() map[string]struct{} {
     return adminCache.adminIDs
 }
 
+// adminGrantLookup, when set, reports admin grants stored outside the
+// environment allowlist
+var adminGrantLookup func(userID string) (bool, error)
+
+// SetAdminGrantLookup makes RequireAdmin also accept users for which lookup
+// reports a grant.
+func SetAdminGrantLookup(lookup func(userID string) (bool, error)) {
+    adminGrantLookup = lookup
+}
+
 // RequireAdmin checks if authenticated user is in the ADMIN_USER_IDS allowlist
+// or holds an admin grant
 func RequireAdmin() gin.HandlerFunc {
     return func(c *gin.Context) {
         uid, err := GetUserID(c)
         if err != nil {
-            c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin only"})
+            c.AbortWithStatusJSON(http.StatusForbidden, Error(c, "admin_only"))
             return
         }
         
         adminIDs := loadAdminIDs()
         if _, ok := adminIDs[uid]; !ok {
-            c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin only"})
-            return
+            granted := false
+            if adminGrantLookup != nil {
+                var err error
+                if granted, err = adminGrantLookup(uid); err != nil {
+                    c.AbortWithStatusJSON(http.StatusInternalServerError, Error(c, "check_admin_access_failed"))
+                    return
+                }
+            }
+            if !granted {
+                c.AbortWithStatusJSON(http.StatusForbidden, Error(c, "admin_only"))
+                return
+            }
         }
         
         c.Next()
@@ -25,20 +25,20 @@ func CSRFProtection() gin.HandlerFunc {
 		// Get CSRF token from cookie
 		cookieToken, err := c.Cookie(csrfCookieName)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "CSRF token missing"})
+			c.AbortWithStatusJSON(http.StatusForbidden, Error(c, "csrf_token_missing"))
 			return
 		}
 
 		// Get CSRF token from header
 		headerToken := c.GetHeader(csrfTokenHeader)
 		if headerToken == "" {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "CSRF token required in header"})
+			c.AbortWithStatusJSON(http.StatusForbidden, Error(c, "csrf_token_required"))
 			return
 		}
 
 		// Verify tokens match
 		if !secureCompare(cookieToken, headerToken) {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "CSRF token mismatch"})
+			c.AbortWithStatusJSON(http.StatusForbidden, Error(c, "csrf_token_mismatch"))
 			return
 		}
 
@@ -51,7 +51,7 @@ func CSRFTokenEndpoint() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token, err := generateCSRFToken()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate CSRF token"})
+			c.JSON(http.StatusInternalServerError, Error(c, "csrf_token_failed"))
 			return
 		}
 
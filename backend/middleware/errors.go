@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+
+	"goaltracker/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error builds an error body: the message for code in the request's locale
+// and the code itself, which clients should match on instead of the text.
+func Error(c *gin.Context, code string, args ...interface{}) gin.H {
+	return gin.H{"error": i18n.Text(GetLocale(c), code, args...), "code": code}
+}
+
+// ValidationFailed builds the 422 body for field-level errors. The code is
+// always validation_failed; message and args pick the summary text.
+func ValidationFailed(c *gin.Context, fields interface{}, message string, args ...interface{}) gin.H {
+	return gin.H{"error": i18n.Text(GetLocale(c), message, args...), "code": "validation_failed", "fields": fields}
+}
+
+// ErrorFrom builds an error body for err. Errors carrying a code (*i18n.Error)
+// are localized; anything else gets fallback's message with the original
+// English text as detail.
+func ErrorFrom(c *gin.Context, err error, fallback string) gin.H {
+	var e *i18n.Error
+	if errors.As(err, &e) {
+		return gin.H{"error": e.Localize(GetLocale(c)), "code": e.Code}
+	}
+	h := Error(c, fallback)
+	h["detail"] = err.Error()
+	return h
+}
+
+// DBError builds an error body for a failed database operation without
+// leaking database details (see SanitizeDBError).
+func DBError(c *gin.Context, err error) gin.H {
+	code := dbErrorCode(err)
+	if code == "" {
+		return ErrorFrom(c, err, "invalid_request")
+	}
+	return Error(c, code)
+}
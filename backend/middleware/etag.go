@@ -0,0 +1,146 @@
+package middleware
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+)
+
+// If-Match strictness for state-changing requests (IF_MATCH_MODE):
+//   required - PUT/PATCH/DELETE without If-Match get 428
+//   optional - If-Match is checked when present (default)
+//   off      - If-Match is ignored
+const (
+    IfMatchRequired = "required"
+    IfMatchOptional = "optional"
+    IfMatchOff      = "off"
+)
+
+// ifMatchMode is the configured strictness, IfMatchOptional until
+// SetIfMatchMode is called.
+var ifMatchMode = IfMatchOptional
+
+// SetIfMatchMode installs the If-Match strictness (config IF_MATCH_MODE).
+// Unknown values fall back to optional.
+func SetIfMatchMode(mode string) {
+    switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+    case IfMatchRequired, IfMatchOff:
+        ifMatchMode = m
+    default:
+        ifMatchMode = IfMatchOptional
+    }
+}
+
+// VersionETag builds a strong ETag for a versioned resource, e.g. "goal-12-v3"
+func VersionETag(kind string, id uint, version int) string {
+    return fmt.Sprintf("\"%s-%d-v%d\"", kind, id, version)
+}
+
+// ContentETag extends a version ETag with a fingerprint of the full
+// representation (e.g. preloaded progress), giving "goal-12-v3+1a2b3c4d".
+// If-None-Match compares the whole tag; If-Match only the version part, so
+// child changes invalidate caches without causing write conflicts.
+func ContentETag(versionTag string, representation interface{}) string {
+    b, err := json.Marshal(representation)
+    if err != nil {
+        return versionTag
+    }
+    sum := sha256.Sum256(b)
+    return strings.TrimSuffix(versionTag, "\"") + "+" + hex.EncodeToString(sum[:4]) + "\""
+}
+
+// HashETag builds a weak ETag from the JSON encoding of v, for list responses
+func HashETag(v interface{}) string {
+    b, err := json.Marshal(v)
+    if err != nil {
+        return ""
+    }
+    sum := sha256.Sum256(b)
+    return "W/\"" + hex.EncodeToString(sum[:12]) + "\""
+}
+
+// NotModified sets the ETag header and, when If-None-Match matches, writes
+// 304 and returns true so the handler can stop.
+func NotModified(c *gin.Context, etag string) bool {
+    if etag == "" {
+        return false
+    }
+    c.Header("ETag", etag)
+    inm := c.GetHeader("If-None-Match")
+    if inm == "" {
+        return false
+    }
+    // If-None-Match uses weak comparison
+    for _, candidate := range splitETags(inm) {
+        if candidate == "*" || stripWeak(candidate) == stripWeak(etag) {
+            c.Status(http.StatusNotModified)
+            return true
+        }
+    }
+    return false
+}
+
+// CheckIfMatch validates If-Match against the current ETag. On failure it
+// writes 428 (missing, strict mode) or 412 with the current representation
+// and returns false.
+func CheckIfMatch(c *gin.Context, etag string, current interface{}) bool {
+    mode := ifMatchMode
+    if mode == IfMatchOff {
+        return true
+    }
+    im := c.GetHeader("If-Match")
+    if im == "" {
+        if mode == IfMatchRequired {
+            c.AbortWithStatusJSON(http.StatusPreconditionRequired, Error(c, "precondition_required"))
+            return false
+        }
+        return true
+    }
+    for _, candidate := range splitETags(im) {
+        if candidate == "*" || versionPart(candidate) == versionPart(etag) {
+            return true
+        }
+    }
+    PreconditionFailed(c, etag, current)
+    return false
+}
+
+// PreconditionFailed writes a 412 carrying the current representation so the
+// client can merge and retry.
+func PreconditionFailed(c *gin.Context, etag string, current interface{}) {
+    if etag != "" {
+        c.Header("ETag", etag)
+    }
+    body := Error(c, "precondition_failed")
+    body["data"] = current
+    c.AbortWithStatusJSON(http.StatusPreconditionFailed, body)
+}
+
+func splitETags(h string) []string {
+    parts := strings.Split(h, ",")
+    out := make([]string, 0, len(parts))
+    for _, p := range parts {
+        if p = strings.TrimSpace(p); p != "" {
+            out = append(out, p)
+        }
+    }
+    return out
+}
+
+func stripWeak(etag string) string {
+    return strings.TrimPrefix(etag, "W/")
+}
+
+// versionPart drops the weak marker and any content fingerprint
+func versionPart(etag string) string {
+    etag = stripWeak(etag)
+    if i := strings.Index(etag, "+"); i >= 0 {
+        return etag[:i] + "\""
+    }
+    return etag
+}
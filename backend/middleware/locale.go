@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"goaltracker/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const localeKey = "locale"
+
+// localePreference returns a user's saved locale ("" for none). Injected by
+// main so this package stays free of the database.
+var localePreference func(userID string) (string, error)
+
+// SetLocalePreference installs the lookup UserLocale uses.
+func SetLocalePreference(lookup func(userID string) (string, error)) {
+	localePreference = lookup
+}
+
+// Locale negotiates the response locale from Accept-Language, falling back
+// to i18n.Default.
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Vary", "Accept-Language")
+		setLocale(c, i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Next()
+	}
+}
+
+// UserLocale lets the signed-in user's saved locale override the negotiated
+// one. Use after AuthMiddleware; a failed lookup keeps the negotiated locale.
+func UserLocale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := GetUserID(c)
+		if err == nil && localePreference != nil {
+			if saved, err := localePreference(userID); err == nil {
+				if l, ok := i18n.Match(saved); ok {
+					setLocale(c, l)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+// GetLocale returns the locale negotiated for the request.
+func GetLocale(c *gin.Context) string {
+	if l := c.GetString(localeKey); l != "" {
+		return l
+	}
+	return i18n.Default
+}
+
+func setLocale(c *gin.Context, locale string) {
+	c.Set(localeKey, locale)
+	c.Header("Content-Language", locale)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+)
+
+// RequiredPolicy is a policy version the user must accept before using
+// protected routes.
+type RequiredPolicy struct {
+    Kind    string `json:"kind"`
+    Version string `json:"version"`
+    Title   string `json:"title"`
+    URL     string `json:"url,omitempty"`
+}
+
+// RequirePolicyAcceptance rejects requests with 403 and code
+// "policy_acceptance_required" while pending reports unaccepted policies for
+// the authenticated user. Mount it after RequireAuth, and leave the routes
+// used to read and accept policies (and data-subject requests) outside it.
+func RequirePolicyAcceptance(pending func(userID string) ([]RequiredPolicy, error)) gin.HandlerFunc {
+    return func(c *gin.Context) {
+        userID, err := GetUserID(c)
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusUnauthorized, Error(c, "authentication_required"))
+            return
+        }
+        required, err := pending(userID)
+        if err != nil {
+            c.AbortWithStatusJSON(http.StatusInternalServerError, Error(c, "check_policy_acceptance_failed"))
+            return
+        }
+        if len(required) > 0 {
+            body := Error(c, "policy_acceptance_required")
+            body["policies"] = required
+            c.AbortWithStatusJSON(http.StatusForbidden, body)
+            return
+        }
+        c.Next()
+    }
+}
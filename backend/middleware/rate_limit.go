@@ -74,7 +74,7 @@ func RateLimitMiddleware(capacity, refillRate int) gin.HandlerFunc {
 			ip = "unknown"
 		}
 		if !rl.allow(ip) {
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded"})
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, Error(c, "rate_limited"))
 			return
 		}
 		c.Next()
@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
 	"strings"
 
+	"goaltracker/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -18,7 +19,7 @@ func RequestSizeLimiter(maxSize int64) gin.HandlerFunc {
 		// Also check Content-Length if available for early rejection
 		if c.Request.ContentLength > 0 && c.Request.ContentLength > maxSize {
 			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, 
-				gin.H{"error": fmt.Sprintf("Request body too large. Maximum size: %d bytes", maxSize)})
+				Error(c, "request_too_large", maxSize))
 			return
 		}
 		
@@ -30,10 +31,10 @@ func RequestSizeLimiter(maxSize int64) gin.HandlerFunc {
 func ValidateStringLength(field, value string, min, max int) error {
 	length := len(strings.TrimSpace(value))
 	if length < min {
-		return fmt.Errorf("%s must be at least %d characters", field, min)
+		return i18n.Errorf("field_too_short", field, min)
 	}
 	if length > max {
-		return fmt.Errorf("%s must not exceed %d characters", field, max)
+		return i18n.Errorf("field_too_long", field, max)
 	}
 	return nil
 }
@@ -41,7 +42,7 @@ func ValidateStringLength(field, value string, min, max int) error {
 // ValidateJSONSize validates JSON field size
 func ValidateJSONSize(field, jsonStr string, maxBytes int) error {
 	if len(jsonStr) > maxBytes {
-		return fmt.Errorf("%s JSON exceeds maximum size of %d bytes", field, maxBytes)
+		return i18n.Errorf("json_field_too_large", field, maxBytes)
 	}
 	return nil
 }
@@ -59,38 +60,46 @@ func SanitizeDBError(err error) string {
 	if err == nil {
 		return ""
 	}
-	
+	if code := dbErrorCode(err); code != "" {
+		return i18n.Text(i18n.Default, code)
+	}
+	// For validation errors, allow the original message
+	return err.Error()
+}
+
+// dbErrorCode classifies a database error; "" means its text is safe to show.
+func dbErrorCode(err error) string {
 	errStr := strings.ToLower(err.Error())
 	
 	// Common database constraint violations
 	if strings.Contains(errStr, "duplicate") || strings.Contains(errStr, "unique") {
-		return "Resource already exists"
+		return "already_exists"
 	}
 	if strings.Contains(errStr, "foreign key") {
-		return "Invalid reference"
+		return "invalid_reference"
 	}
 	if strings.Contains(errStr, "not null") {
-		return "Required field missing"
+		return "required_field_missing"
 	}
 	if strings.Contains(errStr, "check constraint") {
-		return "Invalid data format"
+		return "invalid_data_format"
 	}
 	
 	// Generic database errors
 	if strings.Contains(errStr, "database") || strings.Contains(errStr, "sql") {
-		return "Database operation failed"
+		return "database_error"
 	}
 	
 	// Connection errors
 	if strings.Contains(errStr, "connection") || strings.Contains(errStr, "timeout") {
-		return "Service temporarily unavailable"
+		return "service_unavailable"
 	}
 	
 	// For validation errors, allow the original message
 	if strings.Contains(errStr, "validation") || strings.Contains(errStr, "invalid") {
-		return err.Error()
+		return ""
 	}
 	
 	// Default safe message
-	return "Operation failed"
-}
\ No newline at end of file
+	return "operation_failed"
+}
@@ -0,0 +1,35 @@
+-- Drops every table the baseline creates. All data is lost.
+
+DROP TABLE IF EXISTS "admin_grants" CASCADE;
+DROP TABLE IF EXISTS "catalog_audit_entries" CASCADE;
+DROP TABLE IF EXISTS "policy_acceptances" CASCADE;
+DROP TABLE IF EXISTS "policy_documents" CASCADE;
+DROP TABLE IF EXISTS "erasure_requests" CASCADE;
+DROP TABLE IF EXISTS "blocked_periods" CASCADE;
+DROP TABLE IF EXISTS "competency_expectations" CASCADE;
+DROP TABLE IF EXISTS "career_levels" CASCADE;
+DROP TABLE IF EXISTS "competencies" CASCADE;
+DROP TABLE IF EXISTS "career_tracks" CASCADE;
+DROP TABLE IF EXISTS "job_role_requirements" CASCADE;
+DROP TABLE IF EXISTS "profile_snapshots" CASCADE;
+DROP TABLE IF EXISTS "certification_reminders" CASCADE;
+DROP TABLE IF EXISTS "ce_credits" CASCADE;
+DROP TABLE IF EXISTS "user_certifications" CASCADE;
+DROP TABLE IF EXISTS "certifications" CASCADE;
+DROP TABLE IF EXISTS "skill_proficiency_changes" CASCADE;
+DROP TABLE IF EXISTS "user_skills" CASCADE;
+DROP TABLE IF EXISTS "skills" CASCADE;
+DROP TABLE IF EXISTS "kr_grades" CASCADE;
+DROP TABLE IF EXISTS "cycle_reviews" CASCADE;
+DROP TABLE IF EXISTS "cycles" CASCADE;
+DROP TABLE IF EXISTS "goal_revisions" CASCADE;
+DROP TABLE IF EXISTS "kr_snapshots" CASCADE;
+DROP TABLE IF EXISTS "learning_insights" CASCADE;
+DROP TABLE IF EXISTS "ai_goal_suggestions" CASCADE;
+DROP TABLE IF EXISTS "user_profiles" CASCADE;
+DROP TABLE IF EXISTS "progress_suggestions" CASCADE;
+DROP TABLE IF EXISTS "goal_suggestions" CASCADE;
+DROP TABLE IF EXISTS "progresses" CASCADE;
+DROP TABLE IF EXISTS "goals" CASCADE;
+DROP TABLE IF EXISTS "responsibilities" CASCADE;
+DROP TABLE IF EXISTS "job_roles" CASCADE;
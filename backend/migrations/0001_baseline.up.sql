@@ -0,0 +1,917 @@
+-- Baseline: the schema as the server created it with GORM AutoMigrate before
+-- versioned migrations. Every statement is idempotent: databases AutoMigrate
+-- built run it too (see database.MigrateUp), which adds whatever tables,
+-- columns and constraints an older deployment lacks and leaves the rest.
+
+CREATE TABLE IF NOT EXISTS "job_roles" (
+    "id" bigserial,
+    "slug" text,
+    "title" text NOT NULL,
+    "description" text,
+    "status" text NOT NULL DEFAULT 'published',
+    "published_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "uni_job_roles_title" UNIQUE ("title"),
+    CONSTRAINT "chk_job_roles_status" CHECK (status IN ('draft','published'))
+);
+ALTER TABLE "job_roles"
+    ADD COLUMN IF NOT EXISTS "slug" text,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS "published_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_job_roles_status" ON "job_roles" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_job_roles_slug" ON "job_roles" ("slug") WHERE slug <> '';
+
+CREATE TABLE IF NOT EXISTS "responsibilities" (
+    "id" bigserial,
+    "job_role_id" bigint NOT NULL,
+    "slug" text,
+    "title" text NOT NULL,
+    "description" text,
+    "category" text DEFAULT 'general',
+    "status" text NOT NULL DEFAULT 'published',
+    "published_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_job_roles_responsibilities" FOREIGN KEY ("job_role_id") REFERENCES "job_roles"("id"),
+    CONSTRAINT "chk_responsibilities_status" CHECK (status IN ('draft','published'))
+);
+ALTER TABLE "responsibilities"
+    ADD COLUMN IF NOT EXISTS "job_role_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "slug" text,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "category" text DEFAULT 'general',
+    ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS "published_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_responsibilities_status" ON "responsibilities" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_responsibilities_slug" ON "responsibilities" ("slug") WHERE slug <> '';
+CREATE INDEX IF NOT EXISTS "idx_responsibilities_job_role_id" ON "responsibilities" ("job_role_id");
+
+CREATE TABLE IF NOT EXISTS "goals" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "title" text NOT NULL,
+    "description" text,
+    "job_role_id" bigint,
+    "status" text DEFAULT 'active',
+    "priority" text DEFAULT 'medium',
+    "due_date" timestamptz,
+    "tags" text,
+    "metadata" jsonb,
+    "completed_at" timestamptz,
+    "version" bigint NOT NULL DEFAULT 1,
+    "cycle_id" bigint,
+    "rolled_from_goal_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_goals_job_role" FOREIGN KEY ("job_role_id") REFERENCES "job_roles"("id"),
+    CONSTRAINT "chk_goals_status" CHECK (status IN ('active','completed','paused')),
+    CONSTRAINT "chk_goals_priority" CHECK (priority IN ('low','medium','high'))
+);
+ALTER TABLE "goals"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "job_role_id" bigint,
+    ADD COLUMN IF NOT EXISTS "status" text DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS "priority" text DEFAULT 'medium',
+    ADD COLUMN IF NOT EXISTS "due_date" timestamptz,
+    ADD COLUMN IF NOT EXISTS "tags" text,
+    ADD COLUMN IF NOT EXISTS "metadata" jsonb,
+    ADD COLUMN IF NOT EXISTS "completed_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS "cycle_id" bigint,
+    ADD COLUMN IF NOT EXISTS "rolled_from_goal_id" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_goals_completed_at" ON "goals" ("completed_at");
+CREATE INDEX IF NOT EXISTS "idx_goals_job_role_id" ON "goals" ("job_role_id");
+CREATE INDEX IF NOT EXISTS "idx_goals_user_id" ON "goals" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_goals_deleted_at" ON "goals" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_goals_rolled_from_goal_id" ON "goals" ("rolled_from_goal_id");
+CREATE INDEX IF NOT EXISTS "idx_goals_cycle_id" ON "goals" ("cycle_id");
+
+CREATE TABLE IF NOT EXISTS "progresses" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "goal_id" bigint NOT NULL,
+    "description" text NOT NULL,
+    "percentage" bigint DEFAULT 0,
+    "notes" text,
+    "outcome" text,
+    "action_taken" text,
+    "next_steps" text,
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_goals_progress" FOREIGN KEY ("goal_id") REFERENCES "goals"("id"),
+    CONSTRAINT "chk_progresses_percentage" CHECK (percentage >= 0 AND percentage <= 100)
+);
+ALTER TABLE "progresses"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "goal_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "percentage" bigint DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "notes" text,
+    ADD COLUMN IF NOT EXISTS "outcome" text,
+    ADD COLUMN IF NOT EXISTS "action_taken" text,
+    ADD COLUMN IF NOT EXISTS "next_steps" text,
+    ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_progresses_goal_id" ON "progresses" ("goal_id");
+CREATE INDEX IF NOT EXISTS "idx_progresses_user_id" ON "progresses" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "goal_suggestions" (
+    "id" bigserial,
+    "responsibility_id" bigint NOT NULL,
+    "slug" text,
+    "title" text NOT NULL,
+    "description" text,
+    "category" text DEFAULT 'skill',
+    "priority" text DEFAULT 'medium',
+    "estimated_duration" text,
+    "status" text NOT NULL DEFAULT 'published',
+    "published_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_goal_suggestions_responsibility" FOREIGN KEY ("responsibility_id") REFERENCES "responsibilities"("id"),
+    CONSTRAINT "chk_goal_suggestions_priority" CHECK (priority IN ('low','medium','high')),
+    CONSTRAINT "chk_goal_suggestions_status" CHECK (status IN ('draft','published'))
+);
+ALTER TABLE "goal_suggestions"
+    ADD COLUMN IF NOT EXISTS "responsibility_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "slug" text,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "category" text DEFAULT 'skill',
+    ADD COLUMN IF NOT EXISTS "priority" text DEFAULT 'medium',
+    ADD COLUMN IF NOT EXISTS "estimated_duration" text,
+    ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS "published_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_goal_suggestions_status" ON "goal_suggestions" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_goal_suggestions_slug" ON "goal_suggestions" ("slug") WHERE slug <> '';
+CREATE INDEX IF NOT EXISTS "idx_goal_suggestions_responsibility_id" ON "goal_suggestions" ("responsibility_id");
+
+CREATE TABLE IF NOT EXISTS "progress_suggestions" (
+    "id" bigserial,
+    "goal_suggestion_id" bigint NOT NULL,
+    "slug" text,
+    "progress_stage" text NOT NULL,
+    "suggested_outcome" text NOT NULL,
+    "action_prompt" text,
+    "next_step_prompt" text,
+    "percentage_range" text,
+    "status" text NOT NULL DEFAULT 'published',
+    "published_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_progress_suggestions_goal_suggestion" FOREIGN KEY ("goal_suggestion_id") REFERENCES "goal_suggestions"("id"),
+    CONSTRAINT "chk_progress_suggestions_status" CHECK (status IN ('draft','published'))
+);
+ALTER TABLE "progress_suggestions"
+    ADD COLUMN IF NOT EXISTS "goal_suggestion_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "slug" text,
+    ADD COLUMN IF NOT EXISTS "progress_stage" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "suggested_outcome" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "action_prompt" text,
+    ADD COLUMN IF NOT EXISTS "next_step_prompt" text,
+    ADD COLUMN IF NOT EXISTS "percentage_range" text,
+    ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS "published_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_progress_suggestions_status" ON "progress_suggestions" ("status");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_progress_suggestions_slug" ON "progress_suggestions" ("slug") WHERE slug <> '';
+CREATE INDEX IF NOT EXISTS "idx_progress_suggestions_goal_suggestion_id" ON "progress_suggestions" ("goal_suggestion_id");
+
+CREATE TABLE IF NOT EXISTS "user_profiles" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "current_role" text,
+    "experience_level" text,
+    "industry" text,
+    "company_size" text,
+    "learning_style" text,
+    "available_hours_week" bigint,
+    "career_goals" text,
+    "current_tools" text,
+    "skill_gaps" text,
+    "it_profile" jsonb,
+    "terms_accepted_at" timestamptz,
+    "privacy_accepted_at" timestamptz,
+    "policies_version" text DEFAULT '1.0',
+    "version" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_user_profiles_experience_level" CHECK (experience_level IN ('entry','junior','mid','senior','lead','expert'))
+);
+ALTER TABLE "user_profiles"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "current_role" text,
+    ADD COLUMN IF NOT EXISTS "experience_level" text,
+    ADD COLUMN IF NOT EXISTS "industry" text,
+    ADD COLUMN IF NOT EXISTS "company_size" text,
+    ADD COLUMN IF NOT EXISTS "learning_style" text,
+    ADD COLUMN IF NOT EXISTS "available_hours_week" bigint,
+    ADD COLUMN IF NOT EXISTS "career_goals" text,
+    ADD COLUMN IF NOT EXISTS "current_tools" text,
+    ADD COLUMN IF NOT EXISTS "skill_gaps" text,
+    ADD COLUMN IF NOT EXISTS "it_profile" jsonb,
+    ADD COLUMN IF NOT EXISTS "terms_accepted_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "privacy_accepted_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "policies_version" text DEFAULT '1.0',
+    ADD COLUMN IF NOT EXISTS "version" bigint NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_profiles_user_id" ON "user_profiles" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "ai_goal_suggestions" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "user_profile_id" bigint NOT NULL,
+    "ai_generated_title" text NOT NULL,
+    "ai_generated_path" text,
+    "personalization_context" text,
+    "market_relevance_score" decimal,
+    "difficulty_score" decimal,
+    "priority_score" decimal,
+    "estimated_completion" bigint,
+    "a_iprompt_used" text,
+    "ai_response" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_ai_goal_suggestions_user_profile" FOREIGN KEY ("user_profile_id") REFERENCES "user_profiles"("id")
+);
+ALTER TABLE "ai_goal_suggestions"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "user_profile_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "ai_generated_title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "ai_generated_path" text,
+    ADD COLUMN IF NOT EXISTS "personalization_context" text,
+    ADD COLUMN IF NOT EXISTS "market_relevance_score" decimal,
+    ADD COLUMN IF NOT EXISTS "difficulty_score" decimal,
+    ADD COLUMN IF NOT EXISTS "priority_score" decimal,
+    ADD COLUMN IF NOT EXISTS "estimated_completion" bigint,
+    ADD COLUMN IF NOT EXISTS "a_iprompt_used" text,
+    ADD COLUMN IF NOT EXISTS "ai_response" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_ai_goal_suggestions_user_profile_id" ON "ai_goal_suggestions" ("user_profile_id");
+CREATE INDEX IF NOT EXISTS "idx_ai_goal_suggestions_user_id" ON "ai_goal_suggestions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "learning_insights" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "user_profile_id" bigint NOT NULL,
+    "insight_type" text NOT NULL,
+    "insight_text" text NOT NULL,
+    "confidence" decimal,
+    "action_required" boolean,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "learning_insights"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "user_profile_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "insight_type" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "insight_text" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "confidence" decimal,
+    ADD COLUMN IF NOT EXISTS "action_required" boolean,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_learning_insights_user_profile_id" ON "learning_insights" ("user_profile_id");
+CREATE INDEX IF NOT EXISTS "idx_learning_insights_user_id" ON "learning_insights" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "kr_snapshots" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "goal_id" bigint NOT NULL,
+    "kr_id" text NOT NULL,
+    "value" decimal,
+    "status" text,
+    "confidence" decimal,
+    "captured_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_kr_snapshots_status" CHECK (status IN ('on_track','at_risk','off_track'))
+);
+ALTER TABLE "kr_snapshots"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "goal_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "kr_id" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "value" decimal,
+    ADD COLUMN IF NOT EXISTS "status" text,
+    ADD COLUMN IF NOT EXISTS "confidence" decimal,
+    ADD COLUMN IF NOT EXISTS "captured_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_kr_snapshots_goal_id" ON "kr_snapshots" ("goal_id");
+CREATE INDEX IF NOT EXISTS "idx_kr_snapshots_user_id" ON "kr_snapshots" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_kr_snapshots_captured_at" ON "kr_snapshots" ("captured_at");
+CREATE INDEX IF NOT EXISTS "idx_kr_snapshots_kr_id" ON "kr_snapshots" ("kr_id");
+
+CREATE TABLE IF NOT EXISTS "goal_revisions" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "goal_id" bigint NOT NULL,
+    "revision" bigint NOT NULL,
+    "action" text NOT NULL,
+    "actor_id" uuid NOT NULL,
+    "restored_from" bigint,
+    "snapshot" jsonb NOT NULL,
+    "changes" jsonb,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_goal_revisions_action" CHECK (action IN ('baseline','create','update','restore','delete'))
+);
+ALTER TABLE "goal_revisions"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "goal_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "revision" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "action" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "actor_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "restored_from" bigint,
+    ADD COLUMN IF NOT EXISTS "snapshot" jsonb NOT NULL,
+    ADD COLUMN IF NOT EXISTS "changes" jsonb,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_goal_revisions_user_id" ON "goal_revisions" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_goal_revisions_created_at" ON "goal_revisions" ("created_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_goal_revisions_goal_rev" ON "goal_revisions" ("goal_id","revision");
+
+CREATE TABLE IF NOT EXISTS "cycles" (
+    "id" bigserial,
+    "user_id" uuid,
+    "scope" text NOT NULL DEFAULT 'user',
+    "name" text NOT NULL,
+    "start_date" date NOT NULL,
+    "end_date" date NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_cycles_scope" CHECK (scope IN ('user','org'))
+);
+ALTER TABLE "cycles"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid,
+    ADD COLUMN IF NOT EXISTS "scope" text NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "start_date" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "end_date" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_cycles_user_id" ON "cycles" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "cycle_reviews" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "cycle_id" bigint NOT NULL,
+    "reflection" text,
+    "average_grade" decimal,
+    "next_cycle_id" bigint,
+    "closed_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "cycle_reviews"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "cycle_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "reflection" text,
+    ADD COLUMN IF NOT EXISTS "average_grade" decimal,
+    ADD COLUMN IF NOT EXISTS "next_cycle_id" bigint,
+    ADD COLUMN IF NOT EXISTS "closed_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cycle_reviews_user_cycle" ON "cycle_reviews" ("user_id","cycle_id");
+
+CREATE TABLE IF NOT EXISTS "kr_grades" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "cycle_id" bigint NOT NULL,
+    "goal_id" bigint NOT NULL,
+    "kr_id" text NOT NULL DEFAULT '',
+    "grade" decimal NOT NULL,
+    "note" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_kr_grades_grade" CHECK (grade >= 0 AND grade <= 1)
+);
+ALTER TABLE "kr_grades"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "cycle_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "goal_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "kr_id" text NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS "grade" decimal NOT NULL,
+    ADD COLUMN IF NOT EXISTS "note" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_kr_grades_cycle_goal_kr" ON "kr_grades" ("cycle_id","goal_id","kr_id");
+CREATE INDEX IF NOT EXISTS "idx_kr_grades_user_id" ON "kr_grades" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "skills" (
+    "id" bigserial,
+    "slug" text NOT NULL,
+    "name" text NOT NULL,
+    "category" text NOT NULL DEFAULT 'other',
+    "kind" text NOT NULL DEFAULT 'platform',
+    "aliases" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "skills"
+    ADD COLUMN IF NOT EXISTS "slug" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "category" text NOT NULL DEFAULT 'other',
+    ADD COLUMN IF NOT EXISTS "kind" text NOT NULL DEFAULT 'platform',
+    ADD COLUMN IF NOT EXISTS "aliases" jsonb,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_skills_category" ON "skills" ("category");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_skills_slug" ON "skills" ("slug");
+
+CREATE TABLE IF NOT EXISTS "user_skills" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "skill_id" bigint NOT NULL,
+    "proficiency" bigint NOT NULL DEFAULT 0,
+    "source" text,
+    "last_used_at" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_skills_skill" FOREIGN KEY ("skill_id") REFERENCES "skills"("id"),
+    CONSTRAINT "chk_user_skills_proficiency" CHECK (proficiency >= 0 AND proficiency <= 4)
+);
+ALTER TABLE "user_skills"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "skill_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "proficiency" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "source" text,
+    ADD COLUMN IF NOT EXISTS "last_used_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_skills_user_skill" ON "user_skills" ("user_id","skill_id");
+
+CREATE TABLE IF NOT EXISTS "skill_proficiency_changes" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "skill_id" bigint NOT NULL,
+    "from_level" bigint,
+    "to_level" bigint,
+    "reason" text NOT NULL,
+    "goal_id" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_skill_proficiency_changes_reason" CHECK (reason IN ('profile_update','goal_completed','manual'))
+);
+ALTER TABLE "skill_proficiency_changes"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "skill_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "from_level" bigint,
+    ADD COLUMN IF NOT EXISTS "to_level" bigint,
+    ADD COLUMN IF NOT EXISTS "reason" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "goal_id" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_skill_proficiency_changes_created_at" ON "skill_proficiency_changes" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_skill_proficiency_changes_skill_id" ON "skill_proficiency_changes" ("skill_id");
+CREATE INDEX IF NOT EXISTS "idx_skill_proficiency_changes_user_id" ON "skill_proficiency_changes" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "certifications" (
+    "id" bigserial,
+    "slug" text NOT NULL,
+    "name" text NOT NULL,
+    "issuer" text NOT NULL,
+    "validity_months" bigint NOT NULL DEFAULT 0,
+    "credits_required" decimal NOT NULL DEFAULT 0,
+    "annual_minimum" decimal NOT NULL DEFAULT 0,
+    "credit_unit" text,
+    "aliases" jsonb,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "certifications"
+    ADD COLUMN IF NOT EXISTS "slug" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "issuer" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "validity_months" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "credits_required" decimal NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "annual_minimum" decimal NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "credit_unit" text,
+    ADD COLUMN IF NOT EXISTS "aliases" jsonb,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_certifications_slug" ON "certifications" ("slug");
+
+CREATE TABLE IF NOT EXISTS "user_certifications" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "certification_id" bigint NOT NULL,
+    "credential_id" text,
+    "earned_at" date NOT NULL,
+    "cycle_started_at" date NOT NULL,
+    "expires_at" date,
+    "renewal_goal_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_certifications_certification" FOREIGN KEY ("certification_id") REFERENCES "certifications"("id")
+);
+ALTER TABLE "user_certifications"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "certification_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "credential_id" text,
+    ADD COLUMN IF NOT EXISTS "earned_at" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "cycle_started_at" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "expires_at" date,
+    ADD COLUMN IF NOT EXISTS "renewal_goal_id" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_user_certifications_expires_at" ON "user_certifications" ("expires_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_certs_user_cert" ON "user_certifications" ("user_id","certification_id");
+
+CREATE TABLE IF NOT EXISTS "ce_credits" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "user_certification_id" bigint NOT NULL,
+    "title" text NOT NULL,
+    "provider" text,
+    "credits" decimal NOT NULL,
+    "earned_at" date NOT NULL,
+    "evidence_url" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_ce_credits_credits" CHECK (credits > 0)
+);
+ALTER TABLE "ce_credits"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "user_certification_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "provider" text,
+    ADD COLUMN IF NOT EXISTS "credits" decimal NOT NULL,
+    ADD COLUMN IF NOT EXISTS "earned_at" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "evidence_url" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_ce_credits_user_certification_id" ON "ce_credits" ("user_certification_id");
+CREATE INDEX IF NOT EXISTS "idx_ce_credits_user_id" ON "ce_credits" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "certification_reminders" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "user_certification_id" bigint NOT NULL,
+    "kind" text NOT NULL,
+    "expires_at" date NOT NULL,
+    "message" text,
+    "dismissed_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "certification_reminders"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "user_certification_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "kind" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "expires_at" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "message" text,
+    ADD COLUMN IF NOT EXISTS "dismissed_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_certification_reminders_created_at" ON "certification_reminders" ("created_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_cert_reminders_cert_kind" ON "certification_reminders" ("user_certification_id","kind","expires_at");
+CREATE INDEX IF NOT EXISTS "idx_certification_reminders_user_id" ON "certification_reminders" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "profile_snapshots" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "profile_id" bigint NOT NULL,
+    "profile_version" bigint,
+    "kind" text NOT NULL,
+    "snapshot" jsonb NOT NULL,
+    "changes" jsonb,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_profile_snapshots_kind" CHECK (kind IN ('baseline','create','update'))
+);
+ALTER TABLE "profile_snapshots"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "profile_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "profile_version" bigint,
+    ADD COLUMN IF NOT EXISTS "kind" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "snapshot" jsonb NOT NULL,
+    ADD COLUMN IF NOT EXISTS "changes" jsonb,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_profile_snapshots_created_at" ON "profile_snapshots" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_profile_snapshots_profile_id" ON "profile_snapshots" ("profile_id");
+CREATE INDEX IF NOT EXISTS "idx_profile_snapshots_user_id" ON "profile_snapshots" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "job_role_requirements" (
+    "id" bigserial,
+    "job_role_id" bigint NOT NULL,
+    "skill_id" bigint,
+    "certification_id" bigint,
+    "level" bigint NOT NULL DEFAULT 0,
+    "importance" bigint NOT NULL DEFAULT 2,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_job_role_requirements_skill" FOREIGN KEY ("skill_id") REFERENCES "skills"("id"),
+    CONSTRAINT "fk_job_role_requirements_certification" FOREIGN KEY ("certification_id") REFERENCES "certifications"("id"),
+    CONSTRAINT "chk_job_role_requirements_level" CHECK (level BETWEEN 0 AND 4),
+    CONSTRAINT "chk_job_role_requirements_importance" CHECK (importance BETWEEN 1 AND 3)
+);
+ALTER TABLE "job_role_requirements"
+    ADD COLUMN IF NOT EXISTS "job_role_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "skill_id" bigint,
+    ADD COLUMN IF NOT EXISTS "certification_id" bigint,
+    ADD COLUMN IF NOT EXISTS "level" bigint NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS "importance" bigint NOT NULL DEFAULT 2,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_role_req_cert" ON "job_role_requirements" ("job_role_id","certification_id") WHERE certification_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_role_req_skill" ON "job_role_requirements" ("job_role_id","skill_id") WHERE skill_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS "career_tracks" (
+    "id" bigserial,
+    "slug" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "job_role_id" bigint,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "career_tracks"
+    ADD COLUMN IF NOT EXISTS "slug" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "job_role_id" bigint,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "updated_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_career_tracks_job_role_id" ON "career_tracks" ("job_role_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_career_tracks_slug" ON "career_tracks" ("slug");
+
+CREATE TABLE IF NOT EXISTS "competencies" (
+    "id" bigserial,
+    "track_id" bigint NOT NULL,
+    "slug" text NOT NULL,
+    "name" text NOT NULL,
+    "description" text,
+    "keywords" jsonb,
+    "skills" jsonb,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_career_tracks_competencies" FOREIGN KEY ("track_id") REFERENCES "career_tracks"("id") ON DELETE CASCADE
+);
+ALTER TABLE "competencies"
+    ADD COLUMN IF NOT EXISTS "track_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "slug" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text,
+    ADD COLUMN IF NOT EXISTS "keywords" jsonb,
+    ADD COLUMN IF NOT EXISTS "skills" jsonb;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_competency_slug" ON "competencies" ("track_id","slug");
+
+CREATE TABLE IF NOT EXISTS "career_levels" (
+    "id" bigserial,
+    "track_id" bigint NOT NULL,
+    "slug" text NOT NULL,
+    "name" text NOT NULL,
+    "rank" bigint NOT NULL,
+    "experience_level" text,
+    "description" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_career_tracks_levels" FOREIGN KEY ("track_id") REFERENCES "career_tracks"("id") ON DELETE CASCADE
+);
+ALTER TABLE "career_levels"
+    ADD COLUMN IF NOT EXISTS "track_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "slug" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "name" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "rank" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "experience_level" text,
+    ADD COLUMN IF NOT EXISTS "description" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_career_level_rank" ON "career_levels" ("track_id","rank");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_career_level_slug" ON "career_levels" ("track_id","slug");
+
+CREATE TABLE IF NOT EXISTS "competency_expectations" (
+    "id" bigserial,
+    "level_id" bigint NOT NULL,
+    "competency_id" bigint NOT NULL,
+    "level" bigint NOT NULL,
+    "description" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_competency_expectations_competency" FOREIGN KEY ("competency_id") REFERENCES "competencies"("id"),
+    CONSTRAINT "fk_career_levels_expectations" FOREIGN KEY ("level_id") REFERENCES "career_levels"("id") ON DELETE CASCADE,
+    CONSTRAINT "chk_competency_expectations_level" CHECK (level BETWEEN 1 AND 4)
+);
+ALTER TABLE "competency_expectations"
+    ADD COLUMN IF NOT EXISTS "level_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "competency_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "level" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "description" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_competency_expectation" ON "competency_expectations" ("level_id","competency_id");
+
+CREATE TABLE IF NOT EXISTS "blocked_periods" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "start_date" date NOT NULL,
+    "end_date" date NOT NULL,
+    "reason" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_blocked_periods_end_date" CHECK (end_date >= start_date)
+);
+ALTER TABLE "blocked_periods"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "start_date" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "end_date" date NOT NULL,
+    ADD COLUMN IF NOT EXISTS "reason" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_blocked_periods_user_id" ON "blocked_periods" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "erasure_requests" (
+    "id" bigserial,
+    "user_id" uuid,
+    "subject_hash" text NOT NULL,
+    "status" text NOT NULL DEFAULT 'pending',
+    "reason" text,
+    "requested_at" timestamptz,
+    "scheduled_for" timestamptz,
+    "cancelled_at" timestamptz,
+    "completed_at" timestamptz,
+    "rows_deleted" jsonb,
+    "last_error" text,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_erasure_requests_status" CHECK (status IN ('pending','cancelled','completed'))
+);
+ALTER TABLE "erasure_requests"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid,
+    ADD COLUMN IF NOT EXISTS "subject_hash" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "status" text NOT NULL DEFAULT 'pending',
+    ADD COLUMN IF NOT EXISTS "reason" text,
+    ADD COLUMN IF NOT EXISTS "requested_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "scheduled_for" timestamptz,
+    ADD COLUMN IF NOT EXISTS "cancelled_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "completed_at" timestamptz,
+    ADD COLUMN IF NOT EXISTS "rows_deleted" jsonb,
+    ADD COLUMN IF NOT EXISTS "last_error" text;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_erasure_pending" ON "erasure_requests" ("user_id") WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS "idx_erasure_requests_scheduled_for" ON "erasure_requests" ("scheduled_for");
+CREATE INDEX IF NOT EXISTS "idx_erasure_requests_subject_hash" ON "erasure_requests" ("subject_hash");
+
+CREATE TABLE IF NOT EXISTS "policy_documents" (
+    "id" bigserial,
+    "kind" text NOT NULL,
+    "version" text NOT NULL,
+    "title" text NOT NULL,
+    "body" text,
+    "url" text,
+    "required" boolean NOT NULL DEFAULT true,
+    "effective_at" timestamptz NOT NULL,
+    "published_by" uuid,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_policy_documents_kind" CHECK (kind IN ('terms','privacy'))
+);
+ALTER TABLE "policy_documents"
+    ADD COLUMN IF NOT EXISTS "kind" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "version" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "title" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "body" text,
+    ADD COLUMN IF NOT EXISTS "url" text,
+    ADD COLUMN IF NOT EXISTS "required" boolean NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS "effective_at" timestamptz NOT NULL,
+    ADD COLUMN IF NOT EXISTS "published_by" uuid,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_policy_documents_effective_at" ON "policy_documents" ("effective_at");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_policy_kind_version" ON "policy_documents" ("kind","version");
+
+CREATE TABLE IF NOT EXISTS "policy_acceptances" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "policy_id" bigint,
+    "kind" text NOT NULL,
+    "version" text NOT NULL,
+    "accepted_at" timestamptz NOT NULL,
+    "ip_address" text,
+    "user_agent" text,
+    "source" text NOT NULL DEFAULT 'api',
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "policy_acceptances"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "policy_id" bigint,
+    ADD COLUMN IF NOT EXISTS "kind" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "version" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "accepted_at" timestamptz NOT NULL,
+    ADD COLUMN IF NOT EXISTS "ip_address" text,
+    ADD COLUMN IF NOT EXISTS "user_agent" text,
+    ADD COLUMN IF NOT EXISTS "source" text NOT NULL DEFAULT 'api';
+CREATE INDEX IF NOT EXISTS "idx_policy_acceptances_policy_id" ON "policy_acceptances" ("policy_id");
+CREATE INDEX IF NOT EXISTS "idx_policy_acceptance_user_kind" ON "policy_acceptances" ("user_id","kind");
+
+CREATE TABLE IF NOT EXISTS "catalog_audit_entries" (
+    "id" bigserial,
+    "entity_type" text NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "action" text NOT NULL,
+    "actor_id" uuid,
+    "snapshot" jsonb NOT NULL,
+    "changes" jsonb,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_catalog_audit_entries_action" CHECK (action IN ('create','update','publish','unpublish','delete'))
+);
+ALTER TABLE "catalog_audit_entries"
+    ADD COLUMN IF NOT EXISTS "entity_type" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "entity_id" bigint NOT NULL,
+    ADD COLUMN IF NOT EXISTS "action" text NOT NULL,
+    ADD COLUMN IF NOT EXISTS "actor_id" uuid,
+    ADD COLUMN IF NOT EXISTS "snapshot" jsonb NOT NULL,
+    ADD COLUMN IF NOT EXISTS "changes" jsonb,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE INDEX IF NOT EXISTS "idx_catalog_audit_entries_created_at" ON "catalog_audit_entries" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_catalog_audit_entity" ON "catalog_audit_entries" ("entity_type","entity_id");
+
+CREATE TABLE IF NOT EXISTS "admin_grants" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "note" text,
+    "granted_by" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+ALTER TABLE "admin_grants"
+    ADD COLUMN IF NOT EXISTS "user_id" uuid NOT NULL,
+    ADD COLUMN IF NOT EXISTS "note" text,
+    ADD COLUMN IF NOT EXISTS "granted_by" text,
+    ADD COLUMN IF NOT EXISTS "created_at" timestamptz;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_admin_grants_user_id" ON "admin_grants" ("user_id");
+
+-- Constraints an existing table lacks (CREATE TABLE IF NOT EXISTS skips them)
+DO $$
+DECLARE c record;
+BEGIN
+    FOR c IN SELECT * FROM (VALUES
+        ('job_roles', 'uni_job_roles_title', 'UNIQUE ("title")'),
+        ('job_roles', 'chk_job_roles_status', 'CHECK (status IN (''draft'',''published''))'),
+        ('responsibilities', 'fk_job_roles_responsibilities', 'FOREIGN KEY ("job_role_id") REFERENCES "job_roles"("id")'),
+        ('responsibilities', 'chk_responsibilities_status', 'CHECK (status IN (''draft'',''published''))'),
+        ('goals', 'fk_goals_job_role', 'FOREIGN KEY ("job_role_id") REFERENCES "job_roles"("id")'),
+        ('goals', 'chk_goals_status', 'CHECK (status IN (''active'',''completed'',''paused''))'),
+        ('goals', 'chk_goals_priority', 'CHECK (priority IN (''low'',''medium'',''high''))'),
+        ('progresses', 'fk_goals_progress', 'FOREIGN KEY ("goal_id") REFERENCES "goals"("id")'),
+        ('progresses', 'chk_progresses_percentage', 'CHECK (percentage >= 0 AND percentage <= 100)'),
+        ('goal_suggestions', 'fk_goal_suggestions_responsibility', 'FOREIGN KEY ("responsibility_id") REFERENCES "responsibilities"("id")'),
+        ('goal_suggestions', 'chk_goal_suggestions_priority', 'CHECK (priority IN (''low'',''medium'',''high''))'),
+        ('goal_suggestions', 'chk_goal_suggestions_status', 'CHECK (status IN (''draft'',''published''))'),
+        ('progress_suggestions', 'fk_progress_suggestions_goal_suggestion', 'FOREIGN KEY ("goal_suggestion_id") REFERENCES "goal_suggestions"("id")'),
+        ('progress_suggestions', 'chk_progress_suggestions_status', 'CHECK (status IN (''draft'',''published''))'),
+        ('user_profiles', 'chk_user_profiles_experience_level', 'CHECK (experience_level IN (''entry'',''junior'',''mid'',''senior'',''lead'',''expert''))'),
+        ('ai_goal_suggestions', 'fk_ai_goal_suggestions_user_profile', 'FOREIGN KEY ("user_profile_id") REFERENCES "user_profiles"("id")'),
+        ('kr_snapshots', 'chk_kr_snapshots_status', 'CHECK (status IN (''on_track'',''at_risk'',''off_track''))'),
+        ('goal_revisions', 'chk_goal_revisions_action', 'CHECK (action IN (''baseline'',''create'',''update'',''restore'',''delete''))'),
+        ('cycles', 'chk_cycles_scope', 'CHECK (scope IN (''user'',''org''))'),
+        ('kr_grades', 'chk_kr_grades_grade', 'CHECK (grade >= 0 AND grade <= 1)'),
+        ('user_skills', 'fk_user_skills_skill', 'FOREIGN KEY ("skill_id") REFERENCES "skills"("id")'),
+        ('user_skills', 'chk_user_skills_proficiency', 'CHECK (proficiency >= 0 AND proficiency <= 4)'),
+        ('skill_proficiency_changes', 'chk_skill_proficiency_changes_reason', 'CHECK (reason IN (''profile_update'',''goal_completed'',''manual''))'),
+        ('user_certifications', 'fk_user_certifications_certification', 'FOREIGN KEY ("certification_id") REFERENCES "certifications"("id")'),
+        ('ce_credits', 'chk_ce_credits_credits', 'CHECK (credits > 0)'),
+        ('profile_snapshots', 'chk_profile_snapshots_kind', 'CHECK (kind IN (''baseline'',''create'',''update''))'),
+        ('job_role_requirements', 'fk_job_role_requirements_skill', 'FOREIGN KEY ("skill_id") REFERENCES "skills"("id")'),
+        ('job_role_requirements', 'fk_job_role_requirements_certification', 'FOREIGN KEY ("certification_id") REFERENCES "certifications"("id")'),
+        ('job_role_requirements', 'chk_job_role_requirements_level', 'CHECK (level BETWEEN 0 AND 4)'),
+        ('job_role_requirements', 'chk_job_role_requirements_importance', 'CHECK (importance BETWEEN 1 AND 3)'),
+        ('competencies', 'fk_career_tracks_competencies', 'FOREIGN KEY ("track_id") REFERENCES "career_tracks"("id") ON DELETE CASCADE'),
+        ('career_levels', 'fk_career_tracks_levels', 'FOREIGN KEY ("track_id") REFERENCES "career_tracks"("id") ON DELETE CASCADE'),
+        ('competency_expectations', 'fk_competency_expectations_competency', 'FOREIGN KEY ("competency_id") REFERENCES "competencies"("id")'),
+        ('competency_expectations', 'fk_career_levels_expectations', 'FOREIGN KEY ("level_id") REFERENCES "career_levels"("id") ON DELETE CASCADE'),
+        ('competency_expectations', 'chk_competency_expectations_level', 'CHECK (level BETWEEN 1 AND 4)'),
+        ('blocked_periods', 'chk_blocked_periods_end_date', 'CHECK (end_date >= start_date)'),
+        ('erasure_requests', 'chk_erasure_requests_status', 'CHECK (status IN (''pending'',''cancelled'',''completed''))'),
+        ('policy_documents', 'chk_policy_documents_kind', 'CHECK (kind IN (''terms'',''privacy''))'),
+        ('catalog_audit_entries', 'chk_catalog_audit_entries_action', 'CHECK (action IN (''create'',''update'',''publish'',''unpublish'',''delete''))')
+    ) AS v(tbl, name, def) LOOP
+        IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = c.name AND conrelid = c.tbl::regclass) THEN
+            EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I %s', c.tbl, c.name, c.def);
+        END IF;
+    END LOOP;
+END $$;
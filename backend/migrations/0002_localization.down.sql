@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "catalog_translations" CASCADE;
+ALTER TABLE "user_profiles" DROP COLUMN IF EXISTS "locale";
@@ -0,0 +1,15 @@
+-- Saved locale preference and per-locale catalog text. IF NOT EXISTS keeps
+-- this safe on databases adopted from AutoMigrate, which already have both.
+ALTER TABLE "user_profiles" ADD COLUMN IF NOT EXISTS "locale" text;
+
+CREATE TABLE IF NOT EXISTS "catalog_translations" (
+    "id" bigserial,
+    "entity_type" text NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "locale" text NOT NULL,
+    "field" text NOT NULL,
+    "value" text NOT NULL,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_catalog_translation" ON "catalog_translations" ("entity_type","entity_id","locale","field");
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS "idx_skills_source";
+ALTER TABLE "skills" DROP COLUMN IF EXISTS "source_id";
+ALTER TABLE "skills" DROP COLUMN IF EXISTS "source";
+
+DROP INDEX IF EXISTS "idx_responsibilities_source";
+ALTER TABLE "responsibilities" DROP COLUMN IF EXISTS "source_id";
+ALTER TABLE "responsibilities" DROP COLUMN IF EXISTS "source";
+
+DROP INDEX IF EXISTS "idx_job_roles_source";
+ALTER TABLE "job_roles" DROP COLUMN IF EXISTS "source_id";
+ALTER TABLE "job_roles" DROP COLUMN IF EXISTS "source";
@@ -0,0 +1,13 @@
+-- Provenance of catalog rows and skills imported from competency
+-- frameworks (SFIA, NICE, O*NET), so re-imports can find them.
+ALTER TABLE "job_roles" ADD COLUMN IF NOT EXISTS "source" text;
+ALTER TABLE "job_roles" ADD COLUMN IF NOT EXISTS "source_id" text;
+CREATE INDEX IF NOT EXISTS "idx_job_roles_source" ON "job_roles" ("source","source_id");
+
+ALTER TABLE "responsibilities" ADD COLUMN IF NOT EXISTS "source" text;
+ALTER TABLE "responsibilities" ADD COLUMN IF NOT EXISTS "source_id" text;
+CREATE INDEX IF NOT EXISTS "idx_responsibilities_source" ON "responsibilities" ("source","source_id");
+
+ALTER TABLE "skills" ADD COLUMN IF NOT EXISTS "source" text;
+ALTER TABLE "skills" ADD COLUMN IF NOT EXISTS "source_id" text;
+CREATE INDEX IF NOT EXISTS "idx_skills_source" ON "skills" ("source","source_id");
@@ -0,0 +1,21 @@
+-- Per-user feedback on catalog and AI goal suggestions: impressions,
+-- dismissals, saves, adoptions and ratings.
+CREATE TABLE IF NOT EXISTS "suggestion_feedbacks" (
+    "id" bigserial,
+    "user_id" uuid NOT NULL,
+    "source" text NOT NULL,
+    "suggestion_id" bigint,
+    "suggestion_key" text,
+    "title" text,
+    "event" text NOT NULL,
+    "reason" text,
+    "note" text,
+    "goal_id" bigint,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "chk_suggestion_feedbacks_source" CHECK (source IN ('catalog','ai')),
+    CONSTRAINT "chk_suggestion_feedbacks_event" CHECK (event IN ('impression','dismiss','save','adopt','thumbs_up','thumbs_down'))
+);
+CREATE INDEX IF NOT EXISTS "idx_suggestion_feedback_user" ON "suggestion_feedbacks" ("user_id","source");
+CREATE INDEX IF NOT EXISTS "idx_suggestion_feedback_item" ON "suggestion_feedbacks" ("source","suggestion_id","suggestion_key");
+CREATE INDEX IF NOT EXISTS "idx_suggestion_feedbacks_created_at" ON "suggestion_feedbacks" ("created_at");
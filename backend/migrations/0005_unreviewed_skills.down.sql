@@ -0,0 +1 @@
+ALTER TABLE "skills" DROP COLUMN IF EXISTS "unreviewed";
@@ -0,0 +1,4 @@
+-- Skills created from unmatched names in user profiles are kept out of the
+-- public catalog until reviewed. Existing rows count as reviewed.
+ALTER TABLE "skills" ADD COLUMN IF NOT EXISTS "unreviewed" boolean NOT NULL DEFAULT false;
+CREATE INDEX IF NOT EXISTS "idx_skills_unreviewed" ON "skills" ("unreviewed");
@@ -0,0 +1,4 @@
+ALTER TABLE "progress_suggestions" DROP COLUMN IF EXISTS "fixture_applied";
+ALTER TABLE "goal_suggestions" DROP COLUMN IF EXISTS "fixture_applied";
+ALTER TABLE "responsibilities" DROP COLUMN IF EXISTS "fixture_applied";
+ALTER TABLE "job_roles" DROP COLUMN IF EXISTS "fixture_applied";
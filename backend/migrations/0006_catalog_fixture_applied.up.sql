@@ -0,0 +1,7 @@
+-- Fixture values last applied to each catalog row, so reseeding only writes
+-- fields the fixture changed. Existing rows start empty and record a baseline
+-- on the next load without changing their values.
+ALTER TABLE "job_roles" ADD COLUMN IF NOT EXISTS "fixture_applied" jsonb;
+ALTER TABLE "responsibilities" ADD COLUMN IF NOT EXISTS "fixture_applied" jsonb;
+ALTER TABLE "goal_suggestions" ADD COLUMN IF NOT EXISTS "fixture_applied" jsonb;
+ALTER TABLE "progress_suggestions" ADD COLUMN IF NOT EXISTS "fixture_applied" jsonb;
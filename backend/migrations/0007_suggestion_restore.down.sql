@@ -0,0 +1,4 @@
+DELETE FROM "suggestion_feedbacks" WHERE event = 'restore';
+ALTER TABLE "suggestion_feedbacks" DROP CONSTRAINT IF EXISTS "chk_suggestion_feedbacks_event";
+ALTER TABLE "suggestion_feedbacks" ADD CONSTRAINT "chk_suggestion_feedbacks_event"
+    CHECK (event IN ('impression','dismiss','save','adopt','thumbs_up','thumbs_down'));
@@ -0,0 +1,4 @@
+-- Restoring a dismissed or adopted suggestion brings it back into results.
+ALTER TABLE "suggestion_feedbacks" DROP CONSTRAINT IF EXISTS "chk_suggestion_feedbacks_event";
+ALTER TABLE "suggestion_feedbacks" ADD CONSTRAINT "chk_suggestion_feedbacks_event"
+    CHECK (event IN ('impression','dismiss','save','adopt','thumbs_up','thumbs_down','restore'));
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned schema migrations. Each change is a
+// pair of files, NNNN_name.up.sql and NNNN_name.down.sql, applied in version
+// order by database.MigrateUp. Never edit a file once it has shipped: applied
+// migrations are checksummed and the server refuses to start on a mismatch.
+// Add a new version instead ("migrate create name" scaffolds one).
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS
@@ -0,0 +1,64 @@
+package models
+
+// ITProfileSchemaVersion is the current shape of UserProfile.ITProfile.
+// Bump it (and teach services.ParseITProfile to upgrade) on breaking changes.
+const ITProfileSchemaVersion = 1
+
+// ITProfile is the canonical structure stored in UserProfile.ITProfile.
+// Validation and the served JSON Schema live in services/it_profile.go.
+type ITProfile struct {
+    SchemaVersion  int               `json:"schema_version"`
+    Role           ITRole            `json:"role"`
+    Subdomains     []string          `json:"subdomains,omitempty"`
+    Frameworks     []ITFramework     `json:"frameworks,omitempty"`
+    Certifications []ITCertification `json:"certifications,omitempty"`
+    Platforms      []ITPlatform      `json:"platforms,omitempty"`
+    Environment    *ITEnvironment    `json:"environment,omitempty"`
+    KPIs           []string          `json:"kpis,omitempty"`
+    Jurisdictions  []string          `json:"jurisdictions,omitempty"`
+    Upskilling     *ITUpskilling     `json:"upskilling,omitempty"`
+    Evidence       []ITEvidence      `json:"evidence,omitempty"`
+}
+
+type ITRole struct {
+    Current string `json:"current,omitempty"`
+    Level   string `json:"level,omitempty"` // experience level vocabulary
+    Track   string `json:"track,omitempty"`
+    Target  string `json:"target,omitempty"`
+}
+
+type ITFramework struct {
+    Name  string `json:"name"`
+    Level string `json:"level,omitempty"` // Awareness, Working, Practitioner, Expert
+}
+
+type ITCertification struct {
+    Name      string `json:"name"`
+    Status    string `json:"status,omitempty"`     // planned, in_progress, earned, expired
+    Expires   string `json:"expires,omitempty"`    // YYYY-MM or YYYY-MM-DD
+    PlannedBy string `json:"planned_by,omitempty"` // YYYY-MM or YYYY-MM-DD
+}
+
+type ITPlatform struct {
+    Name     string `json:"name"`
+    Depth    string `json:"depth,omitempty"`     // Basic, Intermediate, Advanced, Expert
+    LastUsed string `json:"last_used,omitempty"` // YYYY-MM or YYYY-MM-DD
+}
+
+type ITEnvironment struct {
+    Clusters         int      `json:"clusters,omitempty"`
+    Regions          []string `json:"regions,omitempty"`
+    UsersSupported   int      `json:"users_supported,omitempty"`
+    UptimeSLOPercent float64  `json:"uptime_slo_percent,omitempty"`
+    P95LatencyMs     int      `json:"p95_latency_ms,omitempty"`
+}
+
+type ITUpskilling struct {
+    HoursPerWeek int      `json:"hours_per_week,omitempty"`
+    Format       []string `json:"format,omitempty"`
+}
+
+type ITEvidence struct {
+    Title string `json:"title"`
+    Link  string `json:"link,omitempty"`
+}
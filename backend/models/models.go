@@ -5,11 +5,27 @@ import (
 	"gorm.io/gorm"
 )
 
+// CatalogState is the draft/publish workflow shared by admin-managed catalog
+// rows. Only published rows are served on the public catalog routes; rows
+// that predate the workflow default to published.
+type CatalogState struct {
+    Status      string     `json:"status" gorm:"not null;default:'published';index;check:status IN ('draft','published')"`
+    PublishedAt *time.Time `json:"published_at"`
+    // FixtureApplied holds the fixture values last applied to the row
+    // (field -> value), so reseeding only writes what the fixture changed
+    // and leaves admin edits alone.
+    FixtureApplied string `json:"-" gorm:"type:jsonb"`
+}
+
 type JobRole struct {
 	ID             uint            `json:"id" gorm:"primaryKey"`
+	Slug           string          `json:"slug,omitempty" gorm:"uniqueIndex:idx_job_roles_slug,where:slug <> ''"`
 	Title          string          `json:"title" gorm:"not null;unique"`
 	Description    string          `json:"description"`
+	Source         string          `json:"source,omitempty" gorm:"index:idx_job_roles_source"`    // framework the row was imported from: sfia, nice or onet
+	SourceID       string          `json:"source_id,omitempty" gorm:"index:idx_job_roles_source"` // its identifier there, e.g. PD-WRL-001
 	Responsibilities []Responsibility `json:"responsibilities,omitempty" gorm:"foreignKey:JobRoleID"`
+	CatalogState
 	CreatedAt      time.Time       `json:"created_at"`
 	UpdatedAt      time.Time       `json:"updated_at"`
 }
@@ -18,9 +34,13 @@ type Responsibility struct {
 	ID          uint     `json:"id" gorm:"primaryKey"`
 	JobRoleID   uint     `json:"job_role_id" gorm:"not null;index"`
 	JobRole     JobRole  `json:"job_role,omitempty" gorm:"foreignKey:JobRoleID"`
+	Slug        string   `json:"slug,omitempty" gorm:"uniqueIndex:idx_responsibilities_slug,where:slug <> ''"`
 	Title       string   `json:"title" gorm:"not null"`
 	Description string   `json:"description"`
 	Category    string   `json:"category" gorm:"default:'general'"`
+	Source      string   `json:"source,omitempty" gorm:"index:idx_responsibilities_source"`
+	SourceID    string   `json:"source_id,omitempty" gorm:"index:idx_responsibilities_source"`
+	CatalogState
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -38,6 +58,10 @@ type Goal struct {
     Tags        string    `json:"tags"` // JSON array of strings
     Metadata    string    `json:"metadata" gorm:"type:jsonb"` // Structured OKR/SMART, initiatives, milestones
 	Progress    []Progress `json:"progress,omitempty" gorm:"foreignKey:GoalID"`
+    CompletedAt *time.Time `json:"completed_at" gorm:"index"` // Set when status transitions to completed
+    Version     int       `json:"version" gorm:"not null;default:1"` // Optimistic concurrency; bumped on every write
+    CycleID     *uint     `json:"cycle_id" gorm:"index"` // Planning cycle (quarter) the goal belongs to
+    RolledFromGoalID *uint `json:"rolled_from_goal_id" gorm:"index"` // Set when carried over from a closed cycle
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -67,6 +91,7 @@ type Progress struct {
 	Outcome     string    `json:"outcome"`
 	ActionTaken string    `json:"action_taken"`
 	NextSteps   string    `json:"next_steps"`
+	Version     int       `json:"version" gorm:"not null;default:1"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
@@ -75,11 +100,13 @@ type ProgressSuggestion struct {
 	ID               uint           `json:"id" gorm:"primaryKey"`
 	GoalSuggestionID uint           `json:"goal_suggestion_id" gorm:"not null;index"`
 	GoalSuggestion   GoalSuggestion `json:"goal_suggestion,omitempty" gorm:"foreignKey:GoalSuggestionID"`
+	Slug             string         `json:"slug,omitempty" gorm:"uniqueIndex:idx_progress_suggestions_slug,where:slug <> ''"`
 	ProgressStage    string         `json:"progress_stage" gorm:"not null"`
 	SuggestedOutcome string         `json:"suggested_outcome" gorm:"not null"`
 	ActionPrompt     string         `json:"action_prompt"`
 	NextStepPrompt   string         `json:"next_step_prompt"`
 	PercentageRange  string         `json:"percentage_range"`
+	CatalogState
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
@@ -101,6 +128,8 @@ type UserProfile struct {
 	TermsAcceptedAt   *time.Time `json:"terms_accepted_at"`
 	PrivacyAcceptedAt *time.Time `json:"privacy_accepted_at"`
 	PoliciesVersion   string     `json:"policies_version" gorm:"default:'1.0'"`
+	Locale            string     `json:"locale"` // preferred API/catalog locale; "" negotiates from Accept-Language
+	Version           int        `json:"version" gorm:"not null;default:1"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
@@ -139,14 +168,370 @@ type GoalSuggestion struct {
 	ID               uint           `json:"id" gorm:"primaryKey"`
 	ResponsibilityID uint           `json:"responsibility_id" gorm:"not null;index"`
 	Responsibility   Responsibility `json:"responsibility,omitempty" gorm:"foreignKey:ResponsibilityID"`
+	Slug             string         `json:"slug,omitempty" gorm:"uniqueIndex:idx_goal_suggestions_slug,where:slug <> ''"`
 	Title            string         `json:"title" gorm:"not null"`
 	Description      string         `json:"description"`
 	Category         string         `json:"category" gorm:"default:'skill'"`
 	Priority         string         `json:"priority" gorm:"default:'medium';check:priority IN ('low','medium','high')"`
 	EstimatedDuration string        `json:"estimated_duration"`
+	CatalogState
 	CreatedAt        time.Time      `json:"created_at"`
 	UpdatedAt        time.Time      `json:"updated_at"`
 }
 
 // Ingestion models removed for now
 
+
+// GoalRevision is an append-only record of every write to a goal. Snapshot
+// holds the full tracked state after the write; Changes the field-level diff
+// against the previous revision.
+type GoalRevision struct {
+    ID           uint      `json:"id" gorm:"primaryKey"`
+    UserID       string    `json:"-" gorm:"type:uuid;not null;index"`
+    GoalID       uint      `json:"goal_id" gorm:"not null;uniqueIndex:idx_goal_revisions_goal_rev"`
+    Revision     int       `json:"revision" gorm:"not null;uniqueIndex:idx_goal_revisions_goal_rev"`
+    Action       string    `json:"action" gorm:"not null;check:action IN ('baseline','create','update','restore','delete')"`
+    ActorID      string    `json:"actor_id" gorm:"type:uuid;not null"`
+    RestoredFrom *int      `json:"restored_from,omitempty"`
+    Snapshot     string    `json:"-" gorm:"type:jsonb;not null"`
+    Changes      string    `json:"-" gorm:"type:jsonb"`
+    CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// Cycle is a planning period (typically a quarter). User cycles belong to one
+// user; org cycles have no owner and are shared by everyone.
+type Cycle struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    *string   `json:"-" gorm:"type:uuid;index"`
+    Scope     string    `json:"scope" gorm:"not null;default:'user';check:scope IN ('user','org')"`
+    Name      string    `json:"name" gorm:"not null"`
+    StartDate time.Time `json:"start_date" gorm:"type:date;not null"`
+    EndDate   time.Time `json:"end_date" gorm:"type:date;not null"` // inclusive
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CycleReview is a user's end-of-cycle close-out: reflection, overall grade
+// and where unfinished goals were rolled to.
+type CycleReview struct {
+    ID           uint      `json:"id" gorm:"primaryKey"`
+    UserID       string    `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_cycle_reviews_user_cycle"`
+    CycleID      uint      `json:"cycle_id" gorm:"not null;uniqueIndex:idx_cycle_reviews_user_cycle"`
+    Reflection   string    `json:"reflection"`
+    AverageGrade float64   `json:"average_grade"`
+    NextCycleID  *uint     `json:"next_cycle_id"`
+    ClosedAt     time.Time `json:"closed_at"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// KRGrade is the 0.0-1.0 end-of-cycle score for one key result. An empty
+// KRID grades the objective itself (goals without key results).
+type KRGrade struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    string    `json:"-" gorm:"type:uuid;not null;index"`
+    CycleID   uint      `json:"cycle_id" gorm:"not null;uniqueIndex:idx_kr_grades_cycle_goal_kr"`
+    GoalID    uint      `json:"goal_id" gorm:"not null;uniqueIndex:idx_kr_grades_cycle_goal_kr"`
+    KRID      string    `json:"kr_id" gorm:"not null;default:'';uniqueIndex:idx_kr_grades_cycle_goal_kr"`
+    Grade     float64   `json:"grade" gorm:"not null;check:grade >= 0 AND grade <= 1"`
+    Note      string    `json:"note"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Skill is a catalog entry in the normalized skills taxonomy. Aliases (JSON
+// array) let free-text profile entries like "k8s" resolve to "kubernetes".
+type Skill struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    Slug      string    `json:"slug" gorm:"uniqueIndex;not null"`
+    Name      string    `json:"name" gorm:"not null"`
+    Category  string    `json:"category" gorm:"not null;default:'other';index"`
+    Kind      string    `json:"kind" gorm:"not null;default:'platform'"` // platform (tools, languages, clouds) or framework (methods, standards)
+    Aliases   string    `json:"aliases" gorm:"type:jsonb"`
+    Source    string    `json:"source,omitempty" gorm:"index:idx_skills_source"` // framework import, see JobRole.Source
+    SourceID  string    `json:"source_id,omitempty" gorm:"index:idx_skills_source"`
+    // Unreviewed skills were created from a name in a user's profile that
+    // matched nothing. They stay out of the public catalog and text matching
+    // until an admin saves them.
+    Unreviewed bool     `json:"unreviewed,omitempty" gorm:"not null;default:false;index"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserSkill is a user's current proficiency in one catalog skill.
+// Proficiency runs 0 (none/gap) to 4 (expert); see services.ProficiencyLevels.
+type UserSkill struct {
+    ID          uint       `json:"id" gorm:"primaryKey"`
+    UserID      string     `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_user_skills_user_skill"`
+    SkillID     uint       `json:"skill_id" gorm:"not null;uniqueIndex:idx_user_skills_user_skill"`
+    Skill       Skill      `json:"skill" gorm:"foreignKey:SkillID"`
+    Proficiency int        `json:"proficiency" gorm:"not null;default:0;check:proficiency >= 0 AND proficiency <= 4"`
+    Source      string     `json:"source"` // profile, goal, manual
+    LastUsedAt  *time.Time `json:"last_used_at"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// SkillProficiencyChange is the append-only history of UserSkill.Proficiency.
+type SkillProficiencyChange struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    string    `json:"-" gorm:"type:uuid;not null;index"`
+    SkillID   uint      `json:"skill_id" gorm:"not null;index"`
+    FromLevel int       `json:"from_level"`
+    ToLevel   int       `json:"to_level"`
+    Reason    string    `json:"reason" gorm:"not null;check:reason IN ('profile_update','goal_completed','manual')"`
+    GoalID    *uint     `json:"goal_id"`
+    CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// Certification is a catalog entry for an industry certification and its
+// renewal rules. ValidityMonths 0 means it does not expire.
+type Certification struct {
+    ID              uint      `json:"id" gorm:"primaryKey"`
+    Slug            string    `json:"slug" gorm:"uniqueIndex;not null"`
+    Name            string    `json:"name" gorm:"not null"`
+    Issuer          string    `json:"issuer" gorm:"not null"`
+    ValidityMonths  int       `json:"validity_months" gorm:"not null;default:0"`
+    CreditsRequired float64   `json:"credits_required" gorm:"not null;default:0"` // per renewal cycle
+    AnnualMinimum   float64   `json:"annual_minimum" gorm:"not null;default:0"`
+    CreditUnit      string    `json:"credit_unit"` // CPE, CEU, PDU
+    Aliases         string    `json:"aliases" gorm:"type:jsonb"`
+    CreatedAt       time.Time `json:"created_at"`
+    UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// UserCertification is a certification held by a user. CycleStartedAt marks
+// the start of the current renewal cycle; credits earned since then count.
+type UserCertification struct {
+    ID              uint          `json:"id" gorm:"primaryKey"`
+    UserID          string        `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_user_certs_user_cert"`
+    CertificationID uint          `json:"certification_id" gorm:"not null;uniqueIndex:idx_user_certs_user_cert"`
+    Certification   Certification `json:"certification" gorm:"foreignKey:CertificationID"`
+    CredentialID    string        `json:"credential_id"`
+    EarnedAt        time.Time     `json:"earned_at" gorm:"type:date;not null"`
+    CycleStartedAt  time.Time     `json:"cycle_started_at" gorm:"type:date;not null"`
+    ExpiresAt       *time.Time    `json:"expires_at" gorm:"type:date;index"`
+    RenewalGoalID   *uint         `json:"renewal_goal_id"`
+    CreatedAt       time.Time     `json:"created_at"`
+    UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// CECredit is a continuing-education credit (CPE/CEU/PDU) logged against a
+// held certification.
+type CECredit struct {
+    ID                  uint      `json:"id" gorm:"primaryKey"`
+    UserID              string    `json:"-" gorm:"type:uuid;not null;index"`
+    UserCertificationID uint      `json:"user_certification_id" gorm:"not null;index"`
+    Title               string    `json:"title" gorm:"not null"`
+    Provider            string    `json:"provider"`
+    Credits             float64   `json:"credits" gorm:"not null;check:credits > 0"`
+    EarnedAt            time.Time `json:"earned_at" gorm:"type:date;not null"`
+    EvidenceURL         string    `json:"evidence_url"`
+    CreatedAt           time.Time `json:"created_at"`
+}
+
+// CertificationReminder is generated ahead of expiry at fixed lead times
+// (Kind: 90d, 60d, 30d, 7d, expired).
+type CertificationReminder struct {
+    ID                  uint       `json:"id" gorm:"primaryKey"`
+    UserID              string     `json:"-" gorm:"type:uuid;not null;index"`
+    UserCertificationID uint       `json:"user_certification_id" gorm:"not null;uniqueIndex:idx_cert_reminders_cert_kind"`
+    Kind                string     `json:"kind" gorm:"not null;uniqueIndex:idx_cert_reminders_cert_kind"`
+    ExpiresAt           time.Time  `json:"expires_at" gorm:"type:date;not null;uniqueIndex:idx_cert_reminders_cert_kind"`
+    Message             string     `json:"message"`
+    DismissedAt         *time.Time `json:"dismissed_at"`
+    CreatedAt           time.Time  `json:"created_at" gorm:"index"`
+}
+
+// ProfileSnapshot records the user profile after each change to its tracked
+// fields, so career growth can be replayed and compared over time.
+type ProfileSnapshot struct {
+    ID             uint      `json:"id" gorm:"primaryKey"`
+    UserID         string    `json:"-" gorm:"type:uuid;not null;index"`
+    ProfileID      uint      `json:"profile_id" gorm:"not null;index"`
+    ProfileVersion int       `json:"profile_version"`
+    Kind           string    `json:"kind" gorm:"not null;check:kind IN ('baseline','create','update')"`
+    Snapshot       string    `json:"-" gorm:"type:jsonb;not null"`
+    Changes        string    `json:"-" gorm:"type:jsonb"`
+    CreatedAt      time.Time `json:"created_at" gorm:"index"`
+}
+
+// JobRoleRequirement is a skill level or certification a job role expects.
+// Exactly one of SkillID and CertificationID is set. Importance runs from
+// 1 (nice to have) to 3 (critical).
+type JobRoleRequirement struct {
+    ID              uint           `json:"id" gorm:"primaryKey"`
+    JobRoleID       uint           `json:"job_role_id" gorm:"not null;uniqueIndex:idx_role_req_skill;uniqueIndex:idx_role_req_cert"`
+    SkillID         *uint          `json:"skill_id,omitempty" gorm:"uniqueIndex:idx_role_req_skill,where:skill_id IS NOT NULL"`
+    Skill           *Skill         `json:"skill,omitempty" gorm:"foreignKey:SkillID"`
+    CertificationID *uint          `json:"certification_id,omitempty" gorm:"uniqueIndex:idx_role_req_cert,where:certification_id IS NOT NULL"`
+    Certification   *Certification `json:"certification,omitempty" gorm:"foreignKey:CertificationID"`
+    Level           int            `json:"level" gorm:"not null;default:0;check:level BETWEEN 0 AND 4"` // minimum skill proficiency
+    Importance      int            `json:"importance" gorm:"not null;default:2;check:importance BETWEEN 1 AND 3"`
+    CreatedAt       time.Time      `json:"created_at"`
+    UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// CareerTrack is a career ladder: ordered levels, the competencies the
+// track assesses, and what each level expects of each competency.
+type CareerTrack struct {
+    ID           uint           `json:"id" gorm:"primaryKey"`
+    Slug         string         `json:"slug" gorm:"uniqueIndex;not null"`
+    Name         string         `json:"name" gorm:"not null"`
+    Description  string         `json:"description"`
+    JobRoleID    *uint          `json:"job_role_id,omitempty" gorm:"index"`
+    Competencies []Competency   `json:"competencies,omitempty" gorm:"foreignKey:TrackID;constraint:OnDelete:CASCADE"`
+    Levels       []CareerLevel  `json:"levels,omitempty" gorm:"foreignKey:TrackID;constraint:OnDelete:CASCADE"`
+    CreatedAt    time.Time      `json:"created_at"`
+    UpdatedAt    time.Time      `json:"updated_at"`
+}
+
+// CareerLevel is one rung of a track; Rank orders levels from 1 upwards.
+// ExperienceLevel optionally ties the level to UserProfile.ExperienceLevel.
+type CareerLevel struct {
+    ID              uint                    `json:"id" gorm:"primaryKey"`
+    TrackID         uint                    `json:"track_id" gorm:"not null;uniqueIndex:idx_career_level_slug;uniqueIndex:idx_career_level_rank"`
+    Slug            string                  `json:"slug" gorm:"not null;uniqueIndex:idx_career_level_slug"`
+    Name            string                  `json:"name" gorm:"not null"`
+    Rank            int                     `json:"rank" gorm:"not null;uniqueIndex:idx_career_level_rank"`
+    ExperienceLevel string                  `json:"experience_level,omitempty"`
+    Description     string                  `json:"description"`
+    Expectations    []CompetencyExpectation `json:"expectations,omitempty" gorm:"foreignKey:LevelID;constraint:OnDelete:CASCADE"`
+}
+
+// Competency is an assessed dimension of a track. Keywords and Skills
+// (JSON arrays of phrases and skill slugs) map goals and evidence to it.
+type Competency struct {
+    ID          uint   `json:"id" gorm:"primaryKey"`
+    TrackID     uint   `json:"track_id" gorm:"not null;uniqueIndex:idx_competency_slug"`
+    Slug        string `json:"slug" gorm:"not null;uniqueIndex:idx_competency_slug"`
+    Name        string `json:"name" gorm:"not null"`
+    Description string `json:"description"`
+    Keywords    string `json:"keywords" gorm:"type:jsonb"`
+    Skills      string `json:"skills" gorm:"type:jsonb"`
+}
+
+// CompetencyExpectation is the level (1-4, same scale as skill proficiency)
+// a career level expects for a competency.
+type CompetencyExpectation struct {
+    ID           uint        `json:"id" gorm:"primaryKey"`
+    LevelID      uint        `json:"level_id" gorm:"not null;uniqueIndex:idx_competency_expectation"`
+    CompetencyID uint        `json:"competency_id" gorm:"not null;uniqueIndex:idx_competency_expectation"`
+    Competency   *Competency `json:"competency,omitempty" gorm:"foreignKey:CompetencyID"`
+    Level        int         `json:"level" gorm:"not null;check:level BETWEEN 1 AND 4"`
+    Description  string      `json:"description"`
+}
+
+// BlockedPeriod is a span of days (inclusive) with no capacity for goal
+// work, such as leave or an on-call week; the planner skips it.
+type BlockedPeriod struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    string    `json:"-" gorm:"type:uuid;not null;index"`
+    StartDate time.Time `json:"start_date" gorm:"type:date;not null"`
+    EndDate   time.Time `json:"end_date" gorm:"type:date;not null;check:end_date >= start_date"`
+    Reason    string    `json:"reason"`
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// ErasureRequest schedules deletion of everything a user owns after a grace
+// period. Once executed UserID is cleared and only SubjectHash (SHA-256 of
+// the user id) remains, so the erasure log holds no personal data but can
+// still confirm that a given user was erased.
+type ErasureRequest struct {
+    ID           uint       `json:"id" gorm:"primaryKey"`
+    UserID       *string    `json:"user_id,omitempty" gorm:"type:uuid;uniqueIndex:idx_erasure_pending,where:status = 'pending'"`
+    SubjectHash  string     `json:"subject_hash" gorm:"not null;index"`
+    Status       string     `json:"status" gorm:"not null;default:'pending';check:status IN ('pending','cancelled','completed')"`
+    Reason       string     `json:"reason,omitempty"`
+    RequestedAt  time.Time  `json:"requested_at"`
+    ScheduledFor time.Time  `json:"scheduled_for" gorm:"index"`
+    CancelledAt  *time.Time `json:"cancelled_at"`
+    CompletedAt  *time.Time `json:"completed_at"`
+    RowsDeleted  string     `json:"rows_deleted" gorm:"type:jsonb"` // table -> count
+    LastError    string     `json:"last_error,omitempty"`
+}
+
+// PolicyDocument is a published version of a legal policy (Kind terms or
+// privacy). Versions are immutable; publish a new one to change the text.
+// The enforced version of a kind is the latest required one in effect.
+type PolicyDocument struct {
+    ID          uint      `json:"id" gorm:"primaryKey"`
+    Kind        string    `json:"kind" gorm:"not null;uniqueIndex:idx_policy_kind_version;check:kind IN ('terms','privacy')"`
+    Version     string    `json:"version" gorm:"not null;uniqueIndex:idx_policy_kind_version"`
+    Title       string    `json:"title" gorm:"not null"`
+    Body        string    `json:"body,omitempty" gorm:"type:text"`
+    URL         string    `json:"url,omitempty"`
+    Required    bool      `json:"required" gorm:"not null;default:true"`
+    EffectiveAt time.Time `json:"effective_at" gorm:"not null;index"`
+    PublishedBy string    `json:"-" gorm:"type:uuid"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+// PolicyAcceptance is the append-only consent ledger: one row per acceptance
+// of a policy version. PolicyID is nil for acceptances recorded before
+// policy documents existed (Source legacy).
+type PolicyAcceptance struct {
+    ID         uint      `json:"id" gorm:"primaryKey"`
+    UserID     string    `json:"-" gorm:"type:uuid;not null;index:idx_policy_acceptance_user_kind"`
+    PolicyID   *uint     `json:"policy_id" gorm:"index"`
+    Kind       string    `json:"kind" gorm:"not null;index:idx_policy_acceptance_user_kind"`
+    Version    string    `json:"version" gorm:"not null"`
+    AcceptedAt time.Time `json:"accepted_at" gorm:"not null"`
+    IPAddress  string    `json:"ip_address"`
+    UserAgent  string    `json:"user_agent"`
+    Source     string    `json:"source" gorm:"not null;default:'api'"` // api, profile or legacy
+}
+
+// CatalogAuditEntry is an append-only record of one admin change to a
+// catalog row. Snapshot holds the row after the change (before it, for
+// deletes); Changes the field-level diff.
+type CatalogAuditEntry struct {
+    ID         uint      `json:"id" gorm:"primaryKey"`
+    EntityType string    `json:"entity_type" gorm:"not null;index:idx_catalog_audit_entity"`
+    EntityID   uint      `json:"entity_id" gorm:"not null;index:idx_catalog_audit_entity"`
+    Action     string    `json:"action" gorm:"not null;check:action IN ('create','update','publish','unpublish','delete')"`
+    ActorID    *string   `json:"actor_id" gorm:"type:uuid"` // nil for fixture loads
+    Snapshot   string    `json:"-" gorm:"type:jsonb;not null"`
+    Changes    string    `json:"-" gorm:"type:jsonb"`
+    CreatedAt  time.Time `json:"created_at" gorm:"index"`
+}
+
+// CatalogTranslation is one field of a catalog row in another locale. The
+// row itself holds the English text, which untranslated fields fall back to.
+type CatalogTranslation struct {
+    ID         uint      `json:"-" gorm:"primaryKey"`
+    EntityType string    `json:"-" gorm:"not null;uniqueIndex:idx_catalog_translation"` // catalog type name, e.g. job-roles
+    EntityID   uint      `json:"-" gorm:"not null;uniqueIndex:idx_catalog_translation"`
+    Locale     string    `json:"locale" gorm:"not null;uniqueIndex:idx_catalog_translation"`
+    Field      string    `json:"field" gorm:"not null;uniqueIndex:idx_catalog_translation"`
+    Value      string    `json:"value" gorm:"not null"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// AdminGrant gives a user admin access in addition to the ADMIN_USER_IDS
+// allowlist. Grants are managed with the "admin grant/revoke" commands.
+type AdminGrant struct {
+    ID        uint      `json:"id" gorm:"primaryKey"`
+    UserID    string    `json:"user_id" gorm:"type:uuid;not null;uniqueIndex"`
+    Note      string    `json:"note"`
+    GrantedBy string    `json:"granted_by"` // operator name recorded by the CLI
+    CreatedAt time.Time `json:"created_at"`
+}
+
+// SuggestionFeedback is one thing a user did with a goal suggestion: saw
+// it, dismissed, saved or adopted it, or rated it. Catalog suggestions are
+// referenced by SuggestionID; AI suggestions are never stored, so they are
+// referenced by a key derived from their title and keep the title here.
+type SuggestionFeedback struct {
+    ID            uint      `json:"id" gorm:"primaryKey"`
+    UserID        string    `json:"-" gorm:"type:uuid;not null;index:idx_suggestion_feedback_user"`
+    Source        string    `json:"source" gorm:"not null;index:idx_suggestion_feedback_user;index:idx_suggestion_feedback_item;check:source IN ('catalog','ai')"`
+    SuggestionID  *uint     `json:"suggestion_id,omitempty" gorm:"index:idx_suggestion_feedback_item"`
+    SuggestionKey string    `json:"suggestion_key,omitempty" gorm:"index:idx_suggestion_feedback_item"`
+    Title         string    `json:"title,omitempty"` // AI suggestions only
+    Event         string    `json:"event" gorm:"not null;check:event IN ('impression','dismiss','save','adopt','thumbs_up','thumbs_down','restore')"`
+    Reason        string    `json:"reason,omitempty"` // dismiss only, see services.DismissReasons
+    Note          string    `json:"note,omitempty"`
+    GoalID        *uint     `json:"goal_id,omitempty"` // adopt only: the goal created from the suggestion
+    CreatedAt     time.Time `json:"created_at" gorm:"index"`
+}
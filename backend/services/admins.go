@@ -0,0 +1,35 @@
+package services
+
+import (
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// IsGrantedAdmin reports whether userID holds an admin grant.
+func IsGrantedAdmin(db *gorm.DB, userID string) (bool, error) {
+    var n int64
+    err := db.Model(&models.AdminGrant{}).Where("user_id = ?", userID).Count(&n).Error
+    return n > 0, err
+}
+
+// GrantAdmin records an admin grant; granting twice keeps the first grant.
+// created is false when the user already had one.
+func GrantAdmin(db *gorm.DB, userID, note, grantedBy string) (models.AdminGrant, bool, error) {
+    grant := models.AdminGrant{UserID: userID, Note: note, GrantedBy: grantedBy}
+    res := db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}}, DoNothing: true}).Create(&grant)
+    if res.Error != nil { return grant, false, res.Error }
+    if res.RowsAffected == 0 {
+        err := db.Where("user_id = ?", userID).First(&grant).Error
+        return grant, false, err
+    }
+    return grant, true, nil
+}
+
+// RevokeAdmin removes userID's grant, reporting whether there was one. Users
+// in the ADMIN_USER_IDS allowlist stay admins.
+func RevokeAdmin(db *gorm.DB, userID string) (bool, error) {
+    res := db.Where("user_id = ?", userID).Delete(&models.AdminGrant{})
+    return res.RowsAffected > 0, res.Error
+}
@@ -6,6 +6,7 @@ import (
     "fmt"
     "goaltracker/models"
     "io"
+    "math"
     "net/http"
     "os"
     "strings"
@@ -28,6 +29,7 @@ type GoalSuggestionRequest struct {
 }
 
 type AIGoalResponse struct {
+	Key                     string    `json:"key,omitempty"` // AISuggestionKey, set when served
 	Title                   string    `json:"title"`
 	PersonalizedDescription string    `json:"personalized_description"`
 	LearningPath            []string  `json:"learning_path"`
@@ -146,48 +148,62 @@ func (ai *AIService) RefineSMART(req RefineSMARTRequest) (OKRSmart, error) {
 
 // ---- Milestones generation ----
 type Milestone struct {
-    Label   string `json:"label"`
-    DueDate string `json:"due_date"`
+    Label       string  `json:"label"`
+    DueDate     string  `json:"due_date"`
+    EffortHours float64 `json:"effort_hours"` // estimate the planner schedules by
 }
 
 type GenerateMilestonesRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
-    DueDate     string `json:"due_date"`
-    Count       int    `json:"count"`
+    Title       string  `json:"title"`
+    Description string  `json:"description"`
+    DueDate     string  `json:"due_date"`
+    Count       int     `json:"count"`
+    EffortHours float64 `json:"effort_hours"` // total for the goal; estimated when 0
 }
 
+// milestonePhaseShares split a goal's effort across the heuristic phases.
+var milestonePhaseShares = []float64{0.15, 0.2, 0.4, 0.15, 0.1}
+
+// defaultGoalEffortHours is the effort assumed for a goal with no estimate;
+// goals touching infrastructure or migrations get half as much again.
+const defaultGoalEffortHours = 20.0
+
 func (ai *AIService) GenerateMilestones(req GenerateMilestonesRequest) ([]Milestone, error) {
     // Heuristic phases
     phases := []string{"Define scope", "Draft solution", "Implement", "Review & QA", "Deliver"}
     if req.Count > 0 && req.Count < len(phases) {
         phases = phases[:req.Count]
     }
-    // Date spacing
-    // If due date provided (YYYY-MM-DD), spread evenly; else use +7d increments from today
-    var result []Milestone
-    if req.DueDate != "" && len(phases) > 0 {
-        // parse YYYY-MM-DD (best-effort)
-        end := req.DueDate
-        // simple splitting; no timezone math to keep dependencies minimal
-        // create offsets by fraction of phases
-        for i, p := range phases {
-            // naive: keep same due date for last, earlier ones step back by 7 days each
-            d := end
-            if i < len(phases)-1 {
-                // not precise arithmetic; client can edit
-                // label due date left blank to encourage edits
-                d = ""
-            }
-            result = append(result, Milestone{Label: p, DueDate: d})
+    // Spread due dates evenly from today to the goal's due date; without a
+    // due date leave them blank. The planner (BuildPlan) replaces these with
+    // capacity-aware dates once effort estimates are known.
+    t := strings.ToLower(req.Title + " " + req.Description)
+    total := req.EffortHours
+    if total <= 0 {
+        total = defaultGoalEffortHours
+        for _, k := range []string{"kubernetes", "migrat", "platform", "certif", "reliability"} {
+            if strings.Contains(t, k) { total *= 1.5; break }
         }
-    } else {
-        for _, p := range phases {
-            result = append(result, Milestone{Label: p, DueDate: ""})
+    }
+    shares := 0.0
+    for i := range phases { shares += milestonePhaseShares[i] }
+
+    var result []Milestone
+    end, err := time.Parse("2006-01-02", req.DueDate)
+    start := truncateDay(time.Now())
+    for i, p := range phases {
+        d := ""
+        if err == nil && end.After(start) {
+            step := end.Sub(start) * time.Duration(i+1) / time.Duration(len(phases))
+            d = start.Add(step).Format("2006-01-02")
+        } else if err == nil {
+            d = req.DueDate
         }
+        // effort in half hours, at least one
+        effort := math.Max(0.5, math.Round(total*milestonePhaseShares[i]/shares*2)/2)
+        result = append(result, Milestone{Label: p, DueDate: d, EffortHours: effort})
     }
     // Light tailoring based on title keywords
-    t := strings.ToLower(req.Title + " " + req.Description)
     if strings.Contains(t, "latency") || strings.Contains(t, "reliability") || strings.Contains(t, "slo") {
         result[0].Label = "Define SLOs/SLIs"
         if len(result) > 2 { result[2].Label = "Add alerts & dashboards" }
@@ -320,31 +336,9 @@ func (ai *AIService) generateRoleBasedGoals(req GoalSuggestionRequest) []AIGoalR
 }
 
 // --- IT-profile rule engine ---
-type itProfilePayload struct {
-    Role struct {
-        Current string `json:"current"`
-        Level   string `json:"level"`
-        Track   string `json:"track"`
-        Target  string `json:"target"`
-    } `json:"role"`
-    Subdomains     []string `json:"subdomains"`
-    Frameworks     []struct { Name string `json:"name"`; Level string `json:"level"` } `json:"frameworks"`
-    Certifications []struct { Name string `json:"name"`; Status string `json:"status"`; Expires string `json:"expires"`; PlannedBy string `json:"planned_by"` } `json:"certifications"`
-    Platforms      []struct { Name string `json:"name"`; Depth string `json:"depth"`; LastUsed string `json:"last_used"` } `json:"platforms"`
-    Environment    struct { Clusters int `json:"clusters"`; Regions []string `json:"regions"`; UsersSupported int `json:"users_supported"`; UptimeSLOPercent float64 `json:"uptime_slo_percent"`; P95LatencyMs int `json:"p95_latency_ms"` } `json:"environment"`
-    KPIs           []string `json:"kpis"`
-    Jurisdictions  []string `json:"jurisdictions"`
-    Upskilling     struct { HoursPerWeek int `json:"hours_per_week"`; Format []string `json:"format"` } `json:"upskilling"`
-    Evidence       []struct { Title string `json:"title"`; Link string `json:"link"` } `json:"evidence"`
-}
-
 func (ai *AIService) generateITTriggeredGoals(req GoalSuggestionRequest) []AIGoalResponse {
     profile := req.UserProfile
-    var it itProfilePayload
-    // ITProfile stored as JSON string
-    if strings.TrimSpace(profile.ITProfile) != "" {
-        _ = json.Unmarshal([]byte(profile.ITProfile), &it)
-    }
+    it := DecodeITProfile(profile.ITProfile)
 
     goals := []AIGoalResponse{}
 
@@ -717,46 +711,9 @@ func (ai *AIService) generateWithOpenAI(req GoalSuggestionRequest) ([]AIGoalResp
         safe(p.Industry), safe(p.CurrentRole), safe(p.ExperienceLevel), safe(r.Title), safe(r.Category),
     )
 
-    // OpenAI chat completions JSON-mode style body
-    body := map[string]any{
-        "model":       ai.openAIModel,
-        "temperature": 0.2,
-        "max_tokens":  600,
-        "response_format": map[string]string{"type": "json_object"},
-        "messages": []map[string]string{
-            {"role": "system", "content": "You are a helpful assistant that returns STRICT JSON objects with a 'goals' array."},
-            {"role": "user", "content": prompt},
-        },
-    }
-
-    buf, _ := json.Marshal(body)
-    httpClient := &http.Client{Timeout: 10 * time.Second}
-    reqHTTP, _ := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(buf))
-    reqHTTP.Header.Set("Authorization", "Bearer "+ai.openAIKey)
-    reqHTTP.Header.Set("Content-Type", "application/json")
-    resp, err := httpClient.Do(reqHTTP)
+    content, err := ai.chatJSON("You are a helpful assistant that returns STRICT JSON objects with a 'goals' array.", prompt, 600)
     if err != nil {
-        return nil, sanitizeError(err)
-    }
-    defer resp.Body.Close()
-    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-        b, _ := io.ReadAll(resp.Body)
-        apiErr := fmt.Errorf("openai api error: %s", string(b))
-        return nil, sanitizeError(apiErr)
-    }
-
-    var parsed struct {
-        Choices []struct {
-            Message struct {
-                Content string `json:"content"`
-            } `json:"message"`
-        } `json:"choices"`
-    }
-    if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
-        return nil, sanitizeError(err)
-    }
-    if len(parsed.Choices) == 0 {
-        return nil, sanitizeError(fmt.Errorf("no choices"))
+        return nil, err
     }
 
     // The content should be a JSON object with goals array
@@ -769,7 +726,7 @@ func (ai *AIService) generateWithOpenAI(req GoalSuggestionRequest) ([]AIGoalResp
             Tags          []string `json:"tags"`
         } `json:"goals"`
     }
-    if err := json.Unmarshal([]byte(parsed.Choices[0].Message.Content), &j); err != nil {
+    if err := json.Unmarshal([]byte(content), &j); err != nil {
         return nil, sanitizeError(err)
     }
     if len(j.Goals) == 0 {
@@ -807,6 +764,55 @@ func (ai *AIService) generateWithOpenAI(req GoalSuggestionRequest) ([]AIGoalResp
     return out, nil
 }
 
+// chatJSON makes one JSON-mode chat completion call (low temperature, 10s
+// timeout) and returns the raw JSON content of the first choice.
+func (ai *AIService) chatJSON(system, prompt string, maxTokens int) (string, error) {
+    if ai.openAIKey == "" {
+        return "", fmt.Errorf("missing OPENAI_API_KEY")
+    }
+    body := map[string]any{
+        "model":       ai.openAIModel,
+        "temperature": 0.2,
+        "max_tokens":  maxTokens,
+        "response_format": map[string]string{"type": "json_object"},
+        "messages": []map[string]string{
+            {"role": "system", "content": system},
+            {"role": "user", "content": prompt},
+        },
+    }
+
+    buf, _ := json.Marshal(body)
+    httpClient := &http.Client{Timeout: 10 * time.Second}
+    reqHTTP, _ := http.NewRequest("POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(buf))
+    reqHTTP.Header.Set("Authorization", "Bearer "+ai.openAIKey)
+    reqHTTP.Header.Set("Content-Type", "application/json")
+    resp, err := httpClient.Do(reqHTTP)
+    if err != nil {
+        return "", sanitizeError(err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+        b, _ := io.ReadAll(resp.Body)
+        apiErr := fmt.Errorf("openai api error: %s", string(b))
+        return "", sanitizeError(apiErr)
+    }
+
+    var parsed struct {
+        Choices []struct {
+            Message struct {
+                Content string `json:"content"`
+            } `json:"message"`
+        } `json:"choices"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+        return "", sanitizeError(err)
+    }
+    if len(parsed.Choices) == 0 {
+        return "", sanitizeError(fmt.Errorf("no choices"))
+    }
+    return parsed.Choices[0].Message.Content, nil
+}
+
 func getEnvOrDefault(key, def string) string {
     if v := os.Getenv(key); v != "" {
         return v
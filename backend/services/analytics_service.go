@@ -0,0 +1,356 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "time"
+
+    "gorm.io/gorm"
+)
+
+// AnalyticsService computes per-user dashboard aggregates directly in SQL
+// over goals, progresses and kr_snapshots. Days, weeks and quarters are UTC,
+// whatever the session time zone, to line up with the Go-side streak dates.
+type AnalyticsService struct {
+    db *gorm.DB
+}
+
+func NewAnalyticsService(db *gorm.DB) *AnalyticsService {
+    return &AnalyticsService{db: db}
+}
+
+// AnalyticsRange bounds every aggregate; From is inclusive, To exclusive.
+type AnalyticsRange struct {
+    From time.Time `json:"from"`
+    To   time.Time `json:"to"`
+}
+
+type QuarterCompletion struct {
+    Quarter        string  `json:"quarter"`
+    Total          int     `json:"total"`
+    Completed      int     `json:"completed"`
+    CompletionRate float64 `json:"completion_rate"`
+}
+
+type BucketCount struct {
+    Key   string `json:"key"`
+    Count int    `json:"count"`
+}
+
+type JobRoleCount struct {
+    JobRoleID *uint  `json:"job_role_id"`
+    Title     string `json:"title"`
+    Count     int    `json:"count"`
+}
+
+type CheckInFrequency struct {
+    Total       int           `json:"total"`
+    PerWeek     float64       `json:"per_week"`
+    ActiveDays  int           `json:"active_days"`
+    WeeklyCount []BucketCount `json:"weekly"`
+}
+
+type Streaks struct {
+    CurrentDays  int `json:"current_days"`
+    LongestDays  int `json:"longest_days"`
+    CurrentWeeks int `json:"current_weeks"`
+    LongestWeeks int `json:"longest_weeks"`
+}
+
+// VelocityPoint is one week of progress movement: the summed positive
+// percentage deltas across goals plus KR snapshot health for that week.
+type VelocityPoint struct {
+    Week             string  `json:"week"`
+    PercentageGained int     `json:"percentage_gained"`
+    CheckIns         int     `json:"check_ins"`
+    KRSnapshots      int     `json:"kr_snapshots"`
+    AvgKRConfidence  float64 `json:"avg_kr_confidence"`
+    OnTrack          int     `json:"on_track"`
+    AtRisk           int     `json:"at_risk"`
+    OffTrack         int     `json:"off_track"`
+}
+
+type AnalyticsSummary struct {
+    Range               AnalyticsRange      `json:"range"`
+    TotalGoals          int                 `json:"total_goals"`
+    CompletedGoals      int                 `json:"completed_goals"`
+    CompletionByQuarter []QuarterCompletion `json:"completion_by_quarter"`
+    GoalsByStatus       []BucketCount       `json:"goals_by_status"`
+    GoalsByPriority     []BucketCount       `json:"goals_by_priority"`
+    GoalsByJobRole      []JobRoleCount      `json:"goals_by_job_role"`
+    AvgDaysToComplete   float64             `json:"avg_days_to_complete"`
+    CheckInFrequency    CheckInFrequency    `json:"check_in_frequency"`
+    Streaks             Streaks             `json:"streaks"`
+    VelocityTrend       []VelocityPoint     `json:"velocity_trend"`
+}
+
+// Summary builds the full analytics payload for a user within the range.
+// Goals are bucketed by creation date; completions by completed_at.
+func (s *AnalyticsService) Summary(userID string, rng AnalyticsRange) (*AnalyticsSummary, error) {
+    out := &AnalyticsSummary{Range: rng}
+
+    var totals struct {
+        Total     int
+        Completed int
+    }
+    if err := s.db.Raw(`
+        SELECT COUNT(*) AS total,
+               COUNT(*) FILTER (WHERE status = 'completed') AS completed
+        FROM goals
+        WHERE user_id = ? AND deleted_at IS NULL AND created_at >= ? AND created_at < ?`,
+        userID, rng.From, rng.To).Scan(&totals).Error; err != nil {
+        return nil, err
+    }
+    out.TotalGoals, out.CompletedGoals = totals.Total, totals.Completed
+
+    var quarters []struct {
+        QuarterStart time.Time
+        Total        int
+        Completed    int
+    }
+    if err := s.db.Raw(`
+        SELECT date_trunc('quarter', created_at AT TIME ZONE 'UTC') AS quarter_start,
+               COUNT(*) AS total,
+               COUNT(*) FILTER (WHERE status = 'completed') AS completed
+        FROM goals
+        WHERE user_id = ? AND deleted_at IS NULL AND created_at >= ? AND created_at < ?
+        GROUP BY 1 ORDER BY 1`,
+        userID, rng.From, rng.To).Scan(&quarters).Error; err != nil {
+        return nil, err
+    }
+    out.CompletionByQuarter = make([]QuarterCompletion, 0, len(quarters))
+    for _, q := range quarters {
+        rate := 0.0
+        if q.Total > 0 { rate = float64(q.Completed) / float64(q.Total) }
+        out.CompletionByQuarter = append(out.CompletionByQuarter, QuarterCompletion{
+            Quarter:        quarterLabel(q.QuarterStart),
+            Total:          q.Total,
+            Completed:      q.Completed,
+            CompletionRate: rate,
+        })
+    }
+
+    var err error
+    if out.GoalsByStatus, err = s.goalBuckets(userID, rng, "status"); err != nil { return nil, err }
+    if out.GoalsByPriority, err = s.goalBuckets(userID, rng, "priority"); err != nil { return nil, err }
+
+    out.GoalsByJobRole = []JobRoleCount{}
+    if err := s.db.Raw(`
+        SELECT g.job_role_id AS job_role_id,
+               COALESCE(jr.title, 'Unassigned') AS title,
+               COUNT(*) AS count
+        FROM goals g
+        LEFT JOIN job_roles jr ON jr.id = g.job_role_id
+        WHERE g.user_id = ? AND g.deleted_at IS NULL AND g.created_at >= ? AND g.created_at < ?
+        GROUP BY g.job_role_id, jr.title
+        ORDER BY count DESC`,
+        userID, rng.From, rng.To).Scan(&out.GoalsByJobRole).Error; err != nil {
+        return nil, err
+    }
+
+    // Goals completed before completed_at existed fall back to updated_at
+    var avg struct{ AvgDays *float64 }
+    if err := s.db.Raw(`
+        SELECT AVG(EXTRACT(EPOCH FROM (COALESCE(completed_at, updated_at) - created_at)) / 86400.0) AS avg_days
+        FROM goals
+        WHERE user_id = ? AND deleted_at IS NULL AND status = 'completed'
+          AND COALESCE(completed_at, updated_at) >= ? AND COALESCE(completed_at, updated_at) < ?`,
+        userID, rng.From, rng.To).Scan(&avg).Error; err != nil {
+        return nil, err
+    }
+    if avg.AvgDays != nil { out.AvgDaysToComplete = *avg.AvgDays }
+
+    if out.CheckInFrequency, err = s.checkIns(userID, rng); err != nil { return nil, err }
+    if out.Streaks, err = s.streaks(userID, rng); err != nil { return nil, err }
+    if out.VelocityTrend, err = s.velocity(userID, rng); err != nil { return nil, err }
+
+    return out, nil
+}
+
+// goalBuckets groups goals by a whitelisted column.
+func (s *AnalyticsService) goalBuckets(userID string, rng AnalyticsRange, column string) ([]BucketCount, error) {
+    if column != "status" && column != "priority" {
+        column = "status"
+    }
+    rows := []BucketCount{}
+    err := s.db.Raw(`
+        SELECT `+column+` AS key, COUNT(*) AS count
+        FROM goals
+        WHERE user_id = ? AND deleted_at IS NULL AND created_at >= ? AND created_at < ?
+        GROUP BY 1 ORDER BY 2 DESC`,
+        userID, rng.From, rng.To).Scan(&rows).Error
+    return rows, err
+}
+
+func (s *AnalyticsService) checkIns(userID string, rng AnalyticsRange) (CheckInFrequency, error) {
+    out := CheckInFrequency{WeeklyCount: []BucketCount{}}
+    var agg struct {
+        Total      int
+        ActiveDays int
+    }
+    if err := s.db.Raw(`
+        SELECT COUNT(*) AS total, COUNT(DISTINCT (created_at AT TIME ZONE 'UTC')::date) AS active_days
+        FROM progresses
+        WHERE user_id = ? AND created_at >= ? AND created_at < ?`,
+        userID, rng.From, rng.To).Scan(&agg).Error; err != nil {
+        return out, err
+    }
+    out.Total, out.ActiveDays = agg.Total, agg.ActiveDays
+    if weeks := rng.To.Sub(rng.From).Hours() / (24 * 7); weeks > 0 {
+        out.PerWeek = float64(agg.Total) / weeks
+    }
+
+    var weekly []struct {
+        WeekStart time.Time
+        Count     int
+    }
+    if err := s.db.Raw(`
+        SELECT date_trunc('week', created_at AT TIME ZONE 'UTC') AS week_start, COUNT(*) AS count
+        FROM progresses
+        WHERE user_id = ? AND created_at >= ? AND created_at < ?
+        GROUP BY 1 ORDER BY 1`,
+        userID, rng.From, rng.To).Scan(&weekly).Error; err != nil {
+        return out, err
+    }
+    for _, w := range weekly {
+        out.WeeklyCount = append(out.WeeklyCount, BucketCount{Key: w.WeekStart.Format("2006-01-02"), Count: w.Count})
+    }
+    return out, nil
+}
+
+// streaks counts consecutive check-in days and ISO weeks. The current streak
+// is only alive if it reaches today (or this week), or yesterday (last week).
+func (s *AnalyticsService) streaks(userID string, rng AnalyticsRange) (Streaks, error) {
+    var days []time.Time
+    if err := s.db.Raw(`
+        SELECT DISTINCT (created_at AT TIME ZONE 'UTC')::date AS day
+        FROM progresses
+        WHERE user_id = ? AND created_at >= ? AND created_at < ?
+        ORDER BY 1`,
+        userID, rng.From, rng.To).Scan(&days).Error; err != nil {
+        return Streaks{}, err
+    }
+
+    var weeks []time.Time
+    seen := map[time.Time]struct{}{}
+    for _, d := range days {
+        w := weekStart(d)
+        if _, ok := seen[w]; !ok {
+            seen[w] = struct{}{}
+            weeks = append(weeks, w)
+        }
+    }
+    sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+
+    today := truncateDay(time.Now().UTC())
+    var out Streaks
+    out.CurrentDays, out.LongestDays = runLengths(days, 24*time.Hour, today)
+    out.CurrentWeeks, out.LongestWeeks = runLengths(weeks, 7*24*time.Hour, weekStart(today))
+    return out, nil
+}
+
+func (s *AnalyticsService) velocity(userID string, rng AnalyticsRange) ([]VelocityPoint, error) {
+    var progressRows []struct {
+        WeekStart time.Time
+        Gained    int
+        CheckIns  int
+    }
+    // LAG over the full history so the first entry in range still gets a delta
+    if err := s.db.Raw(`
+        WITH deltas AS (
+            SELECT p.created_at,
+                   p.percentage - COALESCE(LAG(p.percentage) OVER (PARTITION BY p.goal_id ORDER BY p.created_at), 0) AS delta
+            FROM progresses p
+            WHERE p.user_id = ? AND p.created_at < ?
+        )
+        SELECT date_trunc('week', created_at AT TIME ZONE 'UTC') AS week_start,
+               COALESCE(SUM(GREATEST(delta, 0)), 0) AS gained,
+               COUNT(*) AS check_ins
+        FROM deltas
+        WHERE created_at >= ?
+        GROUP BY 1 ORDER BY 1`,
+        userID, rng.To, rng.From).Scan(&progressRows).Error; err != nil {
+        return nil, err
+    }
+
+    var krRows []struct {
+        WeekStart     time.Time
+        Snapshots     int
+        AvgConfidence float64
+        OnTrack       int
+        AtRisk        int
+        OffTrack      int
+    }
+    if err := s.db.Raw(`
+        SELECT date_trunc('week', captured_at AT TIME ZONE 'UTC') AS week_start,
+               COUNT(*) AS snapshots,
+               COALESCE(AVG(confidence), 0) AS avg_confidence,
+               COUNT(*) FILTER (WHERE status = 'on_track') AS on_track,
+               COUNT(*) FILTER (WHERE status = 'at_risk') AS at_risk,
+               COUNT(*) FILTER (WHERE status = 'off_track') AS off_track
+        FROM kr_snapshots
+        WHERE user_id = ? AND captured_at >= ? AND captured_at < ?
+        GROUP BY 1 ORDER BY 1`,
+        userID, rng.From, rng.To).Scan(&krRows).Error; err != nil {
+        return nil, err
+    }
+
+    byWeek := map[string]*VelocityPoint{}
+    point := func(t time.Time) *VelocityPoint {
+        key := t.Format("2006-01-02")
+        if p, ok := byWeek[key]; ok { return p }
+        p := &VelocityPoint{Week: key}
+        byWeek[key] = p
+        return p
+    }
+    for _, r := range progressRows {
+        p := point(r.WeekStart)
+        p.PercentageGained, p.CheckIns = r.Gained, r.CheckIns
+    }
+    for _, r := range krRows {
+        p := point(r.WeekStart)
+        p.KRSnapshots, p.AvgKRConfidence = r.Snapshots, r.AvgConfidence
+        p.OnTrack, p.AtRisk, p.OffTrack = r.OnTrack, r.AtRisk, r.OffTrack
+    }
+
+    out := make([]VelocityPoint, 0, len(byWeek))
+    for _, p := range byWeek { out = append(out, *p) }
+    sort.Slice(out, func(i, j int) bool { return out[i].Week < out[j].Week })
+    return out, nil
+}
+
+// runLengths returns the current and longest run of consecutive points spaced
+// exactly step apart. points must be sorted ascending and truncated.
+func runLengths(points []time.Time, step time.Duration, now time.Time) (current, longest int) {
+    run := 0
+    for i, p := range points {
+        if i > 0 && p.Sub(points[i-1]) == step {
+            run++
+        } else {
+            run = 1
+        }
+        if run > longest { longest = run }
+    }
+    if len(points) == 0 { return 0, 0 }
+    last := points[len(points)-1]
+    if gap := now.Sub(last); gap == 0 || gap == step {
+        current = run
+    }
+    return current, longest
+}
+
+func truncateDay(t time.Time) time.Time {
+    return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart returns the Monday of t's ISO week, matching date_trunc('week').
+func weekStart(t time.Time) time.Time {
+    d := truncateDay(t)
+    offset := (int(d.Weekday()) + 6) % 7
+    return d.AddDate(0, 0, -offset)
+}
+
+func quarterLabel(t time.Time) string {
+    q := (int(t.Month())-1)/3 + 1
+    return fmt.Sprintf("%d-Q%d", t.Year(), q)
+}
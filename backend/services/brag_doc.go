@@ -0,0 +1,422 @@
+package services
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "html/template"
+    "regexp"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// uncategorized is the section for goals with no linked responsibility; it
+// matches the Responsibility.Category column default.
+const uncategorized = "general"
+
+// BragDoc is a review-period accomplishment report, grouped into sections
+// by the category of the responsibility each goal serves.
+type BragDoc struct {
+    Range         AnalyticsRange      `json:"range"`
+    Summary       string              `json:"summary"`
+    SummaryEngine string              `json:"summary_engine"` // rules or ai
+    Totals        BragTotals          `json:"totals"`
+    Sections      []BragSection       `json:"sections"`
+    Evidence      []models.ITEvidence `json:"evidence"` // from the IT profile
+}
+
+type BragTotals struct {
+    Goals     int `json:"goals"`
+    Completed int `json:"completed"`
+    CheckIns  int `json:"check_ins"`
+}
+
+type BragSection struct {
+    Category string     `json:"category"`
+    Items    []BragItem `json:"items"`
+}
+
+// BragItem is one goal that was completed or had progress in the range.
+type BragItem struct {
+    GoalID           uint                 `json:"goal_id"`
+    Title            string               `json:"title"`
+    Status           string               `json:"status"`
+    CompletedAt      *time.Time           `json:"completed_at,omitempty"`
+    CompletedInRange bool                 `json:"completed_in_range"`
+    JobRole          string               `json:"job_role,omitempty"`
+    Responsibilities []BragResponsibility `json:"responsibilities"`
+    Percentage       int                  `json:"percentage"` // latest check-in
+    CheckIns         int                  `json:"check_ins"`
+    Outcomes         []string             `json:"outcomes"`
+    Actions          []string             `json:"actions"`
+    KRDeltas         []KRDelta            `json:"kr_deltas"`
+    Links            []string             `json:"links"` // URLs found in the goal and its check-ins
+}
+
+type BragResponsibility struct {
+    ID       uint   `json:"id"`
+    Title    string `json:"title"`
+    Category string `json:"category"`
+}
+
+// KRDelta is how far a key result moved during the range. From is the last
+// value before the range (or the baseline), To the last value inside it.
+type KRDelta struct {
+    KRID   string   `json:"kr_id"`
+    Name   string   `json:"name,omitempty"`
+    Unit   string   `json:"unit,omitempty"`
+    From   *float64 `json:"from,omitempty"`
+    To     float64  `json:"to"`
+    Delta  *float64 `json:"delta,omitempty"`
+    Target *float64 `json:"target,omitempty"`
+}
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>()"']+`)
+
+// CompileBragDoc collects every goal the user completed or checked in on
+// within rng, with its outcomes, actions, KR movement, links and linked
+// responsibilities. The summary is the deterministic one; callers may
+// replace it with SummarizeBragDoc.
+func CompileBragDoc(db *gorm.DB, userID string, rng AnalyticsRange) (*BragDoc, error) {
+    doc := &BragDoc{Range: rng, SummaryEngine: "rules", Sections: []BragSection{}}
+
+    var goalIDs []uint
+    if err := db.Raw(`
+        SELECT id FROM goals
+        WHERE user_id = ? AND deleted_at IS NULL AND status = 'completed' AND completed_at >= ? AND completed_at < ?
+        UNION
+        SELECT DISTINCT p.goal_id FROM progresses p JOIN goals g ON g.id = p.goal_id
+        WHERE p.user_id = ? AND g.deleted_at IS NULL AND p.created_at >= ? AND p.created_at < ?`,
+        userID, rng.From, rng.To, userID, rng.From, rng.To).Scan(&goalIDs).Error; err != nil {
+        return nil, err
+    }
+
+    var profile models.UserProfile
+    db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+    doc.Evidence = DecodeITProfile(profile.ITProfile).Evidence
+    if len(goalIDs) == 0 {
+        doc.Summary = doc.defaultSummary()
+        return doc, nil
+    }
+
+    var goals []models.Goal
+    if err := db.Preload("JobRole.Responsibilities").
+        Preload("Progress", func(tx *gorm.DB) *gorm.DB {
+            return tx.Where("created_at >= ? AND created_at < ?", rng.From, rng.To).Order("created_at")
+        }).
+        Where("id IN ?", goalIDs).Order("completed_at DESC NULLS LAST, updated_at DESC").Find(&goals).Error; err != nil {
+        return nil, err
+    }
+
+    var snaps []models.KRSnapshot
+    if err := db.Where("goal_id IN ? AND captured_at < ?", goalIDs, rng.To).
+        Order("captured_at, id").Find(&snaps).Error; err != nil {
+        return nil, err
+    }
+    byGoal := map[uint][]models.KRSnapshot{}
+    for _, s := range snaps { byGoal[s.GoalID] = append(byGoal[s.GoalID], s) }
+
+    sections := map[string]*BragSection{}
+    for _, g := range goals {
+        item := BragItem{
+            GoalID: g.ID, Title: g.Title, Status: g.Status, CompletedAt: g.CompletedAt,
+            CheckIns: len(g.Progress), Outcomes: []string{}, Actions: []string{}, Links: []string{},
+        }
+        item.CompletedInRange = g.Status == "completed" && g.CompletedAt != nil &&
+            !g.CompletedAt.Before(rng.From) && g.CompletedAt.Before(rng.To)
+        if g.JobRole != nil { item.JobRole = g.JobRole.Title }
+        item.Responsibilities = linkedResponsibilities(g)
+
+        links := map[string]bool{}
+        addLinks := func(s string) {
+            for _, u := range urlPattern.FindAllString(s, -1) {
+                u = strings.TrimRight(u, ".,;:!?")
+                if !links[u] { links[u] = true; item.Links = append(item.Links, u) }
+            }
+        }
+        addLinks(g.Description)
+        for _, p := range g.Progress {
+            if o := strings.TrimSpace(p.Outcome); o != "" { item.Outcomes = append(item.Outcomes, o) }
+            if a := strings.TrimSpace(p.ActionTaken); a != "" { item.Actions = append(item.Actions, a) }
+            item.Percentage = p.Percentage
+            addLinks(p.Description + " " + p.Notes + " " + p.Outcome + " " + p.ActionTaken)
+        }
+        item.KRDeltas = krDeltas(GoalKeyResults(g.Metadata), byGoal[g.ID], rng.From)
+
+        doc.Totals.Goals++
+        if item.CompletedInRange { doc.Totals.Completed++ }
+        doc.Totals.CheckIns += item.CheckIns
+
+        category := uncategorized
+        if len(item.Responsibilities) > 0 && item.Responsibilities[0].Category != "" {
+            category = item.Responsibilities[0].Category
+        }
+        sec, ok := sections[category]
+        if !ok {
+            sec = &BragSection{Category: category}
+            sections[category] = sec
+        }
+        sec.Items = append(sec.Items, item)
+    }
+
+    for _, sec := range sections { doc.Sections = append(doc.Sections, *sec) }
+    sort.Slice(doc.Sections, func(i, j int) bool {
+        a, b := doc.Sections[i], doc.Sections[j]
+        if (a.Category == uncategorized) != (b.Category == uncategorized) { return b.Category == uncategorized }
+        if len(a.Items) != len(b.Items) { return len(a.Items) > len(b.Items) }
+        return a.Category < b.Category
+    })
+    doc.Summary = doc.defaultSummary()
+    return doc, nil
+}
+
+// linkedResponsibilities returns the responsibility a goal names in
+// metadata.responsibility_id, or else the job-role responsibility whose
+// title shares the most words with the goal.
+func linkedResponsibilities(g models.Goal) []BragResponsibility {
+    out := []BragResponsibility{}
+    if g.JobRole == nil || len(g.JobRole.Responsibilities) == 0 { return out }
+    var meta struct {
+        ResponsibilityID uint `json:"responsibility_id"`
+    }
+    _ = json.Unmarshal([]byte(g.Metadata), &meta)
+
+    goalWords := significantWords(g.Title + " " + g.Description + " " + strings.Join(decodeStringList(g.Tags), " "))
+    best, bestScore := -1, 0
+    for i, r := range g.JobRole.Responsibilities {
+        if meta.ResponsibilityID != 0 && r.ID == meta.ResponsibilityID { best = i; break }
+        score := 0
+        for w := range significantWords(r.Title) {
+            if goalWords[w] { score++ }
+        }
+        if score > bestScore { best, bestScore = i, score }
+    }
+    if best < 0 { return out }
+    r := g.JobRole.Responsibilities[best]
+    return append(out, BragResponsibility{ID: r.ID, Title: r.Title, Category: r.Category})
+}
+
+var stopWords = map[string]bool{
+    "and": true, "the": true, "for": true, "with": true, "from": true, "into": true, "that": true,
+    "this": true, "your": true, "our": true, "improve": true, "manage": true, "management": true,
+}
+
+func significantWords(s string) map[string]bool {
+    out := map[string]bool{}
+    for _, w := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+        return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+    }) {
+        if len(w) >= 3 && !stopWords[w] { out[w] = true }
+    }
+    return out
+}
+
+// krDeltas reports movement for each key result that has a snapshot on or
+// after from. snaps must be ordered by capture time.
+func krDeltas(krs []OKRKeyResult, snaps []models.KRSnapshot, from time.Time) []KRDelta {
+    out := []KRDelta{}
+    for _, kr := range krs {
+        var start *float64
+        if kr.Baseline != nil { b := *kr.Baseline; start = &b }
+        var end *float64
+        for _, s := range snaps {
+            if s.KRID != kr.ID { continue }
+            v := s.Value
+            if s.CapturedAt.Before(from) { start = &v } else { end = &v }
+        }
+        if end == nil { continue }
+        d := KRDelta{KRID: kr.ID, Name: kr.Name, Unit: kr.Unit, From: start, To: *end, Target: kr.Target}
+        if start != nil { delta := *end - *start; d.Delta = &delta }
+        out = append(out, d)
+    }
+    return out
+}
+
+func (doc *BragDoc) defaultSummary() string {
+    period := fmt.Sprintf("%s to %s", doc.Range.From.Format("2 Jan 2006"), doc.Range.To.AddDate(0, 0, -1).Format("2 Jan 2006"))
+    if doc.Totals.Goals == 0 { return "No goal activity was recorded from " + period + "." }
+    areas := make([]string, 0, len(doc.Sections))
+    for _, s := range doc.Sections { areas = append(areas, sectionTitle(s.Category)) }
+    s := fmt.Sprintf("From %s: completed %d %s", period, doc.Totals.Completed, plural(doc.Totals.Completed, "goal", "goals"))
+    if advanced := doc.Totals.Goals - doc.Totals.Completed; advanced > 0 {
+        s += fmt.Sprintf(" and advanced %d more", advanced)
+    }
+    s += fmt.Sprintf(" across %s, with %d %s.", strings.Join(areas, ", "), doc.Totals.CheckIns, plural(doc.Totals.CheckIns, "check-in", "check-ins"))
+
+    var moved []string
+    for _, sec := range doc.Sections {
+        for _, it := range sec.Items {
+            for _, d := range it.KRDeltas {
+                if d.Delta != nil && *d.Delta != 0 && len(moved) < 3 {
+                    moved = append(moved, fmt.Sprintf("%s %s", firstNonEmpty(d.Name, d.KRID), signed(*d.Delta, d.Unit)))
+                }
+            }
+        }
+    }
+    if len(moved) > 0 { s += " Key results moved: " + strings.Join(moved, "; ") + "." }
+    return s
+}
+
+func plural(n int, one, many string) string {
+    if n == 1 { return one }
+    return many
+}
+
+func signed(v float64, unit string) string {
+    s := fmt.Sprintf("%+g", v)
+    if unit != "" { s += " " + unit }
+    return s
+}
+
+// SummarizeBragDoc asks the AI provider for a short narrative summary.
+// It errors when no provider is configured; callers keep the default.
+func (ai *AIService) SummarizeBragDoc(doc *BragDoc) (string, error) {
+    if ai.provider != "openai" || ai.openAIKey == "" {
+        return "", errors.New("no AI provider configured")
+    }
+    var facts strings.Builder
+    for _, sec := range doc.Sections {
+        fmt.Fprintf(&facts, "[%s]\n", sec.Category)
+        for _, it := range sec.Items {
+            fmt.Fprintf(&facts, "- %s (%s, %d%%)\n", it.Title, it.Status, it.Percentage)
+            for _, o := range it.Outcomes { fmt.Fprintf(&facts, "  outcome: %s\n", oneLine(o)) }
+            for _, d := range it.KRDeltas {
+                if d.Delta != nil { fmt.Fprintf(&facts, "  KR %s: %s\n", firstNonEmpty(d.Name, d.KRID), signed(*d.Delta, d.Unit)) }
+            }
+        }
+    }
+    text := facts.String()
+    if len(text) > 12000 { text = text[:12000] }
+    prompt := "Write a 3-5 sentence first-person summary of these accomplishments for a performance review. " +
+        "Use only the facts given; mention measurable results. Return JSON {\"summary\": string}.\n\n" + text
+
+    content, err := ai.chatJSON("You write concise, factual performance review summaries and return STRICT JSON.", prompt, 400)
+    if err != nil { return "", err }
+    var out struct {
+        Summary string `json:"summary"`
+    }
+    if err := json.Unmarshal([]byte(content), &out); err != nil { return "", sanitizeError(err) }
+    if strings.TrimSpace(out.Summary) == "" { return "", errors.New("empty summary") }
+    return strings.TrimSpace(out.Summary), nil
+}
+
+// Markdown renders the brag document.
+func (doc *BragDoc) Markdown() string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "# Brag document: %s – %s\n\n", doc.Range.From.Format("2 Jan 2006"), doc.Range.To.AddDate(0, 0, -1).Format("2 Jan 2006"))
+    b.WriteString(doc.Summary + "\n\n")
+    for _, sec := range doc.Sections {
+        fmt.Fprintf(&b, "## %s\n\n", mdEscape(sectionTitle(sec.Category)))
+        for _, it := range sec.Items {
+            fmt.Fprintf(&b, "### %s\n\n", mdEscape(it.Title))
+            b.WriteString("_" + it.statusLine() + "_\n\n")
+            if len(it.Responsibilities) > 0 {
+                names := make([]string, 0, len(it.Responsibilities))
+                for _, r := range it.Responsibilities { names = append(names, mdEscape(r.Title)) }
+                b.WriteString("**Responsibilities:** " + strings.Join(names, ", ") + "\n\n")
+            }
+            writeMDList(&b, "Outcomes", it.Outcomes)
+            writeMDList(&b, "Actions taken", it.Actions)
+            if len(it.KRDeltas) > 0 {
+                b.WriteString("**Key results**\n\n")
+                for _, d := range it.KRDeltas { b.WriteString("- " + mdEscape(d.line()) + "\n") }
+                b.WriteString("\n")
+            }
+            if len(it.Links) > 0 {
+                b.WriteString("**Evidence**\n\n")
+                for _, l := range it.Links { fmt.Fprintf(&b, "- <%s>\n", l) }
+                b.WriteString("\n")
+            }
+        }
+    }
+    if len(doc.Evidence) > 0 {
+        b.WriteString("## Portfolio evidence\n\n")
+        for _, e := range doc.Evidence {
+            if e.Link != "" {
+                fmt.Fprintf(&b, "- [%s](%s)\n", mdEscape(e.Title), e.Link)
+            } else {
+                b.WriteString("- " + mdEscape(e.Title) + "\n")
+            }
+        }
+    }
+    return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func writeMDList(b *strings.Builder, title string, items []string) {
+    if len(items) == 0 { return }
+    b.WriteString("**" + title + "**\n\n")
+    for _, s := range items { b.WriteString("- " + oneLine(s) + "\n") }
+    b.WriteString("\n")
+}
+
+var bragHTML = template.Must(template.New("brag").Funcs(template.FuncMap{
+    "title": sectionTitle,
+    "date":  func(t time.Time) string { return t.Format("2 Jan 2006") },
+    "last":  func(t time.Time) string { return t.AddDate(0, 0, -1).Format("2 Jan 2006") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en"><head><meta charset="utf-8">
+<title>Brag document: {{date .Range.From}} – {{last .Range.To}}</title>
+<style>body{font-family:system-ui,sans-serif;max-width:48rem;margin:2rem auto;line-height:1.5;color:#1f2937}h2{border-bottom:1px solid #e5e7eb;padding-bottom:.25rem}.meta{color:#6b7280;font-style:italic}</style>
+</head><body>
+<h1>Brag document: {{date .Range.From}} – {{last .Range.To}}</h1>
+<p>{{.Summary}}</p>
+{{range .Sections}}<h2>{{title .Category}}</h2>
+{{range .Items}}<h3>{{.Title}}</h3>
+<p class="meta">{{.StatusLine}}</p>
+{{if .Responsibilities}}<p><strong>Responsibilities:</strong> {{range $i, $r := .Responsibilities}}{{if $i}}, {{end}}{{$r.Title}}{{end}}</p>{{end}}
+{{if .Outcomes}}<p><strong>Outcomes</strong></p><ul>{{range .Outcomes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .Actions}}<p><strong>Actions taken</strong></p><ul>{{range .Actions}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{if .KRDeltas}}<p><strong>Key results</strong></p><ul>{{range .KRDeltas}}<li>{{.Line}}</li>{{end}}</ul>{{end}}
+{{if .Links}}<p><strong>Evidence</strong></p><ul>{{range .Links}}<li><a href="{{.}}">{{.}}</a></li>{{end}}</ul>{{end}}
+{{end}}{{end}}
+{{if .Evidence}}<h2>Portfolio evidence</h2><ul>{{range .Evidence}}<li>{{if .Link}}<a href="{{.Link}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}</li>{{end}}</ul>{{end}}
+</body></html>
+`))
+
+// HTML renders the brag document as a standalone page.
+func (doc *BragDoc) HTML() (string, error) {
+    var buf bytes.Buffer
+    err := bragHTML.Execute(&buf, doc)
+    return buf.String(), err
+}
+
+// StatusLine and Line are exported for the HTML template.
+func (it BragItem) StatusLine() string { return it.statusLine() }
+func (d KRDelta) Line() string         { return d.line() }
+
+func (it BragItem) statusLine() string {
+    parts := []string{}
+    if it.Status == "completed" && it.CompletedAt != nil {
+        parts = append(parts, "Completed "+it.CompletedAt.Format("2 Jan 2006"))
+    } else {
+        parts = append(parts, fmt.Sprintf("In progress (%d%%)", it.Percentage))
+    }
+    if it.JobRole != "" { parts = append(parts, it.JobRole) }
+    parts = append(parts, fmt.Sprintf("%d %s", it.CheckIns, plural(it.CheckIns, "check-in", "check-ins")))
+    return strings.Join(parts, " · ")
+}
+
+func (d KRDelta) line() string {
+    s := firstNonEmpty(d.Name, d.KRID) + ": "
+    if d.From != nil {
+        s += fmt.Sprintf("%g → %g", *d.From, d.To)
+    } else {
+        s += fmt.Sprintf("%g", d.To)
+    }
+    if d.Unit != "" { s += " " + d.Unit }
+    if d.Delta != nil { s += " (" + signed(*d.Delta, "") + ")" }
+    if d.Target != nil { s += fmt.Sprintf(", target %g", *d.Target) }
+    return s
+}
+
+func sectionTitle(category string) string {
+    category = strings.TrimSpace(strings.ReplaceAll(category, "_", " "))
+    if category == "" { return "General" }
+    return strings.ToUpper(category[:1]) + category[1:]
+}
@@ -0,0 +1,466 @@
+package services
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "sort"
+    "strconv"
+    "strings"
+
+    "goaltracker/models"
+
+    "gopkg.in/yaml.v3"
+    "gorm.io/gorm"
+)
+
+// LadderDocument is the import format for a career ladder. Levels are listed
+// from most junior to most senior; their order becomes the rank.
+//
+//   slug: software-engineering
+//   name: Software Engineering
+//   job_role: Software Engineer
+//   competencies:
+//     - slug: delivery
+//       name: Delivery
+//       keywords: [shipped, launched, release]
+//       skills: [github-actions]
+//   levels:
+//     - slug: se2
+//       name: Software Engineer II
+//       experience_level: mid
+//       expectations:
+//         - competency: delivery
+//           level: 2
+//           description: Ships well-scoped features with little guidance
+type LadderDocument struct {
+    Slug         string             `json:"slug" yaml:"slug"`
+    Name         string             `json:"name" yaml:"name"`
+    Description  string             `json:"description" yaml:"description,omitempty"`
+    JobRole      string             `json:"job_role" yaml:"job_role,omitempty"`
+    Competencies []LadderCompetency `json:"competencies" yaml:"competencies"`
+    Levels       []LadderLevel      `json:"levels" yaml:"levels"`
+}
+
+type LadderCompetency struct {
+    Slug        string   `json:"slug" yaml:"slug"`
+    Name        string   `json:"name" yaml:"name"`
+    Description string   `json:"description" yaml:"description,omitempty"`
+    Keywords    []string `json:"keywords" yaml:"keywords,omitempty"`
+    Skills      []string `json:"skills" yaml:"skills,omitempty"`
+}
+
+type LadderLevel struct {
+    Slug            string              `json:"slug" yaml:"slug"`
+    Name            string              `json:"name" yaml:"name"`
+    ExperienceLevel string              `json:"experience_level" yaml:"experience_level,omitempty"`
+    Description     string              `json:"description" yaml:"description,omitempty"`
+    Expectations    []LadderExpectation `json:"expectations" yaml:"expectations"`
+}
+
+type LadderExpectation struct {
+    Competency  string `json:"competency" yaml:"competency"`
+    Level       int    `json:"level" yaml:"level"`
+    Description string `json:"description" yaml:"description,omitempty"`
+}
+
+// Size limits for imported ladders
+const (
+    maxLadderLevels       = 20
+    maxLadderCompetencies = 50
+)
+
+// ParseLadder decodes a ladder from YAML or JSON (format "yaml" or "json";
+// anything else is sniffed from the first character). Unknown fields are
+// rejected so typos in hand-written files surface.
+func ParseLadder(body []byte, format string) (LadderDocument, error) {
+    var doc LadderDocument
+    err := decodeDocument(body, format, &doc)
+    return doc, err
+}
+
+// decodeDocument strictly decodes a JSON or YAML document into v. An unknown
+// format is sniffed from the first byte.
+func decodeDocument(body []byte, format string, v interface{}) error {
+    trimmed := bytes.TrimSpace(body)
+    if format != "json" && format != "yaml" {
+        format = "yaml"
+        if len(trimmed) > 0 && trimmed[0] == '{' { format = "json" }
+    }
+    if format == "json" {
+        dec := json.NewDecoder(bytes.NewReader(trimmed))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(v); err != nil { return fmt.Errorf("invalid JSON: %v", err) }
+        return nil
+    }
+    dec := yaml.NewDecoder(bytes.NewReader(trimmed))
+    dec.KnownFields(true)
+    if err := dec.Decode(v); err != nil { return fmt.Errorf("invalid YAML: %v", err) }
+    return nil
+}
+
+// ValidateLadder normalizes slugs and checks references between levels and
+// competencies. skills resolves competency skill names to catalog slugs.
+func ValidateLadder(doc *LadderDocument, skills *SkillCatalog) []FieldError {
+    var errs []FieldError
+    add := func(field, code string, args ...interface{}) {
+        errs = append(errs, NewFieldError(field, code, args...))
+    }
+    doc.Name = strings.TrimSpace(doc.Name)
+    if doc.Name == "" { add("name", "value_required") }
+    doc.Slug = SkillSlug(firstNonEmpty(doc.Slug, doc.Name))
+    if doc.Slug == "" { add("slug", "value_required") }
+    if len(doc.Competencies) == 0 { add("competencies", "value_empty") }
+    if len(doc.Competencies) > maxLadderCompetencies { add("competencies", "value_too_many_items", maxLadderCompetencies) }
+    if len(doc.Levels) == 0 { add("levels", "value_empty") }
+    if len(doc.Levels) > maxLadderLevels { add("levels", "value_too_many_items", maxLadderLevels) }
+
+    comps := map[string]bool{}
+    for i := range doc.Competencies {
+        c := &doc.Competencies[i]
+        field := fmt.Sprintf("competencies[%d]", i)
+        c.Name = strings.TrimSpace(c.Name)
+        if c.Name == "" { add(field+".name", "value_required") }
+        c.Slug = SkillSlug(firstNonEmpty(c.Slug, c.Name))
+        if comps[c.Slug] { add(field+".slug", "value_duplicate", c.Slug) }
+        comps[c.Slug] = true
+        for j, name := range c.Skills {
+            skill, ok := skills.Resolve(name)
+            if !ok {
+                add(fmt.Sprintf("%s.skills[%d]", field, j), "value_unknown_skill", name)
+                continue
+            }
+            c.Skills[j] = skill.Slug
+        }
+    }
+
+    levels := map[string]bool{}
+    for i := range doc.Levels {
+        l := &doc.Levels[i]
+        field := fmt.Sprintf("levels[%d]", i)
+        l.Name = strings.TrimSpace(l.Name)
+        if l.Name == "" { add(field+".name", "value_required") }
+        l.Slug = SkillSlug(firstNonEmpty(l.Slug, l.Name))
+        if levels[l.Slug] { add(field+".slug", "value_duplicate", l.Slug) }
+        levels[l.Slug] = true
+        if l.ExperienceLevel != "" {
+            l.ExperienceLevel = matchVocab(l.ExperienceLevel, ExperienceLevels)
+            if l.ExperienceLevel == "" { add(field+".experience_level", "value_not_one_of", strings.Join(ExperienceLevels, ", ")) }
+        }
+        seen := map[string]bool{}
+        for j := range l.Expectations {
+            e := &l.Expectations[j]
+            efield := fmt.Sprintf("%s.expectations[%d]", field, j)
+            e.Competency = SkillSlug(e.Competency)
+            switch {
+            case !comps[e.Competency]:
+                add(efield+".competency", "value_unknown_competency", e.Competency)
+            case seen[e.Competency]:
+                add(efield+".competency", "value_duplicate", e.Competency)
+            }
+            seen[e.Competency] = true
+            if e.Level < 1 || e.Level > 4 { add(efield+".level", "value_out_of_range", 1, 4) }
+        }
+    }
+    return errs
+}
+
+// ImportLadder creates or replaces the track with doc.Slug. Levels,
+// competencies and expectations are replaced wholesale; the track keeps its
+// id. doc must have passed ValidateLadder.
+func ImportLadder(db *gorm.DB, doc LadderDocument) (models.CareerTrack, bool, error) {
+    var track models.CareerTrack
+    created := false
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if tx.Where("slug = ?", doc.Slug).Limit(1).Find(&track).RowsAffected == 0 {
+            track = models.CareerTrack{Slug: doc.Slug}
+            created = true
+        }
+        track.Name, track.Description, track.JobRoleID = doc.Name, strings.TrimSpace(doc.Description), nil
+        if doc.JobRole != "" {
+            var role models.JobRole
+            if tx.Where("LOWER(title) = LOWER(?)", strings.TrimSpace(doc.JobRole)).Limit(1).Find(&role).RowsAffected > 0 {
+                track.JobRoleID = &role.ID
+            }
+        }
+        if err := tx.Save(&track).Error; err != nil { return err }
+
+        if !created {
+            levelIDs := tx.Model(&models.CareerLevel{}).Select("id").Where("track_id = ?", track.ID)
+            if err := tx.Where("level_id IN (?)", levelIDs).Delete(&models.CompetencyExpectation{}).Error; err != nil { return err }
+            if err := tx.Where("track_id = ?", track.ID).Delete(&models.CareerLevel{}).Error; err != nil { return err }
+            if err := tx.Where("track_id = ?", track.ID).Delete(&models.Competency{}).Error; err != nil { return err }
+        }
+
+        compIDs := map[string]uint{}
+        for _, c := range doc.Competencies {
+            comp := models.Competency{
+                TrackID: track.ID, Slug: c.Slug, Name: c.Name, Description: strings.TrimSpace(c.Description),
+                Keywords: encodeStrings(c.Keywords), Skills: encodeStrings(c.Skills),
+            }
+            if err := tx.Create(&comp).Error; err != nil { return err }
+            compIDs[c.Slug] = comp.ID
+        }
+        for i, l := range doc.Levels {
+            level := models.CareerLevel{
+                TrackID: track.ID, Slug: l.Slug, Name: l.Name, Rank: i + 1,
+                ExperienceLevel: l.ExperienceLevel, Description: strings.TrimSpace(l.Description),
+            }
+            if err := tx.Create(&level).Error; err != nil { return err }
+            for _, e := range l.Expectations {
+                exp := models.CompetencyExpectation{LevelID: level.ID, CompetencyID: compIDs[e.Competency], Level: e.Level, Description: strings.TrimSpace(e.Description)}
+                if err := tx.Create(&exp).Error; err != nil { return err }
+            }
+        }
+        return nil
+    })
+    if err != nil { return track, false, err }
+    track, err = LoadLadder(db, track.Slug)
+    return track, created, err
+}
+
+// LoadLadder reads a track by slug (or numeric id) with its full matrix.
+func LoadLadder(db *gorm.DB, ref string) (models.CareerTrack, error) {
+    var track models.CareerTrack
+    q := db.Preload("Competencies", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+        Preload("Levels", func(db *gorm.DB) *gorm.DB { return db.Order("rank") }).
+        Preload("Levels.Expectations", func(db *gorm.DB) *gorm.DB { return db.Order("competency_id") })
+    err := q.Where("slug = ?", ref).First(&track).Error
+    if err == gorm.ErrRecordNotFound {
+        if id, perr := strconv.ParseUint(ref, 10, 32); perr == nil { err = q.First(&track, id).Error }
+    }
+    return track, err
+}
+
+// ExportLadder converts a loaded track back into the import format.
+func ExportLadder(track models.CareerTrack, jobRole string) LadderDocument {
+    doc := LadderDocument{Slug: track.Slug, Name: track.Name, Description: track.Description, JobRole: jobRole}
+    slugs := map[uint]string{}
+    for _, c := range track.Competencies {
+        slugs[c.ID] = c.Slug
+        doc.Competencies = append(doc.Competencies, LadderCompetency{
+            Slug: c.Slug, Name: c.Name, Description: c.Description,
+            Keywords: decodeStringList(c.Keywords), Skills: decodeStringList(c.Skills),
+        })
+    }
+    for _, l := range track.Levels {
+        level := LadderLevel{Slug: l.Slug, Name: l.Name, ExperienceLevel: l.ExperienceLevel, Description: l.Description}
+        for _, e := range l.Expectations {
+            level.Expectations = append(level.Expectations, LadderExpectation{Competency: slugs[e.CompetencyID], Level: e.Level, Description: e.Description})
+        }
+        doc.Levels = append(doc.Levels, level)
+    }
+    return doc
+}
+
+func encodeStrings(items []string) string {
+    out := []string{}
+    for _, s := range items {
+        if s = strings.TrimSpace(s); s != "" { out = append(out, s) }
+    }
+    b, _ := json.Marshal(out)
+    return string(b)
+}
+
+// Readiness thresholds: the share of the next level's expectations met
+// overall, and the floor no single competency may fall below.
+const (
+    readinessReady        = 0.85
+    readinessAlmost       = 0.6
+    readinessCompetency   = 0.5
+    maxCompetencyEvidence = 5
+)
+
+// CompetencyEvidence is a completed goal, profile evidence item or skill that
+// counted towards a competency.
+type CompetencyEvidence struct {
+    Kind   string `json:"kind"` // goal, evidence or skill
+    ID     uint   `json:"id,omitempty"`
+    Title  string `json:"title"`
+    Reason string `json:"reason"`
+}
+
+// CompetencyReadiness scores one competency against the next level.
+type CompetencyReadiness struct {
+    Competency    models.Competency    `json:"competency"`
+    Expected      int                  `json:"expected"`
+    CurrentTarget int                  `json:"current_target"` // expectation at the user's current level
+    Demonstrated  int                  `json:"demonstrated"`
+    Score         float64              `json:"score"`
+    Evidence      []CompetencyEvidence `json:"evidence"`
+}
+
+// Readiness is a promotion-readiness estimate for the next level of a track.
+type Readiness struct {
+    Track        models.CareerTrack    `json:"track"`
+    CurrentLevel *models.CareerLevel   `json:"current_level"`
+    NextLevel    *models.CareerLevel   `json:"next_level"`
+    LevelSource  string                `json:"level_source"` // query, experience_level or none
+    Overall      float64               `json:"overall"`
+    Status       string                `json:"status"` // ready, almost, developing, top_of_ladder
+    Competencies []CompetencyReadiness `json:"competencies"`
+    Blockers     []string              `json:"blockers"`
+}
+
+// PlaceOnLadder picks the user's current level: the explicit level slug if
+// given, otherwise the most senior level tied to the profile's experience
+// level or below it. Returns nil when the user sits below the first rung.
+func PlaceOnLadder(track models.CareerTrack, levelSlug, experienceLevel string) (*models.CareerLevel, string, error) {
+    if levelSlug != "" {
+        for i := range track.Levels {
+            if track.Levels[i].Slug == levelSlug || fmt.Sprint(track.Levels[i].ID) == levelSlug { return &track.Levels[i], "query", nil }
+        }
+        return nil, "", fmt.Errorf("level %q is not on the %s ladder", levelSlug, track.Name)
+    }
+    rank := indexOf(ExperienceLevels, experienceLevel)
+    if rank < 0 { return nil, "none", nil }
+    var best *models.CareerLevel
+    for i := range track.Levels {
+        l := &track.Levels[i]
+        if r := indexOf(ExperienceLevels, l.ExperienceLevel); r >= 0 && r <= rank { best = l }
+    }
+    if best == nil { return nil, "none", nil }
+    return best, "experience_level", nil
+}
+
+func indexOf(list []string, s string) int {
+    for i, v := range list {
+        if v == s { return i }
+    }
+    return -1
+}
+
+// AssessReadiness maps the user's completed goals (with progress outcomes),
+// IT profile evidence and skill levels to the track's competencies and scores
+// them against the expectations of the level after current. track must be
+// loaded with LoadLadder.
+func AssessReadiness(db *gorm.DB, userID string, track models.CareerTrack, current *models.CareerLevel) (*Readiness, error) {
+    r := &Readiness{Track: track, CurrentLevel: current, Competencies: []CompetencyReadiness{}, Blockers: []string{}}
+    nextRank := 1
+    if current != nil { nextRank = current.Rank + 1 }
+    for i := range track.Levels {
+        if track.Levels[i].Rank == nextRank { r.NextLevel = &track.Levels[i] }
+    }
+    if r.NextLevel == nil {
+        r.Status, r.Overall = "top_of_ladder", 1
+        return r, nil
+    }
+
+    var goals []models.Goal
+    if err := db.Preload("Progress").Where("user_id = ? AND status = 'completed'", userID).Order("completed_at").Find(&goals).Error; err != nil {
+        return nil, err
+    }
+    var profile models.UserProfile
+    db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+    evidence := DecodeITProfile(profile.ITProfile).Evidence
+    var userSkills []models.UserSkill
+    if err := db.Preload("Skill").Where("user_id = ?", userID).Find(&userSkills).Error; err != nil { return nil, err }
+    catalog, err := LoadSkillCatalog(db)
+    if err != nil { return nil, err }
+
+    expectations := func(l *models.CareerLevel) map[uint]models.CompetencyExpectation {
+        out := map[uint]models.CompetencyExpectation{}
+        if l == nil { return out }
+        for _, e := range l.Expectations { out[e.CompetencyID] = e }
+        return out
+    }
+    next, cur := expectations(r.NextLevel), expectations(current)
+
+    totalWeight, weighted := 0.0, 0.0
+    for _, comp := range track.Competencies {
+        exp, ok := next[comp.ID]
+        if !ok { continue }
+        cr := CompetencyReadiness{Competency: comp, Expected: exp.Level, CurrentTarget: cur[comp.ID].Level, Evidence: []CompetencyEvidence{}}
+        skillSlugs := map[string]bool{}
+        for _, s := range decodeStringList(comp.Skills) { skillSlugs[s] = true }
+        keywords := competencyKeywords(comp)
+
+        matches := 0
+        for _, g := range goals {
+            text := g.Title + " " + g.Description
+            for _, p := range g.Progress { text += " " + p.Outcome }
+            if reason := competencyMatch(g.Metadata, text, comp.Slug, keywords, skillSlugs, catalog); reason != "" {
+                matches++
+                cr.Evidence = append(cr.Evidence, CompetencyEvidence{Kind: "goal", ID: g.ID, Title: g.Title, Reason: reason})
+            }
+        }
+        for _, e := range evidence {
+            if reason := competencyMatch("", e.Title, comp.Slug, keywords, skillSlugs, catalog); reason != "" {
+                matches++
+                cr.Evidence = append(cr.Evidence, CompetencyEvidence{Kind: "evidence", Title: e.Title, Reason: reason})
+            }
+        }
+        skillLevel := 0
+        for _, us := range userSkills {
+            if !skillSlugs[us.Skill.Slug] || us.Proficiency == 0 { continue }
+            if us.Proficiency > skillLevel { skillLevel = us.Proficiency }
+            cr.Evidence = append(cr.Evidence, CompetencyEvidence{Kind: "skill", ID: us.SkillID, Title: us.Skill.Name, Reason: fmt.Sprintf("proficiency %d", us.Proficiency)})
+        }
+        cr.Demonstrated = evidenceLevel(matches)
+        if skillLevel > cr.Demonstrated { cr.Demonstrated = skillLevel }
+        cr.Score = float64(cr.Demonstrated) / float64(cr.Expected)
+        if cr.Score > 1 { cr.Score = 1 }
+        if len(cr.Evidence) > maxCompetencyEvidence { cr.Evidence = cr.Evidence[len(cr.Evidence)-maxCompetencyEvidence:] }
+        if cr.Score < readinessCompetency {
+            r.Blockers = append(r.Blockers, fmt.Sprintf("%s: demonstrated %d of %d expected", comp.Name, cr.Demonstrated, cr.Expected))
+        }
+
+        // Higher bars weigh more: a gap at level 4 matters more than one at 1
+        totalWeight += float64(cr.Expected)
+        weighted += cr.Score * float64(cr.Expected)
+        r.Competencies = append(r.Competencies, cr)
+    }
+    if totalWeight > 0 { r.Overall = weighted / totalWeight }
+    switch {
+    case r.Overall >= readinessReady && len(r.Blockers) == 0:
+        r.Status = "ready"
+    case r.Overall >= readinessAlmost:
+        r.Status = "almost"
+    default:
+        r.Status = "developing"
+    }
+    sort.SliceStable(r.Competencies, func(i, j int) bool { return r.Competencies[i].Score < r.Competencies[j].Score })
+    return r, nil
+}
+
+// evidenceLevel converts a count of supporting goals and evidence items to
+// the 0-4 scale: one item shows awareness, five or more show expertise.
+func evidenceLevel(n int) int {
+    switch {
+    case n >= 5:
+        return 4
+    case n >= 3:
+        return 3
+    default:
+        return n
+    }
+}
+
+func competencyKeywords(c models.Competency) []string {
+    words := []string{strings.ToLower(c.Name)}
+    for _, k := range decodeStringList(c.Keywords) {
+        if k = strings.ToLower(strings.TrimSpace(k)); k != "" { words = append(words, k) }
+    }
+    return words
+}
+
+// competencyMatch explains why text supports a competency, or returns "".
+// An explicit metadata.competency tag wins over keyword and skill matches.
+func competencyMatch(metadata, text, slug string, keywords []string, skills map[string]bool, catalog *SkillCatalog) string {
+    if metadata != "" {
+        var meta struct {
+            Competency string `json:"competency"`
+        }
+        if json.Unmarshal([]byte(metadata), &meta) == nil && meta.Competency == slug { return "tagged" }
+    }
+    lower := strings.ToLower(text)
+    for _, k := range keywords {
+        if containsWord(lower, []string{k}) { return "mentions " + k }
+    }
+    if len(skills) > 0 {
+        for _, s := range catalog.MatchText(text) {
+            if skills[s.Slug] { return "uses " + s.Name }
+        }
+    }
+    return ""
+}
@@ -0,0 +1,336 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+
+    "goaltracker/i18n"
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// CatalogType describes one admin-managed catalog table.
+type CatalogType struct {
+    Name         string // route segment and audit entity type, e.g. job-roles
+    Label        string // for messages, e.g. Job role
+    Table        string
+    ParentTable  string // table ParentColumn points at; empty for roots
+    ParentColumn string
+    Refs         []CatalogRef // rows that block deletion
+    Cascade      []CatalogRef // rows deleted along with the item
+    New          func() interface{}
+    NewSlice     func() interface{}
+}
+
+// CatalogRef is a column in another table pointing at a catalog row.
+type CatalogRef struct {
+    Table  string
+    Column string
+}
+
+// CatalogTypes lists the catalog tables in dependency order (parents first).
+var CatalogTypes = []CatalogType{
+    {
+        Name: "job-roles", Label: "Job role", Table: "job_roles",
+        Refs:    []CatalogRef{{"responsibilities", "job_role_id"}, {"goals", "job_role_id"}, {"career_tracks", "job_role_id"}},
+        Cascade: []CatalogRef{{"job_role_requirements", "job_role_id"}},
+        New:      func() interface{} { return &models.JobRole{} },
+        NewSlice: func() interface{} { return &[]models.JobRole{} },
+    },
+    {
+        Name: "responsibilities", Label: "Responsibility", Table: "responsibilities",
+        ParentTable: "job_roles", ParentColumn: "job_role_id",
+        Refs:     []CatalogRef{{"goal_suggestions", "responsibility_id"}},
+        New:      func() interface{} { return &models.Responsibility{} },
+        NewSlice: func() interface{} { return &[]models.Responsibility{} },
+    },
+    {
+        Name: "goal-suggestions", Label: "Goal suggestion", Table: "goal_suggestions",
+        ParentTable: "responsibilities", ParentColumn: "responsibility_id",
+        Refs:     []CatalogRef{{"progress_suggestions", "goal_suggestion_id"}},
+        New:      func() interface{} { return &models.GoalSuggestion{} },
+        NewSlice: func() interface{} { return &[]models.GoalSuggestion{} },
+    },
+    {
+        Name: "progress-suggestions", Label: "Progress suggestion", Table: "progress_suggestions",
+        ParentTable: "goal_suggestions", ParentColumn: "goal_suggestion_id",
+        New:      func() interface{} { return &models.ProgressSuggestion{} },
+        NewSlice: func() interface{} { return &[]models.ProgressSuggestion{} },
+    },
+}
+
+var (
+    ErrCatalogInUse           = errors.New("catalog item is still referenced")
+    ErrCatalogParentDraft     = errors.New("parent is not published")
+    ErrCatalogStatusUnchanged = errors.New("catalog item already has that status")
+)
+
+// FindCatalogType looks a type up by route segment or table name.
+func FindCatalogType(name string) (CatalogType, bool) {
+    for _, t := range CatalogTypes {
+        if t.Name == name || t.Table == name { return t, true }
+    }
+    return CatalogType{}, false
+}
+
+// Published restricts a query on a catalog table to rows that are published
+// and whose parents are all published too. Pass it to Scopes, or to Preload
+// for a catalog association.
+func Published(table string) func(db *gorm.DB) *gorm.DB {
+    cond := publishedCondition(table)
+    return func(db *gorm.DB) *gorm.DB { return db.Where(cond) }
+}
+
+func publishedCondition(table string) string {
+    cond := table + ".status = 'published'"
+    if t, ok := FindCatalogType(table); ok && t.ParentTable != "" {
+        cond += " AND " + table + "." + t.ParentColumn + " IN (SELECT id FROM " + t.ParentTable + " WHERE " + publishedCondition(t.ParentTable) + ")"
+    }
+    return cond
+}
+
+// catalogRow points at the fields every catalog model shares.
+type catalogRow struct {
+    ID        *uint
+    State     *models.CatalogState
+    CreatedAt *time.Time
+    Slug      *string
+    Parent    *uint // nil for roots
+}
+
+func catalogFields(item interface{}) catalogRow {
+    switch v := item.(type) {
+    case *models.JobRole:
+        return catalogRow{&v.ID, &v.CatalogState, &v.CreatedAt, &v.Slug, nil}
+    case *models.Responsibility:
+        return catalogRow{&v.ID, &v.CatalogState, &v.CreatedAt, &v.Slug, &v.JobRoleID}
+    case *models.GoalSuggestion:
+        return catalogRow{&v.ID, &v.CatalogState, &v.CreatedAt, &v.Slug, &v.ResponsibilityID}
+    case *models.ProgressSuggestion:
+        return catalogRow{&v.ID, &v.CatalogState, &v.CreatedAt, &v.Slug, &v.GoalSuggestionID}
+    }
+    panic(fmt.Sprintf("not a catalog item: %T", item))
+}
+
+// CatalogItemID returns the primary key of a catalog row.
+func CatalogItemID(item interface{}) uint {
+    return *catalogFields(item).ID
+}
+
+// KeepCatalogFields copies the fields clients may not set (id, workflow
+// state, created_at, framework source) from before onto item after a payload
+// was bound to it, and drops any nested associations the payload carried.
+func KeepCatalogFields(item, before interface{}) {
+    row, prev := catalogFields(item), catalogFields(before)
+    *row.ID, *row.State, *row.CreatedAt = *prev.ID, *prev.State, *prev.CreatedAt
+    switch v := item.(type) {
+    case *models.JobRole:
+        p := before.(*models.JobRole)
+        v.Source, v.SourceID = p.Source, p.SourceID
+        v.Responsibilities = nil
+    case *models.Responsibility:
+        p := before.(*models.Responsibility)
+        v.Source, v.SourceID = p.Source, p.SourceID
+        v.JobRole = models.JobRole{}
+    case *models.GoalSuggestion:
+        v.Responsibility = models.Responsibility{}
+    case *models.ProgressSuggestion:
+        v.GoalSuggestion = models.GoalSuggestion{}
+    }
+}
+
+var (
+    goalSuggestionCategories = []string{"skill", "project", "certification"}
+    suggestionPriorities     = []string{"low", "medium", "high"}
+    percentageRanges         = []string{"0-25", "26-50", "51-75", "76-100"}
+)
+
+// ValidateCatalogItem normalizes item (trimmed text, lower-case categories,
+// defaults) and checks it, including that its parent exists.
+func ValidateCatalogItem(db *gorm.DB, t CatalogType, item interface{}) []FieldError {
+    var errs []FieldError
+    add := func(field, code string, args ...interface{}) {
+        errs = append(errs, NewFieldError(field, code, args...))
+    }
+    text := func(field string, s *string, min, max int) {
+        *s = strings.TrimSpace(*s)
+        switch {
+        case min > 0 && *s == "":
+            add(field, "value_required")
+        case len(*s) > max:
+            add(field, "value_too_long", max)
+        }
+    }
+    oneOf := func(field string, s *string, allowed []string, def string) {
+        *s = strings.ToLower(strings.TrimSpace(*s))
+        if *s == "" { *s = def }
+        for _, a := range allowed {
+            if *s == a { return }
+        }
+        add(field, "value_not_one_of", strings.Join(allowed, ", "))
+    }
+
+    switch v := item.(type) {
+    case *models.JobRole:
+        text("title", &v.Title, 1, 100)
+        text("description", &v.Description, 0, 1000)
+        if v.Title != "" {
+            var n int64
+            db.Model(&models.JobRole{}).Where("LOWER(title) = LOWER(?) AND id <> ?", v.Title, v.ID).Count(&n)
+            if n > 0 { add("title", "value_taken", i18n.Code("catalog_type_"+t.Table)) }
+        }
+    case *models.Responsibility:
+        text("title", &v.Title, 1, 200)
+        text("description", &v.Description, 0, 1000)
+        v.Category = strings.ToLower(strings.TrimSpace(v.Category))
+        if v.Category == "" { v.Category = "general" }
+        text("category", &v.Category, 1, 50)
+    case *models.GoalSuggestion:
+        text("title", &v.Title, 1, 200)
+        text("description", &v.Description, 0, 1000)
+        oneOf("category", &v.Category, goalSuggestionCategories, "skill")
+        oneOf("priority", &v.Priority, suggestionPriorities, "medium")
+        text("estimated_duration", &v.EstimatedDuration, 0, 50)
+    case *models.ProgressSuggestion:
+        text("progress_stage", &v.ProgressStage, 1, 100)
+        text("suggested_outcome", &v.SuggestedOutcome, 1, 500)
+        text("action_prompt", &v.ActionPrompt, 0, 500)
+        text("next_step_prompt", &v.NextStepPrompt, 0, 500)
+        oneOf("percentage_range", &v.PercentageRange, percentageRanges, "0-25")
+    }
+
+    row := catalogFields(item)
+    if *row.Slug = SkillSlug(*row.Slug); *row.Slug != "" {
+        var n int64
+        db.Table(t.Table).Where("slug = ? AND id <> ?", *row.Slug, *row.ID).Count(&n)
+        if n > 0 { add("slug", "value_taken", i18n.Code("catalog_type_"+t.Table)) }
+    }
+    if t.ParentTable != "" {
+        var n int64
+        if *row.Parent != 0 { db.Table(t.ParentTable).Where("id = ?", *row.Parent).Count(&n) }
+        if n == 0 {
+            p, _ := FindCatalogType(t.ParentTable)
+            add(t.ParentColumn, "value_unknown_reference", i18n.Code("catalog_type_"+p.Table))
+        }
+    }
+    return errs
+}
+
+// SaveCatalogItem creates (zero id, as a draft) or updates item and appends
+// an audit entry. before is nil on create.
+func SaveCatalogItem(db *gorm.DB, t CatalogType, item, before interface{}, actorID string) (*models.CatalogAuditEntry, error) {
+    row := catalogFields(item)
+    action := "update"
+    if *row.ID == 0 {
+        action = "create"
+        *row.State = models.CatalogState{Status: "draft"}
+    }
+    var entry *models.CatalogAuditEntry
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Omit(clause.Associations).Save(item).Error; err != nil { return err }
+        var err error
+        entry, err = recordCatalogAudit(tx, t, *row.ID, action, before, item, actorID)
+        return err
+    })
+    if err == nil { InvalidateSuggestionIndex() }
+    return entry, err
+}
+
+// SetCatalogStatus publishes or unpublishes a row. Publishing requires the
+// parent to be published; unpublishing a parent hides its published children
+// from the public routes without changing them.
+func SetCatalogStatus(db *gorm.DB, t CatalogType, id uint, status, actorID string, now time.Time) (interface{}, error) {
+    item := t.New()
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(item, id).Error; err != nil { return err }
+        row := catalogFields(item)
+        if row.State.Status == status { return ErrCatalogStatusUnchanged }
+        if status == "published" && t.ParentTable != "" {
+            var n int64
+            tx.Table(t.ParentTable).Where("id = ? AND status = 'published'", *row.Parent).Count(&n)
+            if n == 0 { return ErrCatalogParentDraft }
+        }
+        before := t.New()
+        b, _ := json.Marshal(item)
+        _ = json.Unmarshal(b, before)
+
+        updates := map[string]interface{}{"status": status}
+        action := "unpublish"
+        if status == "published" {
+            updates["published_at"] = now
+            action = "publish"
+        }
+        if err := tx.Model(item).Updates(updates).Error; err != nil { return err }
+        if err := tx.First(item, id).Error; err != nil { return err }
+        _, err := recordCatalogAudit(tx, t, id, action, before, item, actorID)
+        return err
+    })
+    if err == nil { InvalidateSuggestionIndex() }
+    return item, err
+}
+
+// DeleteCatalogItem removes a row and its translations unless other rows
+// still reference it (ErrCatalogInUse, with the blocking counts by table).
+func DeleteCatalogItem(db *gorm.DB, t CatalogType, id uint, actorID string) (map[string]int64, error) {
+    refs := map[string]int64{}
+    err := db.Transaction(func(tx *gorm.DB) error {
+        item := t.New()
+        if err := tx.First(item, id).Error; err != nil { return err }
+        for _, r := range t.Refs {
+            var n int64
+            if err := tx.Table(r.Table).Where(r.Column+" = ?", id).Count(&n).Error; err != nil { return err }
+            if n > 0 { refs[r.Table] = n }
+        }
+        if len(refs) > 0 { return ErrCatalogInUse }
+        for _, r := range t.Cascade {
+            if err := tx.Exec("DELETE FROM "+r.Table+" WHERE "+r.Column+" = ?", id).Error; err != nil { return err }
+        }
+        if err := deleteCatalogTranslations(tx, t, id); err != nil { return err }
+        if err := tx.Delete(item).Error; err != nil { return err }
+        _, err := recordCatalogAudit(tx, t, id, "delete", item, nil, actorID)
+        return err
+    })
+    if err == nil { InvalidateSuggestionIndex() }
+    return refs, err
+}
+
+func recordCatalogAudit(tx *gorm.DB, t CatalogType, id uint, action string, before, after interface{}, actorID string) (*models.CatalogAuditEntry, error) {
+    snapshot := after
+    if snapshot == nil { snapshot = before }
+    sb, err := json.Marshal(snapshot)
+    if err != nil { return nil, err }
+    changes := []FieldChange{}
+    if before != nil && after != nil {
+        for _, ch := range DiffJSON(before, after) {
+            if ch.Field == "updated_at" { continue }
+            changes = append(changes, ch)
+        }
+    }
+    cb, err := json.Marshal(changes)
+    if err != nil { return nil, err }
+    entry := &models.CatalogAuditEntry{
+        EntityType: t.Name, EntityID: id, Action: action,
+        Snapshot: string(sb), Changes: string(cb),
+    }
+    if actorID != "" { entry.ActorID = &actorID }
+    return entry, tx.Create(entry).Error
+}
+
+// CatalogAuditView is an audit entry with its JSON columns decoded.
+type CatalogAuditView struct {
+    models.CatalogAuditEntry
+    Snapshot interface{}   `json:"snapshot"`
+    Changes  []FieldChange `json:"changes"`
+}
+
+// ViewCatalogAudit decodes the stored JSON columns for API output.
+func ViewCatalogAudit(e models.CatalogAuditEntry) CatalogAuditView {
+    v := CatalogAuditView{CatalogAuditEntry: e, Snapshot: decodeJSONString(e.Snapshot), Changes: []FieldChange{}}
+    if strings.TrimSpace(e.Changes) != "" { _ = json.Unmarshal([]byte(e.Changes), &v.Changes) }
+    return v
+}
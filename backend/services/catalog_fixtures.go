@@ -0,0 +1,652 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "goaltracker/fixtures"
+    "goaltracker/i18n"
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// CatalogFixtureVersion is the fixture format version this build reads.
+const CatalogFixtureVersion = 1
+
+// CatalogFixture is one pack of catalog rows. Rows are keyed by slug and refer
+// to their parent by slug, so a pack may extend or override rows from packs
+// applied before it. Fixtures never delete rows; unpublish them instead.
+//
+//   version: 1
+//   pack: acme
+//   job_roles:
+//     - slug: data-engineer
+//       title: Data Engineer
+//   responsibilities:
+//     - slug: data-pipelines
+//       job_role: data-engineer
+//       title: Data Pipelines
+//       category: technical
+//   goal_suggestions:
+//     - slug: learn-airflow
+//       responsibility: data-pipelines
+//       title: Learn Airflow
+//       category: skill
+//       priority: high
+//   progress_suggestions:
+//     - slug: learn-airflow-0-25
+//       goal_suggestion: learn-airflow
+//       percentage_range: "0-25"
+//       progress_stage: Getting Started
+//       suggested_outcome: Run a first DAG locally
+//       translations:
+//         de:
+//           progress_stage: Erste Schritte
+//
+// Job roles and responsibilities imported from a competency framework
+// carry source and source_id (see ParseNICE and friends). Any row may carry
+// translations (locale -> field -> text) of its
+// translatable fields; see TranslatableFields. A pack only adds or changes
+// the translations it lists.
+type CatalogFixture struct {
+    Version             int                         `json:"version" yaml:"version"`
+    Pack                string                      `json:"pack" yaml:"pack"`
+    JobRoles            []FixtureJobRole            `json:"job_roles" yaml:"job_roles,omitempty"`
+    Responsibilities    []FixtureResponsibility     `json:"responsibilities" yaml:"responsibilities,omitempty"`
+    GoalSuggestions     []FixtureGoalSuggestion     `json:"goal_suggestions" yaml:"goal_suggestions,omitempty"`
+    ProgressSuggestions []FixtureProgressSuggestion `json:"progress_suggestions" yaml:"progress_suggestions,omitempty"`
+}
+
+// Status on a fixture row is optional: new rows default to published, and
+// existing rows keep whatever status an admin gave them unless it is set.
+type FixtureJobRole struct {
+    Slug         string         `json:"slug" yaml:"slug"`
+    Title        string         `json:"title" yaml:"title"`
+    Description  string         `json:"description" yaml:"description,omitempty"`
+    Source       string         `json:"source,omitempty" yaml:"source,omitempty"`
+    SourceID     string         `json:"source_id,omitempty" yaml:"source_id,omitempty"`
+    Status       string         `json:"status,omitempty" yaml:"status,omitempty"`
+    Translations CatalogLocales `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+type FixtureResponsibility struct {
+    Slug         string         `json:"slug" yaml:"slug"`
+    JobRole      string         `json:"job_role" yaml:"job_role"`
+    Title        string         `json:"title" yaml:"title"`
+    Description  string         `json:"description" yaml:"description,omitempty"`
+    Category     string         `json:"category" yaml:"category,omitempty"`
+    Source       string         `json:"source,omitempty" yaml:"source,omitempty"`
+    SourceID     string         `json:"source_id,omitempty" yaml:"source_id,omitempty"`
+    Status       string         `json:"status,omitempty" yaml:"status,omitempty"`
+    Translations CatalogLocales `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+type FixtureGoalSuggestion struct {
+    Slug              string         `json:"slug" yaml:"slug"`
+    Responsibility    string         `json:"responsibility" yaml:"responsibility"`
+    Title             string         `json:"title" yaml:"title"`
+    Description       string         `json:"description" yaml:"description,omitempty"`
+    Category          string         `json:"category" yaml:"category,omitempty"`
+    Priority          string         `json:"priority" yaml:"priority,omitempty"`
+    EstimatedDuration string         `json:"estimated_duration" yaml:"estimated_duration,omitempty"`
+    Status            string         `json:"status,omitempty" yaml:"status,omitempty"`
+    Translations      CatalogLocales `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+type FixtureProgressSuggestion struct {
+    Slug             string         `json:"slug" yaml:"slug"`
+    GoalSuggestion   string         `json:"goal_suggestion" yaml:"goal_suggestion"`
+    ProgressStage    string         `json:"progress_stage" yaml:"progress_stage"`
+    SuggestedOutcome string         `json:"suggested_outcome" yaml:"suggested_outcome"`
+    ActionPrompt     string         `json:"action_prompt" yaml:"action_prompt,omitempty"`
+    NextStepPrompt   string         `json:"next_step_prompt" yaml:"next_step_prompt,omitempty"`
+    PercentageRange  string         `json:"percentage_range" yaml:"percentage_range"`
+    Status           string         `json:"status,omitempty" yaml:"status,omitempty"`
+    Translations     CatalogLocales `json:"translations,omitempty" yaml:"translations,omitempty"`
+}
+
+// CatalogFixtureError reports why a pack could not be applied.
+type CatalogFixtureError struct {
+    Pack   string       `json:"pack"`
+    Fields []FieldError `json:"fields"`
+}
+
+func (e *CatalogFixtureError) Error() string {
+    msgs := make([]string, 0, len(e.Fields))
+    for _, f := range e.Fields { msgs = append(msgs, f.Field+": "+f.Text(i18n.Default)) }
+    return fmt.Sprintf("catalog pack %q: %s", e.Pack, strings.Join(msgs, "; "))
+}
+
+// ParseCatalogFixture decodes a pack from YAML or JSON (see ParseLadder for
+// format handling) and checks it on its own; parent slugs are resolved when
+// it is applied.
+func ParseCatalogFixture(body []byte, format string) (CatalogFixture, error) {
+    var fx CatalogFixture
+    if err := decodeDocument(body, format, &fx); err != nil { return fx, err }
+    if errs := validateCatalogFixture(&fx); len(errs) > 0 { return fx, &CatalogFixtureError{Pack: fx.Pack, Fields: errs} }
+    return fx, nil
+}
+
+func validateCatalogFixture(fx *CatalogFixture) []FieldError {
+    var errs []FieldError
+    add := func(field, code string, args ...interface{}) {
+        errs = append(errs, NewFieldError(field, code, args...))
+    }
+    if fx.Version != CatalogFixtureVersion { add("version", "value_not_equal", CatalogFixtureVersion) }
+    if fx.Pack = strings.TrimSpace(fx.Pack); fx.Pack == "" { add("pack", "value_required") }
+    check := func(list string, seen map[string]bool, i int, slug *string, status string, parentField string, parent *string, translations *CatalogLocales) {
+        field := fmt.Sprintf("%s[%d]", list, i)
+        t, _ := FindCatalogType(list)
+        canonical := CatalogLocales{}
+        locales := make([]string, 0, len(*translations))
+        for locale := range *translations { locales = append(locales, locale) }
+        sort.Strings(locales)
+        for _, locale := range locales {
+            fields := (*translations)[locale]
+            l, fieldErrs := ValidateCatalogTranslations(t, locale, fields)
+            for _, fe := range fieldErrs {
+                path := field + ".translations." + locale
+                if fe.Field != "locale" { path += "." + fe.Field }
+                add(path, fe.Code, fe.Args...)
+            }
+            if len(fieldErrs) == 0 { canonical[l] = fields }
+        }
+        *translations = canonical
+        if *slug = SkillSlug(*slug); *slug == "" {
+            add(field+".slug", "value_required")
+        } else if seen[*slug] {
+            add(field+".slug", "value_duplicate", *slug)
+        }
+        seen[*slug] = true
+        if status != "" && status != "draft" && status != "published" { add(field+".status", "value_not_one_of", "draft, published") }
+        if parent != nil {
+            if *parent = SkillSlug(*parent); *parent == "" { add(field+"."+parentField, "value_required") }
+        }
+    }
+    seen := map[string]bool{}
+    for i := range fx.JobRoles {
+        r := &fx.JobRoles[i]
+        check("job_roles", seen, i, &r.Slug, r.Status, "", nil, &r.Translations)
+    }
+    seen = map[string]bool{}
+    for i := range fx.Responsibilities {
+        r := &fx.Responsibilities[i]
+        check("responsibilities", seen, i, &r.Slug, r.Status, "job_role", &r.JobRole, &r.Translations)
+    }
+    seen = map[string]bool{}
+    for i := range fx.GoalSuggestions {
+        g := &fx.GoalSuggestions[i]
+        check("goal_suggestions", seen, i, &g.Slug, g.Status, "responsibility", &g.Responsibility, &g.Translations)
+    }
+    seen = map[string]bool{}
+    for i := range fx.ProgressSuggestions {
+        p := &fx.ProgressSuggestions[i]
+        check("progress_suggestions", seen, i, &p.Slug, p.Status, "goal_suggestion", &p.GoalSuggestion, &p.Translations)
+    }
+    return errs
+}
+
+// ReadCatalogFixtures parses the packs at paths, in order. A directory
+// contributes its *.yaml, *.yml and *.json files in name order.
+func ReadCatalogFixtures(paths []string) ([]CatalogFixture, error) {
+    var packs []CatalogFixture
+    for _, p := range paths {
+        if p = strings.TrimSpace(p); p == "" { continue }
+        info, err := os.Stat(p)
+        if err != nil { return nil, err }
+        files := []string{p}
+        if info.IsDir() {
+            entries, err := os.ReadDir(p)
+            if err != nil { return nil, err }
+            files = files[:0]
+            for _, e := range entries {
+                if !e.IsDir() && fixtureFormat(e.Name()) != "" { files = append(files, filepath.Join(p, e.Name())) }
+            }
+        }
+        for _, f := range files {
+            body, err := os.ReadFile(f)
+            if err != nil { return nil, err }
+            fx, err := ParseCatalogFixture(body, fixtureFormat(f))
+            if err != nil { return nil, fmt.Errorf("%s: %w", f, err) }
+            packs = append(packs, fx)
+        }
+    }
+    return packs, nil
+}
+
+// ReadCatalogFixturesFS parses every pack in dir of fsys, in name order.
+func ReadCatalogFixturesFS(fsys fs.FS, dir string) ([]CatalogFixture, error) {
+    entries, err := fs.ReadDir(fsys, dir)
+    if err != nil { return nil, err }
+    var packs []CatalogFixture
+    for _, e := range entries {
+        if e.IsDir() || fixtureFormat(e.Name()) == "" { continue }
+        body, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+        if err != nil { return nil, err }
+        fx, err := ParseCatalogFixture(body, fixtureFormat(e.Name()))
+        if err != nil { return nil, fmt.Errorf("%s: %w", e.Name(), err) }
+        packs = append(packs, fx)
+    }
+    return packs, nil
+}
+
+func fixtureFormat(name string) string {
+    switch strings.ToLower(filepath.Ext(name)) {
+    case ".yaml", ".yml":
+        return "yaml"
+    case ".json":
+        return "json"
+    }
+    return ""
+}
+
+// FixtureCounts tallies what applying fixtures did to one catalog type.
+type FixtureCounts struct {
+    Created   int `json:"created"`
+    Updated   int `json:"updated"`
+    Unchanged int `json:"unchanged"`
+}
+
+// FixtureChange is one row a fixture load created or updated.
+type FixtureChange struct {
+    Pack    string        `json:"pack"`
+    Type    string        `json:"type"`
+    Slug    string        `json:"slug"`
+    ID      uint          `json:"id"`
+    Action  string        `json:"action"` // create, update or adopt
+    Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// CatalogFixtureReport summarizes a fixture load. Counts are keyed by catalog
+// type (job-roles, ...).
+type CatalogFixtureReport struct {
+    DryRun  bool                      `json:"dry_run"`
+    Packs   []string                  `json:"packs"`
+    Counts  map[string]*FixtureCounts `json:"counts"`
+    Changes []FixtureChange           `json:"changes"`
+}
+
+// Changed reports whether the load created or updated anything.
+func (r *CatalogFixtureReport) Changed() bool { return len(r.Changes) > 0 }
+
+// errFixtureDryRun rolls back a dry run's transaction.
+var errFixtureDryRun = errors.New("dry run")
+
+// ApplyCatalogFixtures upserts the packs in order inside one transaction.
+// Rows are matched by slug; a row without a slug that has the same title
+// under the same parent (or, for progress suggestions, the same percentage
+// range) is adopted and given the slug, so databases seeded before fixtures
+// existed converge instead of gaining duplicates. On existing rows only the
+// fields whose fixture value changed since it was last applied are written,
+// so edits made through the admin API survive reseeding. Rows from before
+// this was tracked take a fixture value where they still hold the value the
+// server originally seeded (see fixtures.LegacySeed), and keep it where an
+// admin changed it. Every create and update is written to the catalog audit
+// trail. With dryRun nothing is written.
+func ApplyCatalogFixtures(db *gorm.DB, packs []CatalogFixture, dryRun bool, now time.Time) (*CatalogFixtureReport, error) {
+    report := &CatalogFixtureReport{DryRun: dryRun, Packs: []string{}, Counts: map[string]*FixtureCounts{}, Changes: []FixtureChange{}}
+    for _, t := range CatalogTypes { report.Counts[t.Name] = &FixtureCounts{} }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        for _, fx := range packs {
+            report.Packs = append(report.Packs, fx.Pack)
+            if err := applyCatalogFixture(tx, fx, report, now); err != nil { return err }
+        }
+        if dryRun { return errFixtureDryRun }
+        return nil
+    })
+    if errors.Is(err, errFixtureDryRun) { err = nil }
+    if err != nil { return nil, err }
+    if !dryRun && report.Changed() { InvalidateSuggestionIndex() }
+    return report, nil
+}
+
+// fixtureRow is one fixture entry ready to upsert.
+type fixtureRow struct {
+    field   string // for errors, e.g. responsibilities[3]
+    slug    string
+    parent  string // parent slug; empty for roots
+    parentField string
+    status  string
+    translations CatalogLocales
+    natural func(tx *gorm.DB, parentID uint) *gorm.DB // matches an unslugged row to adopt
+    fields  map[string]string                         // fixture values by JSON field name
+}
+
+// catalogFixtureRows flattens a pack into rows by catalog type.
+func catalogFixtureRows(fx CatalogFixture) map[string][]fixtureRow {
+    byTitle := func(column string) func(string) func(*gorm.DB, uint) *gorm.DB {
+        return func(title string) func(*gorm.DB, uint) *gorm.DB {
+            return func(q *gorm.DB, parentID uint) *gorm.DB {
+                q = q.Where("LOWER(title) = LOWER(?)", strings.TrimSpace(title))
+                if column != "" { q = q.Where(column+" = ?", parentID) }
+                return q
+            }
+        }
+    }
+    rows := map[string][]fixtureRow{}
+    for i, r := range fx.JobRoles {
+        r := r
+        rows["job-roles"] = append(rows["job-roles"], fixtureRow{
+            field: fmt.Sprintf("job_roles[%d]", i), slug: r.Slug, status: r.Status, translations: r.Translations, natural: byTitle("")(r.Title),
+            fields: map[string]string{"title": r.Title, "description": r.Description, "source": r.Source, "source_id": r.SourceID},
+        })
+    }
+    for i, r := range fx.Responsibilities {
+        r := r
+        rows["responsibilities"] = append(rows["responsibilities"], fixtureRow{
+            field: fmt.Sprintf("responsibilities[%d]", i), slug: r.Slug, parent: r.JobRole, parentField: "job_role", status: r.Status, translations: r.Translations, natural: byTitle("job_role_id")(r.Title),
+            fields: map[string]string{"title": r.Title, "description": r.Description, "category": r.Category, "source": r.Source, "source_id": r.SourceID},
+        })
+    }
+    for i, g := range fx.GoalSuggestions {
+        g := g
+        rows["goal-suggestions"] = append(rows["goal-suggestions"], fixtureRow{
+            field: fmt.Sprintf("goal_suggestions[%d]", i), slug: g.Slug, parent: g.Responsibility, parentField: "responsibility", status: g.Status, translations: g.Translations, natural: byTitle("responsibility_id")(g.Title),
+            fields: map[string]string{"title": g.Title, "description": g.Description, "category": g.Category, "priority": g.Priority, "estimated_duration": g.EstimatedDuration},
+        })
+    }
+    for i, p := range fx.ProgressSuggestions {
+        p := p
+        rows["progress-suggestions"] = append(rows["progress-suggestions"], fixtureRow{
+            field: fmt.Sprintf("progress_suggestions[%d]", i), slug: p.Slug, parent: p.GoalSuggestion, parentField: "goal_suggestion", status: p.Status, translations: p.Translations,
+            natural: func(q *gorm.DB, parentID uint) *gorm.DB {
+                return q.Where("goal_suggestion_id = ? AND percentage_range = ?", parentID, strings.TrimSpace(p.PercentageRange))
+            },
+            fields: map[string]string{
+                "progress_stage": p.ProgressStage, "suggested_outcome": p.SuggestedOutcome, "action_prompt": p.ActionPrompt,
+                "next_step_prompt": p.NextStepPrompt, "percentage_range": p.PercentageRange,
+            },
+        })
+    }
+
+    return rows
+}
+
+func applyCatalogFixture(tx *gorm.DB, fx CatalogFixture, report *CatalogFixtureReport, now time.Time) error {
+    var errs []FieldError
+    rows := catalogFixtureRows(fx)
+    for _, t := range CatalogTypes {
+        for _, r := range rows[t.Name] {
+            change, fieldErrs, err := upsertFixtureRow(tx, t, r, now)
+            if err != nil { return fmt.Errorf("catalog pack %q %s: %w", fx.Pack, r.field, err) }
+            if len(fieldErrs) > 0 {
+                errs = append(errs, fieldErrs...)
+                continue
+            }
+            counts := report.Counts[t.Name]
+            switch {
+            case change == nil:
+                counts.Unchanged++
+                continue
+            case change.Action == "create":
+                counts.Created++
+            default:
+                counts.Updated++
+            }
+            change.Pack = fx.Pack
+            report.Changes = append(report.Changes, *change)
+        }
+    }
+    if len(errs) > 0 { return &CatalogFixtureError{Pack: fx.Pack, Fields: errs} }
+    return nil
+}
+
+// upsertFixtureRow creates or updates one row. It returns nil when nothing
+// visible changed.
+func upsertFixtureRow(tx *gorm.DB, t CatalogType, r fixtureRow, now time.Time) (*FixtureChange, []FieldError, error) {
+    var parentID uint
+    if t.ParentTable != "" {
+        var ids []uint
+        if err := tx.Table(t.ParentTable).Where("slug = ?", r.parent).Pluck("id", &ids).Error; err != nil { return nil, nil, err }
+        if len(ids) == 0 {
+            parent, _ := FindCatalogType(t.ParentTable)
+            return nil, []FieldError{NewFieldError(r.field+"."+r.parentField, "value_unknown_slug", i18n.Code("catalog_type_"+parent.Table), r.parent)}, nil
+        }
+        parentID = ids[0]
+    }
+
+    item := t.New()
+    action := "update"
+    res := tx.Where("slug = ?", r.slug).Limit(1).Find(item)
+    if res.Error != nil { return nil, nil, res.Error }
+    if res.RowsAffected == 0 {
+        action = "adopt"
+        res = r.natural(tx.Where("slug IS NULL OR slug = ''"), parentID).Order("id").Limit(1).Find(item)
+        if res.Error != nil { return nil, nil, res.Error }
+        if res.RowsAffected == 0 { action = "create" }
+    }
+    var before interface{}
+    existing := CatalogLocales{}
+    if action != "create" {
+        before = t.New()
+        b, _ := json.Marshal(item)
+        _ = json.Unmarshal(b, before)
+        byID, err := loadCatalogTranslations(tx, t, []uint{CatalogItemID(item)}, "")
+        if err != nil { return nil, nil, err }
+        if byID[CatalogItemID(item)] != nil { existing = byID[CatalogItemID(item)] }
+    }
+    row := catalogFields(item)
+    prevApplied := row.State.FixtureApplied
+    applied := map[string]string{}
+    legacy := action != "create" && prevApplied == ""
+    if !legacy {
+        if err := json.Unmarshal([]byte(prevApplied), &applied); err != nil { return nil, nil, err }
+    }
+    seeded := legacySeedValues(t, r.slug)
+    desired := map[string]string{}
+    // changed reports whether the fixture's value for key is new to the row:
+    // always on create, never on rows that predate tracking, otherwise when
+    // it differs from the value applied last time
+    changed := func(key, value string) bool {
+        desired[key] = value
+        if action == "create" { return true }
+        if legacy { return false }
+        prev, ok := applied[key]
+        return !ok || prev != value
+    }
+
+    fields := fixtureFields(item)
+    for k, v := range r.fields {
+        if changed(k, v) || legacy && *fields[k] == seeded[k] { *fields[k] = v }
+    }
+    translations := CatalogLocales{}
+    for l, f := range existing { translations[l] = f }
+    for l, f := range r.translations {
+        merged := map[string]string{}
+        for k, v := range existing[l] { merged[k] = v }
+        for k, v := range trimTranslations(f) {
+            // the original seed had no translations, so legacy rows take any they lack
+            if changed("translations."+l+"."+k, v) || legacy && merged[k] == "" { merged[k] = v }
+        }
+        translations[l] = merged
+    }
+
+    *row.Slug = r.slug
+    if row.Parent != nil && changed("parent", r.parent) { *row.Parent = parentID }
+    setStatus := r.status != "" && changed("status", r.status)
+    switch {
+    case action == "create" && r.status == "draft":
+        *row.State = models.CatalogState{Status: "draft"}
+    case action == "create", setStatus && r.status == "published" && row.State.Status != "published":
+        published := now
+        *row.State = models.CatalogState{Status: "published", PublishedAt: &published}
+    case setStatus && r.status == "draft":
+        row.State.Status = "draft"
+    }
+    b, _ := json.Marshal(desired)
+    row.State.FixtureApplied = string(b)
+    if errs := ValidateCatalogItem(tx, t, item); len(errs) > 0 {
+        for i := range errs { errs[i].Field = r.field + "." + errs[i].Field }
+        return nil, errs, nil
+    }
+
+    var beforeSnap interface{}
+    if before != nil { beforeSnap = catalogSnapshot(before, existing) }
+    afterSnap := catalogSnapshot(item, translations)
+    changes := []FieldChange{}
+    if before != nil {
+        for _, ch := range DiffJSON(beforeSnap, afterSnap) {
+            if ch.Field != "updated_at" { changes = append(changes, ch) }
+        }
+        if len(changes) == 0 {
+            if row.State.FixtureApplied == prevApplied { return nil, nil, nil }
+            return nil, nil, tx.Model(item).UpdateColumn("fixture_applied", row.State.FixtureApplied).Error
+        }
+    }
+    if err := tx.Omit(clause.Associations).Save(item).Error; err != nil { return nil, nil, err }
+    for l := range r.translations {
+        if err := writeCatalogTranslations(tx, t, *row.ID, l, translations[l]); err != nil { return nil, nil, err }
+    }
+    auditAction := action
+    if auditAction == "adopt" { auditAction = "update" }
+    if _, err := recordCatalogAudit(tx, t, *row.ID, auditAction, beforeSnap, afterSnap, ""); err != nil { return nil, nil, err }
+    return &FixtureChange{Type: t.Name, Slug: r.slug, ID: *row.ID, Action: action, Changes: changes}, nil, nil
+}
+
+// fixtureFields returns item's fixture-managed fields by JSON name (the keys
+// of fixtureRow.fields).
+func fixtureFields(item interface{}) map[string]*string {
+    switch v := item.(type) {
+    case *models.JobRole:
+        return map[string]*string{"title": &v.Title, "description": &v.Description, "source": &v.Source, "source_id": &v.SourceID}
+    case *models.Responsibility:
+        return map[string]*string{"title": &v.Title, "description": &v.Description, "category": &v.Category, "source": &v.Source, "source_id": &v.SourceID}
+    case *models.GoalSuggestion:
+        return map[string]*string{"title": &v.Title, "description": &v.Description, "category": &v.Category, "priority": &v.Priority, "estimated_duration": &v.EstimatedDuration}
+    case *models.ProgressSuggestion:
+        return map[string]*string{
+            "progress_stage": &v.ProgressStage, "suggested_outcome": &v.SuggestedOutcome, "action_prompt": &v.ActionPrompt,
+            "next_step_prompt": &v.NextStepPrompt, "percentage_range": &v.PercentageRange,
+        }
+    }
+    panic(fmt.Sprintf("not a catalog item: %T", item))
+}
+
+var (
+    legacySeedOnce sync.Once
+    legacySeed     map[string]map[string]string // "type/slug" -> field -> value
+)
+
+// legacySeedValues returns the fields the server seeded for the row before
+// fixture packs existed; rows it never seeded get none, so only their empty
+// fields count as untouched.
+func legacySeedValues(t CatalogType, slug string) map[string]string {
+    legacySeedOnce.Do(func() {
+        legacySeed = map[string]map[string]string{}
+        fx, err := ParseCatalogFixture(fixtures.LegacySeed, "yaml")
+        if err != nil { panic(fmt.Sprintf("legacy catalog seed: %v", err)) }
+        for name, rows := range catalogFixtureRows(fx) {
+            for _, r := range rows { legacySeed[name+"/"+r.slug] = r.fields }
+        }
+    })
+    return legacySeed[t.Name+"/"+slug]
+}
+
+// ExportCatalogFixture dumps the catalog as a fixture pack that
+// ApplyCatalogFixtures reads back. Drafts are included (with their status)
+// when includeDrafts is set, and so are translations. Rows without a slug get
+// one derived from their title, so exporting and re-importing converges on
+// the same rows.
+func ExportCatalogFixture(db *gorm.DB, pack string, includeDrafts bool) (CatalogFixture, error) {
+    fx := CatalogFixture{Version: CatalogFixtureVersion, Pack: pack}
+    scope := func(q *gorm.DB) *gorm.DB {
+        if includeDrafts { return q }
+        return q.Where("status = 'published'")
+    }
+    status := func(s models.CatalogState) string {
+        if s.Status == "draft" { return "draft" }
+        return ""
+    }
+    // slugger derives missing slugs for one table; stored slugs are reserved
+    // up front so a derived one never collides with them.
+    slugger := func(stored []string) func(id uint, slug, base string) string {
+        used := map[string]bool{}
+        for _, s := range stored { used[s] = true }
+        return func(id uint, slug, base string) string {
+            if slug != "" { return slug }
+            if slug = SkillSlug(base); slug == "" || used[slug] { slug = strings.TrimPrefix(fmt.Sprintf("%s-%d", slug, id), "-") }
+            used[slug] = true
+            return slug
+        }
+    }
+    translations := map[string]map[uint]CatalogLocales{}
+    for _, t := range CatalogTypes {
+        byID, err := loadCatalogTranslations(db, t, nil, "")
+        if err != nil { return fx, err }
+        translations[t.Name] = byID
+    }
+
+    var roles []models.JobRole
+    if err := scope(db).Order("id").Find(&roles).Error; err != nil { return fx, err }
+    stored := []string{}
+    for _, r := range roles { stored = append(stored, r.Slug) }
+    slugFor := slugger(stored)
+    roleSlugs := map[uint]string{}
+    for _, r := range roles {
+        roleSlugs[r.ID] = slugFor(r.ID, r.Slug, r.Title)
+        fx.JobRoles = append(fx.JobRoles, FixtureJobRole{
+            Slug: roleSlugs[r.ID], Title: r.Title, Description: r.Description, Source: r.Source, SourceID: r.SourceID,
+            Status: status(r.CatalogState),
+            Translations: translations["job-roles"][r.ID],
+        })
+    }
+
+    var resps []models.Responsibility
+    if err := scope(db).Order("id").Find(&resps).Error; err != nil { return fx, err }
+    stored = stored[:0]
+    for _, r := range resps { stored = append(stored, r.Slug) }
+    slugFor = slugger(stored)
+    respSlugs := map[uint]string{}
+    for _, r := range resps {
+        if roleSlugs[r.JobRoleID] == "" { continue }
+        respSlugs[r.ID] = slugFor(r.ID, r.Slug, r.Title)
+        fx.Responsibilities = append(fx.Responsibilities, FixtureResponsibility{
+            Slug: respSlugs[r.ID], JobRole: roleSlugs[r.JobRoleID], Title: r.Title, Description: r.Description,
+            Category: r.Category, Source: r.Source, SourceID: r.SourceID, Status: status(r.CatalogState),
+            Translations: translations["responsibilities"][r.ID],
+        })
+    }
+
+    var goals []models.GoalSuggestion
+    if err := scope(db).Order("id").Find(&goals).Error; err != nil { return fx, err }
+    stored = stored[:0]
+    for _, g := range goals { stored = append(stored, g.Slug) }
+    slugFor = slugger(stored)
+    goalSlugs := map[uint]string{}
+    for _, g := range goals {
+        if respSlugs[g.ResponsibilityID] == "" { continue }
+        goalSlugs[g.ID] = slugFor(g.ID, g.Slug, g.Title)
+        fx.GoalSuggestions = append(fx.GoalSuggestions, FixtureGoalSuggestion{
+            Slug: goalSlugs[g.ID], Responsibility: respSlugs[g.ResponsibilityID], Title: g.Title, Description: g.Description,
+            Category: g.Category, Priority: g.Priority, EstimatedDuration: g.EstimatedDuration, Status: status(g.CatalogState),
+            Translations: translations["goal-suggestions"][g.ID],
+        })
+    }
+
+    var progress []models.ProgressSuggestion
+    if err := scope(db).Order("id").Find(&progress).Error; err != nil { return fx, err }
+    stored = stored[:0]
+    for _, p := range progress { stored = append(stored, p.Slug) }
+    slugFor = slugger(stored)
+    for _, p := range progress {
+        if goalSlugs[p.GoalSuggestionID] == "" { continue }
+        fx.ProgressSuggestions = append(fx.ProgressSuggestions, FixtureProgressSuggestion{
+            Slug: slugFor(p.ID, p.Slug, goalSlugs[p.GoalSuggestionID]+"-"+p.PercentageRange),
+            GoalSuggestion: goalSlugs[p.GoalSuggestionID], ProgressStage: p.ProgressStage, SuggestedOutcome: p.SuggestedOutcome,
+            ActionPrompt: p.ActionPrompt, NextStepPrompt: p.NextStepPrompt, PercentageRange: p.PercentageRange, Status: status(p.CatalogState),
+            Translations: translations["progress-suggestions"][p.ID],
+        })
+    }
+    return fx, nil
+}
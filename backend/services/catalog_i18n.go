@@ -0,0 +1,231 @@
+package services
+
+import (
+    "encoding/json"
+    "reflect"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/i18n"
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// maxTranslationLength bounds one translated field; the English columns are
+// at most 1000 characters and translations run longer.
+const maxTranslationLength = 1500
+
+// CatalogLocales maps locale -> field -> text for one catalog row.
+type CatalogLocales map[string]map[string]string
+
+// translatableFields returns the text fields of a catalog row that take
+// translations, keyed by JSON name. Slugs, categories, priorities and ranges
+// are codes clients match on and stay as they are.
+func translatableFields(item interface{}) map[string]*string {
+    switch v := item.(type) {
+    case *models.JobRole:
+        return map[string]*string{"title": &v.Title, "description": &v.Description}
+    case *models.Responsibility:
+        return map[string]*string{"title": &v.Title, "description": &v.Description}
+    case *models.GoalSuggestion:
+        return map[string]*string{"title": &v.Title, "description": &v.Description, "estimated_duration": &v.EstimatedDuration}
+    case *models.ProgressSuggestion:
+        return map[string]*string{
+            "progress_stage": &v.ProgressStage, "suggested_outcome": &v.SuggestedOutcome,
+            "action_prompt": &v.ActionPrompt, "next_step_prompt": &v.NextStepPrompt,
+        }
+    }
+    return nil
+}
+
+// TranslatableFields lists the fields of t that take translations, sorted.
+func TranslatableFields(t CatalogType) []string {
+    var out []string
+    for f := range translatableFields(t.New()) { out = append(out, f) }
+    sort.Strings(out)
+    return out
+}
+
+// ValidateCatalogTranslations checks one locale's translations for a row of
+// t and returns the locale in canonical form (e.g. "pt-br" -> "pt-BR").
+// English is the row itself and cannot be translated into.
+func ValidateCatalogTranslations(t CatalogType, locale string, fields map[string]string) (string, []FieldError) {
+    var errs []FieldError
+    canonical, ok := i18n.Canonical(locale)
+    if !ok || canonical == i18n.Default {
+        others := []string{}
+        for _, l := range i18n.Supported {
+            if l != i18n.Default { others = append(others, l) }
+        }
+        errs = append(errs, NewFieldError("locale", "value_not_one_of", strings.Join(others, ", ")))
+    }
+    allowed := translatableFields(t.New())
+    for field, value := range fields {
+        switch {
+        case allowed[field] == nil:
+            errs = append(errs, NewFieldError(field, "value_not_translatable", strings.Join(TranslatableFields(t), ", ")))
+        case len(strings.TrimSpace(value)) > maxTranslationLength:
+            errs = append(errs, NewFieldError(field, "value_too_long", maxTranslationLength))
+        }
+    }
+    sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+    return canonical, errs
+}
+
+// CatalogTranslations returns every translation of one row.
+func CatalogTranslations(db *gorm.DB, t CatalogType, id uint) (CatalogLocales, error) {
+    byID, err := loadCatalogTranslations(db, t, []uint{id}, "")
+    if err != nil { return nil, err }
+    if byID[id] == nil { return CatalogLocales{}, nil }
+    return byID[id], nil
+}
+
+// SetCatalogTranslations replaces a row's translations for one locale (nil or
+// empty fields remove them all) and records the change in the catalog audit
+// trail. Blank values remove single fields. Returns the row's translations
+// after the change.
+func SetCatalogTranslations(db *gorm.DB, t CatalogType, id uint, locale string, fields map[string]string, actorID string) (CatalogLocales, error) {
+    var after CatalogLocales
+    err := db.Transaction(func(tx *gorm.DB) error {
+        item := t.New()
+        if err := tx.First(item, id).Error; err != nil { return err }
+        before, err := CatalogTranslations(tx, t, id)
+        if err != nil { return err }
+        after = CatalogLocales{}
+        for l, f := range before {
+            if l != locale { after[l] = f }
+        }
+        if kept := trimTranslations(fields); len(kept) > 0 { after[locale] = kept }
+        if reflect.DeepEqual(before, after) { return nil }
+
+        if err := writeCatalogTranslations(tx, t, id, locale, after[locale]); err != nil { return err }
+        _, err = recordCatalogAudit(tx, t, id, "update", catalogSnapshot(item, before), catalogSnapshot(item, after), actorID)
+        return err
+    })
+    return after, err
+}
+
+// LocalizeCatalog replaces the text of every catalog row reachable from v (a
+// pointer to a row, a slice of rows, or a struct holding either, preloaded
+// parents included) with its translation into locale. Fields without one
+// keep their English text.
+func LocalizeCatalog(db *gorm.DB, locale string, v interface{}) error {
+    if locale == "" || locale == i18n.Default { return nil }
+    rows := map[string][]interface{}{} // catalog type name -> rows
+    collectCatalogRows(reflect.ValueOf(v), rows)
+    for _, t := range CatalogTypes {
+        items := rows[t.Name]
+        if len(items) == 0 { continue }
+        ids := make([]uint, 0, len(items))
+        for _, item := range items { ids = append(ids, CatalogItemID(item)) }
+        byID, err := loadCatalogTranslations(db, t, ids, locale)
+        if err != nil { return err }
+        for _, item := range items {
+            fields := translatableFields(item)
+            for field, text := range byID[CatalogItemID(item)][locale] {
+                if p := fields[field]; p != nil { *p = text }
+            }
+        }
+    }
+    return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func collectCatalogRows(v reflect.Value, rows map[string][]interface{}) {
+    switch v.Kind() {
+    case reflect.Ptr, reflect.Interface:
+        if !v.IsNil() { collectCatalogRows(v.Elem(), rows) }
+    case reflect.Slice, reflect.Array:
+        for i := 0; i < v.Len(); i++ { collectCatalogRows(v.Index(i), rows) }
+    case reflect.Map:
+        for _, k := range v.MapKeys() { collectCatalogRows(v.MapIndex(k), rows) }
+    case reflect.Struct:
+        if v.Type() == timeType { return }
+        if v.CanAddr() {
+            if t, ok := catalogTypeOf(v.Addr().Interface()); ok {
+                rows[t.Name] = append(rows[t.Name], v.Addr().Interface())
+            }
+        }
+        for i := 0; i < v.NumField(); i++ {
+            if v.Type().Field(i).IsExported() { collectCatalogRows(v.Field(i), rows) }
+        }
+    }
+}
+
+func catalogTypeOf(item interface{}) (CatalogType, bool) {
+    switch item.(type) {
+    case *models.JobRole:
+        return FindCatalogType("job_roles")
+    case *models.Responsibility:
+        return FindCatalogType("responsibilities")
+    case *models.GoalSuggestion:
+        return FindCatalogType("goal_suggestions")
+    case *models.ProgressSuggestion:
+        return FindCatalogType("progress_suggestions")
+    }
+    return CatalogType{}, false
+}
+
+// loadCatalogTranslations fetches translations for rows of t, optionally
+// for one locale only, keyed by row id.
+func loadCatalogTranslations(db *gorm.DB, t CatalogType, ids []uint, locale string) (map[uint]CatalogLocales, error) {
+    q := db.Where("entity_type = ?", t.Name)
+    if ids != nil { q = q.Where("entity_id IN ?", ids) }
+    if locale != "" { q = q.Where("locale = ?", locale) }
+    var list []models.CatalogTranslation
+    if err := q.Find(&list).Error; err != nil { return nil, err }
+    out := map[uint]CatalogLocales{}
+    for _, tr := range list {
+        if out[tr.EntityID] == nil { out[tr.EntityID] = CatalogLocales{} }
+        if out[tr.EntityID][tr.Locale] == nil { out[tr.EntityID][tr.Locale] = map[string]string{} }
+        out[tr.EntityID][tr.Locale][tr.Field] = tr.Value
+    }
+    return out, nil
+}
+
+// writeCatalogTranslations makes fields the complete set of translations for
+// one row and locale.
+func writeCatalogTranslations(tx *gorm.DB, t CatalogType, id uint, locale string, fields map[string]string) error {
+    q := tx.Where("entity_type = ? AND entity_id = ? AND locale = ?", t.Name, id, locale)
+    if len(fields) > 0 {
+        keep := make([]string, 0, len(fields))
+        for f := range fields { keep = append(keep, f) }
+        q = q.Where("field NOT IN ?", keep)
+    }
+    if err := q.Delete(&models.CatalogTranslation{}).Error; err != nil { return err }
+    for field, value := range fields {
+        tr := models.CatalogTranslation{EntityType: t.Name, EntityID: id, Locale: locale, Field: field, Value: value}
+        err := tx.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "entity_type"}, {Name: "entity_id"}, {Name: "locale"}, {Name: "field"}},
+            DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+        }).Create(&tr).Error
+        if err != nil { return err }
+    }
+    return nil
+}
+
+func deleteCatalogTranslations(tx *gorm.DB, t CatalogType, id uint) error {
+    return tx.Where("entity_type = ? AND entity_id = ?", t.Name, id).Delete(&models.CatalogTranslation{}).Error
+}
+
+func trimTranslations(fields map[string]string) map[string]string {
+    out := map[string]string{}
+    for f, v := range fields {
+        if v = strings.TrimSpace(v); v != "" { out[f] = v }
+    }
+    return out
+}
+
+// catalogSnapshot is a row as the audit trail records it once translations
+// are involved: its JSON with the translations under "translations".
+func catalogSnapshot(item interface{}, translations CatalogLocales) interface{} {
+    var m map[string]interface{}
+    b, _ := json.Marshal(item)
+    _ = json.Unmarshal(b, &m)
+    if len(translations) > 0 { m["translations"] = translations }
+    return m
+}
@@ -0,0 +1,278 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "log"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// reminderLeads are the days-before-expiry at which reminders are raised.
+// Only the tightest threshold crossed is recorded, so a cert added 20 days
+// before expiry gets one "30d" reminder rather than three.
+var reminderLeads = []struct {
+    Kind string
+    Days int
+}{{"7d", 7}, {"30d", 30}, {"60d", 60}, {"90d", 90}}
+
+// CertificationView is a held certification with its renewal position.
+type CertificationView struct {
+    models.UserCertification
+    Status           string  `json:"status"` // active, expiring, expired, no_expiry
+    DaysToExpiry     *int    `json:"days_to_expiry"`
+    CreditsEarned    float64 `json:"credits_earned"` // this renewal cycle
+    CreditsRemaining float64 `json:"credits_remaining"`
+    CreditsThisYear  float64 `json:"credits_this_year"`
+    AnnualShortfall  float64 `json:"annual_shortfall"`
+}
+
+// DefaultExpiry derives the expiry from the catalog validity period.
+func DefaultExpiry(cert models.Certification, from time.Time) *time.Time {
+    if cert.ValidityMonths <= 0 { return nil }
+    t := truncateDay(from).AddDate(0, cert.ValidityMonths, 0)
+    return &t
+}
+
+// ViewCertification computes status and credit totals. credits must belong
+// to uc; ones earned before the current cycle are ignored.
+func ViewCertification(uc models.UserCertification, credits []models.CECredit, now time.Time, leadDays int) CertificationView {
+    v := CertificationView{UserCertification: uc, Status: "no_expiry"}
+    today := truncateDay(now)
+    yearStart := today.AddDate(-1, 0, 0)
+    for _, cr := range credits {
+        if cr.EarnedAt.Before(truncateDay(uc.CycleStartedAt)) { continue }
+        v.CreditsEarned += cr.Credits
+        if !cr.EarnedAt.Before(yearStart) { v.CreditsThisYear += cr.Credits }
+    }
+    if r := uc.Certification.CreditsRequired - v.CreditsEarned; r > 0 { v.CreditsRemaining = r }
+    if m := uc.Certification.AnnualMinimum - v.CreditsThisYear; m > 0 { v.AnnualShortfall = m }
+
+    if uc.ExpiresAt != nil {
+        days := daysUntil(today, *uc.ExpiresAt)
+        v.DaysToExpiry = &days
+        switch {
+        case days < 0:
+            v.Status = "expired"
+        case days <= leadDays:
+            v.Status = "expiring"
+        default:
+            v.Status = "active"
+        }
+    }
+    return v
+}
+
+// SweepResult counts what a certification sweep generated.
+type SweepResult struct {
+    Reminders    int `json:"reminders"`
+    RenewalGoals int `json:"renewal_goals"`
+}
+
+// SweepCertifications raises expiry reminders and creates renewal goals for
+// certifications expiring within leadDays. userID limits the sweep to one
+// user; empty sweeps everyone. Safe to run repeatedly.
+func SweepCertifications(db *gorm.DB, userID string, now time.Time, leadDays int) (SweepResult, error) {
+    var res SweepResult
+    today := truncateDay(now)
+    horizon := today.AddDate(0, 0, leadDays)
+
+    q := db.Preload("Certification").Where("expires_at IS NOT NULL AND expires_at <= ?", horizon)
+    if userID != "" { q = q.Where("user_id = ?", userID) }
+    var held []models.UserCertification
+    if err := q.Find(&held).Error; err != nil { return res, err }
+
+    for _, uc := range held {
+        err := db.Transaction(func(tx *gorm.DB) error {
+            created, err := raiseReminder(tx, uc, today)
+            if err != nil { return err }
+            if created { res.Reminders++ }
+
+            // Every instance sweeps (and so does GET /certifications/me):
+            // re-read the row locked so only one of them creates the goal
+            var cur models.UserCertification
+            if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "renewal_goal_id").
+                Where("id = ?", uc.ID).Limit(1).Find(&cur).Error; err != nil { return err }
+            if cur.ID == 0 || cur.RenewalGoalID != nil || daysUntil(today, *uc.ExpiresAt) < 0 { return nil }
+            var credits []models.CECredit
+            if err := tx.Where("user_certification_id = ?", uc.ID).Find(&credits).Error; err != nil { return err }
+            goal, err := createRenewalGoal(tx, uc, ViewCertification(uc, credits, now, leadDays))
+            if err != nil { return err }
+            res.RenewalGoals++
+            return tx.Model(&models.UserCertification{}).Where("id = ?", uc.ID).Update("renewal_goal_id", goal.ID).Error
+        })
+        if err != nil { return res, err }
+    }
+    return res, nil
+}
+
+// RunCertificationSweeper sweeps all users now and then every interval.
+func RunCertificationSweeper(db *gorm.DB, leadDays int, interval time.Duration) {
+    for {
+        res, err := SweepCertifications(db, "", time.Now(), leadDays)
+        if err != nil {
+            log.Printf("certification sweep failed: %v", err)
+        } else if res.Reminders > 0 || res.RenewalGoals > 0 {
+            log.Printf("certification sweep: %d reminders, %d renewal goals", res.Reminders, res.RenewalGoals)
+        }
+        time.Sleep(interval)
+    }
+}
+
+func raiseReminder(tx *gorm.DB, uc models.UserCertification, today time.Time) (bool, error) {
+    days := daysUntil(today, *uc.ExpiresAt)
+    kind := ""
+    if days < 0 {
+        kind = "expired"
+    } else {
+        for _, l := range reminderLeads {
+            if days <= l.Days { kind = l.Kind; break }
+        }
+    }
+    if kind == "" { return false, nil }
+
+    msg := fmt.Sprintf("%s expires on %s (%d days)", uc.Certification.Name, uc.ExpiresAt.Format("2006-01-02"), days)
+    if days < 0 { msg = fmt.Sprintf("%s expired on %s", uc.Certification.Name, uc.ExpiresAt.Format("2006-01-02")) }
+    r := models.CertificationReminder{
+        UserID: uc.UserID, UserCertificationID: uc.ID, Kind: kind, ExpiresAt: *uc.ExpiresAt, Message: msg,
+    }
+    res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&r)
+    return res.RowsAffected > 0, res.Error
+}
+
+// createRenewalGoal adds a goal due on the expiry date. When the cert needs
+// continuing-education credits the goal carries a key result for them, so
+// logged credits show up in KR snapshots and cycle grading.
+func createRenewalGoal(tx *gorm.DB, uc models.UserCertification, v CertificationView) (models.Goal, error) {
+    cert := uc.Certification
+    due := truncateDay(*uc.ExpiresAt)
+    desc := fmt.Sprintf("%s (%s) expires on %s.", cert.Name, cert.Issuer, due.Format("2006-01-02"))
+    meta := map[string]interface{}{
+        "certification": map[string]interface{}{
+            "user_certification_id": uc.ID,
+            "slug":                  cert.Slug,
+            "expires_at":            due.Format("2006-01-02"),
+        },
+    }
+    if cert.CreditsRequired > 0 {
+        desc += fmt.Sprintf(" %.0f of %.0f %s credits earned this cycle.", v.CreditsEarned, cert.CreditsRequired, cert.CreditUnit)
+        baseline, target := 0.0, cert.CreditsRequired
+        meta["key_results"] = []OKRKeyResult{{
+            ID: RenewalCreditsKRID, Name: fmt.Sprintf("Earn %.0f %s credits", target, cert.CreditUnit),
+            MetricType: "number", Unit: cert.CreditUnit, Direction: "increase", Baseline: &baseline, Target: &target,
+        }}
+    }
+    priority := "medium"
+    if v.DaysToExpiry != nil && *v.DaysToExpiry <= 30 { priority = "high" }
+    metaJSON, _ := json.Marshal(meta)
+    tags, _ := json.Marshal([]string{"certification", "renewal", cert.Slug})
+
+    g := models.Goal{
+        UserID: uc.UserID, Title: "Renew " + cert.Name, Description: strings.TrimSpace(desc),
+        Status: "active", Priority: priority, DueDate: &due,
+        Tags: string(tags), Metadata: string(metaJSON), Version: 1,
+    }
+    if err := tx.Create(&g).Error; err != nil { return g, err }
+    _, err := RecordGoalRevision(tx, nil, g, uc.UserID, "create", nil)
+    return g, err
+}
+
+// RenewalCreditsKRID is the key result id on renewal goals that tracks credits.
+const RenewalCreditsKRID = "ce-credits"
+
+// RenewalCreditStatus rates credits earned against a target pro-rated over
+// the renewal cycle: on_track at or above it, at_risk within three quarters
+// of it, off_track below that or once expired with credits still missing.
+func RenewalCreditStatus(v CertificationView, now time.Time) string {
+    required := v.Certification.CreditsRequired
+    if required <= 0 || v.CreditsRemaining <= 0 || v.ExpiresAt == nil { return "on_track" }
+    start, end := truncateDay(v.CycleStartedAt), truncateDay(*v.ExpiresAt)
+    if !truncateDay(now).Before(end) { return "off_track" }
+    target := required
+    if span := end.Sub(start); span > 0 {
+        target = required * float64(truncateDay(now).Sub(start)) / float64(span)
+    }
+    switch {
+    case v.CreditsEarned >= target:
+        return "on_track"
+    case v.CreditsEarned >= 0.75*target:
+        return "at_risk"
+    }
+    return "off_track"
+}
+
+// CertificationCatalog resolves certification names, slugs and aliases.
+type CertificationCatalog map[string]models.Certification
+
+func LoadCertificationCatalog(db *gorm.DB) (CertificationCatalog, error) {
+    var certs []models.Certification
+    if err := db.Find(&certs).Error; err != nil { return nil, err }
+    cat := CertificationCatalog{}
+    for _, c := range certs {
+        var aliases []string
+        _ = json.Unmarshal([]byte(c.Aliases), &aliases)
+        for _, k := range append([]string{c.Slug, c.Name}, aliases...) {
+            if k = normalizeSkillKey(k); k != "" {
+                if _, taken := cat[k]; !taken { cat[k] = c }
+            }
+        }
+    }
+    return cat, nil
+}
+
+func (c CertificationCatalog) Resolve(name string) (models.Certification, bool) {
+    cert, ok := c[normalizeSkillKey(name)]
+    return cert, ok
+}
+
+// MatchText returns the certifications named in text, in order of appearance.
+func (c CertificationCatalog) MatchText(text string) []models.Certification {
+    maxLen := 1
+    for k := range c {
+        if n := len(strings.Fields(k)); n > maxLen { maxLen = n }
+    }
+    seen := map[uint]bool{}
+    var out []models.Certification
+    scanPhrases(text, maxLen, func(phrase string) bool {
+        cert, ok := c[phrase]
+        if ok && !seen[cert.ID] { seen[cert.ID] = true; out = append(out, cert) }
+        return ok
+    })
+    return out
+}
+
+// SyncProfileCertifications starts tracking certs the IT profile lists as
+// earned. Existing tracker records are left alone; the tracker is the
+// source of truth once a cert is in it.
+func SyncProfileCertifications(tx *gorm.DB, p models.UserProfile) error {
+    it := DecodeITProfile(p.ITProfile)
+    if len(it.Certifications) == 0 { return nil }
+    cat, err := LoadCertificationCatalog(tx)
+    if err != nil { return err }
+
+    for _, pc := range it.Certifications {
+        if !strings.EqualFold(pc.Status, "earned") { continue }
+        cert, ok := cat.Resolve(pc.Name)
+        if !ok { continue }
+        uc := models.UserCertification{UserID: p.UserID, CertificationID: cert.ID}
+        uc.ExpiresAt = parseMonthOrDay(pc.Expires)
+        uc.EarnedAt = truncateDay(time.Now())
+        if uc.ExpiresAt != nil && cert.ValidityMonths > 0 {
+            uc.EarnedAt = uc.ExpiresAt.AddDate(0, -cert.ValidityMonths, 0)
+        } else if uc.ExpiresAt == nil {
+            uc.ExpiresAt = DefaultExpiry(cert, uc.EarnedAt)
+        }
+        uc.CycleStartedAt = uc.EarnedAt
+        if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&uc).Error; err != nil { return err }
+    }
+    return nil
+}
+
+func daysUntil(today, t time.Time) int {
+    return int(truncateDay(t).Sub(truncateDay(today)).Hours() / 24)
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+    "errors"
+    "fmt"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// ErrVersionConflict signals that another writer bumped the row's version
+// between our read and our write.
+var ErrVersionConflict = errors.New("version conflict")
+
+// SaveVersioned writes every column of model only if the stored version is
+// still prev. The caller must already have set the model's version to prev+1.
+// Unlike Save, a lost race never falls back to an insert.
+func SaveVersioned(tx *gorm.DB, model interface{}, prev int) error {
+    res := tx.Model(model).Where("version = ?", prev).Select("*").Omit(clause.Associations).Updates(model)
+    if res.Error != nil {
+        return res.Error
+    }
+    if res.RowsAffected == 0 {
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+// DeleteVersioned deletes model only if the stored version is still prev.
+func DeleteVersioned(tx *gorm.DB, model interface{}, prev int) error {
+    res := tx.Where("version = ?", prev).Delete(model)
+    if res.Error != nil {
+        return res.Error
+    }
+    if res.RowsAffected == 0 {
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+// GoalConflictError is a version conflict on one goal of a multi-goal write,
+// naming the goal so callers can report its current state.
+type GoalConflictError struct {
+    GoalID uint
+}
+
+func (e *GoalConflictError) Error() string { return fmt.Sprintf("goal %d: %v", e.GoalID, ErrVersionConflict) }
+
+func (e *GoalConflictError) Unwrap() error { return ErrVersionConflict }
@@ -0,0 +1,408 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "strings"
+    "time"
+
+    "goaltracker/i18n"
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// ErrGoalsNotFound is returned when some of the named goals don't exist or
+// aren't the user's.
+var ErrGoalsNotFound = errors.New("goals not found")
+
+// CycleStatus derives upcoming/active/ended from the cycle's dates.
+func CycleStatus(c models.Cycle, now time.Time) string {
+    day := truncateDay(now.UTC())
+    switch {
+    case day.Before(truncateDay(c.StartDate)):
+        return "upcoming"
+    case day.After(truncateDay(c.EndDate)):
+        return "ended"
+    default:
+        return "active"
+    }
+}
+
+// QuarterBounds returns the first and last day of t's calendar quarter.
+func QuarterBounds(t time.Time) (time.Time, time.Time) {
+    q := (int(t.Month()) - 1) / 3
+    start := time.Date(t.Year(), time.Month(q*3+1), 1, 0, 0, 0, 0, time.UTC)
+    return start, start.AddDate(0, 3, -1)
+}
+
+// NextCycleWindow proposes the cycle that follows c. Calendar quarters roll to
+// the next quarter ("2026-Q3"); anything else repeats its length.
+func NextCycleWindow(c models.Cycle) (name string, start, end time.Time) {
+    qs, qe := QuarterBounds(c.StartDate)
+    if truncateDay(c.StartDate).Equal(qs) && truncateDay(c.EndDate).Equal(qe) {
+        start, end = QuarterBounds(qe.AddDate(0, 0, 1))
+        return quarterLabel(start), start, end
+    }
+    length := truncateDay(c.EndDate).Sub(truncateDay(c.StartDate))
+    start = truncateDay(c.EndDate).AddDate(0, 0, 1)
+    return strings.TrimSpace(c.Name) + " (next)", start, start.Add(length)
+}
+
+// KRGradeSuggestion is one row of the end-of-cycle grading sheet.
+type KRGradeSuggestion struct {
+    GoalID         uint     `json:"goal_id"`
+    GoalTitle      string   `json:"goal_title"`
+    GoalStatus     string   `json:"goal_status"`
+    KRID           string   `json:"kr_id"`
+    KRName         string   `json:"kr_name,omitempty"`
+    Baseline       *float64 `json:"baseline,omitempty"`
+    Target         *float64 `json:"target,omitempty"`
+    LatestValue    *float64 `json:"latest_value,omitempty"`
+    SuggestedGrade float64  `json:"suggested_grade"`
+    Basis          string   `json:"basis"`
+    Grade          *float64 `json:"grade,omitempty"`
+    Note           string   `json:"note,omitempty"`
+}
+
+// GoalKeyResults reads key_results from goal metadata, if any.
+func GoalKeyResults(metadata string) []OKRKeyResult {
+    if strings.TrimSpace(metadata) == "" { return nil }
+    var meta struct {
+        KeyResults []OKRKeyResult `json:"key_results"`
+    }
+    if err := json.Unmarshal([]byte(metadata), &meta); err != nil { return nil }
+    out := make([]OKRKeyResult, 0, len(meta.KeyResults))
+    for _, kr := range meta.KeyResults {
+        if strings.TrimSpace(kr.ID) != "" { out = append(out, kr) }
+    }
+    return out
+}
+
+// SuggestGrades builds the grading sheet for a goal. latest maps KR id to
+// its most recent snapshot value. KRs with baseline and target are scored
+// by distance travelled; otherwise the objective is graded from progress.
+func SuggestGrades(g models.Goal, latest map[string]float64, latestPercentage int) []KRGradeSuggestion {
+    krs := GoalKeyResults(g.Metadata)
+    if len(krs) == 0 {
+        grade, basis := float64(latestPercentage)/100, "latest progress percentage"
+        if g.Status == "completed" { grade, basis = 1, "goal completed" }
+        return []KRGradeSuggestion{{
+            GoalID: g.ID, GoalTitle: g.Title, GoalStatus: g.Status,
+            SuggestedGrade: clampGrade(grade), Basis: basis,
+        }}
+    }
+
+    out := make([]KRGradeSuggestion, 0, len(krs))
+    for _, kr := range krs {
+        s := KRGradeSuggestion{
+            GoalID: g.ID, GoalTitle: g.Title, GoalStatus: g.Status,
+            KRID: kr.ID, KRName: kr.Name, Baseline: kr.Baseline, Target: kr.Target,
+        }
+        v, hasValue := latest[kr.ID]
+        if hasValue { s.LatestValue = &v }
+        switch {
+        case hasValue && kr.Baseline != nil && kr.Target != nil && *kr.Target != *kr.Baseline:
+            s.SuggestedGrade = clampGrade((v - *kr.Baseline) / (*kr.Target - *kr.Baseline))
+            s.Basis = "latest snapshot vs baseline/target"
+        case g.Status == "completed":
+            s.SuggestedGrade, s.Basis = 1, "goal completed"
+        default:
+            s.SuggestedGrade, s.Basis = clampGrade(float64(latestPercentage)/100), "latest progress percentage"
+        }
+        out = append(out, s)
+    }
+    return out
+}
+
+// RolloverGoal copies an unfinished goal into the next cycle, linked back to
+// the original. The due date moves to the end of the next cycle. Callers
+// pause the original in the same transaction so only the copy stays active.
+func RolloverGoal(orig models.Goal, next models.Cycle) models.Goal {
+    end := truncateDay(next.EndDate)
+    g := models.Goal{
+        UserID:           orig.UserID,
+        Title:            orig.Title,
+        Description:      orig.Description,
+        JobRoleID:        orig.JobRoleID,
+        Status:           "active",
+        Priority:         orig.Priority,
+        Tags:             orig.Tags,
+        Metadata:         orig.Metadata,
+        Version:          1,
+        CycleID:          &next.ID,
+        RolledFromGoalID: &orig.ID,
+    }
+    if orig.DueDate != nil { g.DueDate = &end }
+    return g
+}
+
+// ValidateGrade rejects grades outside 0.0-1.0.
+func ValidateGrade(g float64) error {
+    if g < 0 || g > 1 {
+        return i18n.Errorf("invalid_grade")
+    }
+    return nil
+}
+
+func clampGrade(g float64) float64 {
+    if g < 0 { return 0 }
+    if g > 1 { return 1 }
+    // grades are reported to one decimal like OKR scoring sheets
+    return float64(int(g*10+0.5)) / 10
+}
+
+// VisibleCycles scopes a query to org cycles plus userID's own.
+func VisibleCycles(db *gorm.DB, userID string) *gorm.DB {
+    return db.Where("scope = 'org' OR user_id = ?", userID)
+}
+
+// CycleVisibleTo reports whether userID may attach a goal to cycle id.
+func CycleVisibleTo(db *gorm.DB, id uint, userID string) bool {
+    var n int64
+    VisibleCycles(db, userID).Model(&models.Cycle{}).Where("id = ?", id).Count(&n)
+    return n > 0
+}
+
+// CycleGoals lists userID's goals in a cycle.
+func CycleGoals(db *gorm.DB, cycleID uint, userID string) ([]models.Goal, error) {
+    var goals []models.Goal
+    err := db.Where("cycle_id = ? AND user_id = ?", cycleID, userID).Order("id").Find(&goals).Error
+    return goals, err
+}
+
+// CycleClosed reports whether userID has already closed the cycle.
+func CycleClosed(db *gorm.DB, cycleID uint, userID string) bool {
+    var n int64
+    db.Model(&models.CycleReview{}).Where("cycle_id = ? AND user_id = ?", cycleID, userID).Count(&n)
+    return n > 0
+}
+
+// AttachCycleGoals moves userID's goals into a cycle, recording a revision
+// for each one that moves. It returns ErrGoalsNotFound when an id isn't one
+// of the user's goals, and a *GoalConflictError when a goal changed
+// concurrently.
+func AttachCycleGoals(db *gorm.DB, cycleID uint, userID string, goalIDs []uint) error {
+    var goals []models.Goal
+    if err := db.Where("user_id = ? AND id IN ?", userID, goalIDs).Find(&goals).Error; err != nil { return err }
+    if len(goals) != len(uniqueIDs(goalIDs)) { return ErrGoalsNotFound }
+    return db.Transaction(func(tx *gorm.DB) error {
+        for _, g := range goals {
+            if g.CycleID != nil && *g.CycleID == cycleID { continue }
+            before := g
+            g.CycleID = &cycleID
+            g.Version = before.Version + 1
+            if err := SaveVersioned(tx, &g, before.Version); err != nil {
+                if errors.Is(err, ErrVersionConflict) { return &GoalConflictError{GoalID: g.ID} }
+                return err
+            }
+            if _, err := RecordGoalRevision(tx, &before, g, userID, "update", nil); err != nil { return err }
+        }
+        return nil
+    })
+}
+
+// KRGradeInput is one grade submitted for a goal's key result ("" grades a
+// goal that has none).
+type KRGradeInput struct {
+    GoalID uint    `json:"goal_id" binding:"required"`
+    KRID   string  `json:"kr_id"`
+    Grade  float64 `json:"grade"`
+    Note   string  `json:"note"`
+}
+
+// ValidateGrades checks each grade is in range and names a goal in the cycle
+// and one of its key results.
+func ValidateGrades(grades []KRGradeInput, goals []models.Goal) error {
+    byID := map[uint]models.Goal{}
+    for _, g := range goals { byID[g.ID] = g }
+    for _, in := range grades {
+        if err := ValidateGrade(in.Grade); err != nil {
+            return i18n.Errorf("invalid_kr_grade", in.GoalID, in.KRID)
+        }
+        g, ok := byID[in.GoalID]
+        if !ok { return i18n.Errorf("goal_not_in_cycle", in.GoalID) }
+        krs := GoalKeyResults(g.Metadata)
+        if len(krs) == 0 {
+            if in.KRID != "" { return i18n.Errorf("key_result_not_found", in.GoalID, in.KRID) }
+            continue
+        }
+        found := false
+        for _, kr := range krs {
+            if kr.ID == in.KRID { found = true; break }
+        }
+        if !found { return i18n.Errorf("key_result_not_found", in.GoalID, in.KRID) }
+    }
+    return nil
+}
+
+// SaveGrades upserts draft grades for userID's cycle.
+func SaveGrades(tx *gorm.DB, cycleID uint, userID string, grades []KRGradeInput) error {
+    for _, in := range grades {
+        g := models.KRGrade{UserID: userID, CycleID: cycleID, GoalID: in.GoalID, KRID: in.KRID, Grade: in.Grade, Note: in.Note}
+        err := tx.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "cycle_id"}, {Name: "goal_id"}, {Name: "kr_id"}},
+            DoUpdates: clause.AssignmentColumns([]string{"grade", "note", "updated_at"}),
+        }).Create(&g).Error
+        if err != nil { return err }
+    }
+    return nil
+}
+
+// SelectRollover picks the goals to carry over: the requested ids, or every
+// goal in the cycle that is not completed.
+func SelectRollover(goals []models.Goal, ids *[]uint) ([]models.Goal, error) {
+    if ids == nil {
+        var out []models.Goal
+        for _, g := range goals {
+            if g.Status != "completed" { out = append(out, g) }
+        }
+        return out, nil
+    }
+    byID := map[uint]models.Goal{}
+    for _, g := range goals { byID[g.ID] = g }
+    out := make([]models.Goal, 0, len(*ids))
+    for _, id := range uniqueIDs(*ids) {
+        g, ok := byID[id]
+        if !ok { return nil, i18n.Errorf("goal_not_in_cycle", id) }
+        out = append(out, g)
+    }
+    return out, nil
+}
+
+// ProposeNextCycle reuses a visible cycle covering the period after cycle,
+// or returns an unsaved personal cycle for it.
+func ProposeNextCycle(db *gorm.DB, cycle models.Cycle, userID string) *models.Cycle {
+    name, start, end := NextCycleWindow(cycle)
+    var existing models.Cycle
+    if VisibleCycles(db, userID).Where("start_date = ? AND end_date = ?", start, end).
+        Order("scope DESC").Limit(1).Find(&existing).RowsAffected > 0 {
+        return &existing
+    }
+    return &models.Cycle{UserID: &userID, Scope: "user", Name: name, StartDate: start, EndDate: end}
+}
+
+// CycleClosure is what closing a cycle produced.
+type CycleClosure struct {
+    Review      models.CycleReview `json:"review"`
+    Grades      []models.KRGrade   `json:"grades"`
+    NextCycle   *models.Cycle      `json:"next_cycle"`
+    RolledGoals []models.Goal      `json:"rolled_goals"`
+}
+
+// CloseCycle runs the end-of-cycle workflow in one transaction: save the
+// grades, record the reflection and average grade, create next if it is
+// unsaved, and copy each rollover goal into next, pausing the original. A
+// goal changed concurrently fails it with a *GoalConflictError.
+func CloseCycle(db *gorm.DB, cycle models.Cycle, userID string, grades []KRGradeInput, reflection string, next *models.Cycle, rollover []models.Goal, now time.Time) (*CycleClosure, error) {
+    out := &CycleClosure{
+        Review:    models.CycleReview{UserID: userID, CycleID: cycle.ID, Reflection: reflection, ClosedAt: now},
+        NextCycle: next,
+    }
+    err := db.Transaction(func(tx *gorm.DB) error {
+        if err := SaveGrades(tx, cycle.ID, userID, grades); err != nil { return err }
+        var avg *float64
+        if err := tx.Model(&models.KRGrade{}).Where("cycle_id = ? AND user_id = ?", cycle.ID, userID).
+            Select("AVG(grade)").Scan(&avg).Error; err != nil { return err }
+        if avg != nil { out.Review.AverageGrade = *avg }
+
+        if next != nil {
+            if next.ID == 0 {
+                if err := tx.Create(next).Error; err != nil { return err }
+            }
+            out.Review.NextCycleID = &next.ID
+        }
+        if err := tx.Create(&out.Review).Error; err != nil { return err }
+
+        for _, g := range rollover {
+            carried := RolloverGoal(g, *next)
+            if err := tx.Create(&carried).Error; err != nil { return err }
+            if _, err := RecordGoalRevision(tx, nil, carried, userID, "create", nil); err != nil { return err }
+            out.RolledGoals = append(out.RolledGoals, carried)
+
+            // The copy carries on; the original is paused so only one stays active
+            if g.Status != "active" { continue }
+            before := g
+            g.Status, g.Version = "paused", before.Version+1
+            if err := SaveVersioned(tx, &g, before.Version); err != nil {
+                if errors.Is(err, ErrVersionConflict) { return &GoalConflictError{GoalID: g.ID} }
+                return err
+            }
+            if _, err := RecordGoalRevision(tx, &before, g, userID, "update", nil); err != nil { return err }
+        }
+        return nil
+    })
+    if err != nil { return nil, err }
+    if err := db.Where("cycle_id = ? AND user_id = ?", cycle.ID, userID).Order("goal_id, kr_id").Find(&out.Grades).Error; err != nil {
+        return nil, err
+    }
+    return out, nil
+}
+
+// GradingSheet builds userID's grading sheet for a cycle: one row per KR
+// (or per goal without KRs) with a suggested grade and any saved grade.
+func GradingSheet(db *gorm.DB, cycleID uint, userID string) ([]KRGradeSuggestion, error) {
+    goals, err := CycleGoals(db, cycleID, userID)
+    if err != nil { return nil, err }
+    rows := []KRGradeSuggestion{}
+    if len(goals) == 0 { return rows, nil }
+    ids := make([]uint, 0, len(goals))
+    for _, g := range goals { ids = append(ids, g.ID) }
+
+    var snaps []struct {
+        GoalID uint
+        KRID   string
+        Value  float64
+    }
+    if err := db.Raw(`SELECT DISTINCT ON (goal_id, kr_id) goal_id, kr_id, value
+        FROM kr_snapshots WHERE goal_id IN ? ORDER BY goal_id, kr_id, captured_at DESC`, ids).Scan(&snaps).Error; err != nil {
+        return nil, err
+    }
+    var pcts []struct {
+        GoalID     uint
+        Percentage int
+    }
+    if err := db.Raw(`SELECT DISTINCT ON (goal_id) goal_id, percentage
+        FROM progresses WHERE goal_id IN ? ORDER BY goal_id, created_at DESC`, ids).Scan(&pcts).Error; err != nil {
+        return nil, err
+    }
+    var saved []models.KRGrade
+    if err := db.Where("cycle_id = ? AND user_id = ?", cycleID, userID).Find(&saved).Error; err != nil {
+        return nil, err
+    }
+
+    latest := map[uint]map[string]float64{}
+    for _, s := range snaps {
+        if latest[s.GoalID] == nil { latest[s.GoalID] = map[string]float64{} }
+        latest[s.GoalID][s.KRID] = s.Value
+    }
+    pct := map[uint]int{}
+    for _, p := range pcts { pct[p.GoalID] = p.Percentage }
+    type key struct {
+        goal uint
+        kr   string
+    }
+    grades := map[key]models.KRGrade{}
+    for _, g := range saved { grades[key{g.GoalID, g.KRID}] = g }
+
+    for _, g := range goals {
+        for _, row := range SuggestGrades(g, latest[g.ID], pct[g.ID]) {
+            if sg, ok := grades[key{row.GoalID, row.KRID}]; ok {
+                grade := sg.Grade
+                row.Grade, row.Note = &grade, sg.Note
+            }
+            rows = append(rows, row)
+        }
+    }
+    return rows, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+    seen := map[uint]bool{}
+    out := make([]uint, 0, len(ids))
+    for _, id := range ids {
+        if !seen[id] { seen[id] = true; out = append(out, id) }
+    }
+    return out
+}
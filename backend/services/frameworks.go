@@ -0,0 +1,577 @@
+package services
+
+import (
+    "bufio"
+    "encoding/csv"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "sort"
+    "strings"
+    "time"
+    "unicode/utf8"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// FrameworkCrosswalks maps framework roles onto existing catalog job roles
+// (source -> source id -> job role slug). A mapped role is not imported as a
+// role of its own: its tasks become responsibilities of the catalog role and
+// its skills that role's requirements.
+var FrameworkCrosswalks = map[string]map[string]string{
+    "nice": {
+        "PD-WRL-001": "security-analyst", // Defensive Cybersecurity
+        "PR-CDA-001": "security-analyst", // Cyber Defense Analyst (2017 edition)
+    },
+    "onet": {
+        "15-1212.00": "security-analyst",  // Information Security Analysts
+        "15-1252.00": "software-engineer", // Software Developers
+        "15-1255.00": "designer",          // Web and Digital Interface Designers
+    },
+}
+
+// FrameworkOptions narrows an import. Only lists the NICE work roles or
+// O*NET occupations to import by source id; Crosswalk adds to or overrides
+// FrameworkCrosswalks.
+type FrameworkOptions struct {
+    Only      []string
+    Crosswalk map[string]string
+}
+
+// FrameworkImport is a framework's files mapped onto the catalog: a fixture
+// pack of job roles and responsibilities, the skills it defines and the skill
+// levels its roles expect. Rows keep the framework's identifiers in source
+// and source_id, and their slugs derive from them, so re-importing a newer
+// release updates the same rows.
+type FrameworkImport struct {
+    Source       string                 `json:"source"`
+    Pack         CatalogFixture         `json:"pack"`
+    Skills       []FrameworkSkill       `json:"skills"`
+    Requirements []FrameworkRequirement `json:"requirements"`
+    seen         map[string]bool
+}
+
+// FrameworkSkill is a skill a framework defines. With Reuse set a catalog
+// skill of the same name or alias is used instead when there is one (tool
+// names like "Python" are already in the taxonomy).
+type FrameworkSkill struct {
+    Slug     string `json:"slug"`
+    Name     string `json:"name"`
+    Category string `json:"category"`
+    Kind     string `json:"kind"`
+    SourceID string `json:"source_id"`
+    Reuse    bool   `json:"reuse,omitempty"`
+}
+
+// FrameworkRequirement is a skill level a job role (by slug) expects. Skill
+// names one skill; Text is a statement ("Knowledge of ...") and every
+// catalog skill it mentions counts.
+type FrameworkRequirement struct {
+    Role       string `json:"role"`
+    Skill      string `json:"skill,omitempty"`
+    Text       string `json:"text,omitempty"`
+    Level      int    `json:"level"`
+    Importance int    `json:"importance"`
+}
+
+func newFrameworkImport(source string) *FrameworkImport {
+    return &FrameworkImport{
+        Source: source,
+        Pack:   CatalogFixture{Version: CatalogFixtureVersion, Pack: source},
+        seen:   map[string]bool{},
+    }
+}
+
+// role adds a framework role unless it is crosswalked, and returns the job
+// role slug its tasks and requirements go to.
+func (imp *FrameworkImport) role(crosswalk map[string]string, id, title, description string) string {
+    if slug := crosswalk[id]; slug != "" { return slug }
+    slug := SkillSlug(imp.Source + " " + id)
+    if !imp.seen[slug] {
+        imp.seen[slug] = true
+        imp.Pack.JobRoles = append(imp.Pack.JobRoles, FixtureJobRole{
+            Slug: slug, Title: clipText(title, 100), Description: clipText(description, 1000), Source: imp.Source, SourceID: id,
+        })
+    }
+    return slug
+}
+
+// task adds a task of a framework role as a responsibility of role. Tasks
+// are shared between framework roles, so the slug includes the role's id.
+// Statements too long for a title are cut there and kept whole in the
+// description.
+func (imp *FrameworkImport) task(role, roleID, id, text, category string) {
+    text = strings.Join(strings.Fields(text), " ")
+    slug := SkillSlug(imp.Source + " " + roleID + " " + id)
+    if text == "" || imp.seen[slug] { return }
+    imp.seen[slug] = true
+    title, description := clipText(text, 200), ""
+    if title != text { description = clipText(text, 1000) }
+    imp.Pack.Responsibilities = append(imp.Pack.Responsibilities, FixtureResponsibility{
+        Slug: slug, JobRole: role, Title: title, Description: description, Category: category, Source: imp.Source, SourceID: id,
+    })
+}
+
+func (imp *FrameworkImport) validate() error {
+    if errs := validateCatalogFixture(&imp.Pack); len(errs) > 0 {
+        return &CatalogFixtureError{Pack: imp.Pack.Pack, Fields: errs}
+    }
+    return nil
+}
+
+// clipText shortens s to at most max bytes, at a word boundary when there
+// is one, marking the cut with an ellipsis.
+func clipText(s string, max int) string {
+    s = strings.TrimSpace(s)
+    if len(s) <= max { return s }
+    cut := max - len("…")
+    for cut > 0 && !utf8.RuneStart(s[cut]) { cut-- }
+    if i := strings.LastIndex(s[:cut], " "); i > cut/2 { cut = i }
+    return strings.TrimRight(s[:cut], " ,;:.") + "…"
+}
+
+func frameworkCrosswalk(source string, extra map[string]string) map[string]string {
+    out := map[string]string{}
+    for id, slug := range FrameworkCrosswalks[source] { out[id] = slug }
+    for id, slug := range extra { out[strings.TrimSpace(id)] = SkillSlug(slug) }
+    return out
+}
+
+func stringSet(ids []string) map[string]bool {
+    out := map[string]bool{}
+    for _, id := range ids {
+        if id = strings.TrimSpace(id); id != "" { out[id] = true }
+    }
+    return out
+}
+
+// ParseSFIA reads the SFIA skills list saved as CSV, one row per skill as in
+// the "Skills" sheet of the SFIA download. It uses the Code, Skill and
+// Category columns, found by header name; level descriptions are not
+// imported. SFIA has no roles, so only skills come out, with the slug
+// sfia-<code>.
+func ParseSFIA(r io.Reader) (*FrameworkImport, error) {
+    table, err := readFrameworkTable(r, ',')
+    if err != nil { return nil, fmt.Errorf("invalid SFIA CSV: %v", err) }
+    if !table.has("code", "skill code") || !table.has("skill", "skill name", "name") {
+        return nil, errors.New("invalid SFIA CSV: needs Code and Skill columns")
+    }
+    imp := newFrameworkImport("sfia")
+    for _, rec := range table.rows {
+        code := strings.ToUpper(table.get(rec, "code", "skill code"))
+        name := table.get(rec, "skill", "skill name", "name")
+        slug := SkillSlug("sfia " + code)
+        if code == "" || name == "" || imp.seen[slug] { continue }
+        imp.seen[slug] = true
+        category := SkillSlug(table.get(rec, "category"))
+        if category == "" { category = "other" }
+        imp.Skills = append(imp.Skills, FrameworkSkill{Slug: slug, Name: name, Category: category, Kind: "framework", SourceID: code})
+    }
+    if len(imp.Skills) == 0 { return nil, errors.New("no SFIA skills found") }
+    return imp, imp.validate()
+}
+
+// niceDocument is the NICE Framework components JSON as NIST publishes it:
+// elements of every type (work_role, task, knowledge, skill, ...) in one
+// list and the links between them in another. The bare object holding the
+// two lists is accepted as well.
+type niceDocument struct {
+    Response *struct {
+        Elements niceElements `json:"elements"`
+    } `json:"response"`
+    niceElements
+}
+
+type niceElements struct {
+    Elements      []niceElement      `json:"elements"`
+    Relationships []niceRelationship `json:"relationships"`
+}
+
+type niceElement struct {
+    Type  string `json:"element_type"`
+    ID    string `json:"element_identifier"`
+    Title string `json:"title"`
+    Text  string `json:"text"`
+}
+
+type niceRelationship struct {
+    Source string `json:"source_element_identifier"`
+    Dest   string `json:"dest_element_identifier"`
+}
+
+// niceCategories names work role categories by id prefix, for the
+// responsibility category of their tasks. The first set is NICE 1.0, the
+// second the 2017 edition.
+var niceCategories = map[string]string{
+    "OG": "oversight-and-governance", "DD": "design-and-development", "IO": "implementation-and-operation",
+    "PD": "protection-and-defense", "IN": "investigation", "CI": "cyberspace-intelligence", "CE": "cyberspace-effects",
+    "SP": "securely-provision", "OM": "operate-and-maintain", "OV": "oversee-and-govern", "PR": "protect-and-defend",
+    "AN": "analyze", "CO": "collect-and-operate",
+}
+
+// ParseNICE reads the NICE Cybersecurity Workforce Framework components
+// JSON. Work roles become job roles (slug nice-<id>) unless crosswalked;
+// their tasks become responsibilities; their knowledge and skill statements
+// become requirements for the catalog skills they mention (working level for
+// knowledge, practitioner for skills, more important the more statements
+// mention a skill). Statements that mention no catalog skill are dropped.
+func ParseNICE(r io.Reader, opts FrameworkOptions) (*FrameworkImport, error) {
+    var doc niceDocument
+    if err := json.NewDecoder(r).Decode(&doc); err != nil { return nil, fmt.Errorf("invalid NICE JSON: %v", err) }
+    els := doc.niceElements
+    if doc.Response != nil { els = doc.Response.Elements }
+
+    byID := map[string]niceElement{}
+    var roles []niceElement
+    for _, e := range els.Elements {
+        e.Type = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(e.Type), " ", "_"))
+        e.ID = strings.TrimSpace(e.ID)
+        byID[e.ID] = e
+        if e.Type == "work_role" { roles = append(roles, e) }
+    }
+    links := map[string][]string{}
+    for _, rel := range els.Relationships {
+        src := strings.TrimSpace(rel.Source)
+        links[src] = append(links[src], strings.TrimSpace(rel.Dest))
+    }
+
+    only := stringSet(opts.Only)
+    crosswalk := frameworkCrosswalk("nice", opts.Crosswalk)
+    imp := newFrameworkImport("nice")
+    for _, wr := range roles {
+        if len(only) > 0 && !only[wr.ID] { continue }
+        slug := imp.role(crosswalk, wr.ID, wr.Title, wr.Text)
+        category := niceCategories[strings.SplitN(wr.ID, "-", 2)[0]]
+        if category == "" { category = "security" }
+        for _, id := range links[wr.ID] {
+            el := byID[id]
+            text := el.Text
+            if text == "" { text = el.Title }
+            switch el.Type {
+            case "task":
+                imp.task(slug, wr.ID, el.ID, text, category)
+            case "knowledge":
+                imp.Requirements = append(imp.Requirements, FrameworkRequirement{Role: slug, Text: text, Level: 2, Importance: 1})
+            case "skill":
+                imp.Requirements = append(imp.Requirements, FrameworkRequirement{Role: slug, Text: text, Level: 3, Importance: 1})
+            }
+        }
+    }
+    if len(imp.Pack.JobRoles) == 0 && len(imp.Pack.Responsibilities) == 0 { return nil, errors.New("no NICE work roles found") }
+    return imp, imp.validate()
+}
+
+// onetTechCategories assigns O*NET technology commodity titles to skill
+// categories by keyword; the first match wins.
+var onetTechCategories = []struct{ keyword, category string }{
+    {"security", "security"}, {"virus", "security"}, {"authentication", "security"},
+    {"database", "data"}, {"business intelligence", "data"}, {"analytical", "data"},
+    {"development environment", "programming"}, {"object or component oriented", "programming"},
+    {"program testing", "programming"}, {"web platform", "programming"},
+    {"configuration management", "devops"}, {"operating system", "devops"}, {"network monitoring", "devops"},
+    {"graphics", "design"}, {"computer aided design", "design"},
+    {"project management", "product"},
+}
+
+func onetTechCategory(commodity string) string {
+    commodity = strings.ToLower(commodity)
+    for _, c := range onetTechCategories {
+        if strings.Contains(commodity, c.keyword) { return c.category }
+    }
+    return "other"
+}
+
+// ParseONET reads O*NET database text files (tab-delimited, as in the
+// db_XX_X_text download) from fsys: "Occupation Data.txt" for the
+// occupations, "Task Statements.txt" for their core tasks and, when present,
+// "Technology Skills.txt" for the hot technologies they use, which become
+// skills (reusing catalog skills of the same name) and requirements. O*NET
+// has about a thousand occupations, so only opts.Only are imported, by
+// default the crosswalked ones; Only "all" imports every occupation.
+func ParseONET(fsys fs.FS, opts FrameworkOptions) (*FrameworkImport, error) {
+    open := func(name string) (*frameworkTable, error) {
+        f, err := fsys.Open(name)
+        if err != nil { return nil, err }
+        defer f.Close()
+        t, err := readFrameworkTable(f, '\t')
+        if err != nil { return nil, fmt.Errorf("%s: %v", name, err) }
+        return t, nil
+    }
+    const code = "o*net-soc code"
+
+    crosswalk := frameworkCrosswalk("onet", opts.Crosswalk)
+    only := stringSet(opts.Only)
+    if len(only) == 0 {
+        for id := range crosswalk { only[id] = true }
+    }
+    wanted := func(id string) bool { return only["all"] || only[id] }
+
+    occupations, err := open("Occupation Data.txt")
+    if err != nil { return nil, err }
+    imp := newFrameworkImport("onet")
+    roles := map[string]string{} // O*NET-SOC code -> job role slug
+    for _, rec := range occupations.rows {
+        id := occupations.get(rec, code)
+        if id == "" || !wanted(id) { continue }
+        roles[id] = imp.role(crosswalk, id, occupations.get(rec, "title"), occupations.get(rec, "description"))
+    }
+    if len(roles) == 0 { return nil, errors.New("none of the requested O*NET occupations found") }
+
+    tasks, err := open("Task Statements.txt")
+    if err != nil { return nil, err }
+    for _, rec := range tasks.rows {
+        id := tasks.get(rec, code)
+        if roles[id] == "" || strings.EqualFold(tasks.get(rec, "task type"), "supplemental") { continue }
+        imp.task(roles[id], id, tasks.get(rec, "task id"), tasks.get(rec, "task"), "general")
+    }
+
+    tech, err := open("Technology Skills.txt")
+    if errors.Is(err, fs.ErrNotExist) { return imp, imp.validate() }
+    if err != nil { return nil, err }
+    for _, rec := range tech.rows {
+        id, name := tech.get(rec, code), tech.get(rec, "example")
+        if roles[id] == "" || name == "" || !strings.EqualFold(tech.get(rec, "hot technology"), "y") { continue }
+        slug := SkillSlug(name)
+        if !imp.seen["skill:"+slug] {
+            imp.seen["skill:"+slug] = true
+            imp.Skills = append(imp.Skills, FrameworkSkill{
+                Slug: slug, Name: name, Category: onetTechCategory(tech.get(rec, "commodity title")), Kind: "platform",
+                SourceID: tech.get(rec, "commodity code"), Reuse: true,
+            })
+        }
+        importance := 2
+        if strings.EqualFold(tech.get(rec, "in demand"), "y") { importance = 3 }
+        imp.Requirements = append(imp.Requirements, FrameworkRequirement{Role: roles[id], Skill: name, Level: 2, Importance: importance})
+    }
+    return imp, imp.validate()
+}
+
+// frameworkTable is a delimited file with a header row; columns are looked
+// up by lower-cased header name.
+type frameworkTable struct {
+    header map[string]int
+    rows   [][]string
+}
+
+// readFrameworkTable reads CSV, or with comma '\t' the unquoted
+// tab-delimited text O*NET ships (quotes in it are literal).
+func readFrameworkTable(r io.Reader, comma rune) (*frameworkTable, error) {
+    var records [][]string
+    if comma == '\t' {
+        sc := bufio.NewScanner(r)
+        sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+        for sc.Scan() {
+            if line := strings.TrimRight(sc.Text(), "\r"); line != "" { records = append(records, strings.Split(line, "\t")) }
+        }
+        if err := sc.Err(); err != nil { return nil, err }
+    } else {
+        cr := csv.NewReader(r)
+        cr.Comma = comma
+        cr.FieldsPerRecord = -1
+        cr.LazyQuotes = true
+        var err error
+        if records, err = cr.ReadAll(); err != nil { return nil, err }
+    }
+    if len(records) == 0 { return nil, errors.New("empty file") }
+    t := &frameworkTable{header: map[string]int{}, rows: records[1:]}
+    for i, h := range records[0] {
+        h = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+        if _, dup := t.header[h]; !dup { t.header[h] = i }
+    }
+    return t, nil
+}
+
+func (t *frameworkTable) has(names ...string) bool {
+    for _, n := range names {
+        if _, ok := t.header[n]; ok { return true }
+    }
+    return false
+}
+
+// get returns the first of the named columns rec has, trimmed.
+func (t *frameworkTable) get(rec []string, names ...string) string {
+    for _, n := range names {
+        if i, ok := t.header[n]; ok && i < len(rec) { return strings.TrimSpace(rec[i]) }
+    }
+    return ""
+}
+
+// FrameworkImportReport summarizes a framework import. Requirements only
+// count created and unchanged: existing ones are never updated.
+type FrameworkImportReport struct {
+    Source       string                `json:"source"`
+    DryRun       bool                  `json:"dry_run"`
+    Skills       FixtureCounts         `json:"skills"`
+    Requirements FixtureCounts         `json:"requirements"`
+    Catalog      *CatalogFixtureReport `json:"catalog"`
+}
+
+// ApplyFrameworkImport writes an import in one transaction: skills (by slug,
+// or reused), then the pack through ApplyCatalogFixtures, then requirements.
+// Roles and responsibilities the import creates start as drafts for review
+// unless publish is set; existing rows keep their status. Rows a newer
+// release no longer lists are left alone; unpublish them. Requirements are
+// only added, so seeded or admin-edited levels stay. With dryRun nothing is
+// written.
+func ApplyFrameworkImport(db *gorm.DB, imp *FrameworkImport, publish, dryRun bool, now time.Time) (*FrameworkImportReport, error) {
+    report := &FrameworkImportReport{Source: imp.Source, DryRun: dryRun}
+    err := db.Transaction(func(tx *gorm.DB) error {
+        catalog, err := LoadSkillCatalog(tx)
+        if err != nil { return err }
+        for _, s := range imp.Skills {
+            if err := upsertFrameworkSkill(tx, catalog, imp.Source, s, &report.Skills); err != nil { return err }
+        }
+        pack := imp.Pack
+        pack.JobRoles = append([]FixtureJobRole(nil), imp.Pack.JobRoles...)
+        pack.Responsibilities = append([]FixtureResponsibility(nil), imp.Pack.Responsibilities...)
+        if !publish {
+            if err := draftNewFixtureRows(tx, &pack); err != nil { return err }
+        }
+        if report.Catalog, err = ApplyCatalogFixtures(tx, []CatalogFixture{pack}, false, now); err != nil { return err }
+        report.Catalog.DryRun = dryRun
+        if err := addFrameworkRequirements(tx, catalog, imp.Requirements, &report.Requirements); err != nil { return err }
+        if dryRun { return errFixtureDryRun }
+        return nil
+    })
+    if errors.Is(err, errFixtureDryRun) { err = nil }
+    if err != nil { return nil, err }
+    if !dryRun { InvalidateSuggestionIndex() }
+    return report, nil
+}
+
+// draftNewFixtureRows marks the pack's job roles and responsibilities that
+// do not exist yet as drafts.
+func draftNewFixtureRows(tx *gorm.DB, pack *CatalogFixture) error {
+    existing := func(table string, slugs []string) (map[string]bool, error) {
+        var found []string
+        if len(slugs) > 0 {
+            if err := tx.Table(table).Where("slug IN ?", slugs).Pluck("slug", &found).Error; err != nil { return nil, err }
+        }
+        return stringSet(found), nil
+    }
+    slugs := make([]string, 0, len(pack.JobRoles))
+    for _, r := range pack.JobRoles { slugs = append(slugs, r.Slug) }
+    roles, err := existing("job_roles", slugs)
+    if err != nil { return err }
+    for i := range pack.JobRoles {
+        if !roles[pack.JobRoles[i].Slug] { pack.JobRoles[i].Status = "draft" }
+    }
+    slugs = make([]string, 0, len(pack.Responsibilities))
+    for _, r := range pack.Responsibilities { slugs = append(slugs, r.Slug) }
+    resps, err := existing("responsibilities", slugs)
+    if err != nil { return err }
+    for i := range pack.Responsibilities {
+        if !resps[pack.Responsibilities[i].Slug] { pack.Responsibilities[i].Status = "draft" }
+    }
+    return nil
+}
+
+// resolveOne finds the catalog skill for a framework name: an exact name,
+// slug or alias, or else the only skill the name mentions ("Amazon Web
+// Services AWS software" -> aws).
+func (c *SkillCatalog) resolveOne(name string) (models.Skill, bool) {
+    if s, ok := c.Resolve(name); ok { return s, true }
+    if found := c.MatchText(name); len(found) == 1 { return found[0], true }
+    return models.Skill{}, false
+}
+
+// upsertFrameworkSkill creates or updates one framework skill by slug. A
+// skill with the slug from elsewhere (seeded, or another framework) is left
+// as it is, unless it is an unreviewed one from a user profile, which the
+// framework takes over.
+func upsertFrameworkSkill(tx *gorm.DB, catalog *SkillCatalog, source string, s FrameworkSkill, counts *FixtureCounts) error {
+    if s.Reuse {
+        if _, ok := catalog.resolveOne(s.Name); ok {
+            counts.Unchanged++
+            return nil
+        }
+    }
+    var skill models.Skill
+    res := tx.Where("slug = ?", s.Slug).Limit(1).Find(&skill)
+    if res.Error != nil { return res.Error }
+    switch {
+    case res.RowsAffected == 0:
+        skill = models.Skill{Slug: s.Slug, Name: s.Name, Category: s.Category, Kind: s.Kind, Aliases: "[]", Source: source, SourceID: s.SourceID}
+        if err := tx.Create(&skill).Error; err != nil { return err }
+        counts.Created++
+    case skill.Unreviewed:
+        skill.Name, skill.Category, skill.Kind, skill.Source, skill.SourceID, skill.Unreviewed = s.Name, s.Category, s.Kind, source, s.SourceID, false
+        if err := tx.Save(&skill).Error; err != nil { return err }
+        counts.Updated++
+    case skill.Source != source,
+        skill.Name == s.Name && skill.Category == s.Category && skill.Kind == s.Kind && skill.SourceID == s.SourceID:
+        counts.Unchanged++
+    default:
+        skill.Name, skill.Category, skill.Kind, skill.SourceID = s.Name, s.Category, s.Kind, s.SourceID
+        if err := tx.Save(&skill).Error; err != nil { return err }
+        counts.Updated++
+    }
+    catalog.add(skill)
+    return nil
+}
+
+// addFrameworkRequirements resolves requirements to catalog skills and adds
+// the missing ones. A skill several requirements of a role resolve to gets
+// the highest level and is as important as the number of them (up to 3).
+func addFrameworkRequirements(tx *gorm.DB, catalog *SkillCatalog, reqs []FrameworkRequirement, counts *FixtureCounts) error {
+    type key struct {
+        role  string
+        skill uint
+    }
+    type merged struct{ level, importance, mentions int }
+    found := map[key]*merged{}
+    var order []key
+    roleSlugs := map[string]bool{}
+    for _, r := range reqs {
+        var skills []models.Skill
+        if r.Skill != "" {
+            if s, ok := catalog.resolveOne(r.Skill); ok { skills = append(skills, s) }
+        }
+        if r.Text != "" { skills = append(skills, catalog.MatchText(r.Text)...) }
+        for _, s := range skills {
+            k := key{r.Role, s.ID}
+            m := found[k]
+            if m == nil {
+                m = &merged{}
+                found[k] = m
+                order = append(order, k)
+                roleSlugs[r.Role] = true
+            }
+            m.mentions++
+            if r.Level > m.level { m.level = r.Level }
+            if r.Importance > m.importance { m.importance = r.Importance }
+        }
+    }
+    if len(order) == 0 { return nil }
+
+    slugs := make([]string, 0, len(roleSlugs))
+    for s := range roleSlugs { slugs = append(slugs, s) }
+    sort.Strings(slugs)
+    var roles []models.JobRole
+    if err := tx.Where("slug IN ?", slugs).Find(&roles).Error; err != nil { return err }
+    roleIDs := map[string]uint{}
+    for _, r := range roles { roleIDs[r.Slug] = r.ID }
+
+    for _, k := range order {
+        m, roleID := found[k], roleIDs[k.role]
+        if roleID == 0 { continue }
+        importance := m.importance
+        if m.mentions > importance { importance = m.mentions }
+        if importance > 3 { importance = 3 }
+        skillID := k.skill
+        req := models.JobRoleRequirement{JobRoleID: roleID, SkillID: &skillID, Level: m.level, Importance: importance}
+        res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&req)
+        if res.Error != nil { return res.Error }
+        if res.RowsAffected > 0 {
+            counts.Created++
+        } else {
+            counts.Unchanged++
+        }
+    }
+    return nil
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+    "archive/zip"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "io"
+    "log"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// userTable is a table holding rows owned by a user via user_id.
+type userTable struct {
+    Name  string
+    Model interface{}
+    New   func() interface{} // pointer to an empty slice of Model
+}
+
+// userDataTables lists every user-owned table in deletion order: rows that
+// reference others come first so foreign keys are satisfied. Add new
+// user-owned models here; export and erasure both walk this list.
+var userDataTables = []userTable{
+    {"suggestion_feedbacks", &models.SuggestionFeedback{}, func() interface{} { return &[]models.SuggestionFeedback{} }},
+    {"certification_reminders", &models.CertificationReminder{}, func() interface{} { return &[]models.CertificationReminder{} }},
+    {"ce_credits", &models.CECredit{}, func() interface{} { return &[]models.CECredit{} }},
+    {"user_certifications", &models.UserCertification{}, func() interface{} { return &[]models.UserCertification{} }},
+    {"kr_grades", &models.KRGrade{}, func() interface{} { return &[]models.KRGrade{} }},
+    {"cycle_reviews", &models.CycleReview{}, func() interface{} { return &[]models.CycleReview{} }},
+    {"kr_snapshots", &models.KRSnapshot{}, func() interface{} { return &[]models.KRSnapshot{} }},
+    {"goal_revisions", &models.GoalRevision{}, func() interface{} { return &[]models.GoalRevision{} }},
+    {"progress", &models.Progress{}, func() interface{} { return &[]models.Progress{} }},
+    {"ai_goal_suggestions", &models.AIGoalSuggestion{}, func() interface{} { return &[]models.AIGoalSuggestion{} }},
+    {"learning_insights", &models.LearningInsight{}, func() interface{} { return &[]models.LearningInsight{} }},
+    {"skill_proficiency_changes", &models.SkillProficiencyChange{}, func() interface{} { return &[]models.SkillProficiencyChange{} }},
+    {"user_skills", &models.UserSkill{}, func() interface{} { return &[]models.UserSkill{} }},
+    {"profile_snapshots", &models.ProfileSnapshot{}, func() interface{} { return &[]models.ProfileSnapshot{} }},
+    {"blocked_periods", &models.BlockedPeriod{}, func() interface{} { return &[]models.BlockedPeriod{} }},
+    {"admin_grants", &models.AdminGrant{}, func() interface{} { return &[]models.AdminGrant{} }},
+    {"policy_acceptances", &models.PolicyAcceptance{}, func() interface{} { return &[]models.PolicyAcceptance{} }},
+    {"goals", &models.Goal{}, func() interface{} { return &[]models.Goal{} }},
+    {"cycles", &models.Cycle{}, func() interface{} { return &[]models.Cycle{} }},
+    {"user_profiles", &models.UserProfile{}, func() interface{} { return &[]models.UserProfile{} }},
+}
+
+// ErasedActorID replaces an erased user's id where it is kept as the actor
+// of someone else's history.
+const ErasedActorID = "00000000-0000-0000-0000-000000000000"
+
+// actorColumn names the user who changed a row they don't own: a revision
+// of another user's goal, a catalog audit entry, a published policy. Export
+// includes those rows; erasure keeps them and points the column at
+// ErasedActorID so the history stays whole without naming the user.
+type actorColumn struct {
+    Name   string // in the archive
+    Table  string
+    Column string
+    Owner  string // user_id column of tables whose own rows userDataTables already covers
+}
+
+var actorColumns = []actorColumn{
+    {"goal_revisions_as_actor", "goal_revisions", "actor_id", "user_id"},
+    {"catalog_audit_entries", "catalog_audit_entries", "actor_id", ""},
+    {"policy_documents_published", "policy_documents", "published_by", ""},
+}
+
+// ErrErasurePending is returned when the user already has an erasure scheduled.
+var ErrErasurePending = errors.New("an erasure is already scheduled")
+
+// ErrErasureNotPending is returned by ExecuteErasure when the request was
+// cancelled, completed or is being executed by another instance.
+var ErrErasureNotPending = errors.New("erasure is not pending")
+
+// ConsentRecord is the consent state carried in a data export.
+type ConsentRecord struct {
+    TermsAcceptedAt   *time.Time `json:"terms_accepted_at"`
+    PrivacyAcceptedAt *time.Time `json:"privacy_accepted_at"`
+    PoliciesVersion   string     `json:"policies_version"`
+}
+
+// UserDataArchive is everything stored about one user. Tables are keyed by
+// table name; soft-deleted rows are included.
+type UserDataArchive struct {
+    UserID      string                 `json:"user_id"`
+    GeneratedAt time.Time              `json:"generated_at"`
+    Consent     *ConsentRecord         `json:"consent"`
+    Erasure     *models.ErasureRequest `json:"erasure,omitempty"`
+    Tables      map[string]interface{} `json:"tables"`
+    Counts      map[string]int         `json:"counts"`
+}
+
+// ExportUserData collects every row tied to userID.
+func ExportUserData(db *gorm.DB, userID string, now time.Time) (*UserDataArchive, error) {
+    out := &UserDataArchive{UserID: userID, GeneratedAt: now, Tables: map[string]interface{}{}, Counts: map[string]int{}}
+    for _, t := range userDataTables {
+        rows := t.New()
+        res := db.Unscoped().Where("user_id = ?", userID).Order("id").Find(rows)
+        if res.Error != nil { return nil, res.Error }
+        out.Tables[t.Name] = rows
+        out.Counts[t.Name] = int(res.RowsAffected)
+        if profiles, ok := rows.(*[]models.UserProfile); ok && len(*profiles) > 0 {
+            p := (*profiles)[0]
+            out.Consent = &ConsentRecord{TermsAcceptedAt: p.TermsAcceptedAt, PrivacyAcceptedAt: p.PrivacyAcceptedAt, PoliciesVersion: p.PoliciesVersion}
+        }
+    }
+    for _, a := range actorColumns {
+        rows := []map[string]interface{}{}
+        q := db.Table(a.Table).Where(a.Column+" = ?", userID)
+        if a.Owner != "" { q = q.Where(a.Owner+" <> ?", userID) }
+        if err := q.Order("id").Find(&rows).Error; err != nil { return nil, err }
+        out.Tables[a.Name] = rows
+        out.Counts[a.Name] = len(rows)
+    }
+    var req models.ErasureRequest
+    if db.Where("user_id = ? AND status = 'pending'", userID).Limit(1).Find(&req).RowsAffected > 0 { out.Erasure = &req }
+    return out, nil
+}
+
+// WriteZip writes the archive as a zip with one JSON file per table plus a
+// manifest (user, consent, counts).
+func (a *UserDataArchive) WriteZip(w io.Writer) error {
+    zw := zip.NewWriter(w)
+    add := func(name string, v interface{}) error {
+        f, err := zw.Create(name)
+        if err != nil { return err }
+        enc := json.NewEncoder(f)
+        enc.SetIndent("", "  ")
+        return enc.Encode(v)
+    }
+    manifest := map[string]interface{}{
+        "user_id": a.UserID, "generated_at": a.GeneratedAt, "consent": a.Consent, "erasure": a.Erasure, "counts": a.Counts,
+    }
+    if err := add("manifest.json", manifest); err != nil { return err }
+    for _, t := range userDataTables {
+        if err := add(t.Name+".json", a.Tables[t.Name]); err != nil { return err }
+    }
+    for _, col := range actorColumns {
+        if err := add(col.Name+".json", a.Tables[col.Name]); err != nil { return err }
+    }
+    return zw.Close()
+}
+
+// SubjectHash identifies an erased user in the log without storing the id.
+func SubjectHash(userID string) string {
+    sum := sha256.Sum256([]byte(userID))
+    return hex.EncodeToString(sum[:])
+}
+
+// RequestErasure schedules erasure of userID's data graceDays from now.
+func RequestErasure(db *gorm.DB, userID, reason string, graceDays int, now time.Time) (models.ErasureRequest, error) {
+    var existing models.ErasureRequest
+    if db.Where("user_id = ? AND status = 'pending'", userID).Limit(1).Find(&existing).RowsAffected > 0 {
+        return existing, ErrErasurePending
+    }
+    uid := userID
+    req := models.ErasureRequest{
+        UserID: &uid, SubjectHash: SubjectHash(userID), Status: "pending", Reason: reason,
+        RequestedAt: now, ScheduledFor: now.AddDate(0, 0, graceDays), RowsDeleted: "{}",
+    }
+    err := db.Create(&req).Error
+    return req, err
+}
+
+// ExecuteErasure hard-deletes every row the request's user owns, including
+// soft-deleted goals, replaces the user as actor elsewhere with
+// ErasedActorID, and anonymizes the request itself. The request row is
+// claimed first (locked, skipping it if another instance holds it), so
+// concurrent sweepers never run one request twice.
+func ExecuteErasure(db *gorm.DB, req *models.ErasureRequest, now time.Time) error {
+    if req.Status != "pending" || req.UserID == nil { return ErrErasureNotPending }
+    userID := *req.UserID
+    counts := map[string]int64{}
+    err := db.Transaction(func(tx *gorm.DB) error {
+        var claimed models.ErasureRequest
+        res := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+            Where("id = ? AND status = 'pending'", req.ID).Limit(1).Find(&claimed)
+        if res.Error != nil { return res.Error }
+        if res.RowsAffected == 0 { return ErrErasureNotPending }
+        for _, t := range userDataTables {
+            res := tx.Unscoped().Where("user_id = ?", userID).Delete(t.Model)
+            if res.Error != nil { return res.Error }
+            counts[t.Name] = res.RowsAffected
+        }
+        for _, a := range actorColumns {
+            res := tx.Table(a.Table).Where(a.Column+" = ?", userID).Update(a.Column, ErasedActorID)
+            if res.Error != nil { return res.Error }
+            counts[a.Name] = res.RowsAffected
+        }
+        if err := PruneUnreviewedSkills(tx); err != nil { return err }
+        b, _ := json.Marshal(counts)
+        done := now
+        res = tx.Model(req).Where("status = 'pending'").Select("user_id", "status", "completed_at", "rows_deleted", "last_error").Updates(models.ErasureRequest{
+            UserID: nil, Status: "completed", CompletedAt: &done, RowsDeleted: string(b), LastError: "",
+        })
+        if res.Error != nil { return res.Error }
+        if res.RowsAffected == 0 { return ErrErasureNotPending }
+        return nil
+    })
+    if errors.Is(err, ErrErasureNotPending) { return err }
+    if err != nil {
+        db.Model(req).Update("last_error", err.Error())
+        return err
+    }
+    req.UserID, req.Status = nil, "completed"
+    return nil
+}
+
+// RunErasureSweeper executes due erasure requests now and then every interval.
+func RunErasureSweeper(db *gorm.DB, interval time.Duration) {
+    for {
+        var due []models.ErasureRequest
+        if err := db.Where("status = 'pending' AND scheduled_for <= ?", time.Now()).Find(&due).Error; err != nil {
+            log.Printf("erasure sweep failed: %v", err)
+        }
+        for i := range due {
+            err := ExecuteErasure(db, &due[i], time.Now())
+            if errors.Is(err, ErrErasureNotPending) { continue } // claimed elsewhere or cancelled meanwhile
+            if err != nil {
+                log.Printf("erasure %d failed: %v", due[i].ID, err)
+                continue
+            }
+            log.Printf("erasure %d completed", due[i].ID)
+        }
+        time.Sleep(interval)
+    }
+}
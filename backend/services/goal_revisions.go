@@ -0,0 +1,204 @@
+package services
+
+import (
+    "encoding/json"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// GoalSnapshot is the tracked, user-editable state of a goal. Tags and
+// Metadata are decoded so revisions diff at the nested-field level.
+type GoalSnapshot struct {
+    Title       string      `json:"title"`
+    Description string      `json:"description"`
+    Status      string      `json:"status"`
+    Priority    string      `json:"priority"`
+    DueDate     *time.Time  `json:"due_date"`
+    Tags        interface{} `json:"tags"`
+    Metadata    interface{} `json:"metadata"`
+    JobRoleID   *uint       `json:"job_role_id"`
+    CycleID     *uint       `json:"cycle_id"`
+    CompletedAt *time.Time  `json:"completed_at"`
+}
+
+// GoalRevisionView is the API shape of a stored revision.
+type GoalRevisionView struct {
+    models.GoalRevision
+    Snapshot GoalSnapshot  `json:"snapshot"`
+    Changes  []FieldChange `json:"changes"`
+}
+
+// DueDateSlip records a revision that moved the due date later.
+type DueDateSlip struct {
+    Revision  int        `json:"revision"`
+    From      *time.Time `json:"from"`
+    To        *time.Time `json:"to"`
+    SlipDays  int        `json:"slip_days"`
+    ActorID   string     `json:"actor_id"`
+    ChangedAt time.Time  `json:"changed_at"`
+}
+
+func SnapshotGoal(g models.Goal) GoalSnapshot {
+    return GoalSnapshot{
+        Title:       g.Title,
+        Description: g.Description,
+        Status:      g.Status,
+        Priority:    g.Priority,
+        DueDate:     g.DueDate,
+        Tags:        decodeJSONString(g.Tags),
+        Metadata:    decodeJSONString(g.Metadata),
+        JobRoleID:   g.JobRoleID,
+        CycleID:     g.CycleID,
+        CompletedAt: g.CompletedAt,
+    }
+}
+
+// ApplySnapshot writes snapshot fields back onto a goal for restore.
+func ApplySnapshot(g *models.Goal, s GoalSnapshot) {
+    g.Title = s.Title
+    g.Description = s.Description
+    g.Status = s.Status
+    g.Priority = s.Priority
+    g.DueDate = s.DueDate
+    g.Tags = encodeJSONString(s.Tags)
+    g.Metadata = encodeJSONString(s.Metadata)
+    g.JobRoleID = s.JobRoleID
+    g.CycleID = s.CycleID
+    g.CompletedAt = s.CompletedAt
+}
+
+// DiffGoalSnapshots returns field-level changes from a to b. Due-date moves
+// to a later date carry the slip in days.
+func DiffGoalSnapshots(a, b GoalSnapshot) []FieldChange {
+    changes := DiffJSON(a, b)
+    for i := range changes {
+        if changes[i].Field != "due_date" { continue }
+        if a.DueDate != nil && b.DueDate != nil && b.DueDate.After(*a.DueDate) {
+            days := int(b.DueDate.Sub(*a.DueDate).Hours() / 24)
+            changes[i].SlipDays = &days
+        }
+    }
+    return changes
+}
+
+// RecordGoalRevision appends a revision for goal after a write, inside tx.
+// before is the state prior to the write (nil on create). Goals that predate
+// revision tracking get a baseline revision first so the old values survive.
+func RecordGoalRevision(tx *gorm.DB, before *models.Goal, after models.Goal, actorID, action string, restoredFrom *int) (*models.GoalRevision, error) {
+    var last models.GoalRevision
+    err := tx.Where("goal_id = ?", after.ID).Order("revision DESC").Limit(1).Find(&last).Error
+    if err != nil {
+        return nil, err
+    }
+    revs, err := nextGoalRevisions(last, before, after, actorID, action, restoredFrom)
+    if err != nil { return nil, err }
+    for _, r := range revs {
+        if err := tx.Create(r).Error; err != nil { return nil, err }
+    }
+    return revs[len(revs)-1], nil
+}
+
+// nextGoalRevisions builds the rows RecordGoalRevision appends after last
+// (zero if the goal has none yet): a baseline of before when the goal
+// predates revision tracking, then the revision for after.
+func nextGoalRevisions(last models.GoalRevision, before *models.Goal, after models.Goal, actorID, action string, restoredFrom *int) ([]*models.GoalRevision, error) {
+    var out []*models.GoalRevision
+    var prev *GoalSnapshot
+    next := last.Revision + 1
+    if last.ID != 0 {
+        var s GoalSnapshot
+        if err := json.Unmarshal([]byte(last.Snapshot), &s); err == nil { prev = &s }
+    } else if before != nil {
+        s := SnapshotGoal(*before)
+        baseline, err := newGoalRevision(after, actorID, "baseline", 1, nil, s, []FieldChange{})
+        if err != nil { return nil, err }
+        out = append(out, baseline)
+        prev = &s
+        next = 2
+    }
+
+    snap := SnapshotGoal(after)
+    changes := []FieldChange{}
+    if prev != nil { changes = DiffGoalSnapshots(*prev, snap) }
+
+    rev, err := newGoalRevision(after, actorID, action, next, restoredFrom, snap, changes)
+    if err != nil { return nil, err }
+    return append(out, rev), nil
+}
+
+func newGoalRevision(g models.Goal, actorID, action string, n int, restoredFrom *int, snap GoalSnapshot, changes []FieldChange) (*models.GoalRevision, error) {
+    sb, err := json.Marshal(snap)
+    if err != nil { return nil, err }
+    cb, err := json.Marshal(changes)
+    if err != nil { return nil, err }
+    return &models.GoalRevision{
+        UserID:       g.UserID,
+        GoalID:       g.ID,
+        Revision:     n,
+        Action:       action,
+        ActorID:      actorID,
+        RestoredFrom: restoredFrom,
+        Snapshot:     string(sb),
+        Changes:      string(cb),
+    }, nil
+}
+
+// ViewGoalRevision decodes the stored JSON columns for API output.
+func ViewGoalRevision(r models.GoalRevision) GoalRevisionView {
+    v := GoalRevisionView{GoalRevision: r, Changes: []FieldChange{}}
+    _ = json.Unmarshal([]byte(r.Snapshot), &v.Snapshot)
+    if strings.TrimSpace(r.Changes) != "" { _ = json.Unmarshal([]byte(r.Changes), &v.Changes) }
+    return v
+}
+
+// DueDateSlips extracts every revision that pushed the due date later,
+// oldest first.
+func DueDateSlips(revisions []GoalRevisionView) []DueDateSlip {
+    slips := []DueDateSlip{}
+    for _, r := range revisions {
+        for _, ch := range r.Changes {
+            if ch.Field != "due_date" || ch.SlipDays == nil { continue }
+            slips = append(slips, DueDateSlip{
+                Revision:  r.Revision,
+                From:      parseTimeValue(ch.From),
+                To:        parseTimeValue(ch.To),
+                SlipDays:  *ch.SlipDays,
+                ActorID:   r.ActorID,
+                ChangedAt: r.CreatedAt,
+            })
+        }
+    }
+    sort.Slice(slips, func(i, j int) bool { return slips[i].Revision < slips[j].Revision })
+    return slips
+}
+
+func decodeJSONString(s string) interface{} {
+    if strings.TrimSpace(s) == "" { return nil }
+    var v interface{}
+    if err := json.Unmarshal([]byte(s), &v); err != nil {
+        // legacy non-JSON content is kept verbatim
+        return s
+    }
+    return v
+}
+
+func encodeJSONString(v interface{}) string {
+    if v == nil { return "" }
+    if s, ok := v.(string); ok { return s }
+    b, err := json.Marshal(v)
+    if err != nil { return "" }
+    return string(b)
+}
+
+func parseTimeValue(v interface{}) *time.Time {
+    s, ok := v.(string)
+    if !ok { return nil }
+    t, err := time.Parse(time.RFC3339, s)
+    if err != nil { return nil }
+    return &t
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+    "encoding/json"
+    "reflect"
+    "testing"
+    "time"
+
+    "goaltracker/models"
+)
+
+func testDay(d int) *time.Time {
+    t := time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC)
+    return &t
+}
+
+func TestDiffGoalSnapshots(t *testing.T) {
+    slip := func(n int) *int { return &n }
+    base := models.Goal{
+        Title: "Learn Go", Status: "active", Priority: "medium", DueDate: testDay(10),
+        Tags: `["go"]`, Metadata: `{"smart":{"specific":"ship a CLI","measurable":"3 commands"}}`,
+    }
+    tests := []struct {
+        name   string
+        change func(g *models.Goal)
+        want   []FieldChange
+    }{
+        {name: "no change", change: func(g *models.Goal) {}, want: []FieldChange{}},
+        {
+            name:   "top-level field",
+            change: func(g *models.Goal) { g.Title = "Learn Rust" },
+            want:   []FieldChange{{Field: "title", From: "Learn Go", To: "Learn Rust"}},
+        },
+        {
+            name:   "nested metadata path",
+            change: func(g *models.Goal) { g.Metadata = `{"smart":{"specific":"ship a library","measurable":"3 commands"}}` },
+            want:   []FieldChange{{Field: "metadata.smart.specific", From: "ship a CLI", To: "ship a library"}},
+        },
+        {
+            name:   "added metadata key",
+            change: func(g *models.Goal) { g.Metadata = `{"smart":{"specific":"ship a CLI","measurable":"3 commands","relevant":"team"}}` },
+            want:   []FieldChange{{Field: "metadata.smart.relevant", From: nil, To: "team"}},
+        },
+        {
+            name:   "arrays compare whole",
+            change: func(g *models.Goal) { g.Tags = `["go","cli"]` },
+            want:   []FieldChange{{Field: "tags", From: []interface{}{"go"}, To: []interface{}{"go", "cli"}}},
+        },
+        {
+            name:   "later due date slips",
+            change: func(g *models.Goal) { g.DueDate = testDay(13) },
+            want:   []FieldChange{{Field: "due_date", From: "2026-03-10T00:00:00Z", To: "2026-03-13T00:00:00Z", SlipDays: slip(3)}},
+        },
+        {
+            name:   "earlier due date does not slip",
+            change: func(g *models.Goal) { g.DueDate = testDay(8) },
+            want:   []FieldChange{{Field: "due_date", From: "2026-03-10T00:00:00Z", To: "2026-03-08T00:00:00Z"}},
+        },
+        {
+            name:   "cleared due date does not slip",
+            change: func(g *models.Goal) { g.DueDate = nil },
+            want:   []FieldChange{{Field: "due_date", From: "2026-03-10T00:00:00Z", To: nil}},
+        },
+        {
+            name:   "changes sorted by field",
+            change: func(g *models.Goal) { g.Status, g.Priority = "paused", "high" },
+            want: []FieldChange{
+                {Field: "priority", From: "medium", To: "high"},
+                {Field: "status", From: "active", To: "paused"},
+            },
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            after := base
+            tt.change(&after)
+            got := DiffGoalSnapshots(SnapshotGoal(base), SnapshotGoal(after))
+            if !reflect.DeepEqual(got, tt.want) { t.Errorf("got %+v, want %+v", got, tt.want) }
+        })
+    }
+}
+
+func TestNextGoalRevisions(t *testing.T) {
+    before := models.Goal{ID: 7, UserID: "u1", Title: "Old", Status: "active", Priority: "low"}
+    after := before
+    after.Title = "New"
+    snapshot := func(g models.Goal) string {
+        b, _ := json.Marshal(SnapshotGoal(g))
+        return string(b)
+    }
+    tests := []struct {
+        name        string
+        last        models.GoalRevision
+        before      *models.Goal
+        action      string
+        wantActions []string
+        wantNumbers []int
+        wantChanges []FieldChange // of the last revision
+    }{
+        {
+            name: "create has no diff", before: nil, action: "create",
+            wantActions: []string{"create"}, wantNumbers: []int{1}, wantChanges: []FieldChange{},
+        },
+        {
+            name: "untracked goal gets a baseline first", before: &before, action: "update",
+            wantActions: []string{"baseline", "update"}, wantNumbers: []int{1, 2},
+            wantChanges: []FieldChange{{Field: "title", From: "Old", To: "New"}},
+        },
+        {
+            name: "tracked goal diffs against the last snapshot", before: &after, action: "update",
+            last:        models.GoalRevision{ID: 40, Revision: 4, Snapshot: snapshot(before)},
+            wantActions: []string{"update"}, wantNumbers: []int{5},
+            wantChanges: []FieldChange{{Field: "title", From: "Old", To: "New"}},
+        },
+        {
+            name: "unreadable last snapshot records no diff", before: &before, action: "update",
+            last:        models.GoalRevision{ID: 40, Revision: 4, Snapshot: "not json"},
+            wantActions: []string{"update"}, wantNumbers: []int{5}, wantChanges: []FieldChange{},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            revs, err := nextGoalRevisions(tt.last, tt.before, after, "actor", tt.action, nil)
+            if err != nil { t.Fatal(err) }
+            var actions []string
+            var numbers []int
+            for _, r := range revs {
+                actions, numbers = append(actions, r.Action), append(numbers, r.Revision)
+                if r.GoalID != 7 || r.UserID != "u1" || r.ActorID != "actor" { t.Errorf("revision %d: wrong goal, owner or actor: %+v", r.Revision, r) }
+            }
+            if !reflect.DeepEqual(actions, tt.wantActions) || !reflect.DeepEqual(numbers, tt.wantNumbers) {
+                t.Fatalf("got %v %v, want %v %v", actions, numbers, tt.wantActions, tt.wantNumbers)
+            }
+
+            last := ViewGoalRevision(*revs[len(revs)-1])
+            if last.Snapshot.Title != "New" { t.Errorf("snapshot title = %q, want New", last.Snapshot.Title) }
+            if !reflect.DeepEqual(last.Changes, tt.wantChanges) { t.Errorf("changes = %+v, want %+v", last.Changes, tt.wantChanges) }
+            if tt.wantActions[0] == "baseline" {
+                baseline := ViewGoalRevision(*revs[0])
+                if baseline.Snapshot.Title != "Old" || len(baseline.Changes) != 0 { t.Errorf("baseline = %+v, want the old state and no changes", baseline) }
+            }
+        })
+    }
+}
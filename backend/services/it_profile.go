@@ -0,0 +1,337 @@
+package services
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+
+    "goaltracker/i18n"
+    "goaltracker/models"
+)
+
+// Controlled vocabularies for the IT profile. Matching is case-insensitive;
+// stored values use the spelling below.
+var (
+    ExperienceLevels      = []string{"entry", "junior", "mid", "senior", "lead", "expert"}
+    FrameworkLevels       = []string{"Awareness", "Working", "Practitioner", "Expert"}
+    PlatformDepths        = []string{"Basic", "Intermediate", "Advanced", "Expert"}
+    CertificationStatuses = []string{"planned", "in_progress", "earned", "expired"}
+)
+
+// Size limits for IT profile fields
+const (
+    itMaxItems      = 50
+    itMaxNameLength = 100
+    itMaxLinkLength = 500
+)
+
+// FieldError is one validation failure addressed by a JSON path such as
+// "frameworks[2].level". Code and Args name the message in the i18n
+// catalog; Message is only filled in, in the client's locale, by
+// LocalizeFieldErrors.
+type FieldError struct {
+    Field   string        `json:"field"`
+    Code    string        `json:"code"`
+    Args    []interface{} `json:"-"`
+    Message string        `json:"message"`
+}
+
+// NewFieldError returns a FieldError for field with a message code.
+func NewFieldError(field, code string, args ...interface{}) FieldError {
+    return FieldError{Field: field, Code: code, Args: args}
+}
+
+// Text renders e's message in locale.
+func (e FieldError) Text(locale string) string {
+    return i18n.Text(locale, e.Code, e.Args...)
+}
+
+// LocalizeFieldErrors returns a copy of errs with each Message rendered in
+// locale.
+func LocalizeFieldErrors(errs []FieldError, locale string) []FieldError {
+    out := make([]FieldError, len(errs))
+    for i, e := range errs {
+        e.Message = e.Text(locale)
+        out[i] = e
+    }
+    return out
+}
+
+// ParseITProfile strictly decodes and validates an IT profile document and
+// returns it normalized (trimmed, canonical vocabulary spelling, current
+// schema_version). Unknown fields are rejected.
+func ParseITProfile(raw []byte) (models.ITProfile, []FieldError) {
+    var it models.ITProfile
+    if len(bytes.TrimSpace(raw)) == 0 || string(bytes.TrimSpace(raw)) == "null" {
+        it.SchemaVersion = models.ITProfileSchemaVersion
+        return it, nil
+    }
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(&it); err != nil {
+        return it, []FieldError{decodeFieldError(err)}
+    }
+    v := &itValidator{}
+    v.profile(&it)
+    return it, v.errs
+}
+
+// DecodeITProfile leniently reads a stored IT profile for scoring and
+// prompts. Malformed data yields an empty profile.
+func DecodeITProfile(s string) models.ITProfile {
+    var it models.ITProfile
+    if strings.TrimSpace(s) != "" {
+        _ = json.Unmarshal([]byte(s), &it)
+    }
+    return it
+}
+
+// EncodeITProfile is the stored form of a normalized profile.
+func EncodeITProfile(it models.ITProfile) string {
+    b, err := json.Marshal(it)
+    if err != nil {
+        return "{}"
+    }
+    return string(b)
+}
+
+type itValidator struct {
+    errs []FieldError
+}
+
+func (v *itValidator) add(field, code string, args ...interface{}) {
+    v.errs = append(v.errs, NewFieldError(field, code, args...))
+}
+
+func (v *itValidator) profile(it *models.ITProfile) {
+    switch {
+    case it.SchemaVersion == 0:
+        it.SchemaVersion = models.ITProfileSchemaVersion
+    case it.SchemaVersion != models.ITProfileSchemaVersion:
+        v.add("schema_version", "value_unsupported_schema_version", it.SchemaVersion, models.ITProfileSchemaVersion)
+    }
+
+    it.Role.Current = v.text("role.current", it.Role.Current, false)
+    it.Role.Track = v.text("role.track", it.Role.Track, false)
+    it.Role.Target = v.text("role.target", it.Role.Target, false)
+    it.Role.Level = v.vocab("role.level", it.Role.Level, ExperienceLevels)
+
+    it.Subdomains = v.list("subdomains", it.Subdomains)
+    it.KPIs = v.list("kpis", it.KPIs)
+    it.Jurisdictions = v.list("jurisdictions", it.Jurisdictions)
+
+    v.count("frameworks", len(it.Frameworks))
+    for i := range it.Frameworks {
+        f := &it.Frameworks[i]
+        path := fmt.Sprintf("frameworks[%d]", i)
+        f.Name = v.text(path+".name", f.Name, true)
+        f.Level = v.vocab(path+".level", f.Level, FrameworkLevels)
+    }
+
+    v.count("certifications", len(it.Certifications))
+    for i := range it.Certifications {
+        cert := &it.Certifications[i]
+        path := fmt.Sprintf("certifications[%d]", i)
+        cert.Name = v.text(path+".name", cert.Name, true)
+        cert.Status = v.vocab(path+".status", cert.Status, CertificationStatuses)
+        cert.Expires = v.date(path+".expires", cert.Expires)
+        cert.PlannedBy = v.date(path+".planned_by", cert.PlannedBy)
+    }
+
+    v.count("platforms", len(it.Platforms))
+    for i := range it.Platforms {
+        p := &it.Platforms[i]
+        path := fmt.Sprintf("platforms[%d]", i)
+        p.Name = v.text(path+".name", p.Name, true)
+        p.Depth = v.vocab(path+".depth", p.Depth, PlatformDepths)
+        p.LastUsed = v.date(path+".last_used", p.LastUsed)
+    }
+
+    if env := it.Environment; env != nil {
+        v.nonNegative("environment.clusters", env.Clusters)
+        v.nonNegative("environment.users_supported", env.UsersSupported)
+        v.nonNegative("environment.p95_latency_ms", env.P95LatencyMs)
+        if env.UptimeSLOPercent < 0 || env.UptimeSLOPercent > 100 {
+            v.add("environment.uptime_slo_percent", "value_out_of_range", 0, 100)
+        }
+        env.Regions = v.list("environment.regions", env.Regions)
+    }
+
+    if up := it.Upskilling; up != nil {
+        if up.HoursPerWeek < 0 || up.HoursPerWeek > 168 {
+            v.add("upskilling.hours_per_week", "value_out_of_range", 0, 168)
+        }
+        up.Format = v.list("upskilling.format", up.Format)
+    }
+
+    v.count("evidence", len(it.Evidence))
+    for i := range it.Evidence {
+        e := &it.Evidence[i]
+        path := fmt.Sprintf("evidence[%d]", i)
+        e.Title = v.text(path+".title", e.Title, true)
+        e.Link = strings.TrimSpace(e.Link)
+        if e.Link == "" { continue }
+        if len(e.Link) > itMaxLinkLength {
+            v.add(path+".link", "value_too_long", itMaxLinkLength)
+        } else if u, err := url.Parse(e.Link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            v.add(path+".link", "value_not_http_url")
+        }
+    }
+}
+
+func (v *itValidator) text(field, s string, required bool) string {
+    s = strings.TrimSpace(s)
+    if required && s == "" {
+        v.add(field, "value_required")
+    }
+    if len(s) > itMaxNameLength {
+        v.add(field, "value_too_long", itMaxNameLength)
+    }
+    return s
+}
+
+func (v *itValidator) list(field string, items []string) []string {
+    v.count(field, len(items))
+    out := make([]string, 0, len(items))
+    for i, s := range items {
+        s = v.text(fmt.Sprintf("%s[%d]", field, i), s, true)
+        if s != "" { out = append(out, s) }
+    }
+    if len(out) == 0 { return nil }
+    return out
+}
+
+func (v *itValidator) count(field string, n int) {
+    if n > itMaxItems {
+        v.add(field, "value_too_many_items", itMaxItems)
+    }
+}
+
+func (v *itValidator) nonNegative(field string, n int) {
+    if n < 0 {
+        v.add(field, "value_negative")
+    }
+}
+
+// vocab returns the canonical spelling of s, or records an error. Empty is allowed.
+func (v *itValidator) vocab(field, s string, allowed []string) string {
+    s = strings.TrimSpace(s)
+    if s == "" { return "" }
+    for _, a := range allowed {
+        if strings.EqualFold(a, s) { return a }
+    }
+    v.add(field, "value_not_one_of", strings.Join(allowed, ", "))
+    return s
+}
+
+func (v *itValidator) date(field, s string) string {
+    s = strings.TrimSpace(s)
+    if s == "" { return "" }
+    if _, err := time.Parse("2006-01-02", s); err == nil { return s }
+    if _, err := time.Parse("2006-01", s); err == nil { return s }
+    v.add(field, "value_not_month_or_date")
+    return s
+}
+
+func decodeFieldError(err error) FieldError {
+    var typeErr *json.UnmarshalTypeError
+    if errors.As(err, &typeErr) {
+        return NewFieldError(typeErr.Field, "value_wrong_type", jsonTypeName(typeErr.Type.Kind().String()))
+    }
+    msg := strings.TrimPrefix(err.Error(), "json: ")
+    if strings.HasPrefix(msg, "unknown field ") {
+        return NewFieldError(strings.Trim(strings.TrimPrefix(msg, "unknown field "), `"`), "value_unknown_field")
+    }
+    return NewFieldError("", "value_not_json", msg)
+}
+
+// jsonTypeName maps a Go kind onto the JSON type the field expects.
+func jsonTypeName(kind string) string {
+    switch kind {
+    case "bool":
+        return "boolean"
+    case "slice":
+        return "array"
+    case "struct", "ptr", "map":
+        return "object"
+    case "float64", "int":
+        return "number"
+    default:
+        return kind
+    }
+}
+
+// ITProfileJSONSchema describes models.ITProfile as a JSON Schema document
+// (draft 2020-12). Enums come from the vocabularies above so the served
+// schema and server-side validation cannot drift apart.
+func ITProfileJSONSchema() map[string]interface{} {
+    str := func(max int) map[string]interface{} {
+        return map[string]interface{}{"type": "string", "maxLength": max}
+    }
+    enum := func(values []string) map[string]interface{} {
+        return map[string]interface{}{"type": "string", "enum": values}
+    }
+    date := map[string]interface{}{
+        "type": "string", "pattern": `^\d{4}-\d{2}(-\d{2})?$`, "description": "YYYY-MM or YYYY-MM-DD",
+    }
+    list := func(items map[string]interface{}) map[string]interface{} {
+        return map[string]interface{}{"type": "array", "maxItems": itMaxItems, "items": items}
+    }
+    object := func(props map[string]interface{}, required ...string) map[string]interface{} {
+        o := map[string]interface{}{"type": "object", "additionalProperties": false, "properties": props}
+        if len(required) > 0 { o["required"] = required }
+        return o
+    }
+    nonNeg := map[string]interface{}{"type": "integer", "minimum": 0}
+
+    schema := object(map[string]interface{}{
+        "schema_version": map[string]interface{}{"type": "integer", "const": models.ITProfileSchemaVersion},
+        "role": object(map[string]interface{}{
+            "current": str(itMaxNameLength),
+            "level":   enum(ExperienceLevels),
+            "track":   str(itMaxNameLength),
+            "target":  str(itMaxNameLength),
+        }),
+        "subdomains": list(str(itMaxNameLength)),
+        "frameworks": list(object(map[string]interface{}{
+            "name":  str(itMaxNameLength),
+            "level": enum(FrameworkLevels),
+        }, "name")),
+        "certifications": list(object(map[string]interface{}{
+            "name":       str(itMaxNameLength),
+            "status":     enum(CertificationStatuses),
+            "expires":    date,
+            "planned_by": date,
+        }, "name")),
+        "platforms": list(object(map[string]interface{}{
+            "name":      str(itMaxNameLength),
+            "depth":     enum(PlatformDepths),
+            "last_used": date,
+        }, "name")),
+        "environment": object(map[string]interface{}{
+            "clusters":           nonNeg,
+            "regions":            list(str(itMaxNameLength)),
+            "users_supported":    nonNeg,
+            "uptime_slo_percent": map[string]interface{}{"type": "number", "minimum": 0, "maximum": 100},
+            "p95_latency_ms":     nonNeg,
+        }),
+        "kpis":          list(str(itMaxNameLength)),
+        "jurisdictions": list(str(itMaxNameLength)),
+        "upskilling": object(map[string]interface{}{
+            "hours_per_week": map[string]interface{}{"type": "integer", "minimum": 0, "maximum": 168},
+            "format":         list(str(itMaxNameLength)),
+        }),
+        "evidence": list(object(map[string]interface{}{
+            "title": str(itMaxNameLength),
+            "link":  map[string]interface{}{"type": "string", "format": "uri", "maxLength": itMaxLinkLength},
+        }, "title")),
+    })
+    schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+    schema["$id"] = fmt.Sprintf("/api/v1/schemas/it-profile/v%d", models.ITProfileSchemaVersion)
+    schema["title"] = "IT profile"
+    return schema
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+    "encoding/json"
+    "reflect"
+    "sort"
+)
+
+// FieldChange is a single field-level difference. Nested JSON objects are
+// flattened into dotted paths (e.g. "metadata.smart.specific"); arrays are
+// compared as whole values.
+type FieldChange struct {
+    Field    string      `json:"field"`
+    From     interface{} `json:"from"`
+    To       interface{} `json:"to"`
+    SlipDays *int        `json:"slip_days,omitempty"`
+}
+
+// DiffJSON normalizes both values through JSON and returns the changed paths,
+// sorted by field name. Either side may be nil.
+func DiffJSON(before, after interface{}) []FieldChange {
+    a := normalizeJSON(before)
+    b := normalizeJSON(after)
+    changes := []FieldChange{}
+    diffValues("", a, b, &changes)
+    sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+    return changes
+}
+
+func normalizeJSON(v interface{}) interface{} {
+    if v == nil { return nil }
+    b, err := json.Marshal(v)
+    if err != nil { return nil }
+    var out interface{}
+    if err := json.Unmarshal(b, &out); err != nil { return nil }
+    return out
+}
+
+func diffValues(path string, a, b interface{}, out *[]FieldChange) {
+    am, aIsMap := a.(map[string]interface{})
+    bm, bIsMap := b.(map[string]interface{})
+    if aIsMap && bIsMap {
+        keys := map[string]struct{}{}
+        for k := range am { keys[k] = struct{}{} }
+        for k := range bm { keys[k] = struct{}{} }
+        for k := range keys {
+            diffValues(joinPath(path, k), am[k], bm[k], out)
+        }
+        return
+    }
+    if !reflect.DeepEqual(a, b) {
+        *out = append(*out, FieldChange{Field: path, From: a, To: b})
+    }
+}
+
+func joinPath(prefix, key string) string {
+    if prefix == "" { return key }
+    return prefix + "." + key
+}
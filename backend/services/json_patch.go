@@ -0,0 +1,239 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "strconv"
+    "strings"
+)
+
+// Content types accepted by PATCH endpoints
+const (
+    MergePatchContentType = "application/merge-patch+json"
+    JSONPatchContentType  = "application/json-patch+json"
+)
+
+// ErrPatchTestFailed is returned when a JSON Patch "test" operation does not match.
+var ErrPatchTestFailed = errors.New("test operation failed")
+
+// JSONPatchOp is one RFC 6902 operation. Value stays raw so an explicit
+// null can be told apart from a missing value.
+type JSONPatchOp struct {
+    Op    string          `json:"op"`
+    Path  string          `json:"path"`
+    From  string          `json:"from,omitempty"`
+    Value json.RawMessage `json:"value,omitempty"`
+}
+
+// MergePatch applies an RFC 7386 merge patch to target and returns the
+// result. target is not modified.
+func MergePatch(target, patch interface{}) interface{} {
+    pm, ok := patch.(map[string]interface{})
+    if !ok {
+        return deepCopyJSON(patch)
+    }
+    out := map[string]interface{}{}
+    if tm, ok := target.(map[string]interface{}); ok {
+        for k, v := range tm { out[k] = deepCopyJSON(v) }
+    }
+    for k, v := range pm {
+        if v == nil {
+            delete(out, k)
+            continue
+        }
+        out[k] = MergePatch(out[k], v)
+    }
+    return out
+}
+
+// ApplyJSONPatch applies RFC 6902 operations in order to a copy of doc.
+// Either every operation succeeds or an error is returned and doc is untouched.
+func ApplyJSONPatch(doc interface{}, ops []JSONPatchOp) (interface{}, error) {
+    out := deepCopyJSON(doc)
+    for i, op := range ops {
+        var err error
+        out, err = applyPatchOp(out, op)
+        if err != nil {
+            return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+        }
+    }
+    return out, nil
+}
+
+func applyPatchOp(doc interface{}, op JSONPatchOp) (interface{}, error) {
+    path, err := parsePointer(op.Path)
+    if err != nil { return nil, err }
+
+    value := func() (interface{}, error) {
+        if op.Value == nil { return nil, errors.New("value is required") }
+        var v interface{}
+        if err := json.Unmarshal(op.Value, &v); err != nil { return nil, errors.New("invalid value") }
+        return v, nil
+    }
+
+    switch op.Op {
+    case "add":
+        v, err := value()
+        if err != nil { return nil, err }
+        return mutateAt(doc, path, func(c interface{}, key string) (interface{}, error) { return addChild(c, key, v) }, v)
+    case "remove":
+        if len(path) == 0 { return nil, errors.New("cannot remove the document root") }
+        return mutateAt(doc, path, removeChild, nil)
+    case "replace":
+        v, err := value()
+        if err != nil { return nil, err }
+        if _, err := getAt(doc, path); err != nil { return nil, err }
+        return mutateAt(doc, path, func(c interface{}, key string) (interface{}, error) { return setChild(c, key, v) }, v)
+    case "move", "copy":
+        from, err := parsePointer(op.From)
+        if err != nil { return nil, err }
+        v, err := getAt(doc, from)
+        if err != nil { return nil, fmt.Errorf("from: %w", err) }
+        v = deepCopyJSON(v)
+        if op.Op == "move" {
+            if isPrefix(from, path) && len(from) < len(path) {
+                return nil, errors.New("cannot move a value into its own child")
+            }
+            if doc, err = mutateAt(doc, from, removeChild, nil); err != nil { return nil, err }
+        }
+        return mutateAt(doc, path, func(c interface{}, key string) (interface{}, error) { return addChild(c, key, v) }, v)
+    case "test":
+        v, err := value()
+        if err != nil { return nil, err }
+        cur, err := getAt(doc, path)
+        if err != nil { return nil, err }
+        if !reflect.DeepEqual(cur, v) { return nil, ErrPatchTestFailed }
+        return doc, nil
+    default:
+        return nil, fmt.Errorf("unsupported op %q", op.Op)
+    }
+}
+
+// parsePointer splits an RFC 6901 JSON Pointer into unescaped tokens.
+func parsePointer(p string) ([]string, error) {
+    if p == "" { return []string{}, nil }
+    if !strings.HasPrefix(p, "/") { return nil, fmt.Errorf("invalid JSON pointer %q", p) }
+    parts := strings.Split(p[1:], "/")
+    for i, t := range parts {
+        parts[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+    }
+    return parts, nil
+}
+
+func getAt(doc interface{}, path []string) (interface{}, error) {
+    cur := doc
+    for _, key := range path {
+        switch c := cur.(type) {
+        case map[string]interface{}:
+            v, ok := c[key]
+            if !ok { return nil, fmt.Errorf("path not found at %q", key) }
+            cur = v
+        case []interface{}:
+            i, err := arrayIndex(key, len(c), false)
+            if err != nil { return nil, err }
+            cur = c[i]
+        default:
+            return nil, fmt.Errorf("path not found at %q", key)
+        }
+    }
+    return cur, nil
+}
+
+// mutateAt walks to the parent of path and lets leaf rewrite it. An empty
+// path replaces the whole document with root.
+func mutateAt(doc interface{}, path []string, leaf func(container interface{}, key string) (interface{}, error), root interface{}) (interface{}, error) {
+    if len(path) == 0 { return root, nil }
+    if len(path) == 1 { return leaf(doc, path[0]) }
+    child, err := getAt(doc, path[:1])
+    if err != nil { return nil, err }
+    updated, err := mutateAt(child, path[1:], leaf, root)
+    if err != nil { return nil, err }
+    return setChild(doc, path[0], updated)
+}
+
+func addChild(container interface{}, key string, v interface{}) (interface{}, error) {
+    switch c := container.(type) {
+    case map[string]interface{}:
+        c[key] = v
+        return c, nil
+    case []interface{}:
+        if key == "-" { return append(c, v), nil }
+        i, err := arrayIndex(key, len(c), true)
+        if err != nil { return nil, err }
+        c = append(c, nil)
+        copy(c[i+1:], c[i:])
+        c[i] = v
+        return c, nil
+    default:
+        return nil, fmt.Errorf("cannot add %q to a non-container", key)
+    }
+}
+
+func setChild(container interface{}, key string, v interface{}) (interface{}, error) {
+    switch c := container.(type) {
+    case map[string]interface{}:
+        c[key] = v
+        return c, nil
+    case []interface{}:
+        i, err := arrayIndex(key, len(c), false)
+        if err != nil { return nil, err }
+        c[i] = v
+        return c, nil
+    default:
+        return nil, fmt.Errorf("path not found at %q", key)
+    }
+}
+
+func removeChild(container interface{}, key string) (interface{}, error) {
+    switch c := container.(type) {
+    case map[string]interface{}:
+        if _, ok := c[key]; !ok { return nil, fmt.Errorf("path not found at %q", key) }
+        delete(c, key)
+        return c, nil
+    case []interface{}:
+        i, err := arrayIndex(key, len(c), false)
+        if err != nil { return nil, err }
+        return append(c[:i], c[i+1:]...), nil
+    default:
+        return nil, fmt.Errorf("path not found at %q", key)
+    }
+}
+
+// arrayIndex parses a pointer token as an array index. allowEnd permits
+// index == length (insert at end).
+func arrayIndex(key string, length int, allowEnd bool) (int, error) {
+    if key == "" || (len(key) > 1 && key[0] == '0') {
+        return 0, fmt.Errorf("invalid array index %q", key)
+    }
+    i, err := strconv.Atoi(key)
+    if err != nil || i < 0 { return 0, fmt.Errorf("invalid array index %q", key) }
+    if i > length || (i == length && !allowEnd) {
+        return 0, fmt.Errorf("array index %d out of range", i)
+    }
+    return i, nil
+}
+
+func isPrefix(prefix, path []string) bool {
+    if len(prefix) > len(path) { return false }
+    for i := range prefix {
+        if prefix[i] != path[i] { return false }
+    }
+    return true
+}
+
+func deepCopyJSON(v interface{}) interface{} {
+    switch t := v.(type) {
+    case map[string]interface{}:
+        out := make(map[string]interface{}, len(t))
+        for k, val := range t { out[k] = deepCopyJSON(val) }
+        return out
+    case []interface{}:
+        out := make([]interface{}, len(t))
+        for i, val := range t { out[i] = deepCopyJSON(val) }
+        return out
+    default:
+        return v
+    }
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "reflect"
+    "testing"
+)
+
+func decodeTestJSON(t *testing.T, s string) interface{} {
+    t.Helper()
+    var v interface{}
+    if err := json.Unmarshal([]byte(s), &v); err != nil { t.Fatalf("bad test JSON %s: %v", s, err) }
+    return v
+}
+
+// The cases follow RFC 6902 Appendix A, plus pointer escaping from RFC 6901.
+func TestApplyJSONPatch(t *testing.T) {
+    tests := []struct {
+        name    string
+        doc     string
+        patch   string
+        want    string
+        wantErr bool
+        failed  bool // ErrPatchTestFailed
+    }{
+        {name: "A.1 add object member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"baz":"qux","foo":"bar"}`},
+        {name: "A.2 add array element", doc: `{"foo":["bar","baz"]}`, patch: `[{"op":"add","path":"/foo/1","value":"qux"}]`, want: `{"foo":["bar","qux","baz"]}`},
+        {name: "A.3 remove object member", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"remove","path":"/baz"}]`, want: `{"foo":"bar"}`},
+        {name: "A.4 remove array element", doc: `{"foo":["bar","qux","baz"]}`, patch: `[{"op":"remove","path":"/foo/1"}]`, want: `{"foo":["bar","baz"]}`},
+        {name: "A.5 replace value", doc: `{"baz":"qux","foo":"bar"}`, patch: `[{"op":"replace","path":"/baz","value":"boo"}]`, want: `{"baz":"boo","foo":"bar"}`},
+        {
+            name:  "A.6 move value",
+            doc:   `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`,
+            patch: `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`,
+            want:  `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`,
+        },
+        {name: "A.7 move array element", doc: `{"foo":["all","grass","cows","eat"]}`, patch: `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, want: `{"foo":["all","cows","eat","grass"]}`},
+        {
+            name:  "A.8 test success",
+            doc:   `{"baz":"qux","foo":["a",2,"c"]}`,
+            patch: `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`,
+            want:  `{"baz":"qux","foo":["a",2,"c"]}`,
+        },
+        {name: "A.9 test failure", doc: `{"baz":"qux"}`, patch: `[{"op":"test","path":"/baz","value":"bar"}]`, wantErr: true, failed: true},
+        {name: "A.10 add nested member", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/child","value":{"grandchild":{}}}]`, want: `{"foo":"bar","child":{"grandchild":{}}}`},
+        {name: "A.12 add to nonexistent target", doc: `{"foo":"bar"}`, patch: `[{"op":"add","path":"/baz/bat","value":"qux"}]`, wantErr: true},
+        {name: "A.14 ~0 and ~1 escaping", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":10}]`, want: `{"/":9,"~1":10}`},
+        {name: "A.15 test compares types", doc: `{"/":9,"~1":10}`, patch: `[{"op":"test","path":"/~01","value":"10"}]`, wantErr: true, failed: true},
+        {name: "A.16 add array value", doc: `{"foo":["bar"]}`, patch: `[{"op":"add","path":"/foo/-","value":["abc","def"]}]`, want: `{"foo":["bar",["abc","def"]]}`},
+        {name: "~1 addresses a slash in a key", doc: `{"a/b":1}`, patch: `[{"op":"replace","path":"/a~1b","value":2}]`, want: `{"a/b":2}`},
+        {name: "~01 unescapes to ~1 not /", doc: `{"~1":1,"/":2}`, patch: `[{"op":"remove","path":"/~01"}]`, want: `{"/":2}`},
+        {name: "- appends to an array", doc: `{"tags":["a"]}`, patch: `[{"op":"add","path":"/tags/-","value":"b"}]`, want: `{"tags":["a","b"]}`},
+        {name: "- is not an index for replace", doc: `{"tags":["a"]}`, patch: `[{"op":"replace","path":"/tags/-","value":"b"}]`, wantErr: true},
+        {name: "index past the end", doc: `{"tags":["a"]}`, patch: `[{"op":"add","path":"/tags/2","value":"b"}]`, wantErr: true},
+        {name: "leading zero index", doc: `{"tags":["a","b"]}`, patch: `[{"op":"remove","path":"/tags/01"}]`, wantErr: true},
+        {name: "test on a missing path", doc: `{}`, patch: `[{"op":"test","path":"/a","value":1}]`, wantErr: true},
+        {name: "test compares objects deeply", doc: `{"m":{"a":[1,{"b":null}]}}`, patch: `[{"op":"test","path":"/m","value":{"a":[1,{"b":null}]}}]`, want: `{"m":{"a":[1,{"b":null}]}}`},
+        {name: "test null value", doc: `{"a":null}`, patch: `[{"op":"test","path":"/a","value":null}]`, want: `{"a":null}`},
+        {name: "replace requires an existing path", doc: `{}`, patch: `[{"op":"replace","path":"/a","value":1}]`, wantErr: true},
+        {name: "copy", doc: `{"a":{"b":1}}`, patch: `[{"op":"copy","from":"/a","path":"/c"}]`, want: `{"a":{"b":1},"c":{"b":1}}`},
+        {name: "move into own child", doc: `{"a":{"b":1}}`, patch: `[{"op":"move","from":"/a","path":"/a/c"}]`, wantErr: true},
+        {name: "remove root", doc: `{"a":1}`, patch: `[{"op":"remove","path":""}]`, wantErr: true},
+        {name: "missing value", doc: `{}`, patch: `[{"op":"add","path":"/a"}]`, wantErr: true},
+        {name: "unknown op", doc: `{}`, patch: `[{"op":"frob","path":"/a"}]`, wantErr: true},
+        {name: "failed op discards earlier ones", doc: `{"a":1}`, patch: `[{"op":"add","path":"/b","value":2},{"op":"test","path":"/a","value":2}]`, wantErr: true, failed: true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var ops []JSONPatchOp
+            if err := json.Unmarshal([]byte(tt.patch), &ops); err != nil { t.Fatal(err) }
+            doc := decodeTestJSON(t, tt.doc)
+            got, err := ApplyJSONPatch(doc, ops)
+            if !reflect.DeepEqual(doc, decodeTestJSON(t, tt.doc)) { t.Errorf("input document was modified: %v", doc) }
+            if tt.wantErr {
+                if err == nil { t.Fatalf("want error, got %v", got) }
+                if errors.Is(err, ErrPatchTestFailed) != tt.failed { t.Errorf("errors.Is(err, ErrPatchTestFailed) = %v, want %v (%v)", !tt.failed, tt.failed, err) }
+                return
+            }
+            if err != nil { t.Fatal(err) }
+            if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) { t.Errorf("got %v, want %v", got, want) }
+        })
+    }
+}
+
+// The cases are RFC 7386 Appendix A.
+func TestMergePatch(t *testing.T) {
+    tests := []struct {
+        target, patch, want string
+    }{
+        {`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+        {`{"a":"b"}`, `{"a":null}`, `{}`},
+        {`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+        {`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+        {`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+        {`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+        {`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+        {`["a","b"]`, `["c","d"]`, `["c","d"]`},
+        {`{"a":"b"}`, `["c"]`, `["c"]`},
+        {`{"a":"foo"}`, `null`, `null`},
+        {`{"a":"foo"}`, `"bar"`, `"bar"`},
+        {`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+        {`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+        {`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.target+" + "+tt.patch, func(t *testing.T) {
+            target := decodeTestJSON(t, tt.target)
+            got := MergePatch(target, decodeTestJSON(t, tt.patch))
+            if want := decodeTestJSON(t, tt.want); !reflect.DeepEqual(got, want) { t.Errorf("got %v, want %v", got, want) }
+            if !reflect.DeepEqual(target, decodeTestJSON(t, tt.target)) { t.Errorf("target was modified: %v", target) }
+        })
+    }
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+    "encoding/json"
+    "math"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/i18n"
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// Planner defaults when the profile or a milestone does not say.
+const (
+    defaultWeeklyHours    = 5
+    defaultMilestoneHours = 4.0
+    maxMilestoneHours     = 200
+    planHorizonWeeks      = 52
+    workDaysPerWeek       = 5
+)
+
+// PlanMilestone is one milestone placed on the calendar.
+type PlanMilestone struct {
+    GoalID      uint      `json:"goal_id"`
+    GoalTitle   string    `json:"goal_title"`
+    Index       int       `json:"index"` // position in the goal's metadata.milestones
+    Label       string    `json:"label"`
+    EffortHours float64   `json:"effort_hours"`
+    Start       time.Time `json:"start"`
+    DueDate     time.Time `json:"due_date"`
+    PreviousDue string    `json:"previous_due,omitempty"`
+    Slipped     bool      `json:"slipped"` // previous due date passed before it was done
+}
+
+// PlanWeekItem is the share of a milestone's effort worked in one week.
+type PlanWeekItem struct {
+    GoalID uint    `json:"goal_id"`
+    Index  int     `json:"index"`
+    Label  string  `json:"label"`
+    Hours  float64 `json:"hours"`
+    Due    bool    `json:"due"` // the milestone finishes this week
+}
+
+// PlanWeek is one Monday-based week of the schedule.
+type PlanWeek struct {
+    Start         time.Time      `json:"start"`
+    CapacityHours float64        `json:"capacity_hours"`
+    PlannedHours  float64        `json:"planned_hours"`
+    BlockedDays   int            `json:"blocked_days"`
+    Items         []PlanWeekItem `json:"items"`
+}
+
+// GoalFit reports whether a goal's remaining milestones finish by its due date.
+type GoalFit struct {
+    GoalID         uint       `json:"goal_id"`
+    Title          string     `json:"title"`
+    DueDate        *time.Time `json:"due_date"`
+    PlannedFinish  *time.Time `json:"planned_finish"`
+    RemainingHours float64    `json:"remaining_hours"`
+    Fits           bool       `json:"fits"`
+    OverrunDays    int        `json:"overrun_days,omitempty"`
+    HoursOverDue   float64    `json:"hours_over_due,omitempty"` // effort scheduled after the due date
+}
+
+// UnscheduledGoal is an active goal the planner could not place.
+type UnscheduledGoal struct {
+    GoalID uint   `json:"goal_id"`
+    Title  string `json:"title"`
+    Reason string `json:"reason"` // no_milestones, all_done or beyond_horizon
+}
+
+// Plan is a week-by-week schedule of the user's open milestones.
+type Plan struct {
+    From        time.Time         `json:"from"`
+    WeeklyHours float64           `json:"weekly_hours"`
+    Weeks       []PlanWeek        `json:"weeks"`
+    Milestones  []PlanMilestone   `json:"milestones"`
+    Goals       []GoalFit         `json:"goals"`
+    AtRisk      int               `json:"at_risk"`
+    Unscheduled []UnscheduledGoal `json:"unscheduled"`
+}
+
+// plannedMilestone is a milestone as read from goal metadata.
+type plannedMilestone struct {
+    Label       string  `json:"label"`
+    DueDate     string  `json:"due_date"`
+    EffortHours float64 `json:"effort_hours"`
+    Done        bool    `json:"done"`
+}
+
+// goalMilestones reads metadata.milestones; entries the user has finished
+// (done flag, or a progress entry for the label at 100%) are marked Done.
+func goalMilestones(g models.Goal) []plannedMilestone {
+    var meta struct {
+        Milestones []plannedMilestone `json:"milestones"`
+    }
+    if strings.TrimSpace(g.Metadata) == "" || json.Unmarshal([]byte(g.Metadata), &meta) != nil { return nil }
+    finished := map[string]bool{}
+    for _, p := range g.Progress {
+        if p.Percentage >= 100 { finished[strings.ToLower(strings.TrimSpace(p.Description))] = true }
+    }
+    for i := range meta.Milestones {
+        if finished[strings.ToLower(strings.TrimSpace(meta.Milestones[i].Label))] { meta.Milestones[i].Done = true }
+    }
+    return meta.Milestones
+}
+
+// ValidateGoalMilestones checks the effort_hours of metadata.milestones on a
+// goal write: absent or 0 means "not estimated", otherwise it must be a
+// number of hours up to maxMilestoneHours.
+func ValidateGoalMilestones(metadata string) error {
+    if strings.TrimSpace(metadata) == "" { return nil }
+    var meta struct {
+        Milestones json.RawMessage `json:"milestones"`
+    }
+    if json.Unmarshal([]byte(metadata), &meta) != nil || len(meta.Milestones) == 0 || string(meta.Milestones) == "null" { return nil }
+    var milestones []struct {
+        EffortHours *float64 `json:"effort_hours"`
+    }
+    if err := json.Unmarshal(meta.Milestones, &milestones); err != nil {
+        return i18n.Errorf("invalid_field", "metadata.milestones")
+    }
+    for i, m := range milestones {
+        if m.EffortHours != nil && (*m.EffortHours < 0 || *m.EffortHours > maxMilestoneHours) {
+            return i18n.Errorf("milestone_effort_out_of_range", i+1, maxMilestoneHours)
+        }
+    }
+    return nil
+}
+
+// BuildPlan schedules the open milestones of goals earliest-due-date first
+// (undated goals last, then by priority) into weekday capacity of
+// weeklyHours/5 per day, skipping blocked days. Each milestone's due date is
+// the day its last hour is worked. from is the first plannable day.
+func BuildPlan(goals []models.Goal, weeklyHours int, blocked []models.BlockedPeriod, from time.Time) Plan {
+    if weeklyHours <= 0 { weeklyHours = defaultWeeklyHours }
+    from = truncateDay(from)
+    plan := Plan{From: from, WeeklyHours: float64(weeklyHours), Weeks: []PlanWeek{}, Milestones: []PlanMilestone{}, Goals: []GoalFit{}, Unscheduled: []UnscheduledGoal{}}
+
+    ordered := make([]models.Goal, 0, len(goals))
+    for _, g := range goals {
+        if g.Status == "active" { ordered = append(ordered, g) }
+    }
+    priority := map[string]int{"high": 0, "medium": 1, "low": 2}
+    sort.SliceStable(ordered, func(i, j int) bool {
+        a, b := ordered[i], ordered[j]
+        if (a.DueDate == nil) != (b.DueDate == nil) { return a.DueDate != nil }
+        if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) { return a.DueDate.Before(*b.DueDate) }
+        if priority[a.Priority] != priority[b.Priority] { return priority[a.Priority] < priority[b.Priority] }
+        return a.ID < b.ID
+    })
+
+    // Daily capacity over the horizon
+    end := weekStart(from).AddDate(0, 0, 7*planHorizonWeeks)
+    daily := float64(weeklyHours) / workDaysPerWeek
+    capacity := map[time.Time]float64{}
+    weeks := map[time.Time]*PlanWeek{}
+    var weekOrder []time.Time
+    for d := weekStart(from); d.Before(end); d = d.AddDate(0, 0, 1) {
+        ws := weekStart(d)
+        w, ok := weeks[ws]
+        if !ok {
+            w = &PlanWeek{Start: ws, Items: []PlanWeekItem{}}
+            weeks[ws] = w
+            weekOrder = append(weekOrder, ws)
+        }
+        if d.Before(from) || d.Weekday() == time.Saturday || d.Weekday() == time.Sunday { continue }
+        if dayBlocked(d, blocked) {
+            w.BlockedDays++
+            continue
+        }
+        capacity[d] = daily
+        w.CapacityHours += daily
+    }
+
+    cursor := from
+    for _, g := range ordered {
+        milestones := goalMilestones(g)
+        fit := GoalFit{GoalID: g.ID, Title: g.Title, DueDate: g.DueDate, Fits: true}
+        open := 0
+        for _, m := range milestones {
+            if !m.Done { open++ }
+        }
+        switch {
+        case len(milestones) == 0:
+            plan.Unscheduled = append(plan.Unscheduled, UnscheduledGoal{GoalID: g.ID, Title: g.Title, Reason: "no_milestones"})
+            continue
+        case open == 0:
+            plan.Unscheduled = append(plan.Unscheduled, UnscheduledGoal{GoalID: g.ID, Title: g.Title, Reason: "all_done"})
+            continue
+        }
+
+        var dueDay time.Time
+        if g.DueDate != nil { dueDay = truncateDay(*g.DueDate) }
+        placedAll := true
+        for i, m := range milestones {
+            if m.Done { continue }
+            effort := m.EffortHours
+            if effort <= 0 { effort = defaultMilestoneHours }
+            pm := PlanMilestone{GoalID: g.ID, GoalTitle: g.Title, Index: i, Label: m.Label, EffortHours: effort, PreviousDue: m.DueDate}
+            if prev, err := time.Parse("2006-01-02", m.DueDate); err == nil && prev.Before(from) { pm.Slipped = true }
+            fit.RemainingHours += effort
+
+            need, started := effort, false
+            for need > 1e-9 && cursor.Before(end) {
+                avail := capacity[cursor]
+                if avail <= 1e-9 {
+                    cursor = cursor.AddDate(0, 0, 1)
+                    continue
+                }
+                if !started { pm.Start, started = cursor, true }
+                take := math.Min(avail, need)
+                capacity[cursor] -= take
+                need -= take
+                w := weeks[weekStart(cursor)]
+                w.PlannedHours += take
+                addWeekItem(w, PlanWeekItem{GoalID: g.ID, Index: i, Label: m.Label, Hours: take})
+                if g.DueDate != nil && cursor.After(dueDay) { fit.HoursOverDue += take }
+                pm.DueDate = cursor
+                if capacity[cursor] <= 1e-9 && need > 1e-9 { cursor = cursor.AddDate(0, 0, 1) }
+            }
+            if need > 1e-9 {
+                placedAll = false
+                break
+            }
+            w := weeks[weekStart(pm.DueDate)]
+            w.Items[len(w.Items)-1].Due = true
+            plan.Milestones = append(plan.Milestones, pm)
+            finish := pm.DueDate
+            fit.PlannedFinish = &finish
+        }
+        if !placedAll {
+            fit.Fits = false
+            plan.Unscheduled = append(plan.Unscheduled, UnscheduledGoal{GoalID: g.ID, Title: g.Title, Reason: "beyond_horizon"})
+        }
+        if g.DueDate != nil && fit.PlannedFinish != nil && fit.PlannedFinish.After(dueDay) {
+            fit.Fits = false
+            fit.OverrunDays = int(fit.PlannedFinish.Sub(dueDay).Hours() / 24)
+        }
+        fit.HoursOverDue = roundHours(fit.HoursOverDue)
+        if !fit.Fits { plan.AtRisk++ }
+        plan.Goals = append(plan.Goals, fit)
+    }
+
+    // Trim trailing weeks with nothing planned
+    last := 0
+    for i, ws := range weekOrder {
+        if weeks[ws].PlannedHours > 0 { last = i }
+    }
+    for i := 0; i <= last && i < len(weekOrder); i++ {
+        w := weeks[weekOrder[i]]
+        w.CapacityHours, w.PlannedHours = roundHours(w.CapacityHours), roundHours(w.PlannedHours)
+        for j := range w.Items { w.Items[j].Hours = roundHours(w.Items[j].Hours) }
+        plan.Weeks = append(plan.Weeks, *w)
+    }
+    return plan
+}
+
+// addWeekItem merges consecutive days on the same milestone into one item.
+func addWeekItem(w *PlanWeek, item PlanWeekItem) {
+    if n := len(w.Items); n > 0 && w.Items[n-1].GoalID == item.GoalID && w.Items[n-1].Index == item.Index {
+        w.Items[n-1].Hours += item.Hours
+        return
+    }
+    w.Items = append(w.Items, item)
+}
+
+func dayBlocked(d time.Time, blocked []models.BlockedPeriod) bool {
+    for _, b := range blocked {
+        if !d.Before(truncateDay(b.StartDate)) && !d.After(truncateDay(b.EndDate)) { return true }
+    }
+    return false
+}
+
+func roundHours(h float64) float64 { return math.Round(h*100) / 100 }
+
+// LoadPlan builds the plan for a user's active goals from now, using the
+// profile's AvailableHoursWeek unless weeklyHours overrides it. The goals the
+// plan was built from are returned for ApplyPlanDates.
+func LoadPlan(db *gorm.DB, userID string, weeklyHours int, now time.Time) (Plan, []models.Goal, error) {
+    var goals []models.Goal
+    if err := db.Preload("Progress").Where("user_id = ? AND status = 'active'", userID).Find(&goals).Error; err != nil {
+        return Plan{}, nil, err
+    }
+    var blocked []models.BlockedPeriod
+    if err := db.Where("user_id = ? AND end_date >= ?", userID, truncateDay(now)).Find(&blocked).Error; err != nil {
+        return Plan{}, nil, err
+    }
+    if weeklyHours <= 0 {
+        var profile models.UserProfile
+        db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+        weeklyHours = profile.AvailableHoursWeek
+    }
+    return BuildPlan(goals, weeklyHours, blocked, now), goals, nil
+}
+
+// ApplyPlanDates writes the planned due dates into metadata.milestones,
+// keeping every other metadata key and milestone field. It reports whether
+// anything changed.
+func ApplyPlanDates(g *models.Goal, planned []PlanMilestone) (bool, error) {
+    meta := map[string]interface{}{}
+    if strings.TrimSpace(g.Metadata) != "" {
+        if err := json.Unmarshal([]byte(g.Metadata), &meta); err != nil { return false, err }
+    }
+    list, _ := meta["milestones"].([]interface{})
+    changed := false
+    for _, pm := range planned {
+        if pm.GoalID != g.ID || pm.Index >= len(list) { continue }
+        item, ok := list[pm.Index].(map[string]interface{})
+        if !ok { continue }
+        due := pm.DueDate.Format("2006-01-02")
+        if item["due_date"] == due { continue }
+        item["due_date"] = due
+        changed = true
+    }
+    if !changed { return false, nil }
+    b, err := json.Marshal(meta)
+    if err != nil { return false, err }
+    g.Metadata = string(b)
+    return true, nil
+}
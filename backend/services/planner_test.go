@@ -0,0 +1,165 @@
+package services
+
+import (
+    "fmt"
+    "reflect"
+    "strings"
+    "testing"
+    "time"
+
+    "goaltracker/models"
+)
+
+// planDay is a day in March 2026; the 2nd is a Monday.
+func planDay(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+// planGoal is an active goal with milestones given as effort hours; a
+// negative effort marks the milestone done.
+func planGoal(id uint, due int, priority string, efforts ...float64) models.Goal {
+    g := models.Goal{ID: id, Title: fmt.Sprintf("goal %d", id), Status: "active", Priority: priority}
+    if due > 0 {
+        d := planDay(due)
+        g.DueDate = &d
+    }
+    if len(efforts) > 0 {
+        ms := make([]string, len(efforts))
+        for i, e := range efforts {
+            ms[i] = fmt.Sprintf(`{"label":"m%d","effort_hours":%g,"done":%t}`, i, e, e < 0)
+        }
+        g.Metadata = `{"milestones":[` + strings.Join(ms, ",") + `]}`
+    }
+    return g
+}
+
+func TestBuildPlan(t *testing.T) {
+    type week struct {
+        capacity, planned float64
+        blocked           int
+    }
+    tests := []struct {
+        name        string
+        goals       []models.Goal
+        hours       int
+        blocked     [][2]int // inclusive March days
+        due         map[string]int // "goal/index" -> planned due day
+        overrun     map[uint]int   // goals that don't fit -> overrun days
+        overDue     map[uint]float64
+        unscheduled map[uint]string
+        weeks       []week
+    }{
+        {
+            name:  "fits within capacity",
+            goals: []models.Goal{planGoal(1, 6, "medium", 6)},
+            hours: 10,
+            due:   map[string]int{"1/0": 4},
+            weeks: []week{{capacity: 10, planned: 6}},
+        },
+        {
+            name:    "overflowing capacity misses the due date",
+            goals:   []models.Goal{planGoal(1, 3, "medium", 8)},
+            hours:   10,
+            due:     map[string]int{"1/0": 5},
+            overrun: map[uint]int{1: 2},
+            overDue: map[uint]float64{1: 4},
+            weeks:   []week{{capacity: 10, planned: 8}},
+        },
+        {
+            name:    "blocked days are skipped",
+            goals:   []models.Goal{planGoal(1, 5, "medium", 6)},
+            hours:   10,
+            blocked: [][2]int{{3, 4}},
+            due:     map[string]int{"1/0": 6},
+            overrun: map[uint]int{1: 1},
+            overDue: map[uint]float64{1: 2},
+            weeks:   []week{{capacity: 6, planned: 6, blocked: 2}},
+        },
+        {
+            name:  "weekends carry work into the next week",
+            goals: []models.Goal{planGoal(1, 0, "medium", 12)},
+            hours: 10,
+            due:   map[string]int{"1/0": 9},
+            weeks: []week{{capacity: 10, planned: 10}, {capacity: 10, planned: 2}},
+        },
+        {
+            name:  "earliest due date first, then priority, undated last",
+            goals: []models.Goal{planGoal(1, 0, "high", 2), planGoal(2, 20, "low", 2), planGoal(3, 20, "high", 2), planGoal(4, 10, "low", 2)},
+            hours: 10,
+            due:   map[string]int{"4/0": 2, "3/0": 3, "2/0": 4, "1/0": 5},
+            weeks: []week{{capacity: 10, planned: 8}},
+        },
+        {
+            name:  "done milestones are skipped and unestimated ones take the default",
+            goals: []models.Goal{planGoal(1, 0, "medium", -1, 0)},
+            hours: 10,
+            due:   map[string]int{"1/1": 3},
+            weeks: []week{{capacity: 10, planned: defaultMilestoneHours}},
+        },
+        {
+            name:        "goals without open milestones are unscheduled",
+            goals:       []models.Goal{planGoal(1, 0, "medium"), planGoal(2, 0, "medium", -1)},
+            hours:       10,
+            unscheduled: map[uint]string{1: "no_milestones", 2: "all_done"},
+            weeks:       []week{{capacity: 10}}, // the current week is always shown
+        },
+        {
+            name:        "effort past the horizon",
+            goals:       []models.Goal{planGoal(1, 0, "medium", 200)},
+            hours:       1,
+            overrun:     map[uint]int{1: 0},
+            unscheduled: map[uint]string{1: "beyond_horizon"},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var blocked []models.BlockedPeriod
+            for _, b := range tt.blocked {
+                blocked = append(blocked, models.BlockedPeriod{StartDate: planDay(b[0]), EndDate: planDay(b[1])})
+            }
+            plan := BuildPlan(tt.goals, tt.hours, blocked, planDay(2))
+
+            due := map[string]int{}
+            for _, m := range plan.Milestones { due[fmt.Sprintf("%d/%d", m.GoalID, m.Index)] = m.DueDate.Day() }
+            if tt.due == nil { tt.due = map[string]int{} }
+            if !reflect.DeepEqual(due, tt.due) { t.Errorf("milestone due days = %v, want %v", due, tt.due) }
+            if len(tt.due) > 0 {
+                var order []string
+                for _, m := range plan.Milestones { order = append(order, fmt.Sprintf("%d/%d", m.GoalID, m.Index)) }
+                for i := 1; i < len(plan.Milestones); i++ {
+                    if plan.Milestones[i].DueDate.Before(plan.Milestones[i-1].DueDate) { t.Errorf("milestones out of order: %v", order) }
+                }
+            }
+
+            overrun, overDue := map[uint]int{}, map[uint]float64{}
+            for _, g := range plan.Goals {
+                if !g.Fits { overrun[g.GoalID] = g.OverrunDays }
+                if g.HoursOverDue > 0 { overDue[g.GoalID] = g.HoursOverDue }
+            }
+            if tt.overrun == nil { tt.overrun = map[uint]int{} }
+            if tt.overDue == nil { tt.overDue = map[uint]float64{} }
+            if !reflect.DeepEqual(overrun, tt.overrun) { t.Errorf("overrun = %v, want %v", overrun, tt.overrun) }
+            if !reflect.DeepEqual(overDue, tt.overDue) { t.Errorf("hours over due = %v, want %v", overDue, tt.overDue) }
+            if plan.AtRisk != len(tt.overrun) { t.Errorf("at risk = %d, want %d", plan.AtRisk, len(tt.overrun)) }
+
+            unscheduled := map[uint]string{}
+            for _, u := range plan.Unscheduled { unscheduled[u.GoalID] = u.Reason }
+            if tt.unscheduled == nil { tt.unscheduled = map[uint]string{} }
+            if !reflect.DeepEqual(unscheduled, tt.unscheduled) { t.Errorf("unscheduled = %v, want %v", unscheduled, tt.unscheduled) }
+
+            if tt.weeks != nil {
+                var weeks []week
+                for _, w := range plan.Weeks { weeks = append(weeks, week{w.CapacityHours, w.PlannedHours, w.BlockedDays}) }
+                if !reflect.DeepEqual(weeks, tt.weeks) { t.Errorf("weeks = %+v, want %+v", weeks, tt.weeks) }
+            }
+        })
+    }
+}
+
+func TestBuildPlanIgnoresInactiveGoals(t *testing.T) {
+    paused := planGoal(1, 0, "medium", 4)
+    paused.Status = "paused"
+    plan := BuildPlan([]models.Goal{paused}, 0, nil, planDay(2))
+    if len(plan.Goals) != 0 || len(plan.Milestones) != 0 || len(plan.Unscheduled) != 0 {
+        t.Errorf("paused goal was planned: %+v", plan)
+    }
+    if plan.WeeklyHours != defaultWeeklyHours { t.Errorf("weekly hours = %v, want the default %d", plan.WeeklyHours, defaultWeeklyHours) }
+}
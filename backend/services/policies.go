@@ -0,0 +1,148 @@
+package services
+
+import (
+    "errors"
+    "sync"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// PolicyKinds are the policies users accept.
+var PolicyKinds = []string{"terms", "privacy"}
+
+// LegacyPolicyVersion is recorded for acceptances made before any policy
+// document of that kind was published.
+const LegacyPolicyVersion = "1.0"
+
+// policyCacheTTL bounds how stale the enforced versions may be; publishing
+// clears the cache immediately on this instance.
+const policyCacheTTL = time.Minute
+
+var ErrPolicyNotFound = errors.New("policy version not found")
+
+var policyCache = struct {
+    sync.Mutex
+    loaded  time.Time
+    current map[string]models.PolicyDocument
+}{}
+
+// CurrentPolicies returns the enforced document per kind: the latest
+// required version whose effective date has passed. Kinds with no such
+// document are absent, and nothing is enforced for them.
+func CurrentPolicies(db *gorm.DB, now time.Time) (map[string]models.PolicyDocument, error) {
+    policyCache.Lock()
+    defer policyCache.Unlock()
+    if policyCache.current != nil && now.Sub(policyCache.loaded) < policyCacheTTL { return policyCache.current, nil }
+    current := map[string]models.PolicyDocument{}
+    for _, kind := range PolicyKinds {
+        var doc models.PolicyDocument
+        res := db.Omit("body").Where("kind = ? AND required AND effective_at <= ?", kind, now).
+            Order("effective_at DESC, id DESC").Limit(1).Find(&doc)
+        if res.Error != nil { return nil, res.Error }
+        if res.RowsAffected > 0 { current[kind] = doc }
+    }
+    policyCache.current, policyCache.loaded = current, now
+    return current, nil
+}
+
+// InvalidatePolicyCache forces the next CurrentPolicies call to reload.
+func InvalidatePolicyCache() {
+    policyCache.Lock()
+    policyCache.current = nil
+    policyCache.Unlock()
+}
+
+// PendingPolicy is an enforced policy version the user has not accepted.
+type PendingPolicy struct {
+    Kind     string `json:"kind"`
+    Version  string `json:"version"`
+    Title    string `json:"title"`
+    URL      string `json:"url,omitempty"`
+    PolicyID uint   `json:"policy_id"`
+}
+
+// PendingPolicies lists the enforced versions userID still has to accept.
+// Acceptances match by kind and version, so legacy ledger rows count for a
+// document later published under the same version.
+func PendingPolicies(db *gorm.DB, userID string, now time.Time) ([]PendingPolicy, error) {
+    current, err := CurrentPolicies(db, now)
+    if err != nil || len(current) == 0 { return nil, err }
+    var accepted []models.PolicyAcceptance
+    q := db.Select("kind", "version").Where("user_id = ?", userID)
+    or := db.Where("1 = 0")
+    for _, doc := range current { or = or.Or("kind = ? AND version = ?", doc.Kind, doc.Version) }
+    if err := q.Where(or).Find(&accepted).Error; err != nil { return nil, err }
+    have := map[string]bool{}
+    for _, a := range accepted { have[a.Kind+"@"+a.Version] = true }
+    var pending []PendingPolicy
+    for _, kind := range PolicyKinds {
+        doc, ok := current[kind]
+        if !ok || have[kind+"@"+doc.Version] { continue }
+        pending = append(pending, PendingPolicy{Kind: kind, Version: doc.Version, Title: doc.Title, URL: doc.URL, PolicyID: doc.ID})
+    }
+    return pending, nil
+}
+
+// AcceptanceContext describes where an acceptance came from, for the ledger.
+type AcceptanceContext struct {
+    IPAddress string
+    UserAgent string
+    Source    string // api or profile
+}
+
+// ResolvePolicy finds the document a user is accepting: the given version,
+// else the enforced one, else the newest published. With no documents of
+// kind at all the legacy version is returned with a nil document.
+func ResolvePolicy(db *gorm.DB, kind, version string, now time.Time) (*models.PolicyDocument, string, error) {
+    var doc models.PolicyDocument
+    q := db.Omit("body").Where("kind = ?", kind)
+    if version != "" {
+        q = q.Where("version = ?", version)
+    } else if current, err := CurrentPolicies(db, now); err == nil && current[kind].ID != 0 {
+        q = q.Where("id = ?", current[kind].ID)
+    }
+    res := q.Order("effective_at DESC, id DESC").Limit(1).Find(&doc)
+    if res.Error != nil { return nil, "", res.Error }
+    switch {
+    case res.RowsAffected > 0:
+        return &doc, doc.Version, nil
+    case version != "" && version != LegacyPolicyVersion:
+        return nil, "", ErrPolicyNotFound
+    }
+    return nil, LegacyPolicyVersion, nil
+}
+
+// RecordAcceptance appends a consent ledger row.
+func RecordAcceptance(tx *gorm.DB, userID, kind string, doc *models.PolicyDocument, version string, ctx AcceptanceContext, now time.Time) (models.PolicyAcceptance, error) {
+    acc := models.PolicyAcceptance{
+        UserID: userID, Kind: kind, Version: version, AcceptedAt: now,
+        IPAddress: ctx.IPAddress, UserAgent: truncateString(ctx.UserAgent, 500), Source: ctx.Source,
+    }
+    if doc != nil { acc.PolicyID = &doc.ID }
+    if acc.Source == "" { acc.Source = "api" }
+    err := tx.Create(&acc).Error
+    return acc, err
+}
+
+// AcceptPolicy records userID accepting kind (see ResolvePolicy for how an
+// empty version is chosen) and mirrors it onto the profile's acceptance
+// columns, bumping the profile version so cached copies are not written
+// back over it.
+func AcceptPolicy(tx *gorm.DB, userID, kind, version string, ctx AcceptanceContext, now time.Time) (models.PolicyAcceptance, error) {
+    doc, version, err := ResolvePolicy(tx, kind, version, now)
+    if err != nil { return models.PolicyAcceptance{}, err }
+    acc, err := RecordAcceptance(tx, userID, kind, doc, version, ctx, now)
+    if err != nil { return acc, err }
+    updates := map[string]interface{}{kind + "_accepted_at": now, "version": gorm.Expr("version + 1")}
+    if kind == "terms" { updates["policies_version"] = version }
+    err = tx.Model(&models.UserProfile{}).Where("user_id = ?", userID).Updates(updates).Error
+    return acc, err
+}
+
+func truncateString(s string, n int) string {
+    if len(s) <= n { return s }
+    return s[:n]
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+    "encoding/json"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// ProfileState is the tracked part of a user profile. Policy acceptance and
+// bookkeeping columns are left out so accepting terms is not "growth".
+type ProfileState struct {
+    CurrentRole        string           `json:"current_role"`
+    ExperienceLevel    string           `json:"experience_level"`
+    Industry           string           `json:"industry"`
+    CompanySize        string           `json:"company_size"`
+    LearningStyle      string           `json:"learning_style"`
+    AvailableHoursWeek int              `json:"available_hours_week"`
+    CareerGoals        string           `json:"career_goals"`
+    CurrentTools       interface{}      `json:"current_tools"`
+    SkillGaps          interface{}      `json:"skill_gaps"`
+    ITProfile          models.ITProfile `json:"it_profile"`
+}
+
+// ProfileSnapshotView is the API shape of a stored snapshot.
+type ProfileSnapshotView struct {
+    models.ProfileSnapshot
+    Snapshot ProfileState  `json:"snapshot"`
+    Changes  []FieldChange `json:"changes"`
+}
+
+// ProfileTimelineEvent is one entry on the career timeline: a profile
+// change or a goal completion.
+type ProfileTimelineEvent struct {
+    Type       string        `json:"type"` // profile_change or goal_completed
+    At         time.Time     `json:"at"`
+    SnapshotID *uint         `json:"snapshot_id,omitempty"`
+    Changes    []FieldChange `json:"changes,omitempty"`
+    GoalID     *uint         `json:"goal_id,omitempty"`
+    GoalTitle  string        `json:"goal_title,omitempty"`
+}
+
+func SnapshotProfile(p models.UserProfile) ProfileState {
+    it := DecodeITProfile(p.ITProfile)
+    if it.SchemaVersion == 0 { it.SchemaVersion = models.ITProfileSchemaVersion }
+    return ProfileState{
+        CurrentRole:        p.CurrentRole,
+        ExperienceLevel:    p.ExperienceLevel,
+        Industry:           p.Industry,
+        CompanySize:        p.CompanySize,
+        LearningStyle:      p.LearningStyle,
+        AvailableHoursWeek: p.AvailableHoursWeek,
+        CareerGoals:        p.CareerGoals,
+        CurrentTools:       decodeJSONString(p.CurrentTools),
+        SkillGaps:          decodeJSONString(p.SkillGaps),
+        ITProfile:          it,
+    }
+}
+
+// RecordProfileSnapshot stores the profile after a write, inside tx, when a
+// tracked field changed. before is nil on create. Profiles that predate
+// snapshots get a baseline of their previous state first, dated at its
+// last update, so the first recorded change still has a "from".
+func RecordProfileSnapshot(tx *gorm.DB, before *models.UserProfile, after models.UserProfile) (*models.ProfileSnapshot, error) {
+    var last models.ProfileSnapshot
+    if err := tx.Where("profile_id = ?", after.ID).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+        return nil, err
+    }
+
+    kind := "create"
+    var prev *ProfileState
+    if last.ID != 0 {
+        var s ProfileState
+        if err := json.Unmarshal([]byte(last.Snapshot), &s); err == nil { prev = &s }
+        kind = "update"
+    } else if before != nil {
+        s := SnapshotProfile(*before)
+        baseline, err := newProfileSnapshot(*before, "baseline", s, []FieldChange{})
+        if err != nil { return nil, err }
+        if !before.UpdatedAt.IsZero() { baseline.CreatedAt = before.UpdatedAt }
+        if err := tx.Create(baseline).Error; err != nil { return nil, err }
+        prev = &s
+        kind = "update"
+    }
+
+    state := SnapshotProfile(after)
+    changes := []FieldChange{}
+    if prev != nil {
+        changes = DiffJSON(*prev, state)
+        if len(changes) == 0 { return nil, nil }
+    }
+    snap, err := newProfileSnapshot(after, kind, state, changes)
+    if err != nil { return nil, err }
+    return snap, tx.Create(snap).Error
+}
+
+func newProfileSnapshot(p models.UserProfile, kind string, state ProfileState, changes []FieldChange) (*models.ProfileSnapshot, error) {
+    sb, err := json.Marshal(state)
+    if err != nil { return nil, err }
+    cb, err := json.Marshal(changes)
+    if err != nil { return nil, err }
+    return &models.ProfileSnapshot{
+        UserID:         p.UserID,
+        ProfileID:      p.ID,
+        ProfileVersion: p.Version,
+        Kind:           kind,
+        Snapshot:       string(sb),
+        Changes:        string(cb),
+    }, nil
+}
+
+// ViewProfileSnapshot decodes the stored JSON columns for API output.
+func ViewProfileSnapshot(s models.ProfileSnapshot) ProfileSnapshotView {
+    v := ProfileSnapshotView{ProfileSnapshot: s, Changes: []FieldChange{}}
+    _ = json.Unmarshal([]byte(s.Snapshot), &v.Snapshot)
+    if strings.TrimSpace(s.Changes) != "" { _ = json.Unmarshal([]byte(s.Changes), &v.Changes) }
+    return v
+}
+
+// ProfileTimeline merges profile changes and goal completions in [from, to),
+// newest first. Baseline snapshots carry no change and are skipped.
+func ProfileTimeline(db *gorm.DB, userID string, from, to time.Time) ([]ProfileTimelineEvent, error) {
+    var snaps []models.ProfileSnapshot
+    if err := db.Where("user_id = ? AND kind <> 'baseline' AND created_at >= ? AND created_at < ?", userID, from, to).
+        Order("created_at DESC").Limit(500).Find(&snaps).Error; err != nil {
+        return nil, err
+    }
+    var goals []models.Goal
+    if err := db.Select("id", "title", "completed_at").
+        Where("user_id = ? AND status = 'completed' AND completed_at >= ? AND completed_at < ?", userID, from, to).
+        Order("completed_at DESC").Limit(500).Find(&goals).Error; err != nil {
+        return nil, err
+    }
+
+    events := make([]ProfileTimelineEvent, 0, len(snaps)+len(goals))
+    for _, s := range snaps {
+        v := ViewProfileSnapshot(s)
+        id := s.ID
+        events = append(events, ProfileTimelineEvent{Type: "profile_change", At: s.CreatedAt, SnapshotID: &id, Changes: v.Changes})
+    }
+    for _, g := range goals {
+        id := g.ID
+        events = append(events, ProfileTimelineEvent{Type: "goal_completed", At: *g.CompletedAt, GoalID: &id, GoalTitle: g.Title})
+    }
+    sort.SliceStable(events, func(i, j int) bool { return events[i].At.After(events[j].At) })
+    return events, nil
+}
+
+// ProfileSnapshotAt returns the latest snapshot taken at or before t.
+func ProfileSnapshotAt(db *gorm.DB, userID string, t time.Time) (models.ProfileSnapshot, error) {
+    var s models.ProfileSnapshot
+    err := db.Where("user_id = ? AND created_at <= ?", userID, t).Order("created_at DESC, id DESC").First(&s).Error
+    return s, err
+}
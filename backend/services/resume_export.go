@@ -0,0 +1,258 @@
+package services
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+)
+
+// CVOptions selects what goes into an exported CV. Goals count when they
+// were completed inside [From, To). GoalIDs and ProgressIDs, when set,
+// limit the export to those goals and those progress outcomes.
+type CVOptions struct {
+    From            time.Time
+    To              time.Time
+    GoalIDs         []uint
+    ProgressIDs     []uint
+    IncludeOutcomes bool
+    IncludeEvidence bool
+}
+
+// CV is the export model both renderers work from. IDs are kept so a
+// client can offer the goals and outcomes for selection.
+type CV struct {
+    Role           string                   `json:"role"`
+    Level          string                   `json:"level"`
+    Industry       string                   `json:"industry"`
+    Summary        string                   `json:"summary"`
+    From           time.Time                `json:"from"`
+    To             time.Time                `json:"to"`
+    Achievements   []CVAchievement          `json:"achievements"`
+    Platforms      []models.ITPlatform      `json:"platforms"`
+    Frameworks     []models.ITFramework     `json:"frameworks"`
+    Certifications []models.ITCertification `json:"certifications"`
+    Evidence       []models.ITEvidence      `json:"evidence"`
+}
+
+// CVAchievement is one completed goal and the outcomes recorded against it.
+type CVAchievement struct {
+    GoalID      uint        `json:"goal_id"`
+    Title       string      `json:"title"`
+    Description string      `json:"description"`
+    JobRole     string      `json:"job_role,omitempty"`
+    CompletedAt time.Time   `json:"completed_at"`
+    Tags        []string    `json:"tags"`
+    Outcomes    []CVOutcome `json:"outcomes"`
+}
+
+type CVOutcome struct {
+    ProgressID uint      `json:"progress_id"`
+    Text       string    `json:"text"`
+    RecordedAt time.Time `json:"recorded_at"`
+}
+
+// GoalCompletedAt is when g was completed. Goals completed before
+// completed_at existed fall back to updated_at, as in analytics.
+func GoalCompletedAt(g models.Goal) time.Time {
+    if g.CompletedAt != nil { return *g.CompletedAt }
+    return g.UpdatedAt
+}
+
+// BuildCV assembles a CV from the profile and the user's goals. goals should
+// have Progress and JobRole preloaded; ones not completed in range are skipped.
+func BuildCV(profile models.UserProfile, goals []models.Goal, opts CVOptions) CV {
+    it := DecodeITProfile(profile.ITProfile)
+    cv := CV{
+        Role:         firstNonEmpty(profile.CurrentRole, it.Role.Current),
+        Level:        firstNonEmpty(profile.ExperienceLevel, it.Role.Level),
+        Industry:     profile.Industry,
+        Summary:      strings.TrimSpace(profile.CareerGoals),
+        From:         opts.From,
+        To:           opts.To,
+        Platforms:    it.Platforms,
+        Frameworks:   it.Frameworks,
+        Achievements: []CVAchievement{},
+    }
+    for _, c := range it.Certifications {
+        if c.Status == "" || c.Status == "earned" { cv.Certifications = append(cv.Certifications, c) }
+    }
+    if opts.IncludeEvidence { cv.Evidence = it.Evidence }
+
+    wantGoal := idSet(opts.GoalIDs)
+    wantProgress := idSet(opts.ProgressIDs)
+    for _, g := range goals {
+        if g.Status != "completed" { continue }
+        completed := GoalCompletedAt(g)
+        if completed.Before(opts.From) || !completed.Before(opts.To) { continue }
+        if len(wantGoal) > 0 && !wantGoal[g.ID] { continue }
+
+        a := CVAchievement{
+            GoalID: g.ID, Title: g.Title, Description: strings.TrimSpace(g.Description),
+            CompletedAt: completed, Tags: decodeStringList(g.Tags), Outcomes: []CVOutcome{},
+        }
+        if g.JobRole != nil { a.JobRole = g.JobRole.Title }
+        if opts.IncludeOutcomes {
+            for _, p := range g.Progress {
+                text := strings.TrimSpace(p.Outcome)
+                if text == "" { continue }
+                if len(wantProgress) > 0 && !wantProgress[p.ID] { continue }
+                a.Outcomes = append(a.Outcomes, CVOutcome{ProgressID: p.ID, Text: text, RecordedAt: p.CreatedAt})
+            }
+            sort.Slice(a.Outcomes, func(i, j int) bool { return a.Outcomes[i].RecordedAt.Before(a.Outcomes[j].RecordedAt) })
+        }
+        cv.Achievements = append(cv.Achievements, a)
+    }
+    sort.Slice(cv.Achievements, func(i, j int) bool { return cv.Achievements[i].CompletedAt.After(cv.Achievements[j].CompletedAt) })
+    return cv
+}
+
+// JSONResume renders the CV as a JSON Resume (https://jsonresume.org/schema)
+// document. The current role becomes one work entry whose highlights are the
+// achievements; each achievement is also a project carrying its outcomes.
+func (cv CV) JSONResume() map[string]interface{} {
+    highlights := make([]string, 0, len(cv.Achievements))
+    projects := make([]map[string]interface{}, 0, len(cv.Achievements)+len(cv.Evidence))
+    for _, a := range cv.Achievements {
+        highlights = append(highlights, a.Title)
+        outcomes := make([]string, 0, len(a.Outcomes))
+        for _, o := range a.Outcomes { outcomes = append(outcomes, o.Text) }
+        projects = append(projects, map[string]interface{}{
+            "name":        a.Title,
+            "description": a.Description,
+            "highlights":  outcomes,
+            "keywords":    a.Tags,
+            "endDate":     a.CompletedAt.Format("2006-01-02"),
+        })
+    }
+    for _, e := range cv.Evidence {
+        p := map[string]interface{}{"name": e.Title, "type": "evidence"}
+        if e.Link != "" { p["url"] = e.Link }
+        projects = append(projects, p)
+    }
+
+    skills := make([]map[string]interface{}, 0, len(cv.Platforms)+len(cv.Frameworks))
+    for _, p := range cv.Platforms {
+        skills = append(skills, map[string]interface{}{"name": p.Name, "level": p.Depth})
+    }
+    for _, f := range cv.Frameworks {
+        skills = append(skills, map[string]interface{}{"name": f.Name, "level": f.Level})
+    }
+    certs := make([]map[string]interface{}, 0, len(cv.Certifications))
+    for _, c := range cv.Certifications {
+        certs = append(certs, map[string]interface{}{"name": c.Name})
+    }
+
+    work := []map[string]interface{}{}
+    if cv.Role != "" || len(highlights) > 0 {
+        work = append(work, map[string]interface{}{
+            "position":   cv.Role,
+            "highlights": highlights,
+        })
+    }
+    return map[string]interface{}{
+        "$schema": "https://raw.githubusercontent.com/jsonresume/resume-schema/v1.0.0/schema.json",
+        "basics": map[string]interface{}{
+            "label":   cv.Role,
+            "summary": cv.Summary,
+        },
+        "work":         work,
+        "projects":     projects,
+        "skills":       skills,
+        "certificates": certs,
+        "meta": map[string]interface{}{
+            "version":      "v1.0.0",
+            "lastModified": time.Now().UTC().Format(time.RFC3339),
+            "period":       map[string]string{"from": cv.From.Format("2006-01-02"), "to": cv.To.Format("2006-01-02")},
+        },
+    }
+}
+
+// Markdown renders the CV as a Markdown document.
+func (cv CV) Markdown() string {
+    var b strings.Builder
+    b.WriteString("# " + firstNonEmpty(cv.Role, "Curriculum Vitae") + "\n\n")
+    var facts []string
+    if cv.Level != "" { facts = append(facts, strings.ToUpper(cv.Level[:1])+cv.Level[1:]+" level") }
+    if cv.Industry != "" { facts = append(facts, cv.Industry) }
+    if len(facts) > 0 { b.WriteString("_" + strings.Join(facts, " · ") + "_\n\n") }
+    if cv.Summary != "" { b.WriteString(cv.Summary + "\n\n") }
+
+    if len(cv.Achievements) > 0 {
+        fmt.Fprintf(&b, "## Key achievements (%s – %s)\n\n", cv.From.Format("Jan 2006"), cv.To.AddDate(0, 0, -1).Format("Jan 2006"))
+        for _, a := range cv.Achievements {
+            fmt.Fprintf(&b, "### %s\n\n", mdEscape(a.Title))
+            meta := "Completed " + a.CompletedAt.Format("January 2006")
+            if a.JobRole != "" { meta += " · " + a.JobRole }
+            b.WriteString("_" + meta + "_\n\n")
+            if a.Description != "" { b.WriteString(a.Description + "\n\n") }
+            for _, o := range a.Outcomes { b.WriteString("- " + oneLine(o.Text) + "\n") }
+            if len(a.Outcomes) > 0 { b.WriteString("\n") }
+        }
+    }
+
+    if len(cv.Platforms)+len(cv.Frameworks) > 0 {
+        b.WriteString("## Skills\n\n")
+        if line := skillLine(len(cv.Platforms), func(i int) (string, string) { return cv.Platforms[i].Name, cv.Platforms[i].Depth }); line != "" {
+            b.WriteString("**Platforms:** " + line + "\n\n")
+        }
+        if line := skillLine(len(cv.Frameworks), func(i int) (string, string) { return cv.Frameworks[i].Name, cv.Frameworks[i].Level }); line != "" {
+            b.WriteString("**Frameworks:** " + line + "\n\n")
+        }
+    }
+    if len(cv.Certifications) > 0 {
+        b.WriteString("## Certifications\n\n")
+        for _, c := range cv.Certifications {
+            line := "- " + mdEscape(c.Name)
+            if c.Expires != "" { line += " (valid until " + c.Expires + ")" }
+            b.WriteString(line + "\n")
+        }
+        b.WriteString("\n")
+    }
+    if len(cv.Evidence) > 0 {
+        b.WriteString("## Portfolio\n\n")
+        for _, e := range cv.Evidence {
+            if e.Link != "" {
+                fmt.Fprintf(&b, "- [%s](%s)\n", mdEscape(e.Title), e.Link)
+            } else {
+                b.WriteString("- " + mdEscape(e.Title) + "\n")
+            }
+        }
+        b.WriteString("\n")
+    }
+    return strings.TrimRight(b.String(), "\n") + "\n"
+}
+
+func skillLine(n int, item func(int) (string, string)) string {
+    parts := make([]string, 0, n)
+    for i := 0; i < n; i++ {
+        name, level := item(i)
+        if level != "" { name += " (" + level + ")" }
+        parts = append(parts, mdEscape(name))
+    }
+    return strings.Join(parts, ", ")
+}
+
+// mdEscape keeps user text from being read as Markdown emphasis or links.
+func mdEscape(s string) string {
+    return strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "`", "\\`").Replace(oneLine(s))
+}
+
+func oneLine(s string) string {
+    return strings.Join(strings.Fields(s), " ")
+}
+
+func firstNonEmpty(values ...string) string {
+    for _, v := range values {
+        if strings.TrimSpace(v) != "" { return strings.TrimSpace(v) }
+    }
+    return ""
+}
+
+func idSet(ids []uint) map[uint]bool {
+    m := make(map[uint]bool, len(ids))
+    for _, id := range ids { m[id] = true }
+    return m
+}
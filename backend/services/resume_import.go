@@ -0,0 +1,522 @@
+package services
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+)
+
+// Résumé formats accepted by ParseResume
+const (
+    ResumeFormatJSON = "json_resume"
+    ResumeFormatText = "text" // plain text or Markdown
+)
+
+// resumeMaxTextLength bounds what rules and the AI provider look at.
+const resumeMaxTextLength = 100000
+
+// Resume is an uploaded résumé. Text holds the searchable prose for both
+// formats; JSON is set only for JSON Resume documents.
+type Resume struct {
+    Format string
+    JSON   *JSONResume
+    Text   string
+}
+
+// JSONResume is the subset of https://jsonresume.org/schema used for import.
+type JSONResume struct {
+    Basics struct {
+        Label   string `json:"label"`
+        Summary string `json:"summary"`
+    } `json:"basics"`
+    Work []struct {
+        Name       string   `json:"name"`
+        Company    string   `json:"company"` // pre-1.0 schema
+        Position   string   `json:"position"`
+        StartDate  string   `json:"startDate"`
+        EndDate    string   `json:"endDate"`
+        Summary    string   `json:"summary"`
+        Highlights []string `json:"highlights"`
+    } `json:"work"`
+    Skills []struct {
+        Name     string   `json:"name"`
+        Level    string   `json:"level"`
+        Keywords []string `json:"keywords"`
+    } `json:"skills"`
+    Certificates []struct {
+        Name   string `json:"name"`
+        Date   string `json:"date"`
+        Issuer string `json:"issuer"`
+    } `json:"certificates"`
+}
+
+// ParseResume reads an upload. JSON bodies are either a JSON Resume document
+// or {"format": "text", "content": "..."}; text/plain and text/markdown
+// bodies are taken as text.
+func ParseResume(body []byte, contentType string) (Resume, error) {
+    if len(strings.TrimSpace(string(body))) == 0 {
+        return Resume{}, errors.New("résumé is empty")
+    }
+    if !strings.Contains(contentType, "json") {
+        return textResume(string(body))
+    }
+
+    var wrapper struct {
+        Format  string          `json:"format"`
+        Content json.RawMessage `json:"content"`
+    }
+    if err := json.Unmarshal(body, &wrapper); err != nil {
+        return Resume{}, errors.New("résumé is not valid JSON")
+    }
+    if len(wrapper.Content) > 0 {
+        switch wrapper.Format {
+        case ResumeFormatText, "markdown", "":
+            var text string
+            if err := json.Unmarshal(wrapper.Content, &text); err != nil {
+                return Resume{}, errors.New("content must be a string for text résumés")
+            }
+            return textResume(text)
+        case ResumeFormatJSON:
+            body = wrapper.Content
+        default:
+            return Resume{}, fmt.Errorf("unsupported format %q", wrapper.Format)
+        }
+    }
+
+    var doc JSONResume
+    if err := json.Unmarshal(body, &doc); err != nil {
+        return Resume{}, errors.New("document is not a JSON Resume")
+    }
+    if doc.Basics.Label == "" && len(doc.Work) == 0 && len(doc.Skills) == 0 && len(doc.Certificates) == 0 {
+        return Resume{}, errors.New("document has no basics.label, work, skills or certificates")
+    }
+    var parts []string
+    parts = append(parts, doc.Basics.Label, doc.Basics.Summary)
+    for _, w := range doc.Work {
+        parts = append(parts, w.Position, w.Name, w.Company, w.Summary)
+        parts = append(parts, w.Highlights...)
+    }
+    for _, s := range doc.Skills {
+        parts = append(parts, s.Name)
+        parts = append(parts, s.Keywords...)
+    }
+    for _, c := range doc.Certificates {
+        parts = append(parts, c.Name)
+    }
+    return Resume{Format: ResumeFormatJSON, JSON: &doc, Text: truncateResume(strings.Join(parts, "\n"))}, nil
+}
+
+func textResume(text string) (Resume, error) {
+    if strings.TrimSpace(text) == "" {
+        return Resume{}, errors.New("résumé is empty")
+    }
+    return Resume{Format: ResumeFormatText, Text: truncateResume(text)}, nil
+}
+
+func truncateResume(s string) string {
+    if len(s) > resumeMaxTextLength { return s[:resumeMaxTextLength] }
+    return s
+}
+
+// ResumeExtraction is what an import found. Platforms and frameworks are
+// catalog skills split by Skill.Kind; certifications use catalog names when
+// they resolve.
+type ResumeExtraction struct {
+    CurrentRole     string                   `json:"current_role,omitempty"`
+    ExperienceLevel string                   `json:"experience_level,omitempty"`
+    YearsExperience int                      `json:"years_experience,omitempty"`
+    Industry        string                   `json:"industry,omitempty"`
+    Platforms       []models.ITPlatform      `json:"platforms,omitempty"`
+    Frameworks      []models.ITFramework     `json:"frameworks,omitempty"`
+    Certifications  []models.ITCertification `json:"certifications,omitempty"`
+    Unmatched       []string                 `json:"unmatched_skills,omitempty"` // named skills not in the catalog
+}
+
+// roleKeywords mark a line as a job title.
+var roleKeywords = []string{
+    "engineer", "developer", "administrator", "admin", "analyst", "architect", "manager", "consultant",
+    "specialist", "sre", "devops", "designer", "scientist", "director", "officer", "technician",
+    "programmer", "lead", "head of", "cto", "ciso",
+}
+
+// seniorityKeywords map title words to experience levels, checked in order.
+var seniorityKeywords = []struct {
+    Level string
+    Words []string
+}{
+    {"expert", []string{"principal", "distinguished", "fellow", "chief", "cto", "ciso", "vp", "vice president"}},
+    {"lead", []string{"lead", "head of", "manager", "director", "staff"}},
+    {"senior", []string{"senior", "sr"}},
+    {"junior", []string{"junior", "jr", "associate"}},
+    {"entry", []string{"intern", "trainee", "apprentice", "graduate"}},
+}
+
+// industryKeywords use the industry values offered on the profile page.
+var industryKeywords = map[string][]string{
+    "Finance":       {"bank", "banking", "fintech", "insurance", "trading", "payments", "financial services"},
+    "Healthcare":    {"healthcare", "health", "hospital", "clinical", "pharma", "medical", "nhs"},
+    "Education":     {"university", "school", "edtech", "education", "college"},
+    "Manufacturing": {"manufacturing", "factory", "automotive", "industrial", "plant"},
+    "Consulting":    {"consulting", "consultancy", "advisory", "client engagements"},
+    "Technology":    {"saas", "software", "platform", "startup", "cloud provider"},
+}
+
+var (
+    resumeYearRange  = regexp.MustCompile(`(?i)\b((?:19|20)\d{2})(?:[-/.]\d{1,2})?\s*(?:-|–|—|to|until)\s*((?:19|20)\d{2}|present|current|now|today)\b`)
+    resumeYearsClaim = regexp.MustCompile(`(?i)\b(\d{1,2})\+?\s+years?\b`)
+    markdownNoise    = regexp.MustCompile(`^[#>*\-\s|_]+|[*_|#]+$`)
+)
+
+// ExtractResume applies the rules engine: titles and date ranges give the
+// role, years and level; the catalogs give platforms, frameworks and
+// certifications; a keyword vote gives the industry.
+func ExtractResume(r Resume, skills *SkillCatalog, certs CertificationCatalog, now time.Time) ResumeExtraction {
+    var ex ResumeExtraction
+    var spans [][2]time.Time
+    levels := map[uint]string{} // skill id -> JSON Resume level
+    var found []models.Skill
+
+    if doc := r.JSON; doc != nil {
+        ex.CurrentRole = strings.TrimSpace(doc.Basics.Label)
+        var latest time.Time
+        for _, w := range doc.Work {
+            start := parseResumeDate(w.StartDate)
+            if start == nil { continue }
+            end := now
+            if e := parseResumeDate(w.EndDate); e != nil { end = *e }
+            spans = append(spans, [2]time.Time{*start, end})
+            position := strings.TrimSpace(w.Position)
+            if doc.Basics.Label == "" && position != "" && (ex.CurrentRole == "" || start.After(latest)) {
+                ex.CurrentRole, latest = position, *start
+            }
+        }
+        for _, s := range doc.Skills {
+            names := append([]string{s.Name}, s.Keywords...)
+            resolvedAny := false
+            for _, n := range names {
+                if sk, ok := skills.Resolve(n); ok {
+                    resolvedAny = true
+                    found = append(found, sk)
+                    if s.Level != "" { levels[sk.ID] = s.Level }
+                }
+            }
+            if !resolvedAny && strings.TrimSpace(s.Name) != "" { ex.Unmatched = append(ex.Unmatched, strings.TrimSpace(s.Name)) }
+        }
+        for _, c := range doc.Certificates {
+            name := strings.TrimSpace(c.Name)
+            if name == "" { continue }
+            pc := models.ITCertification{Name: name, Status: "earned"}
+            if cert, ok := certs.Resolve(name); ok {
+                pc.Name = cert.Name
+                if earned := parseResumeDate(c.Date); earned != nil {
+                    if exp := DefaultExpiry(cert, *earned); exp != nil {
+                        pc.Expires = exp.Format("2006-01-02")
+                        if exp.Before(now) { pc.Status = "expired" }
+                    }
+                }
+            } else if len(certs.MatchText(name)) == 1 {
+                pc.Name = certs.MatchText(name)[0].Name
+            }
+            ex.Certifications = appendCertification(ex.Certifications, pc)
+        }
+    } else {
+        ex.CurrentRole = resumeHeadline(r.Text)
+        for _, m := range resumeYearRange.FindAllStringSubmatch(r.Text, -1) {
+            from, _ := strconv.Atoi(m[1])
+            to := now.Year()
+            if y, err := strconv.Atoi(m[2]); err == nil { to = y }
+            if to < from || to > now.Year() { continue }
+            spans = append(spans, [2]time.Time{
+                time.Date(from, 1, 1, 0, 0, 0, 0, time.UTC),
+                time.Date(to, 12, 31, 0, 0, 0, 0, time.UTC),
+            })
+        }
+    }
+
+    ex.YearsExperience = experienceYears(spans, now)
+    if r.Format == ResumeFormatText {
+        for _, m := range resumeYearsClaim.FindAllStringSubmatch(r.Text, -1) {
+            if n, _ := strconv.Atoi(m[1]); n > ex.YearsExperience && n <= 50 { ex.YearsExperience = n }
+        }
+    }
+    ex.ExperienceLevel = levelFromTitle(ex.CurrentRole)
+    if ex.ExperienceLevel == "" && ex.YearsExperience > 0 { ex.ExperienceLevel = levelFromYears(ex.YearsExperience) }
+    ex.Industry = resumeIndustry(r.Text)
+
+    seen := map[uint]bool{}
+    for _, sk := range append(found, skills.MatchText(r.Text)...) {
+        if seen[sk.ID] { continue }
+        seen[sk.ID] = true
+        level := levels[sk.ID]
+        if sk.Kind == "framework" {
+            ex.Frameworks = append(ex.Frameworks, models.ITFramework{Name: sk.Name, Level: jsonResumeLevel(level, FrameworkLevels)})
+        } else {
+            ex.Platforms = append(ex.Platforms, models.ITPlatform{Name: sk.Name, Depth: jsonResumeLevel(level, PlatformDepths)})
+        }
+    }
+    if r.JSON == nil {
+        for _, cert := range certs.MatchText(r.Text) {
+            ex.Certifications = appendCertification(ex.Certifications, models.ITCertification{Name: cert.Name, Status: "earned"})
+        }
+    }
+    return ex
+}
+
+// resumeHeadline picks the first title-like line near the top, falling
+// back to the first one anywhere.
+func resumeHeadline(text string) string {
+    lines := strings.Split(text, "\n")
+    for i, line := range lines {
+        line = strings.TrimSpace(markdownNoise.ReplaceAllString(strings.TrimSpace(line), ""))
+        if line == "" || len(line) > 80 { continue }
+        lower := strings.ToLower(line)
+        if strings.HasPrefix(lower, "title:") || strings.HasPrefix(lower, "role:") {
+            return strings.TrimSpace(line[strings.Index(line, ":")+1:])
+        }
+        if i < 15 && containsWord(lower, roleKeywords) { return line }
+    }
+    for _, line := range lines {
+        line = strings.TrimSpace(markdownNoise.ReplaceAllString(strings.TrimSpace(line), ""))
+        if line != "" && len(line) <= 80 && containsWord(strings.ToLower(line), roleKeywords) { return line }
+    }
+    return ""
+}
+
+func levelFromTitle(title string) string {
+    lower := strings.ToLower(title)
+    for _, s := range seniorityKeywords {
+        if containsWord(lower, s.Words) { return s.Level }
+    }
+    return ""
+}
+
+func levelFromYears(years int) string {
+    switch {
+    case years < 1:
+        return "entry"
+    case years < 3:
+        return "junior"
+    case years < 6:
+        return "mid"
+    case years < 10:
+        return "senior"
+    case years < 15:
+        return "lead"
+    }
+    return "expert"
+}
+
+// resumeIndustry picks the industry with the most keyword hits; ties go
+// to the alphabetically first so results are stable.
+func resumeIndustry(text string) string {
+    lower := " " + strings.ToLower(text) + " "
+    best, bestHits := "", 0
+    names := make([]string, 0, len(industryKeywords))
+    for name := range industryKeywords { names = append(names, name) }
+    sort.Strings(names)
+    for _, name := range names {
+        hits := 0
+        for _, kw := range industryKeywords[name] {
+            hits += countWord(lower, kw)
+        }
+        if hits > bestHits { best, bestHits = name, hits }
+    }
+    return best
+}
+
+// experienceYears sums the calendar covered by the spans, counting
+// overlapping jobs once.
+func experienceYears(spans [][2]time.Time, now time.Time) int {
+    if len(spans) == 0 { return 0 }
+    sort.Slice(spans, func(i, j int) bool { return spans[i][0].Before(spans[j][0]) })
+    var total time.Duration
+    cur := spans[0]
+    for _, s := range spans[1:] {
+        if s[1].After(now) { s[1] = now }
+        if !s[0].After(cur[1]) {
+            if s[1].After(cur[1]) { cur[1] = s[1] }
+            continue
+        }
+        total += cur[1].Sub(cur[0])
+        cur = s
+    }
+    if cur[1].After(now) { cur[1] = now }
+    total += cur[1].Sub(cur[0])
+    return int(total.Hours() / 24 / 365.25)
+}
+
+// jsonResumeLevel maps JSON Resume skill levels (Beginner, Intermediate,
+// Advanced, Master) onto a profile vocabulary of four ascending values.
+func jsonResumeLevel(level string, vocab []string) string {
+    switch strings.ToLower(strings.TrimSpace(level)) {
+    case "beginner", "basic", "novice", "awareness":
+        return vocab[0]
+    case "intermediate", "working", "competent":
+        return vocab[1]
+    case "advanced", "proficient", "practitioner":
+        return vocab[2]
+    case "master", "expert":
+        return vocab[3]
+    }
+    return ""
+}
+
+func parseResumeDate(s string) *time.Time {
+    s = strings.TrimSpace(s)
+    for _, layout := range []string{"2006-01-02", "2006-01", "2006"} {
+        if t, err := time.Parse(layout, s); err == nil { return &t }
+    }
+    return nil
+}
+
+func appendCertification(list []models.ITCertification, c models.ITCertification) []models.ITCertification {
+    for _, have := range list {
+        if strings.EqualFold(have.Name, c.Name) { return list }
+    }
+    return append(list, c)
+}
+
+func containsWord(lower string, words []string) bool {
+    for _, w := range words {
+        if countWord(" "+lower+" ", w) > 0 { return true }
+    }
+    return false
+}
+
+// countWord counts whole-word occurrences of w in padded lower-case text.
+func countWord(padded, w string) int {
+    n := 0
+    for i := 0; ; {
+        j := strings.Index(padded[i:], w)
+        if j < 0 { return n }
+        j += i
+        before, after := padded[j-1], byte(' ')
+        if j+len(w) < len(padded) { after = padded[j+len(w)] }
+        if !isWordByte(before) && !isWordByte(after) { n++ }
+        i = j + len(w)
+    }
+}
+
+func isWordByte(b byte) bool {
+    return b >= 'a' && b <= 'z' || b >= '0' && b <= '9'
+}
+
+// ExtractResumeWithAI asks the configured provider for the same fields and
+// normalizes the answer through the catalogs and vocabularies. It returns
+// an error when no provider is configured or the reply is unusable; callers
+// fall back to ExtractResume.
+func (ai *AIService) ExtractResumeWithAI(r Resume, skills *SkillCatalog, certs CertificationCatalog) (ResumeExtraction, error) {
+    if ai.provider != "openai" || ai.openAIKey == "" {
+        return ResumeExtraction{}, errors.New("no AI provider configured")
+    }
+    text := r.Text
+    if len(text) > 12000 { text = text[:12000] }
+    prompt := fmt.Sprintf(`Extract career facts from this résumé. Return JSON:
+{"current_role": string, "experience_level": one of %s, "years_experience": int, "industry": string,
+ "platforms": [{"name": string, "depth": one of %s}], "frameworks": [{"name": string, "level": one of %s}],
+ "certifications": [{"name": string, "expires": "YYYY-MM" or ""}]}
+Use only facts stated in the résumé.
+
+%s`, strings.Join(ExperienceLevels, "|"), strings.Join(PlatformDepths, "|"), strings.Join(FrameworkLevels, "|"), text)
+
+    content, err := ai.chatJSON("You extract structured profile data from résumés and return STRICT JSON.", prompt, 800)
+    if err != nil { return ResumeExtraction{}, err }
+    var raw ResumeExtraction
+    if err := json.Unmarshal([]byte(content), &raw); err != nil { return ResumeExtraction{}, sanitizeError(err) }
+
+    ex := ResumeExtraction{
+        CurrentRole:     strings.TrimSpace(raw.CurrentRole),
+        YearsExperience: raw.YearsExperience,
+        ExperienceLevel: matchVocab(raw.ExperienceLevel, ExperienceLevels),
+        Industry:        strings.TrimSpace(raw.Industry),
+    }
+    add := func(name, level string) {
+        sk, ok := skills.Resolve(name)
+        if !ok {
+            if name = strings.TrimSpace(name); name != "" { ex.Unmatched = append(ex.Unmatched, name) }
+            return
+        }
+        if sk.Kind == "framework" {
+            ex.Frameworks = append(ex.Frameworks, models.ITFramework{Name: sk.Name, Level: matchVocab(level, FrameworkLevels)})
+        } else {
+            ex.Platforms = append(ex.Platforms, models.ITPlatform{Name: sk.Name, Depth: matchVocab(level, PlatformDepths)})
+        }
+    }
+    for _, p := range raw.Platforms { add(p.Name, p.Depth) }
+    for _, f := range raw.Frameworks { add(f.Name, f.Level) }
+    for _, c := range raw.Certifications {
+        name := strings.TrimSpace(c.Name)
+        if name == "" { continue }
+        if cert, ok := certs.Resolve(name); ok { name = cert.Name }
+        pc := models.ITCertification{Name: name, Status: "earned"}
+        if parseMonthOrDay(c.Expires) != nil { pc.Expires = strings.TrimSpace(c.Expires) }
+        ex.Certifications = appendCertification(ex.Certifications, pc)
+    }
+    if ex.CurrentRole == "" && len(ex.Platforms)+len(ex.Frameworks)+len(ex.Certifications) == 0 {
+        return ex, errors.New("AI extraction found nothing")
+    }
+    return ex, nil
+}
+
+func matchVocab(s string, vocab []string) string {
+    for _, v := range vocab {
+        if strings.EqualFold(v, strings.TrimSpace(s)) { return v }
+    }
+    return ""
+}
+
+// ProposeProfileFromResume merges an extraction into a profile without
+// discarding anything the user already has: scalar fields are replaced
+// only when found, and list entries are added when not already present.
+// The result is the proposed profile; nothing is saved.
+func ProposeProfileFromResume(current models.UserProfile, ex ResumeExtraction) models.UserProfile {
+    p := current
+    if ex.CurrentRole != "" { p.CurrentRole = ex.CurrentRole }
+    if ex.ExperienceLevel != "" { p.ExperienceLevel = ex.ExperienceLevel }
+    if ex.Industry != "" { p.Industry = ex.Industry }
+
+    it := DecodeITProfile(current.ITProfile)
+    if ex.CurrentRole != "" { it.Role.Current = ex.CurrentRole }
+    if ex.ExperienceLevel != "" { it.Role.Level = ex.ExperienceLevel }
+    for _, f := range ex.Frameworks {
+        i := indexByName(len(it.Frameworks), func(i int) string { return it.Frameworks[i].Name }, f.Name)
+        if i < 0 {
+            it.Frameworks = append(it.Frameworks, f)
+        } else if it.Frameworks[i].Level == "" {
+            it.Frameworks[i].Level = f.Level
+        }
+    }
+    for _, pl := range ex.Platforms {
+        i := indexByName(len(it.Platforms), func(i int) string { return it.Platforms[i].Name }, pl.Name)
+        if i < 0 {
+            it.Platforms = append(it.Platforms, pl)
+        } else if it.Platforms[i].Depth == "" {
+            it.Platforms[i].Depth = pl.Depth
+        }
+    }
+    for _, c := range ex.Certifications {
+        i := indexByName(len(it.Certifications), func(i int) string { return it.Certifications[i].Name }, c.Name)
+        if i < 0 {
+            it.Certifications = append(it.Certifications, c)
+        } else if it.Certifications[i].Expires == "" {
+            it.Certifications[i].Expires = c.Expires
+        }
+    }
+    if it.SchemaVersion == 0 { it.SchemaVersion = models.ITProfileSchemaVersion }
+    p.ITProfile = EncodeITProfile(it)
+    return p
+}
+
+func indexByName(n int, name func(int) string, want string) int {
+    for i := 0; i < n; i++ {
+        if strings.EqualFold(strings.TrimSpace(name(i)), strings.TrimSpace(want)) { return i }
+    }
+    return -1
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+    "encoding/json"
+    "sort"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// Defaults for requirements inferred from responsibility text when a role
+// has no explicit skill requirements.
+const (
+    derivedRequirementLevel      = 3 // practitioner
+    derivedRequirementImportance = 2
+    maxGapSuggestions            = 3
+)
+
+// GapTarget records how the target role was chosen.
+type GapTarget struct {
+    Text    string          `json:"text"`
+    Source  string          `json:"source"` // job_role_id, query, it_profile, career_goals
+    Match   string          `json:"match"`  // exact or fuzzy
+    JobRole *models.JobRole `json:"job_role"`
+}
+
+// SkillGap is one requirement of the target role and where the user stands.
+type SkillGap struct {
+    Kind          string                  `json:"kind"` // skill, certification or responsibility
+    ID            uint                    `json:"id"`
+    Name          string                  `json:"name"`
+    Category      string                  `json:"category,omitempty"`
+    RequiredLevel *int                    `json:"required_level,omitempty"`
+    CurrentLevel  *int                    `json:"current_level,omitempty"`
+    Status        string                  `json:"status"` // met, below, missing, expired, planned, uncovered
+    Importance    int                     `json:"importance"`
+    Score         int                     `json:"score"`
+    Source        string                  `json:"source"` // requirement or derived
+    Suggestions   []models.GoalSuggestion `json:"suggestions"`
+}
+
+// GapAnalysis compares a user with a target role. Coverage is the
+// importance-weighted share of requirements already met.
+type GapAnalysis struct {
+    Target   GapTarget  `json:"target"`
+    Coverage float64    `json:"coverage"`
+    Gaps     []SkillGap `json:"gaps"`
+    Met      []SkillGap `json:"met"`
+}
+
+// ResolveTargetRole matches free text ("Senior Security Analyst", a career
+// goal sentence) to a job role: exact title first, then the title sharing
+// the most significant words.
+func ResolveTargetRole(roles []models.JobRole, text string) (*models.JobRole, string) {
+    text = strings.TrimSpace(text)
+    if text == "" { return nil, "" }
+    for i := range roles {
+        if strings.EqualFold(roles[i].Title, text) { return &roles[i], "exact" }
+    }
+    words := significantWords(text)
+    best, bestScore := -1, 0
+    for i := range roles {
+        score := 0
+        titleWords := significantWords(roles[i].Title)
+        for w := range titleWords {
+            if words[w] { score++ }
+        }
+        // require most of the title to appear so "analyst" alone does not pick a role
+        if score*2 < len(titleWords) { continue }
+        if score > bestScore { best, bestScore = i, score }
+    }
+    if best < 0 { return nil, "" }
+    return &roles[best], "fuzzy"
+}
+
+// AnalyzeGaps compares the user's skills, certifications and completed goals
+// with role's requirements and responsibilities. role must have its
+// Responsibilities loaded. Gaps are ranked by importance times shortfall.
+// Suggestions the user dismissed or adopted are not offered.
+func AnalyzeGaps(db *gorm.DB, userID string, role models.JobRole, now time.Time) (*GapAnalysis, error) {
+    out := &GapAnalysis{Gaps: []SkillGap{}, Met: []SkillGap{}}
+
+    var reqs []models.JobRoleRequirement
+    if err := db.Preload("Skill").Preload("Certification").Where("job_role_id = ?", role.ID).Find(&reqs).Error; err != nil {
+        return nil, err
+    }
+    skills, err := LoadSkillCatalog(db)
+    if err != nil { return nil, err }
+    certCat, err := LoadCertificationCatalog(db)
+    if err != nil { return nil, err }
+
+    var userSkills []models.UserSkill
+    if err := db.Where("user_id = ?", userID).Find(&userSkills).Error; err != nil { return nil, err }
+    levels := map[uint]int{}
+    for _, us := range userSkills { levels[us.SkillID] = us.Proficiency }
+
+    var held []models.UserCertification
+    if err := db.Where("user_id = ?", userID).Find(&held).Error; err != nil { return nil, err }
+    heldCerts := map[uint]models.UserCertification{}
+    for _, uc := range held { heldCerts[uc.CertificationID] = uc }
+    var profile models.UserProfile
+    db.Where("user_id = ?", userID).Limit(1).Find(&profile)
+    plannedCerts := map[uint]bool{}
+    for _, pc := range DecodeITProfile(profile.ITProfile).Certifications {
+        if pc.Status != "planned" && pc.Status != "in_progress" { continue }
+        if cert, ok := certCat.Resolve(pc.Name); ok { plannedCerts[cert.ID] = true }
+    }
+
+    var completed []models.Goal
+    if err := db.Where("user_id = ? AND status = 'completed'", userID).Find(&completed).Error; err != nil { return nil, err }
+
+    var suggestions []models.GoalSuggestion
+    if err := db.Scopes(Published("goal_suggestions")).Preload("Responsibility").Order("id").Find(&suggestions).Error; err != nil { return nil, err }
+    hidden, err := LoadHiddenSuggestions(db, userID)
+    if err != nil { return nil, err }
+    suggestions = hidden.VisibleSuggestions(suggestions)
+
+    var gaps []SkillGap
+    hasSkillReq := false
+    for _, r := range reqs {
+        switch {
+        case r.SkillID != nil && r.Skill != nil:
+            hasSkillReq = true
+            gaps = append(gaps, skillGap(*r.Skill, r.Level, r.Importance, levels, "requirement"))
+        case r.CertificationID != nil && r.Certification != nil:
+            gaps = append(gaps, certificationGap(*r.Certification, r.Importance, heldCerts, plannedCerts, now))
+        }
+    }
+    if !hasSkillReq {
+        seen := map[uint]bool{}
+        for _, resp := range role.Responsibilities {
+            for _, s := range skills.MatchText(resp.Title + " " + resp.Description) {
+                if seen[s.ID] { continue }
+                seen[s.ID] = true
+                gaps = append(gaps, skillGap(s, derivedRequirementLevel, derivedRequirementImportance, levels, "derived"))
+            }
+        }
+    }
+    for _, resp := range role.Responsibilities {
+        gaps = append(gaps, responsibilityGap(resp, role, completed))
+    }
+
+    totalWeight, metWeight := 0, 0
+    for _, g := range gaps {
+        totalWeight += g.Importance
+        if g.Status == "met" {
+            metWeight += g.Importance
+            out.Met = append(out.Met, g)
+            continue
+        }
+        g.Suggestions = gapSuggestions(g, role, suggestions, skills, certCat)
+        out.Gaps = append(out.Gaps, g)
+    }
+    if totalWeight > 0 { out.Coverage = float64(metWeight) / float64(totalWeight) }
+    sort.SliceStable(out.Gaps, func(i, j int) bool {
+        a, b := out.Gaps[i], out.Gaps[j]
+        if a.Score != b.Score { return a.Score > b.Score }
+        if a.Importance != b.Importance { return a.Importance > b.Importance }
+        return a.Name < b.Name
+    })
+    return out, nil
+}
+
+func skillGap(s models.Skill, required, importance int, levels map[uint]int, source string) SkillGap {
+    current := levels[s.ID]
+    req := required
+    g := SkillGap{
+        Kind: "skill", ID: s.ID, Name: s.Name, Category: s.Category,
+        RequiredLevel: &req, CurrentLevel: &current, Importance: importance, Source: source,
+    }
+    switch {
+    case current >= required:
+        g.Status = "met"
+    case current == 0:
+        g.Status = "missing"
+    default:
+        g.Status = "below"
+    }
+    if current < required { g.Score = importance * (required - current) }
+    return g
+}
+
+func certificationGap(cert models.Certification, importance int, held map[uint]models.UserCertification, planned map[uint]bool, now time.Time) SkillGap {
+    g := SkillGap{Kind: "certification", ID: cert.ID, Name: cert.Name, Category: cert.Issuer, Importance: importance, Source: "requirement"}
+    uc, ok := held[cert.ID]
+    switch {
+    case ok && (uc.ExpiresAt == nil || !uc.ExpiresAt.Before(truncateDay(now))):
+        g.Status = "met"
+    case ok:
+        g.Status, g.Score = "expired", importance*2
+    case planned[cert.ID]:
+        g.Status, g.Score = "planned", importance*2
+    default:
+        g.Status, g.Score = "missing", importance*3
+    }
+    return g
+}
+
+// responsibilityGap is met when a completed goal served the responsibility.
+func responsibilityGap(resp models.Responsibility, role models.JobRole, completed []models.Goal) SkillGap {
+    g := SkillGap{
+        Kind: "responsibility", ID: resp.ID, Name: resp.Title, Category: resp.Category,
+        Importance: derivedRequirementImportance, Source: "derived", Status: "uncovered",
+        Score: derivedRequirementImportance * 2,
+    }
+    respWords := significantWords(resp.Title)
+    for _, goal := range completed {
+        var meta struct {
+            ResponsibilityID uint `json:"responsibility_id"`
+        }
+        _ = json.Unmarshal([]byte(goal.Metadata), &meta)
+        covered := meta.ResponsibilityID == resp.ID
+        if !covered && meta.ResponsibilityID == 0 && goal.JobRoleID != nil && *goal.JobRoleID == role.ID {
+            goal.JobRole = &role
+            linked := linkedResponsibilities(goal)
+            covered = len(linked) > 0 && linked[0].ID == resp.ID
+        }
+        if !covered && meta.ResponsibilityID == 0 {
+            shared := 0
+            for w := range significantWords(goal.Title + " " + goal.Description) {
+                if respWords[w] { shared++ }
+            }
+            covered = shared >= 2
+        }
+        if covered {
+            g.Status, g.Score = "met", 0
+            break
+        }
+    }
+    return g
+}
+
+// gapSuggestions picks catalog goal suggestions that would close a gap,
+// preferring ones written for the target role and higher priority.
+func gapSuggestions(g SkillGap, role models.JobRole, all []models.GoalSuggestion, skills *SkillCatalog, certs CertificationCatalog) []models.GoalSuggestion {
+    type scored struct {
+        s     models.GoalSuggestion
+        score int
+    }
+    var picks []scored
+    for _, s := range all {
+        text := s.Title + " " + s.Description
+        match := false
+        switch g.Kind {
+        case "responsibility":
+            match = s.ResponsibilityID == g.ID
+        case "skill":
+            for _, sk := range skills.MatchText(text) {
+                if sk.ID == g.ID { match = true; break }
+            }
+        case "certification":
+            for _, c := range certs.MatchText(text) {
+                if c.ID == g.ID { match = true; break }
+            }
+        }
+        if !match { continue }
+        score := 0
+        if s.Responsibility.JobRoleID == role.ID { score += 2 }
+        switch s.Priority {
+        case "high":
+            score += 2
+        case "medium":
+            score++
+        }
+        picks = append(picks, scored{s, score})
+    }
+    sort.SliceStable(picks, func(i, j int) bool { return picks[i].score > picks[j].score })
+    out := []models.GoalSuggestion{}
+    for i := 0; i < len(picks) && i < maxGapSuggestions; i++ { out = append(out, picks[i].s) }
+    return out
+}
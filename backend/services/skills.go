@@ -0,0 +1,337 @@
+package services
+
+import (
+    "encoding/json"
+    "fmt"
+    "strings"
+    "time"
+    "unicode"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// ProficiencyLevels names UserSkill.Proficiency values by index.
+var ProficiencyLevels = []string{"none", "awareness", "working", "practitioner", "expert"}
+
+// goalCompletionCap is the highest level a completed goal can raise a skill
+// to; expert has to be claimed explicitly.
+const goalCompletionCap = 3
+
+// ProficiencyFromLabel maps IT profile framework levels and platform depths
+// onto the 0-4 scale. Unlabelled entries get def.
+func ProficiencyFromLabel(label string, def int) int {
+    switch strings.ToLower(strings.TrimSpace(label)) {
+    case "awareness", "basic":
+        return 1
+    case "working", "intermediate":
+        return 2
+    case "practitioner", "advanced":
+        return 3
+    case "expert":
+        return 4
+    case "none":
+        return 0
+    }
+    return def
+}
+
+// SkillCatalog resolves free-text names and aliases to catalog skills.
+type SkillCatalog struct {
+    byKey  map[string]models.Skill
+    maxLen int // longest key in words, for phrase matching
+}
+
+// LoadSkillCatalog reads the reviewed catalog; it is small and read often.
+func LoadSkillCatalog(db *gorm.DB) (*SkillCatalog, error) {
+    return loadSkillCatalog(db, false)
+}
+
+// loadSkillCatalog reads the catalog, with unreviewed skills if asked; only
+// profile sync needs those, to find the ones it created before.
+func loadSkillCatalog(db *gorm.DB, unreviewed bool) (*SkillCatalog, error) {
+    var skills []models.Skill
+    q := db
+    if !unreviewed { q = q.Where("unreviewed = ?", false) }
+    if err := q.Find(&skills).Error; err != nil {
+        return nil, err
+    }
+    cat := &SkillCatalog{byKey: map[string]models.Skill{}, maxLen: 1}
+    for _, s := range skills { cat.add(s) }
+    return cat, nil
+}
+
+func (c *SkillCatalog) add(s models.Skill) {
+    keys := []string{s.Slug, s.Name}
+    var aliases []string
+    _ = json.Unmarshal([]byte(s.Aliases), &aliases)
+    keys = append(keys, aliases...)
+    for _, k := range keys {
+        k = normalizeSkillKey(k)
+        if k == "" { continue }
+        if _, taken := c.byKey[k]; !taken { c.byKey[k] = s }
+        if n := len(strings.Fields(k)); n > c.maxLen { c.maxLen = n }
+    }
+}
+
+// Resolve finds the skill for a name, slug or alias.
+func (c *SkillCatalog) Resolve(name string) (models.Skill, bool) {
+    if s, ok := c.byKey[normalizeSkillKey(name)]; ok { return s, true }
+    s, ok := c.byKey[normalizeSkillKey(SkillSlug(name))]
+    return s, ok
+}
+
+// ResolveOrCreate resolves name, adding an unreviewed, uncategorised entry
+// when nothing matches so user data is never dropped without the name
+// reaching the public catalog.
+func (c *SkillCatalog) ResolveOrCreate(tx *gorm.DB, name string) (models.Skill, error) {
+    if s, ok := c.Resolve(name); ok { return s, nil }
+    s := models.Skill{Slug: SkillSlug(name), Name: strings.TrimSpace(name), Category: "other", Kind: "platform", Aliases: "[]", Unreviewed: true}
+    if s.Slug == "" { return s, fmt.Errorf("invalid skill name %q", name) }
+    err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "slug"}}, DoNothing: true}).Create(&s).Error
+    if err != nil { return s, err }
+    if s.ID == 0 {
+        if err := tx.Where("slug = ?", s.Slug).First(&s).Error; err != nil { return s, err }
+    }
+    c.add(s)
+    return s, nil
+}
+
+// MatchText returns the catalog skills mentioned in text as whole words or
+// phrases ("site reliability engineering", "k8s"), in order of appearance.
+func (c *SkillCatalog) MatchText(text string) []models.Skill {
+    seen := map[uint]bool{}
+    var out []models.Skill
+    scanPhrases(text, c.maxLen, func(phrase string) bool {
+        s, ok := c.byKey[phrase]
+        if ok && !seen[s.ID] { seen[s.ID] = true; out = append(out, s) }
+        return ok
+    })
+    return out
+}
+
+// scanPhrases offers match every run of up to maxLen words in text, longest
+// first at each position, as a normalized key. A run that match accepts is
+// not also offered as its shorter prefixes.
+func scanPhrases(text string, maxLen int, match func(phrase string) bool) {
+    words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#' && r != '.' && r != '/' && r != '-'
+    })
+    for i := range words { words[i] = strings.Trim(words[i], ".-/") }
+    for i := range words {
+        for n := maxLen; n >= 1; n-- {
+            if i+n > len(words) { continue }
+            if match(strings.Join(words[i:i+n], " ")) { break }
+        }
+    }
+}
+
+// SkillSlug turns a display name into a catalog slug: "GitHub Actions" -> "github-actions".
+func SkillSlug(name string) string {
+    var b strings.Builder
+    dash := false
+    name = strings.NewReplacer("+", " plus ", "#", " sharp ").Replace(strings.ToLower(strings.TrimSpace(name)))
+    for _, r := range name {
+        if unicode.IsLetter(r) || unicode.IsDigit(r) {
+            b.WriteRune(r)
+            dash = false
+        } else if !dash && b.Len() > 0 {
+            b.WriteByte('-')
+            dash = true
+        }
+    }
+    return strings.TrimSuffix(b.String(), "-")
+}
+
+func normalizeSkillKey(s string) string {
+    return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// ProfileSkill is one skill claim extracted from a user profile.
+type ProfileSkill struct {
+    Name        string
+    Proficiency int
+    LastUsed    *time.Time
+}
+
+// ProfileSkills collects skill claims from the IT profile frameworks and
+// platforms, CurrentTools and SkillGaps. A gap never overrides a claim.
+func ProfileSkills(p models.UserProfile) []ProfileSkill {
+    it := DecodeITProfile(p.ITProfile)
+    var out []ProfileSkill
+    for _, f := range it.Frameworks {
+        out = append(out, ProfileSkill{Name: f.Name, Proficiency: ProficiencyFromLabel(f.Level, 1)})
+    }
+    for _, pl := range it.Platforms {
+        out = append(out, ProfileSkill{Name: pl.Name, Proficiency: ProficiencyFromLabel(pl.Depth, 2), LastUsed: parseMonthOrDay(pl.LastUsed)})
+    }
+    for _, t := range decodeStringList(p.CurrentTools) {
+        out = append(out, ProfileSkill{Name: t, Proficiency: 2})
+    }
+    for _, g := range decodeStringList(p.SkillGaps) {
+        out = append(out, ProfileSkill{Name: g, Proficiency: 0})
+    }
+    return out
+}
+
+// SyncProfileSkills updates the user's skill records from a profile write.
+// Only claims that differ from the previous profile are applied, so levels
+// raised by completed goals are not reset by unrelated profile edits.
+func SyncProfileSkills(tx *gorm.DB, before *models.UserProfile, after models.UserProfile) error {
+    cat, err := loadSkillCatalog(tx, true)
+    if err != nil { return err }
+
+    previous := map[uint]ProfileSkill{}
+    if before != nil {
+        for id, ps := range resolveClaims(cat, ProfileSkills(*before)) { previous[id] = ps }
+    }
+
+    claims := map[uint]ProfileSkill{}
+    for _, ps := range ProfileSkills(after) {
+        if strings.TrimSpace(ps.Name) == "" { continue }
+        s, err := cat.ResolveOrCreate(tx, ps.Name)
+        if err != nil { return err }
+        claims[s.ID] = mergeClaim(claims[s.ID], ps)
+    }
+
+    for skillID, ps := range claims {
+        if prev, ok := previous[skillID]; ok && prev.Proficiency == ps.Proficiency && sameDay(prev.LastUsed, ps.LastUsed) {
+            continue
+        }
+        if err := setUserSkill(tx, after.UserID, skillID, ps.Proficiency, ps.LastUsed, "profile", "profile_update", nil); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// ApplyGoalCompletion raises the skills a completed goal exercised (from its
+// tags and title) by one level, up to practitioner, and marks them used.
+func ApplyGoalCompletion(tx *gorm.DB, g models.Goal) error {
+    cat, err := LoadSkillCatalog(tx)
+    if err != nil { return err }
+
+    skills := map[uint]bool{}
+    for _, t := range decodeStringList(g.Tags) {
+        if s, ok := cat.Resolve(t); ok { skills[s.ID] = true }
+    }
+    for _, s := range cat.MatchText(g.Title) { skills[s.ID] = true }
+
+    now := time.Now()
+    for skillID := range skills {
+        var us models.UserSkill
+        tx.Where("user_id = ? AND skill_id = ?", g.UserID, skillID).Limit(1).Find(&us)
+        level := us.Proficiency
+        if level < goalCompletionCap { level++ }
+        goalID := g.ID
+        if err := setUserSkill(tx, g.UserID, skillID, level, &now, "goal", "goal_completed", &goalID); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// SetUserSkill records a self-assessed proficiency.
+func SetUserSkill(tx *gorm.DB, userID string, skillID uint, level int, lastUsed *time.Time) error {
+    return setUserSkill(tx, userID, skillID, level, lastUsed, "manual", "manual", nil)
+}
+
+// setUserSkill upserts a user's skill level and appends history when the
+// level changes.
+func setUserSkill(tx *gorm.DB, userID string, skillID uint, level int, lastUsed *time.Time, source, reason string, goalID *uint) error {
+    var us models.UserSkill
+    found := tx.Where("user_id = ? AND skill_id = ?", userID, skillID).Limit(1).Find(&us).RowsAffected > 0
+    from := us.Proficiency
+    if !found {
+        us = models.UserSkill{UserID: userID, SkillID: skillID, Source: source}
+    }
+    us.Proficiency = level
+    if lastUsed != nil && (us.LastUsedAt == nil || lastUsed.After(*us.LastUsedAt)) {
+        us.LastUsedAt = lastUsed
+    }
+    if found && from != level { us.Source = source }
+    if err := tx.Save(&us).Error; err != nil { return err }
+
+    if found && from == level { return nil }
+    if !found && level == 0 { return nil }
+    return tx.Create(&models.SkillProficiencyChange{
+        UserID: userID, SkillID: skillID, FromLevel: from, ToLevel: level, Reason: reason, GoalID: goalID,
+    }).Error
+}
+
+func resolveClaims(cat *SkillCatalog, claims []ProfileSkill) map[uint]ProfileSkill {
+    out := map[uint]ProfileSkill{}
+    for _, ps := range claims {
+        if s, ok := cat.Resolve(ps.Name); ok { out[s.ID] = mergeClaim(out[s.ID], ps) }
+    }
+    return out
+}
+
+// mergeClaim keeps the strongest claim for a skill listed more than once
+func mergeClaim(cur, next ProfileSkill) ProfileSkill {
+    if cur.Name == "" || next.Proficiency > cur.Proficiency {
+        if next.LastUsed == nil { next.LastUsed = cur.LastUsed }
+        return next
+    }
+    if next.LastUsed != nil && (cur.LastUsed == nil || next.LastUsed.After(*cur.LastUsed)) { cur.LastUsed = next.LastUsed }
+    return cur
+}
+
+func sameDay(a, b *time.Time) bool {
+    if a == nil || b == nil { return a == b }
+    return truncateDay(*a).Equal(truncateDay(*b))
+}
+
+func parseMonthOrDay(s string) *time.Time {
+    for _, layout := range []string{"2006-01-02", "2006-01"} {
+        if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil { return &t }
+    }
+    return nil
+}
+
+// decodeStringList reads a JSON array of names; non-string items (e.g. tool
+// ids) are formatted as text.
+func decodeStringList(s string) []string {
+    if strings.TrimSpace(s) == "" { return nil }
+    var raw []interface{}
+    if err := json.Unmarshal([]byte(s), &raw); err != nil { return nil }
+    out := make([]string, 0, len(raw))
+    for _, v := range raw {
+        switch t := v.(type) {
+        case string:
+            if strings.TrimSpace(t) != "" { out = append(out, t) }
+        case map[string]interface{}:
+            if n, ok := t["name"].(string); ok && n != "" { out = append(out, n) }
+        case nil:
+        default:
+            out = append(out, fmt.Sprint(t))
+        }
+    }
+    return out
+}
+
+// GoalTags reads a goal's stored tags. Besides the JSON array of strings the
+// API writes, it accepts what older clients stored: arrays of other values,
+// a JSON string, or plain comma-separated text.
+func GoalTags(s string) []string {
+    if strings.TrimSpace(s) == "" { return nil }
+    var text string
+    if err := json.Unmarshal([]byte(s), &text); err != nil {
+        if out := decodeStringList(s); out != nil { return out }
+        text = s
+    }
+    out := []string{}
+    for _, t := range strings.Split(text, ",") {
+        if t = strings.TrimSpace(t); t != "" { out = append(out, t) }
+    }
+    return out
+}
+
+// PruneUnreviewedSkills deletes unreviewed skills nobody holds any more,
+// e.g. after the only user who named one is erased.
+func PruneUnreviewedSkills(tx *gorm.DB) error {
+    return tx.Where("unreviewed = ? AND id NOT IN (SELECT skill_id FROM user_skills) AND id NOT IN (SELECT skill_id FROM skill_proficiency_changes) AND id NOT IN (SELECT skill_id FROM job_role_requirements WHERE skill_id IS NOT NULL)", true).
+        Delete(&models.Skill{}).Error
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// Suggestion feedback sources and events (see models.SuggestionFeedback).
+var (
+    SuggestionSources = []string{"catalog", "ai"}
+    SuggestionEvents  = []string{"impression", "dismiss", "save", "adopt", "thumbs_up", "thumbs_down", "restore"}
+    // DismissReasons are the reasons a dismissal may give.
+    DismissReasons = []string{"not_relevant", "already_done", "too_easy", "too_hard", "not_now", "other"}
+)
+
+// impressionWindow suppresses repeated impressions: an item shown to the
+// same user again within it is not recorded twice.
+const impressionWindow = 24 * time.Hour
+
+// Ranking weight of aggregate feedback. A suggestion's boost is
+// 1 + feedbackWeight*(positive-negative)/(positive+negative+feedbackPrior),
+// so it stays within 1±feedbackWeight and needs a few users to move.
+const (
+    feedbackWeight = 0.5
+    feedbackPrior  = 10
+)
+
+// AISuggestionKey identifies an AI suggestion by its title, which is all
+// that is stable across generations: case, punctuation and spacing are
+// ignored.
+func AISuggestionKey(title string) string {
+    sum := sha256.Sum256([]byte(strings.Join(searchTerms(title), " ")))
+    return hex.EncodeToString(sum[:8])
+}
+
+// SuggestionFeedbackInput is the body of a feedback event. Catalog
+// suggestions are named by suggestion_id, AI suggestions by title (and
+// the key returned with them, which is derived from the title if absent).
+type SuggestionFeedbackInput struct {
+    Source        string `json:"source"`
+    SuggestionID  *uint  `json:"suggestion_id"`
+    SuggestionKey string `json:"suggestion_key"`
+    Title         string `json:"title"`
+    Event         string `json:"event"`
+    Reason        string `json:"reason"`
+    Note          string `json:"note"`
+    GoalID        *uint  `json:"goal_id"`
+}
+
+// ValidateSuggestionFeedback normalizes in and checks it for userID: the
+// catalog suggestion must be published, a dismissal needs one of
+// DismissReasons, and an adopted goal must be the user's own.
+func ValidateSuggestionFeedback(db *gorm.DB, userID string, in *SuggestionFeedbackInput) []FieldError {
+    var errs []FieldError
+    add := func(field, code string, args ...interface{}) { errs = append(errs, NewFieldError(field, code, args...)) }
+    oneOf := func(field string, s *string, allowed []string) bool {
+        *s = strings.ToLower(strings.TrimSpace(*s))
+        for _, a := range allowed {
+            if *s == a { return true }
+        }
+        add(field, "value_not_one_of", strings.Join(allowed, ", "))
+        return false
+    }
+
+    if oneOf("source", &in.Source, SuggestionSources) {
+        in.Title, in.SuggestionKey = strings.TrimSpace(in.Title), strings.TrimSpace(in.SuggestionKey)
+        switch in.Source {
+        case "catalog":
+            in.SuggestionKey, in.Title = "", ""
+            if in.SuggestionID == nil {
+                add("suggestion_id", "value_required")
+            } else {
+                var n int64
+                db.Model(&models.GoalSuggestion{}).Scopes(Published("goal_suggestions")).Where("id = ?", *in.SuggestionID).Count(&n)
+                if n == 0 { add("suggestion_id", "value_unknown_suggestion") }
+            }
+        case "ai":
+            in.SuggestionID = nil
+            switch {
+            case in.Title == "":
+                add("title", "value_required")
+            case len(in.Title) > 200:
+                add("title", "value_too_long", 200)
+            case in.SuggestionKey != "" && in.SuggestionKey != AISuggestionKey(in.Title):
+                add("suggestion_key", "value_suggestion_key_mismatch")
+            default:
+                in.SuggestionKey = AISuggestionKey(in.Title)
+            }
+        }
+    }
+    if oneOf("event", &in.Event, SuggestionEvents) {
+        if in.Event == "dismiss" {
+            oneOf("reason", &in.Reason, DismissReasons)
+        } else if strings.TrimSpace(in.Reason) != "" {
+            add("reason", "value_only_when_dismissing")
+        }
+        if in.GoalID != nil {
+            var n int64
+            db.Model(&models.Goal{}).Where("id = ? AND user_id = ?", *in.GoalID, userID).Count(&n)
+            switch {
+            case in.Event != "adopt":
+                add("goal_id", "value_only_when_adopting")
+            case n == 0:
+                add("goal_id", "value_not_your_goal")
+            }
+        }
+    }
+    if in.Note = strings.TrimSpace(in.Note); len(in.Note) > 500 { add("note", "value_too_long", 500) }
+    return errs
+}
+
+// RecordSuggestionFeedback stores a validated feedback event.
+func RecordSuggestionFeedback(db *gorm.DB, userID string, in SuggestionFeedbackInput, now time.Time) (models.SuggestionFeedback, error) {
+    fb := models.SuggestionFeedback{
+        UserID: userID, Source: in.Source, SuggestionID: in.SuggestionID, SuggestionKey: in.SuggestionKey,
+        Title: in.Title, Event: in.Event, Reason: in.Reason, Note: in.Note, GoalID: in.GoalID, CreatedAt: now,
+    }
+    return fb, db.Create(&fb).Error
+}
+
+// CatalogImpression and AIImpression describe a suggestion shown to a user
+// for RecordSuggestionImpressions.
+func CatalogImpression(id uint) models.SuggestionFeedback {
+    return models.SuggestionFeedback{Source: "catalog", SuggestionID: &id}
+}
+
+func AIImpression(title string) models.SuggestionFeedback {
+    return models.SuggestionFeedback{Source: "ai", SuggestionKey: AISuggestionKey(title), Title: title}
+}
+
+// RecordSuggestionImpressions records that userID was shown items, leaving
+// out those already recorded within impressionWindow.
+func RecordSuggestionImpressions(db *gorm.DB, userID string, items []models.SuggestionFeedback, now time.Time) error {
+    if len(items) == 0 { return nil }
+    var recent []models.SuggestionFeedback
+    err := db.Select("source", "suggestion_id", "suggestion_key").
+        Where("user_id = ? AND event = 'impression' AND created_at > ?", userID, now.Add(-impressionWindow)).Find(&recent).Error
+    if err != nil { return err }
+    seen := map[string]bool{}
+    for _, r := range recent { seen[feedbackItemKey(r)] = true }
+    var rows []models.SuggestionFeedback
+    for _, it := range items {
+        if k := feedbackItemKey(it); !seen[k] {
+            seen[k] = true
+            it.UserID, it.Event, it.CreatedAt = userID, "impression", now
+            rows = append(rows, it)
+        }
+    }
+    if len(rows) == 0 { return nil }
+    return db.CreateInBatches(&rows, 100).Error
+}
+
+func feedbackItemKey(f models.SuggestionFeedback) string {
+    if f.SuggestionID != nil { return f.Source + ":" + strconv.FormatUint(uint64(*f.SuggestionID), 10) }
+    return f.Source + ":" + f.SuggestionKey
+}
+
+// HiddenSuggestions are the suggestions a user has dismissed or adopted and
+// not restored since, which are left out of their future results.
+type HiddenSuggestions struct {
+    Catalog map[uint]bool
+    AI      map[string]bool // by AISuggestionKey
+}
+
+// LoadHiddenSuggestions collects userID's hidden suggestions: those whose
+// latest dismiss, adopt or restore event is not a restore.
+func LoadHiddenSuggestions(db *gorm.DB, userID string) (HiddenSuggestions, error) {
+    h := HiddenSuggestions{Catalog: map[uint]bool{}, AI: map[string]bool{}}
+    var rows []models.SuggestionFeedback
+    err := db.Select("source", "suggestion_id", "suggestion_key", "event").
+        Where("user_id = ? AND event IN ('dismiss','adopt','restore')", userID).Order("created_at, id").Find(&rows).Error
+    if err != nil { return h, err }
+    for _, r := range rows {
+        hide := r.Event != "restore"
+        switch {
+        case r.Source == "catalog" && r.SuggestionID != nil:
+            if hide { h.Catalog[*r.SuggestionID] = true } else { delete(h.Catalog, *r.SuggestionID) }
+        case r.Source == "ai":
+            if hide { h.AI[r.SuggestionKey] = true } else { delete(h.AI, r.SuggestionKey) }
+        }
+    }
+    return h, nil
+}
+
+// VisibleSuggestions drops the catalog suggestions hidden for the user.
+func (h HiddenSuggestions) VisibleSuggestions(all []models.GoalSuggestion) []models.GoalSuggestion {
+    if len(h.Catalog) == 0 { return all }
+    out := make([]models.GoalSuggestion, 0, len(all))
+    for _, s := range all {
+        if !h.Catalog[s.ID] { out = append(out, s) }
+    }
+    return out
+}
+
+// SuggestionFeedbackState is where a user stands with one suggestion after
+// all their events: Rating is the latest thumbs ("up" or "down").
+type SuggestionFeedbackState struct {
+    Source        string    `json:"source"`
+    SuggestionID  *uint     `json:"suggestion_id,omitempty"`
+    SuggestionKey string    `json:"suggestion_key,omitempty"`
+    Title         string    `json:"title,omitempty"`
+    Saved         bool      `json:"saved"`
+    Dismissed     bool      `json:"dismissed"`
+    DismissReason string    `json:"dismiss_reason,omitempty"`
+    Adopted       bool      `json:"adopted"`
+    GoalID        *uint     `json:"goal_id,omitempty"`
+    Rating        string    `json:"rating,omitempty"`
+    UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UserSuggestionFeedback folds userID's events (impressions aside) into
+// one state per suggestion, most recently touched first.
+func UserSuggestionFeedback(db *gorm.DB, userID string) ([]SuggestionFeedbackState, error) {
+    var events []models.SuggestionFeedback
+    err := db.Where("user_id = ? AND event <> 'impression'", userID).Order("created_at, id").Find(&events).Error
+    if err != nil { return nil, err }
+    byKey := map[string]*SuggestionFeedbackState{}
+    var out []*SuggestionFeedbackState
+    for _, e := range events {
+        k := feedbackItemKey(e)
+        st := byKey[k]
+        if st == nil {
+            st = &SuggestionFeedbackState{Source: e.Source, SuggestionID: e.SuggestionID, SuggestionKey: e.SuggestionKey, Title: e.Title}
+            byKey[k] = st
+            out = append(out, st)
+        }
+        switch e.Event {
+        case "save":
+            st.Saved = true
+        case "dismiss":
+            st.Dismissed, st.DismissReason = true, e.Reason
+        case "adopt":
+            st.Adopted = true
+            if e.GoalID != nil { st.GoalID = e.GoalID }
+        case "restore":
+            st.Dismissed, st.DismissReason, st.Adopted = false, "", false
+        case "thumbs_up":
+            st.Rating = "up"
+        case "thumbs_down":
+            st.Rating = "down"
+        }
+        st.UpdatedAt = e.CreatedAt
+    }
+    sort.SliceStable(out, func(a, b int) bool { return out[a].UpdatedAt.After(out[b].UpdatedAt) })
+    states := make([]SuggestionFeedbackState, 0, len(out))
+    for _, st := range out { states = append(states, *st) }
+    return states, nil
+}
+
+// SuggestionFeedbackCounts are the number of distinct users behind each
+// event for one suggestion.
+type SuggestionFeedbackCounts struct {
+    Impressions int64 `json:"impressions"`
+    Dismissals  int64 `json:"dismissals"`
+    Saves       int64 `json:"saves"`
+    Adoptions   int64 `json:"adoptions"`
+    ThumbsUp    int64 `json:"thumbs_up"`
+    ThumbsDown  int64 `json:"thumbs_down"`
+}
+
+func (c *SuggestionFeedbackCounts) add(event string, n int64) {
+    switch event {
+    case "impression":
+        c.Impressions += n
+    case "dismiss":
+        c.Dismissals += n
+    case "save":
+        c.Saves += n
+    case "adopt":
+        c.Adoptions += n
+    case "thumbs_up":
+        c.ThumbsUp += n
+    case "thumbs_down":
+        c.ThumbsDown += n
+    }
+}
+
+func (c *SuggestionFeedbackCounts) merge(o SuggestionFeedbackCounts) {
+    c.Impressions += o.Impressions
+    c.Dismissals += o.Dismissals
+    c.Saves += o.Saves
+    c.Adoptions += o.Adoptions
+    c.ThumbsUp += o.ThumbsUp
+    c.ThumbsDown += o.ThumbsDown
+}
+
+// reach is how many users evidently saw the suggestion: feedback given
+// without a recorded impression (e.g. from the plain catalog list) counts.
+func (c SuggestionFeedbackCounts) reach() int64 {
+    return max(c.Impressions, c.Dismissals, c.Saves, c.Adoptions, c.ThumbsUp, c.ThumbsDown)
+}
+
+// FeedbackBoost is the ranking multiplier earned by c. Adoptions count
+// double; saves and thumbs up are positive, dismissals and thumbs down
+// negative.
+func (c SuggestionFeedbackCounts) FeedbackBoost() float64 {
+    pos := float64(2*c.Adoptions + c.Saves + c.ThumbsUp)
+    neg := float64(c.Dismissals + c.ThumbsDown)
+    if pos+neg == 0 { return 1 }
+    return 1 + feedbackWeight*(pos-neg)/(pos+neg+feedbackPrior)
+}
+
+type feedbackCountRow struct {
+    SuggestionID  *uint
+    SuggestionKey string
+    Event         string
+    Reason        string
+    Users         int64
+}
+
+// CatalogFeedbackCounts aggregates feedback on catalog suggestions.
+func CatalogFeedbackCounts(db *gorm.DB) (map[uint]SuggestionFeedbackCounts, error) {
+    var rows []feedbackCountRow
+    err := db.Model(&models.SuggestionFeedback{}).Select("suggestion_id, event, COUNT(DISTINCT user_id) AS users").
+        Where("source = 'catalog' AND suggestion_id IS NOT NULL").Group("suggestion_id, event").Scan(&rows).Error
+    if err != nil { return nil, err }
+    out := map[uint]SuggestionFeedbackCounts{}
+    for _, r := range rows {
+        c := out[*r.SuggestionID]
+        c.add(r.Event, r.Users)
+        out[*r.SuggestionID] = c
+    }
+    return out, nil
+}
+
+// SuggestionAdoption is one suggestion's line in the adoption report.
+// Rates are over the users who saw it.
+type SuggestionAdoption struct {
+    Source        string `json:"source"`
+    SuggestionID  *uint  `json:"suggestion_id,omitempty"`
+    SuggestionKey string `json:"suggestion_key,omitempty"`
+    Slug          string `json:"slug,omitempty"`
+    Title         string `json:"title"`
+    Status        string `json:"status,omitempty"` // catalog only; empty once deleted
+    SuggestionFeedbackCounts
+    DismissReasons map[string]int64 `json:"dismiss_reasons"`
+    AdoptionRate   float64          `json:"adoption_rate"`
+    DismissalRate  float64          `json:"dismissal_rate"`
+}
+
+// SuggestionAdoptionReport lists suggestions with feedback in [From, To),
+// worst adoption first. Totals sum each source's lines, so a user counts
+// once per suggestion.
+type SuggestionAdoptionReport struct {
+    From   time.Time                           `json:"from"`
+    To     time.Time                           `json:"to"`
+    Totals map[string]SuggestionFeedbackCounts `json:"totals"`
+    Items  []SuggestionAdoption                `json:"items"`
+}
+
+// BuildSuggestionAdoptionReport reports feedback in [from, to) for source
+// ("catalog", "ai" or "" for both), leaving out suggestions seen by fewer
+// than minReach users.
+func BuildSuggestionAdoptionReport(db *gorm.DB, source string, from, to time.Time, minReach int64) (SuggestionAdoptionReport, error) {
+    report := SuggestionAdoptionReport{From: from, To: to, Totals: map[string]SuggestionFeedbackCounts{}, Items: []SuggestionAdoption{}}
+    scope := func(q *gorm.DB) *gorm.DB {
+        q = q.Model(&models.SuggestionFeedback{}).Where("created_at >= ? AND created_at < ?", from, to)
+        if source != "" { q = q.Where("source = ?", source) }
+        return q
+    }
+    type row struct {
+        Source string
+        feedbackCountRow
+        Title string
+    }
+    var counts, reasons []row
+    err := db.Scopes(scope).Select("source, suggestion_id, suggestion_key, event, COUNT(DISTINCT user_id) AS users, MAX(title) AS title").
+        Group("source, suggestion_id, suggestion_key, event").Scan(&counts).Error
+    if err == nil {
+        err = db.Scopes(scope).Select("source, suggestion_id, suggestion_key, reason, COUNT(DISTINCT user_id) AS users").
+            Where("event = 'dismiss'").Group("source, suggestion_id, suggestion_key, reason").Scan(&reasons).Error
+    }
+    if err != nil { return report, err }
+
+    items := map[string]*SuggestionAdoption{}
+    var order []string
+    var catalogIDs []uint
+    item := func(r row) *SuggestionAdoption {
+        k := feedbackItemKey(models.SuggestionFeedback{Source: r.Source, SuggestionID: r.SuggestionID, SuggestionKey: r.SuggestionKey})
+        if items[k] == nil {
+            items[k] = &SuggestionAdoption{Source: r.Source, SuggestionID: r.SuggestionID, SuggestionKey: r.SuggestionKey, DismissReasons: map[string]int64{}}
+            order = append(order, k)
+            if r.SuggestionID != nil { catalogIDs = append(catalogIDs, *r.SuggestionID) }
+        }
+        return items[k]
+    }
+    for _, r := range counts {
+        it := item(r)
+        it.add(r.Event, r.Users)
+        if r.Title != "" { it.Title = r.Title }
+    }
+    for _, r := range reasons { item(r).DismissReasons[r.Reason] += r.Users }
+
+    if len(catalogIDs) > 0 {
+        var suggestions []models.GoalSuggestion
+        if err := db.Where("id IN ?", catalogIDs).Find(&suggestions).Error; err != nil { return report, err }
+        for _, s := range suggestions {
+            if it := items[feedbackItemKey(CatalogImpression(s.ID))]; it != nil {
+                it.Slug, it.Title, it.Status = s.Slug, s.Title, s.Status
+            }
+        }
+    }
+
+    for _, k := range order {
+        it := items[k]
+        reach := it.reach()
+        if reach == 0 || reach < minReach { continue }
+        it.AdoptionRate = roundScore(float64(it.Adoptions) / float64(reach))
+        it.DismissalRate = roundScore(float64(it.Dismissals) / float64(reach))
+        t := report.Totals[it.Source]
+        t.merge(it.SuggestionFeedbackCounts)
+        report.Totals[it.Source] = t
+        report.Items = append(report.Items, *it)
+    }
+    sort.SliceStable(report.Items, func(a, b int) bool {
+        x, y := report.Items[a], report.Items[b]
+        if x.AdoptionRate != y.AdoptionRate { return x.AdoptionRate < y.AdoptionRate }
+        if x.DismissalRate != y.DismissalRate { return x.DismissalRate > y.DismissalRate }
+        return x.reach() > y.reach()
+    })
+    return report, nil
+}
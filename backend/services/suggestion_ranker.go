@@ -0,0 +1,332 @@
+package services
+
+import (
+    "encoding/json"
+    "math"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+    "unicode"
+
+    "goaltracker/models"
+
+    "gorm.io/gorm"
+)
+
+// BM25 parameters of the goal suggestion index.
+const (
+    bm25K1 = 1.2
+    bm25B  = 0.75
+)
+
+// suggestionIndexTTL bounds how stale the index may be after catalog changes
+// made on another instance; changes on this one rebuild it immediately.
+const suggestionIndexTTL = 5 * time.Minute
+
+// suggestionFields are the indexed text of a goal suggestion and their
+// boosts (BM25F): a term in the title counts three times one in the
+// description.
+var suggestionFields = []struct {
+    name  string
+    boost float64
+    text  func(s *models.GoalSuggestion) string
+}{
+    {"title", 3, func(s *models.GoalSuggestion) string { return s.Title }},
+    {"description", 1, func(s *models.GoalSuggestion) string { return s.Description }},
+    {"category", 0.5, func(s *models.GoalSuggestion) string { return s.Category }},
+    {"responsibility", 1.5, func(s *models.GoalSuggestion) string { return s.Responsibility.Title }},
+    {"responsibility_description", 0.5, func(s *models.GoalSuggestion) string { return s.Responsibility.Description }},
+    {"job_role", 1, func(s *models.GoalSuggestion) string { return s.Responsibility.JobRole.Title }},
+}
+
+// signalBoosts weight profile signals by kind. Frameworks and platforms are
+// what the IT profile says the user works with, so they count most.
+var signalBoosts = map[string]float64{
+    "framework": 1.5,
+    "platform":  1.5,
+    "subdomain": 1.2,
+    "skill":     1,
+    "role":      0.8,
+}
+
+// priorityBoosts scale a suggestion's score by its catalog priority.
+var priorityBoosts = map[string]float64{"high": 1.2, "medium": 1, "low": 0.8}
+
+// SuggestionIndex is an in-memory BM25F index of goal suggestions, with
+// their responsibility and job role text.
+type SuggestionIndex struct {
+    docs   []indexedSuggestion
+    df     map[string]int // term -> number of suggestions containing it
+    avgLen float64
+}
+
+type indexedSuggestion struct {
+    item     models.GoalSuggestion
+    tf       map[string]map[string]int // term -> field -> count
+    length   float64                   // boosted term count
+    feedback float64                   // SuggestionFeedbackCounts.FeedbackBoost
+}
+
+// NewSuggestionIndex indexes suggestions, which should have Responsibility
+// and Responsibility.JobRole loaded, with the aggregate user feedback on
+// them (may be nil).
+func NewSuggestionIndex(suggestions []models.GoalSuggestion, feedback map[uint]SuggestionFeedbackCounts) *SuggestionIndex {
+    ix := &SuggestionIndex{df: map[string]int{}}
+    total := 0.0
+    for _, s := range suggestions {
+        doc := indexedSuggestion{item: s, tf: map[string]map[string]int{}, feedback: feedback[s.ID].FeedbackBoost()}
+        for _, f := range suggestionFields {
+            for _, t := range searchTerms(f.text(&s)) {
+                if doc.tf[t] == nil { doc.tf[t] = map[string]int{} }
+                doc.tf[t][f.name]++
+                doc.length += f.boost
+            }
+        }
+        for t := range doc.tf { ix.df[t]++ }
+        total += doc.length
+        ix.docs = append(ix.docs, doc)
+    }
+    if len(ix.docs) > 0 { ix.avgLen = total / float64(len(ix.docs)) }
+    return ix
+}
+
+// Len is the number of indexed suggestions.
+func (ix *SuggestionIndex) Len() int { return len(ix.docs) }
+
+var suggestionIndex = struct {
+    sync.Mutex
+    loaded time.Time
+    index  *SuggestionIndex
+}{}
+
+// CurrentSuggestionIndex returns the index of published goal suggestions,
+// building it on first use, after InvalidateSuggestionIndex and once
+// suggestionIndexTTL has passed. New feedback is picked up on the next
+// rebuild rather than invalidating the index.
+func CurrentSuggestionIndex(db *gorm.DB, now time.Time) (*SuggestionIndex, error) {
+    suggestionIndex.Lock()
+    defer suggestionIndex.Unlock()
+    if suggestionIndex.index != nil && now.Sub(suggestionIndex.loaded) < suggestionIndexTTL { return suggestionIndex.index, nil }
+    var suggestions []models.GoalSuggestion
+    err := db.Scopes(Published("goal_suggestions")).Preload("Responsibility").Preload("Responsibility.JobRole").
+        Order("id").Find(&suggestions).Error
+    if err != nil { return nil, err }
+    feedback, err := CatalogFeedbackCounts(db)
+    if err != nil { return nil, err }
+    suggestionIndex.index, suggestionIndex.loaded = NewSuggestionIndex(suggestions, feedback), now
+    return suggestionIndex.index, nil
+}
+
+// InvalidateSuggestionIndex makes the next CurrentSuggestionIndex call
+// rebuild. The catalog services call it after every committed change.
+func InvalidateSuggestionIndex() {
+    suggestionIndex.Lock()
+    suggestionIndex.index = nil
+    suggestionIndex.Unlock()
+}
+
+// ProfileSignal is one thing about the user the ranker searches for: an IT
+// profile framework, platform or subdomain, a skill record, or the current
+// or target role.
+type ProfileSignal struct {
+    Kind   string
+    Value  string   // as the user wrote it, e.g. "k8s"
+    Terms  []string // the value's terms plus those of the catalog skill it resolves to
+    Weight float64
+}
+
+// ProfileSignals builds the ranking query for a user. Signals that resolve
+// to a catalog skill are weighted by the user's level in it, the way goal
+// suggestions always favoured growth: gaps and partial skills count most,
+// mastered ones least. Skill records already covered by a framework or
+// platform are not counted twice.
+func ProfileSignals(p models.UserProfile, userSkills []models.UserSkill, cat *SkillCatalog) []ProfileSignal {
+    levels := map[uint]int{}
+    for _, us := range userSkills { levels[us.SkillID] = us.Proficiency }
+    covered := map[uint]bool{}
+    var out []ProfileSignal
+    add := func(kind, value string, level int) {
+        if value = strings.TrimSpace(value); value == "" { return }
+        terms := searchTerms(value)
+        if s, ok := cat.Resolve(value); ok {
+            if covered[s.ID] && kind == "skill" { return }
+            covered[s.ID] = true
+            terms = append(terms, skillSearchTerms(s)...)
+            if l, held := levels[s.ID]; held { level = l }
+        }
+        if len(terms) == 0 { return }
+        weight := signalBoosts[kind]
+        if level >= 0 { weight *= proficiencyWeight(level) }
+        out = append(out, ProfileSignal{Kind: kind, Value: value, Terms: uniqueStrings(terms), Weight: weight})
+    }
+
+    it := DecodeITProfile(p.ITProfile)
+    for _, f := range it.Frameworks { add("framework", f.Name, ProficiencyFromLabel(f.Level, 1)) }
+    for _, pl := range it.Platforms { add("platform", pl.Name, ProficiencyFromLabel(pl.Depth, 2)) }
+    for _, sd := range it.Subdomains { add("subdomain", sd, -1) }
+    for _, us := range userSkills { add("skill", us.Skill.Name, us.Proficiency) }
+    current := p.CurrentRole
+    if current == "" { current = it.Role.Current }
+    add("role", current, -1)
+    if !strings.EqualFold(strings.TrimSpace(it.Role.Target), strings.TrimSpace(current)) { add("role", it.Role.Target, -1) }
+    return out
+}
+
+// proficiencyWeight scales a skill signal by the user's level (0-4).
+func proficiencyWeight(level int) float64 {
+    switch {
+    case level <= 0:
+        return 1.5 // declared gap
+    case level <= 2:
+        return 1.2
+    case level == 3:
+        return 0.6
+    }
+    return 0.3
+}
+
+func skillSearchTerms(s models.Skill) []string {
+    terms := append(searchTerms(s.Name), searchTerms(strings.ReplaceAll(s.Slug, "-", " "))...)
+    var aliases []string
+    _ = json.Unmarshal([]byte(s.Aliases), &aliases)
+    for _, a := range aliases { terms = append(terms, searchTerms(a)...) }
+    return terms
+}
+
+// SuggestionMatch is one profile signal's share of a result's score.
+type SuggestionMatch struct {
+    Signal string   `json:"signal"` // framework, platform, subdomain, skill, role or priority
+    Value  string   `json:"value"`
+    Terms  []string `json:"terms,omitempty"`  // matched search terms (stemmed)
+    Fields []string `json:"fields,omitempty"` // suggestion fields they matched in
+    Score  float64  `json:"score"`
+}
+
+// RankedSuggestion is a goal suggestion with its score and the signals
+// that earned it, highest first. Signal scores are before Boosts, the
+// multipliers for priority and user feedback that were applied to the total.
+type RankedSuggestion struct {
+    models.GoalSuggestion
+    Score       float64            `json:"score"`
+    Explanation []SuggestionMatch  `json:"explanation"`
+    Boosts      map[string]float64 `json:"boosts,omitempty"`
+}
+
+// Rank scores every suggestion not in hidden against signals and returns
+// the best limit. A signal scores the BM25F sum over its terms, times its
+// weight; the total is scaled by the suggestion's priority and feedback
+// boosts. When fewer than limit suggestions match, high-priority ones fill
+// the list with a score of zero, best received first.
+func (ix *SuggestionIndex) Rank(signals []ProfileSignal, limit int, hidden map[uint]bool) []RankedSuggestion {
+    var ranked, fallback []RankedSuggestion
+    for i := range ix.docs {
+        doc := &ix.docs[i]
+        if hidden[doc.item.ID] { continue }
+        r := RankedSuggestion{GoalSuggestion: doc.item, Explanation: []SuggestionMatch{}, Boosts: map[string]float64{}}
+        if doc.feedback != 1 { r.Boosts["feedback"] = roundScore(doc.feedback) }
+        for _, sig := range signals {
+            m := SuggestionMatch{Signal: sig.Kind, Value: sig.Value}
+            fields := map[string]bool{}
+            for _, t := range sig.Terms {
+                s := ix.termScore(doc, t)
+                if s == 0 { continue }
+                m.Score += s
+                m.Terms = append(m.Terms, t)
+                for f := range doc.tf[t] { fields[f] = true }
+            }
+            if m.Score == 0 { continue }
+            m.Score *= sig.Weight
+            for _, f := range suggestionFields {
+                if fields[f.name] { m.Fields = append(m.Fields, f.name) }
+            }
+            r.Score += m.Score
+            r.Explanation = append(r.Explanation, m)
+        }
+        priority := strings.ToLower(doc.item.Priority)
+        if r.Score == 0 {
+            if priority == "high" {
+                r.Explanation = append(r.Explanation, SuggestionMatch{Signal: "priority", Value: priority})
+                fallback = append(fallback, r)
+            }
+            continue
+        }
+        if boost, ok := priorityBoosts[priority]; ok && boost != 1 { r.Boosts["priority"] = boost }
+        for _, boost := range r.Boosts { r.Score *= boost }
+        sort.SliceStable(r.Explanation, func(a, b int) bool { return r.Explanation[a].Score > r.Explanation[b].Score })
+        for j := range r.Explanation { r.Explanation[j].Score = roundScore(r.Explanation[j].Score) }
+        r.Score = roundScore(r.Score)
+        ranked = append(ranked, r)
+    }
+    sort.SliceStable(ranked, func(a, b int) bool { return ranked[a].Score > ranked[b].Score })
+    sort.SliceStable(fallback, func(a, b int) bool { return fallback[a].feedbackBoost() > fallback[b].feedbackBoost() })
+    ranked = append(ranked, fallback...)
+    if len(ranked) > limit { ranked = ranked[:limit] }
+    return ranked
+}
+
+func (r RankedSuggestion) feedbackBoost() float64 {
+    if b, ok := r.Boosts["feedback"]; ok { return b }
+    return 1
+}
+
+// termScore is BM25F for one term: boosted field counts combined before
+// saturation, normalized by the suggestion's boosted length.
+func (ix *SuggestionIndex) termScore(doc *indexedSuggestion, term string) float64 {
+    fields := doc.tf[term]
+    if len(fields) == 0 { return 0 }
+    tf := 0.0
+    for _, f := range suggestionFields { tf += f.boost * float64(fields[f.name]) }
+    n, df := float64(len(ix.docs)), float64(ix.df[term])
+    idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+    norm := 1 - bm25B + bm25B*doc.length/ix.avgLen
+    return idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+}
+
+func roundScore(f float64) float64 { return math.Round(f*1000) / 1000 }
+
+// searchStopwords are left out of the index and queries.
+var searchStopwords = map[string]bool{
+    "a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+    "for": true, "from": true, "how": true, "in": true, "into": true, "is": true, "it": true, "of": true,
+    "on": true, "or": true, "the": true, "to": true, "with": true, "your": true, "you": true,
+}
+
+// searchTerms splits text into lower-case, lightly stemmed terms. "+" and
+// "#" stay part of words so "c++" and "c#" survive.
+func searchTerms(text string) []string {
+    words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#'
+    })
+    out := make([]string, 0, len(words))
+    for _, w := range words {
+        if searchStopwords[w] { continue }
+        out = append(out, stemTerm(w))
+    }
+    return out
+}
+
+// stemTerm strips common English suffixes so "pipelines", "testing" and
+// "tested" meet "pipeline" and "test". Short words are left alone.
+func stemTerm(w string) string {
+    switch {
+    case len(w) > 5 && strings.HasSuffix(w, "ing"):
+        return w[:len(w)-3]
+    case len(w) > 4 && strings.HasSuffix(w, "ies"):
+        return w[:len(w)-3] + "y"
+    case len(w) > 4 && strings.HasSuffix(w, "ed"):
+        return w[:len(w)-2]
+    case len(w) > 3 && strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && !strings.HasSuffix(w, "us") && !strings.HasSuffix(w, "is"):
+        return w[:len(w)-1]
+    }
+    return w
+}
+
+func uniqueStrings(list []string) []string {
+    seen := map[string]bool{}
+    out := list[:0]
+    for _, s := range list {
+        if !seen[s] { seen[s] = true; out = append(out, s) }
+    }
+    return out
+}
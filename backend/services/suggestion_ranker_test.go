@@ -0,0 +1,152 @@
+package services
+
+import (
+    "math"
+    "reflect"
+    "testing"
+
+    "goaltracker/models"
+)
+
+func rankerSuggestion(id uint, priority, title, description string) models.GoalSuggestion {
+    return models.GoalSuggestion{ID: id, Title: title, Description: description, Category: "skill", Priority: priority}
+}
+
+func rankerSignal(kind, value string, weight float64) ProfileSignal {
+    return ProfileSignal{Kind: kind, Value: value, Terms: searchTerms(value), Weight: weight}
+}
+
+func TestSuggestionIndexRank(t *testing.T) {
+    corpus := []models.GoalSuggestion{
+        rankerSuggestion(1, "medium", "Automate Kubernetes upgrades", "Roll cluster upgrades without downtime"),
+        rankerSuggestion(2, "medium", "Harden cluster access", "Review RBAC across Kubernetes namespaces"),
+        rankerSuggestion(3, "high", "Adopt Terraform modules", "Share infrastructure code between teams"),
+        rankerSuggestion(4, "low", "Document runbooks", "Write runbooks for on-call"),
+        rankerSuggestion(5, "high", "Set up on-call rotation", "Share pager load"),
+    }
+    tests := []struct {
+        name     string
+        docs     []models.GoalSuggestion
+        feedback map[uint]SuggestionFeedbackCounts
+        signals  []ProfileSignal
+        limit    int
+        hidden   map[uint]bool
+        want     []uint
+    }{
+        {
+            name:    "title matches outrank description matches, high priority fills the rest",
+            docs:    corpus,
+            signals: []ProfileSignal{rankerSignal("platform", "Kubernetes", 1)},
+            limit:   10,
+            want:    []uint{1, 2, 3, 5},
+        },
+        {
+            name:    "terms are stemmed",
+            docs:    corpus,
+            signals: []ProfileSignal{rankerSignal("skill", "runbook", 1)},
+            limit:   1,
+            want:    []uint{4},
+        },
+        {
+            name:    "signal weight decides between matches",
+            docs:    corpus,
+            signals: []ProfileSignal{rankerSignal("platform", "Kubernetes", 0.1), rankerSignal("framework", "Terraform", 2)},
+            limit:   2,
+            want:    []uint{3, 1},
+        },
+        {
+            name: "priority boosts equal text",
+            docs: []models.GoalSuggestion{
+                rankerSuggestion(1, "low", "Learn Go", ""),
+                rankerSuggestion(2, "medium", "Learn Go", ""),
+                rankerSuggestion(3, "high", "Learn Go", ""),
+            },
+            signals: []ProfileSignal{rankerSignal("skill", "Go", 1)},
+            limit:   3,
+            want:    []uint{3, 2, 1},
+        },
+        {
+            name: "feedback boosts equal text",
+            docs: []models.GoalSuggestion{
+                rankerSuggestion(1, "medium", "Learn Go", ""),
+                rankerSuggestion(2, "medium", "Learn Go", ""),
+                rankerSuggestion(3, "medium", "Learn Go", ""),
+            },
+            feedback: map[uint]SuggestionFeedbackCounts{1: {Dismissals: 5}, 3: {Adoptions: 5}},
+            signals:  []ProfileSignal{rankerSignal("skill", "Go", 1)},
+            limit:    3,
+            want:     []uint{3, 2, 1},
+        },
+        {
+            name:    "hidden suggestions are left out",
+            docs:    corpus,
+            signals: []ProfileSignal{rankerSignal("platform", "Kubernetes", 1)},
+            limit:   2,
+            hidden:  map[uint]bool{1: true},
+            want:    []uint{2, 3},
+        },
+        {
+            name:     "without matches, high priority best received first",
+            docs:     corpus,
+            feedback: map[uint]SuggestionFeedbackCounts{5: {ThumbsUp: 3}},
+            limit:    10,
+            want:     []uint{5, 3},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := NewSuggestionIndex(tt.docs, tt.feedback).Rank(tt.signals, tt.limit, tt.hidden)
+            var ids []uint
+            for _, r := range got { ids = append(ids, r.ID) }
+            if !reflect.DeepEqual(ids, tt.want) { t.Fatalf("got %v, want %v", ids, tt.want) }
+            for i := 1; i < len(got); i++ {
+                if got[i].Score > got[i-1].Score { t.Errorf("scores not descending: %v after %v", got[i].Score, got[i-1].Score) }
+            }
+        })
+    }
+}
+
+func TestSuggestionIndexRankExplains(t *testing.T) {
+    ix := NewSuggestionIndex([]models.GoalSuggestion{
+        rankerSuggestion(1, "high", "Automate Kubernetes upgrades", "Kubernetes clusters"),
+        rankerSuggestion(2, "medium", "Write docs", ""),
+    }, map[uint]SuggestionFeedbackCounts{1: {Adoptions: 100}})
+    got := ix.Rank([]ProfileSignal{rankerSignal("platform", "Kubernetes", 1.5)}, 1, nil)
+    if len(got) != 1 { t.Fatalf("got %d results", len(got)) }
+    r := got[0]
+    if len(r.Explanation) != 1 { t.Fatalf("explanation = %+v", r.Explanation) }
+    m := r.Explanation[0]
+    if m.Signal != "platform" || !reflect.DeepEqual(m.Terms, searchTerms("Kubernetes")) || !reflect.DeepEqual(m.Fields, []string{"title", "description"}) {
+        t.Errorf("match = %+v", m)
+    }
+    if r.Boosts["priority"] != priorityBoosts["high"] { t.Errorf("priority boost = %v", r.Boosts["priority"]) }
+    if fb := r.Boosts["feedback"]; fb <= 1 || fb >= 1+feedbackWeight { t.Errorf("feedback boost %v outside (1, %v)", fb, 1+feedbackWeight) }
+    if want := roundScore(m.Score * r.Boosts["priority"] * r.Boosts["feedback"]); math.Abs(r.Score-want) > 0.002 {
+        t.Errorf("score = %v, want signal score times boosts %v", r.Score, want)
+    }
+}
+
+func TestFeedbackBoost(t *testing.T) {
+    tests := []struct {
+        name   string
+        counts SuggestionFeedbackCounts
+        want   float64
+    }{
+        {"no feedback", SuggestionFeedbackCounts{}, 1},
+        {"impressions alone", SuggestionFeedbackCounts{Impressions: 1000}, 1},
+        {"balanced", SuggestionFeedbackCounts{Saves: 3, Dismissals: 3}, 1},
+        {"one thumbs up", SuggestionFeedbackCounts{ThumbsUp: 1}, 1 + feedbackWeight*1/11},
+        {"adoptions count double", SuggestionFeedbackCounts{Adoptions: 1, ThumbsDown: 2}, 1},
+        {"one dismissal", SuggestionFeedbackCounts{Dismissals: 1}, 1 - feedbackWeight*1/11},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := tt.counts.FeedbackBoost(); math.Abs(got-tt.want) > 1e-9 { t.Errorf("got %v, want %v", got, tt.want) }
+        })
+    }
+
+    // However lopsided, the boost stays strictly within 1±feedbackWeight
+    for _, c := range []SuggestionFeedbackCounts{{Adoptions: 1e9}, {Dismissals: 1e9, ThumbsDown: 1e9}} {
+        if b := c.FeedbackBoost(); b <= 1-feedbackWeight || b >= 1+feedbackWeight { t.Errorf("%+v: boost %v out of bounds", c, b) }
+    }
+}
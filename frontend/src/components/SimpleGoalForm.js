@@ -137,6 +137,10 @@ const SimpleGoalForm = ({ goal, onSubmit, onClose, isOpen }) => {
         }
       }
 
+      // Record which responsibility a new goal serves so reports can group by it
+      if (!goal && selectedResponsibilityId) {
+        metadata = { ...(metadata || {}), responsibility_id: parseInt(selectedResponsibilityId) };
+      }
       const finalData = metadata ? { ...submitData, metadata } : submitData;
       await onSubmit(finalData);
       onClose();
@@ -2,6 +2,7 @@ import React, { useEffect, useMemo, useRef, useState } from 'react';
 import { useGoals } from '../hooks/useGoals';
 import ProgressModal from '../components/ProgressModal';
 import PageTitle from '../components/PageTitle';
+import { userProfileApi } from '../services/api';
 
 const dayMs = 24 * 60 * 60 * 1000;
 
@@ -34,6 +35,14 @@ export default function Timeline() {
   const headerTicksRef = useRef(null);
   const [scrollProgress, setScrollProgress] = useState(0);
   const snapTimerRef = useRef(null);
+  const [growth, setGrowth] = useState([]);
+
+  useEffect(() => {
+    // Profile changes (role, level, skills...) shown as a career growth row
+    userProfileApi.getTimeline()
+      .then((res) => setGrowth((res.data?.data || []).filter((e) => e.type === 'profile_change')))
+      .catch(() => setGrowth([]));
+  }, []);
 
   const { startDate, endDate } = useMemo(() => {
     const today = startOfDay(new Date());
@@ -155,6 +164,11 @@ export default function Timeline() {
                       </div>
                     ))
                   )}
+                  {growth.length > 0 && (
+                    <div className="h-14 border-b border-gray-50 dark:border-zinc-800 flex items-center px-4 text-sm font-medium text-gray-800 dark:text-zinc-100">
+                      Career growth
+                    </div>
+                  )}
                 </div>
               </div>
 
@@ -206,6 +220,22 @@ export default function Timeline() {
                       </div>
                     );
                   })}
+                  {growth.length > 0 && (
+                    <div className="h-14 border-b border-gray-50/60 dark:border-zinc-800/60">
+                      {growth.map((e) => {
+                        const offset = Math.max(0, diffInDays(startDate, new Date(e.at))) * pxPerDay;
+                        const fields = Array.from(new Set((e.changes || []).map((c) => c.field.split('.').slice(0, 2).join('.'))));
+                        return (
+                          <span
+                            key={e.snapshot_id}
+                            className="absolute mt-5 h-3 w-3 rounded-full bg-accent-500 ring-2 ring-white dark:ring-zinc-900"
+                            style={{ left: offset }}
+                            title={`${new Date(e.at).toLocaleDateString()}: ${fields.join(', ')}`}
+                          />
+                        );
+                      })}
+                    </div>
+                  )}
                 </div>
               </div>
             </div>
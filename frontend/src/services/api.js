@@ -31,6 +31,16 @@ const api = axios.create({
   },
 });
 
+// ETags from responses, keyed by resource path, sent back as If-Match on writes
+const etags = new Map();
+
+const etagKey = (config, data) => {
+  const url = (config?.url || '').split('?')[0];
+  // /profiles/me is written back through /profiles/:id
+  if (url === '/profiles/me' && data?.data?.id) return `/profiles/${data.data.id}`;
+  return url;
+};
+
 // CSRF token management
 let csrfToken = null;
 
@@ -70,6 +80,10 @@ api.interceptors.request.use(async (config) => {
         if (csrf) {
           config.headers['X-CSRF-Token'] = csrf;
         }
+        const etag = etags.get((config.url || '').split('?')[0]);
+        if (etag && ['put', 'patch', 'delete'].includes(config.method.toLowerCase()) && !config.headers['If-Match']) {
+          config.headers['If-Match'] = etag;
+        }
       }
     }
   } catch (e) {
@@ -83,6 +97,11 @@ api.interceptors.request.use(async (config) => {
 api.interceptors.response.use(
   (response) => {
     try { console.debug('[api] response', response.status, response.config.method?.toUpperCase(), response.config.baseURL + (response.config.url || '')); } catch (_) {}
+    try {
+      const etag = response.headers?.etag;
+      if (etag) etags.set(etagKey(response.config, response.data), etag);
+      if (response.config.method?.toLowerCase() === 'delete') etags.delete(etagKey(response.config));
+    } catch (_) {}
     return response;
   },
   async (error) => {
@@ -90,8 +109,13 @@ api.interceptors.response.use(
       const { response, config } = error || {};
       console.error('[api] error', response?.status, config?.method?.toUpperCase(), (config?.baseURL || '') + (config?.url || ''), response?.data || error?.message);
       
+      // Version conflict: remember the current ETag so the caller can merge and retry
+      if (response?.status === 412 && response?.headers?.etag) {
+        etags.set(etagKey(config, response.data), response.headers.etag);
+      }
+
       // Handle CSRF token expiration
-      if (response?.status === 403 && response?.data?.error?.includes('CSRF')) {
+      if (response?.status === 403 && response?.data?.code?.startsWith('csrf_')) {
         // Clear cached CSRF token and retry once
         csrfToken = null;
         if (!config._retried) {
@@ -108,7 +132,52 @@ api.interceptors.response.use(
 export const jobRoleApi = {
   getAll: () => api.get('/job-roles'),
   getById: (id) => api.get(`/job-roles/${id}`),
-  create: (data) => api.post('/job-roles', data),
+  getRequirements: (id) => api.get(`/job-roles/${id}/requirements`),
+  // Admin only; replaces the set. data: { skills: [{ skill, level, importance }], certifications: [{ certification, importance }] }
+  setRequirements: (id, data) => api.put(`/admin/job-roles/${id}/requirements`, data),
+};
+
+export const careerLaddersApi = {
+  getAll: () => api.get('/career-ladders'),
+  // ref: track slug or id
+  get: (ref) => api.get(`/career-ladders/${ref}`),
+  export: (ref, format = 'yaml') => api.get(`/career-ladders/${ref}/export`, { params: { format }, responseType: 'text' }),
+  // Admin only. file: YAML or JSON ladder text
+  import: (file, format = 'yaml') =>
+    api.post('/admin/career-ladders/import', file, {
+      headers: { 'Content-Type': format === 'json' ? 'application/json' : 'application/yaml' },
+    }),
+  // params: track, level; defaults from the profile's role and experience level
+  getReadiness: (params = {}) => api.get('/profiles/me/readiness', { params }),
+};
+
+// Admin only. type: job-roles, responsibilities, goal-suggestions or
+// progress-suggestions. New items start as drafts; only published items
+// appear on the public catalog routes.
+export const catalogAdminApi = {
+  list: (type, params = {}) => api.get(`/admin/catalog/${type}`, { params }),
+  get: (type, id) => api.get(`/admin/catalog/${type}/${id}`),
+  create: (type, data) => api.post(`/admin/catalog/${type}`, data),
+  update: (type, id, data) => api.put(`/admin/catalog/${type}/${id}`, data),
+  remove: (type, id) => api.delete(`/admin/catalog/${type}/${id}`),
+  publish: (type, id) => api.post(`/admin/catalog/${type}/${id}/publish`),
+  unpublish: (type, id) => api.post(`/admin/catalog/${type}/${id}/unpublish`),
+  // params: entity_type, entity_id, actor_id
+  getAudit: (params = {}) => api.get('/admin/catalog/audit', { params }),
+  // { data: { de: { title, ... }, 'pt-BR': {...} }, fields: [translatable fields] }
+  getTranslations: (type, id) => api.get(`/admin/catalog/${type}/${id}/translations`),
+  // Replaces one locale's translations; fields: { title: '...' }. Missing fields fall back to English.
+  setTranslations: (type, id, locale, fields) => api.put(`/admin/catalog/${type}/${id}/translations/${locale}`, fields),
+  removeTranslations: (type, id, locale) => api.delete(`/admin/catalog/${type}/${id}/translations/${locale}`),
+  // body: a fixture pack as YAML text or a JSON object; reports created/updated rows
+  applyFixture: (pack, { dryRun = false } = {}) =>
+    api.post('/admin/catalog/fixtures', pack, {
+      params: dryRun ? { dry_run: true } : {},
+      headers: typeof pack === 'string' ? { 'Content-Type': 'application/yaml' } : {},
+    }),
+  // Per-suggestion impressions, dismissals (with reasons), saves, adoptions and ratings,
+  // lowest adoption rate first. params: source (catalog|ai), from, to, min_reach
+  getSuggestionAdoption: (params = {}) => api.get('/admin/suggestions/adoption', { params }),
 };
 
 export const responsibilityApi = {
@@ -135,6 +204,20 @@ export const goalApi = {
       try { window.dispatchEvent(new Event('goals:changed')); } catch (_) {}
       return response;
     }),
+  patch: (id, patch, { jsonPatch = false } = {}) =>
+    api.patch(`/goals/${id}`, patch, {
+      headers: { 'Content-Type': jsonPatch ? 'application/json-patch+json' : 'application/merge-patch+json' },
+    }).then((response) => {
+      try { window.dispatchEvent(new Event('goals:changed')); } catch (_) {}
+      return response;
+    }),
+  getRevisions: (id) => api.get(`/goals/${id}/revisions`),
+  diffRevisions: (id, from, to) => api.get(`/goals/${id}/revisions/diff`, { params: { from, to } }),
+  restoreRevision: (id, rev) =>
+    api.post(`/goals/${id}/revisions/${rev}/restore`).then((response) => {
+      try { window.dispatchEvent(new Event('goals:changed')); } catch (_) {}
+      return response;
+    }),
 };
 
 export const progressApi = {
@@ -149,6 +232,20 @@ export const goalSuggestionApi = {
   getByResponsibility: (responsibilityId) => api.get(`/goal-suggestions/for-responsibility/${responsibilityId}`),
 };
 
+// Profile-ranked catalog suggestions and feedback on them and on AI suggestions.
+// Dismissed and adopted suggestions are left out of later results.
+export const suggestionsApi = {
+  // params: limit (default 12, at most 50); results carry score, explanation and boosts
+  getForProfile: (params = {}) => api.get('/suggestions/for-profile', { params }),
+  // Current state per suggestion: saved, dismissed, adopted, rating
+  getFeedback: () => api.get('/suggestions/feedback'),
+  // Catalog: { source: 'catalog', suggestion_id, event }. AI: { source: 'ai', title, suggestion_key, event }.
+  // event: impression|dismiss|save|adopt|thumbs_up|thumbs_down|restore; dismiss needs reason
+  // (not_relevant|already_done|too_easy|too_hard|not_now|other); adopt may carry goal_id;
+  // restore brings back a dismissed or adopted suggestion.
+  sendFeedback: (feedback) => api.post('/suggestions/feedback', feedback),
+};
+
 export const progressSuggestionApi = {
   getByGoal: (goalId) => api.get(`/progress-suggestions/for-goal/${goalId}`),
 };
@@ -158,6 +255,27 @@ export const userProfileApi = {
   create: (data) => api.post('/profiles', data),
   getById: (id) => api.get(`/profiles/${id}`),
   update: (id, data) => api.put(`/profiles/${id}`, data),
+  patch: (id, patch, { jsonPatch = false } = {}) =>
+    api.patch(`/profiles/${id}`, patch, {
+      headers: { 'Content-Type': jsonPatch ? 'application/json-patch+json' : 'application/merge-patch+json' },
+    }),
+  // Proposes changes from a résumé; apply them with update(id, proposal.update) and If-Match.
+  importResume: (resume, { engine = 'rules' } = {}) =>
+    api.post('/profiles/me/import', resume, {
+      params: { engine },
+      headers: { 'Content-Type': typeof resume === 'string' ? 'text/markdown' : 'application/json' },
+    }),
+  // params: format (json_resume|markdown|preview), from, to, goal_ids, progress_ids, outcomes, evidence
+  exportCV: (params = {}) =>
+    api.get('/profiles/me/cv', { params, responseType: params.format === 'markdown' ? 'text' : 'json' }),
+  getSnapshots: () => api.get('/profiles/me/snapshots'),
+  getSnapshot: (snapshotId) => api.get(`/profiles/me/snapshots/${snapshotId}`),
+  // Profile changes and goal completions, newest first. params: from, to
+  getTimeline: (params = {}) => api.get('/profiles/me/timeline', { params }),
+  // from/to: snapshot id or date; omit to for the current profile
+  diff: (from, to) => api.get('/profiles/me/diff', { params: { from, to } }),
+  // params: job_role_id or target; defaults to the IT profile target role / career goals
+  getGaps: (params = {}) => api.get('/profiles/me/gaps', { params }),
 };
 
 export const aiApi = {
@@ -166,4 +284,80 @@ export const aiApi = {
   milestones: (data) => api.post('/ai/milestones', data),
 };
 
+export const analyticsApi = {
+  getSummary: (params = {}) => api.get('/analytics', { params }),
+};
+
+export const reportsApi = {
+  // params: from, to, format (json|markdown|html), summary ('ai' for an AI-written summary)
+  getBragDocument: (params = {}) =>
+    api.get('/reports/brag', { params, responseType: params.format && params.format !== 'json' ? 'text' : 'json' }),
+};
+
+export const schemaApi = {
+  getITProfile: () => api.get('/schemas/it-profile'),
+};
+
+export const skillsApi = {
+  getCatalog: (params = {}) => api.get('/skills', { params }),
+  getMine: () => api.get('/skills/me'),
+  getHistory: (params = {}) => api.get('/skills/me/history', { params }),
+  setMine: (skillId, data) => api.put(`/skills/me/${skillId}`, data),
+};
+
+export const certificationsApi = {
+  getCatalog: (params = {}) => api.get('/certifications', { params }),
+  getMine: () => api.get('/certifications/me'),
+  add: (data) => api.post('/certifications/me', data),
+  update: (id, data) => api.put(`/certifications/me/${id}`, data),
+  remove: (id) => api.delete(`/certifications/me/${id}`),
+  renew: (id, data = {}) => api.post(`/certifications/me/${id}/renew`, data),
+  getCredits: (id) => api.get(`/certifications/me/${id}/credits`),
+  addCredit: (id, data) => api.post(`/certifications/me/${id}/credits`, data),
+  removeCredit: (id, creditId) => api.delete(`/certifications/me/${id}/credits/${creditId}`),
+  getReminders: (params = {}) => api.get('/certifications/reminders', { params }),
+  dismissReminder: (id) => api.post(`/certifications/reminders/${id}/dismiss`),
+};
+
+export const plannerApi = {
+  // params: hours (what-if override of available_hours_week)
+  getPlan: (params = {}) => api.get('/planner', { params }),
+  // Writes planned milestone due dates; also used to re-plan after a slip
+  apply: (goalIds = [], params = {}) =>
+    api.post('/planner/apply', goalIds.length ? { goal_ids: goalIds } : {}, { params }),
+  getBlocked: (params = {}) => api.get('/planner/blocked', { params }),
+  addBlocked: (data) => api.post('/planner/blocked', data),
+  removeBlocked: (id) => api.delete(`/planner/blocked/${id}`),
+};
+
+export const accountApi = {
+  // format: json or zip (one file per table)
+  exportData: (format = 'json') => api.get('/account/export', { params: { format }, responseType: 'blob' }),
+  getErasure: () => api.get('/account/erasure'),
+  requestErasure: (reason = '') => api.post('/account/erasure', { confirm: 'ERASE', reason }),
+  cancelErasure: () => api.delete('/account/erasure'),
+  // Pending policy versions plus the full consent ledger
+  getConsents: () => api.get('/account/consents'),
+  // version defaults to the one in effect
+  acceptPolicy: (kind, version) => api.post('/account/consents', version ? { kind, version } : { kind }),
+};
+
+// Protected routes answer 403 with code policy_acceptance_required and the
+// list of policies to accept until accountApi.acceptPolicy is called.
+export const policiesApi = {
+  getAll: () => api.get('/policies'),
+  get: (kind, version) => api.get(`/policies/${kind}/${encodeURIComponent(version)}`),
+};
+
+export const cyclesApi = {
+  getAll: () => api.get('/cycles'),
+  getById: (id) => api.get(`/cycles/${id}`),
+  create: (data) => api.post('/cycles', data),
+  update: (id, data) => api.put(`/cycles/${id}`, data),
+  attachGoals: (id, goalIds) => api.post(`/cycles/${id}/goals`, { goal_ids: goalIds }),
+  getGrading: (id) => api.get(`/cycles/${id}/grading`),
+  saveGrades: (id, grades) => api.put(`/cycles/${id}/grades`, { grades }),
+  close: (id, data) => api.post(`/cycles/${id}/close`, data),
+};
+
 export default api;
\ No newline at end of file